@@ -0,0 +1,157 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/UnknownOlympus/atlas/internal/config"
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/service"
+)
+
+// geocodeCommand is the subcommand that geocodes a single address and exits.
+const geocodeCommand = "geocode"
+
+// Exit codes of the geocode subcommand.
+const (
+	exitGeocoded = 0 // the address was geocoded
+	exitFailed   = 1 // the provider could not be created or found no match
+	exitUsage    = 2 // the arguments are invalid
+)
+
+// providerBuilder creates the geocoding provider of the configuration.
+type providerBuilder func(cfg *config.Config, logger *slog.Logger) (geocoding.Provider, error)
+
+// newGeocodeProvider creates the default provider of the configuration, like the geocoding loop does.
+func newGeocodeProvider(cfg *config.Config, logger *slog.Logger) (geocoding.Provider, error) {
+	provider, err := geocoding.NewProvider(newProviderConfig(cfg, logger))
+	if err != nil {
+		return nil, err
+	}
+
+	return withRegionCentroids(cfg, logger, provider)
+}
+
+// geocodeMain runs the geocode subcommand with the loaded configuration and returns its exit code.
+// An interrupt signal cancels the request in progress.
+func geocodeMain(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return runGeocodeCommand(ctx, config.MustLoad(), args, os.Stdout, os.Stderr, newGeocodeProvider)
+}
+
+// runGeocodeCommand geocodes the address given by args with the provider of cfg, or the one chosen with
+// --provider, and prints the match, including the fallback level used, to stdout. The address is prepared
+// like in the geocoding loop (normalized, prefixed and templated) unless --raw is set, and the provider logs
+// go to stderr, at debug level with --verbose. Neither the database nor any server is touched.
+// It returns the exit code of the subcommand.
+func runGeocodeCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	args []string,
+	stdout, stderr io.Writer,
+	build providerBuilder,
+) int {
+	flags := flag.NewFlagSet(geocodeCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: atlas %s [flags] <address>\n\n", geocodeCommand)
+		flags.PrintDefaults()
+	}
+	providerType := flags.String("provider", cfg.ProviderType,
+		"geocoding provider type to use instead of ATLAS_PROVIDER_TYPE")
+	raw := flags.Bool("raw", false, "send the address as is, without normalization, prefix and template")
+	verbose := flags.Bool("verbose", false, "log the provider requests at debug level")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitGeocoded
+		}
+		return exitUsage
+	}
+
+	address := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(address) == "" {
+		fmt.Fprintln(stderr, "an address to geocode is required")
+		flags.Usage()
+		return exitUsage
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level}))
+
+	provider, err := build(withProviderType(cfg, *providerType), logger)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to create %s provider: %v\n", *providerType, err)
+		return exitFailed
+	}
+
+	if !*raw {
+		address = service.PrepareAddress(service.UkrainianAddressNormalizer{}, cfg.AddrPrefix, cfg.AddressTemplate,
+			address)
+	}
+
+	result, err := geocoding.GeocodeDetailed(ctx, provider, address)
+	if err == nil && result == nil {
+		err = errors.New("geocoding provider returned no coordinates")
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to geocode %q with %s: %v\n", address, *providerType, err)
+		return exitFailed
+	}
+
+	printGeocodeResult(stdout, *providerType, address, result)
+
+	return exitGeocoded
+}
+
+// withProviderType returns the configuration with the default provider replaced by providerType.
+// A provider type configured for routing keeps its own API key, and like the routed providers
+// it doesn't get the extra params and headers of the default provider.
+func withProviderType(cfg *config.Config, providerType string) *config.Config {
+	if providerType == cfg.ProviderType {
+		return cfg
+	}
+
+	overridden := *cfg
+	overridden.ProviderType = providerType
+	overridden.ExtraParams, overridden.ExtraHeaders = nil, nil
+	if apiKey, ok := cfg.RoutedProviders[providerType]; ok {
+		overridden.APIKey = apiKey
+	}
+
+	return &overridden
+}
+
+// printGeocodeResult writes the match of the address, one field per line, leaving out unreported metadata.
+func printGeocodeResult(out io.Writer, providerType, address string, result *models.GeocodeResult) {
+	fmt.Fprintf(out, "provider:          %s\n", providerType)
+	fmt.Fprintf(out, "address:           %s\n", address)
+	fmt.Fprintf(out, "latitude:          %f\n", result.Latitude)
+	fmt.Fprintf(out, "longitude:         %f\n", result.Longitude)
+	fmt.Fprintf(out, "precision:         %s\n", cmp.Or(string(result.Precision), "-"))
+	fmt.Fprintf(out, "fallback level:    %d\n", result.FallbackLevel)
+	if result.FormattedAddress != "" {
+		fmt.Fprintf(out, "formatted address: %s\n", result.FormattedAddress)
+	}
+	if result.PlaceID != "" {
+		fmt.Fprintf(out, "place id:          %s\n", result.PlaceID)
+	}
+	if result.Confidence > 0 {
+		fmt.Fprintf(out, "confidence:        %.2f\n", result.Confidence)
+	}
+}
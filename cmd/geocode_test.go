@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/config"
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// nominatimStub is an HTTP client answering Nominatim searches with responses by query,
+// and an empty result list for any other query.
+type nominatimStub struct {
+	responses map[string]string
+	queries   []string
+}
+
+func (ns *nominatimStub) Do(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query().Get("q")
+	ns.queries = append(ns.queries, query)
+
+	body, ok := ns.responses[query]
+	if !ok {
+		body = `[]`
+	}
+
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+// nominatimBuilder returns a provider builder creating a Nominatim provider that sends its requests to stub.
+func nominatimBuilder(stub *nominatimStub) providerBuilder {
+	return func(_ *config.Config, logger *slog.Logger) (geocoding.Provider, error) {
+		return geocoding.NewNominatimProviderWithClient(stub, logger), nil
+	}
+}
+
+func TestRunGeocodeCommand(t *testing.T) {
+	cfg := &config.Config{ProviderType: "nominatim", AddressTemplate: "{address}, Україна"}
+
+	t.Run("prints the match and the fallback level", func(t *testing.T) {
+		stub := &nominatimStub{responses: map[string]string{
+			"с. Грабовець, вул. Польова, 3": `[{"lat":"49.1234","lon":"24.5678","display_name":"Польова"}]`,
+		}}
+		var stdout, stderr bytes.Buffer
+
+		code := runGeocodeCommand(t.Context(), cfg, []string{"село Грабовець,", "вулиця Польова, 3"},
+			&stdout, &stderr, nominatimBuilder(stub))
+
+		require.Equal(t, exitGeocoded, code, stderr.String())
+		// The address is prepared like in the geocoding loop before the fallbacks are tried
+		assert.Equal(t, "с. Грабовець, вул. Польова, 3, Україна", stub.queries[0])
+		assert.Contains(t, stdout.String(), "address:           с. Грабовець, вул. Польова, 3, Україна\n")
+		assert.Contains(t, stdout.String(), "latitude:          49.123400\n")
+		assert.Contains(t, stdout.String(), "fallback level:    1\n")
+		assert.Contains(t, stdout.String(), "formatted address: Польова\n")
+	})
+
+	t.Run("raw address", func(t *testing.T) {
+		stub := &nominatimStub{responses: map[string]string{"село Грабовець": `[{"lat":"49.1","lon":"24.5"}]`}}
+		var stdout, stderr bytes.Buffer
+
+		code := runGeocodeCommand(t.Context(), cfg, []string{"--raw", "село Грабовець"},
+			&stdout, &stderr, nominatimBuilder(stub))
+
+		require.Equal(t, exitGeocoded, code, stderr.String())
+		assert.Equal(t, []string{"село Грабовець"}, stub.queries)
+		assert.Contains(t, stdout.String(), "fallback level:    0\n")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		stub := &nominatimStub{}
+		var stdout, stderr bytes.Buffer
+
+		code := runGeocodeCommand(t.Context(), cfg, []string{"--raw", "Невідоме"},
+			&stdout, &stderr, nominatimBuilder(stub))
+
+		assert.Equal(t, exitFailed, code)
+		assert.Empty(t, stdout.String())
+		assert.Contains(t, stderr.String(), `failed to geocode "Невідоме" with nominatim`)
+	})
+
+	t.Run("missing address", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := runGeocodeCommand(t.Context(), cfg, nil, &stdout, &stderr, nominatimBuilder(&nominatimStub{}))
+
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr.String(), "an address to geocode is required")
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := runGeocodeCommand(t.Context(), cfg, []string{"--unknown", "Київ"}, &stdout, &stderr,
+			nominatimBuilder(&nominatimStub{}))
+
+		assert.Equal(t, exitUsage, code)
+	})
+}
+
+func TestRunGeocodeCommand_ProviderOverride(t *testing.T) {
+	cfg := &config.Config{
+		ProviderType:    "google",
+		APIKey:          "google-key",
+		RoutedProviders: map[string]string{"here": "here-key"},
+	}
+	coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("routed provider uses its own key", func(t *testing.T) {
+		var built *config.Config
+		provider := mocks.NewProvider(t)
+		provider.On("Geocode", mock.Anything, "м. Київ").Return(&coords, nil).Once()
+		var stdout, stderr bytes.Buffer
+
+		code := runGeocodeCommand(t.Context(), cfg, []string{"--provider", "here", "м. Київ"}, &stdout, &stderr,
+			func(overridden *config.Config, _ *slog.Logger) (geocoding.Provider, error) {
+				built = overridden
+				return provider, nil
+			})
+
+		require.Equal(t, exitGeocoded, code, stderr.String())
+		assert.Equal(t, "here", built.ProviderType)
+		assert.Equal(t, "here-key", built.APIKey)
+		assert.Equal(t, "google", cfg.ProviderType, "the loaded configuration must be left untouched")
+		assert.Contains(t, stdout.String(), "provider:          here\n")
+	})
+
+	t.Run("provider can't be created", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := runGeocodeCommand(t.Context(), cfg, []string{"--provider", "unknown", "м. Київ"}, &stdout, &stderr,
+			newGeocodeProvider)
+
+		assert.Equal(t, exitFailed, code)
+		assert.Contains(t, stderr.String(), "failed to create unknown provider: unsupported provider type: unknown")
+	})
+}
@@ -2,20 +2,34 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"maps"
+	"math"
+	"mime"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/config"
 	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	grpcapi "github.com/UnknownOlympus/atlas/internal/grpc"
 	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
 	"github.com/UnknownOlympus/atlas/internal/repository"
 	"github.com/UnknownOlympus/atlas/internal/service"
+	"github.com/UnknownOlympus/atlas/internal/version"
+	"github.com/UnknownOlympus/atlas/internal/webhook"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
@@ -31,44 +45,93 @@ const (
 
 // main is the entry point of the application.
 func main() {
-	// Create a context that will be canceled when an interrupt signal is received.
-	// This allows for graceful shutdown.
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	// The geocode subcommand geocodes a single address and exits, without the database or the servers,
+	// and the replay-failures subcommand does the same for every address of the failure log.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case geocodeCommand:
+			os.Exit(geocodeMain(os.Args[2:]))
+		case replayCommand:
+			os.Exit(replayMain(os.Args[2:]))
+		}
+	}
+
+	// Shut down in two phases: the first interrupt signal stops polling and lets the current batch finish,
+	// a second one cancels ctx, which aborts the batch and stops the application immediately.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	drainCtx, ctx, stop := shutdownContexts(context.Background(), signals)
 
 	// Load application configuration.
 	cfg := config.MustLoad()
 
-	// Set up the logger based on the environment.
-	logger := setupLogger(cfg.Env)
+	// Set up the logger based on the environment, at the configured level if any.
+	logger := setupLogger(cfg.Env, cfg.LogLevel)
 
 	// Create a separate registry for metrics with exemplar
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(collectors.NewGoCollector())
 	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-	appMetrics := metrics.NewMetrics(reg)
+	appMetrics := metrics.NewMetrics(reg, metrics.WithRequestBuckets(cfg.RequestBuckets))
+
+	// Publish the running build, so behavior changes can be correlated with deploys.
+	build := version.Get()
+	appMetrics.SetBuildInfo(build.Version, build.Commit, build.GoVersion)
+	logger.InfoContext(ctx, "Starting atlas", "version", build.Version, "commit", build.Commit,
+		"go_version", build.GoVersion)
 
 	// Initialize the database connection.
-	dtb, err := repository.NewDatabase(
-		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name,
-	)
+	dtb, err := newDatabase(ctx, cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to DB: %v", err)
 	}
 
 	// Create a new repository instance using the database connection.
-	repo := repository.NewRepository(dtb, logger)
+	// Fetch options restrict tasks to a region, let urgent tasks jump the queue, hold back recently failed tasks
+	// and, if tasks can be routed to additional providers or set their own language, read those of each task.
+	// Tasks an operator skipped are left out if skipping is enabled.
+	// If stale coordinates are refreshed, the time coordinates are stored is recorded with them.
+	// With the claim strategy, tasks are claimed per instance so that replicas don't geocode the same tasks.
+	repoOpts := []repository.Option{
+		repository.WithFetchOptions(repository.FetchOptions{
+			Region:             cfg.TaskRegion,
+			ByPriority:         cfg.TaskPriority,
+			MinAttemptInterval: cfg.AttemptInterval,
+			PreferredProvider:  len(cfg.RoutedProviders) > 0,
+			Language:           cfg.TaskLanguage,
+			SkipFlagged:        cfg.TaskSkip,
+		}),
+		repository.WithCacheTTL(cfg.GeocodeCacheTTL),
+		repository.WithNegativeCacheTTL(cfg.NegativeCacheTTL),
+		repository.WithNotFoundStatus(cfg.NotFoundStatus),
+		repository.WithGeohash(cfg.GeohashPrecision),
+		repository.WithGeometryColumn(cfg.GeometryColumn),
+		repository.WithGeocodedAt(cfg.StaleAfter > 0),
+	}
+	if cfg.TaskLock == "claim" {
+		repoOpts = append(repoOpts, repository.WithTaskClaim(instanceID(), cfg.TaskLockTTL))
+	}
+	// Pending tasks are read from the replica if one is configured, while writes go to the primary.
+	if cfg.ReadDatabaseURL != "" {
+		readDB, readErr := repository.NewDatabaseFromURL(ctx, cfg.ReadDatabaseURL, poolOptions(cfg.Database)...)
+		if readErr != nil {
+			log.Fatalf("Failed to connect to the read replica: %v", readErr)
+		}
+		repoOpts = append(repoOpts, repository.WithReadDatabase(readDB, cfg.ReplicaMaxLag))
+	}
+	repo := repository.NewRepository(dtb, logger, repoOpts...)
 
 	// Create geocoding provider using factory pattern based on configuration
 	// This allows runtime selection between different providers (Google, Visicom, Nominatim, etc.)
-	rateLimit := 50
-	providerConfig := geocoding.ProviderConfig{
-		Type:      geocoding.ProviderType(cfg.ProviderType),
-		APIKey:    cfg.APIKey,
-		RateLimit: rateLimit / cfg.Workers,
-		Logger:    logger,
+	// Tasks with a preferred provider are routed to one of the additional providers, built like the default one.
+	// Provider requests and responses are recorded, if enabled, to diagnose why addresses fail.
+	recording, err := setupRecording(cfg.DebugRecording)
+	if err != nil {
+		log.Fatalf("Failed to set up provider recording: %v", err)
 	}
 
-	geoProvider, err := geocoding.NewProvider(providerConfig)
+	geoProvider, routedProviders, err := newProviders(ctx, cfg, logger, appMetrics, recording)
 	if err != nil {
 		log.Fatalf("Failed to create geocoding provider: %v", err)
 	}
@@ -76,74 +139,456 @@ func main() {
 
 	logger.InfoContext(ctx, "Geocoding provider initialized", "type", cfg.ProviderType)
 
+	// Set up the audit sink for geocoding results, separate from the application logger.
+	auditLogger, err := setupAuditLogger(cfg.AuditLog)
+	if err != nil {
+		log.Fatalf("Failed to set up audit log: %v", err)
+	}
+
+	// Failed attempts are appended to the failure log, if any, to be replayed with the replay-failures subcommand.
+	failureLog, err := setupFailureLog(cfg.FailureLog)
+	if err != nil {
+		log.Fatalf("Failed to set up failure log: %v", err)
+	}
+
 	// Init a new geocode service using the geo provider.
+	serviceOpts := []service.Option{
+		service.WithAuditLogger(auditLogger),
+		service.WithWorkerStagger(cfg.WorkerStagger),
+		service.WithDryRun(cfg.DryRun),
+		service.WithPollJitter(cfg.PollJitter),
+		service.WithAdaptivePolling(cfg.IntervalFloor, cfg.IntervalCeiling),
+		service.WithImmediatePoll(cfg.ImmediatePoll),
+		service.WithPipeline(cfg.PipelineDepth),
+		service.WithPollTimeout(cfg.PollTimeout),
+		service.WithProviders(routedProviders),
+		service.WithMaxConcurrentRequests(cfg.MaxConcurrentRequests),
+		service.WithAdaptiveConcurrency(cfg.AdaptiveConcurrency),
+		service.WithFailFastUnauthorized(cfg.FailFastUnauthorized),
+		service.WithRequestBudget(cfg.RequestBudget),
+		service.WithTaskTimeout(cfg.TaskTimeout),
+		service.WithAddressTemplate(cfg.AddressTemplate),
+		service.WithAddressRewrite(cfg.RewriteAddress),
+		service.WithStructuredAddresses(cfg.AddressFormat == "json"),
+		service.WithServiceArea(cfg.ServiceArea),
+		service.WithStaleRefresh(cfg.StaleAfter),
+		service.WithNotFoundStatus(cfg.NotFoundStatus),
+	}
+	if failureLog != nil {
+		serviceOpts = append(serviceOpts, service.WithFailureLog(failureLog))
+	}
+	// Tasks left for the next poll give up their claim at the end of the poll instead of waiting for it to expire.
+	if cfg.TaskLock == "claim" {
+		serviceOpts = append(serviceOpts, service.WithClaimReleaser(repo))
+	}
+	// The geocode cache lives in the same database, so it is shared by all replicas.
+	if cfg.GeocodeCache {
+		serviceOpts = append(serviceOpts, service.WithGeocodeCache(repo))
+	}
+	// Geocoded coordinates are converted to their what3words address for field apps, if enabled.
+	if cfg.What3Words {
+		serviceOpts = append(serviceOpts, service.WithWhat3Words(geocoding.NewWhat3WordsClient(cfg.What3WordsKey, logger)))
+	}
+	// Completed tasks are posted to the webhook, if any, from a bounded queue, so a slow endpoint never holds up
+	// geocoding.
+	var notifier *webhook.Notifier
+	if cfg.WebhookURL != "" {
+		notifier = newWebhookNotifier(cfg, logger, appMetrics)
+		serviceOpts = append(serviceOpts, service.WithCompletionNotifier(notifier))
+	}
+
+	// The database operations of the geocoding loop are timed, so a slow database shows up next to the provider.
 	geoService := service.NewGeocodingServie(
 		logger,
-		repo,
+		repository.NewInstrumentedRepository(repo, observeDBOperation(appMetrics)),
 		geoProvider,
 		cfg.ProviderType, // Provider name for metrics
 		appMetrics,
 		cfg.Workers,
 		cfg.Interval,
 		cfg.AddrPrefix,
+		serviceOpts...,
 	)
 
+	if cfg.DryRun {
+		logger.WarnContext(ctx, "Dry run enabled, geocoding results will not be written to the database")
+	}
+
 	// Log that the application has started.
 	logger.InfoContext(ctx, "Application started. Press Ctrl+C to stop.")
 
+	// Create a cached provider health probe, unless it is disabled by configuration.
+	var healthProbe *geocoding.HealthProbe
+	if cfg.ProviderHealthTTL > 0 {
+		healthProbe = geocoding.NewHealthProbe(geoProvider, cfg.ProviderHealthTTL)
+	}
+
+	// The gRPC port is bound before the service starts, so a port conflict fails startup before any task is processed.
+	// Port 0 disables the gRPC server.
+	var grpcListener net.Listener
+	if cfg.GRPCPort != 0 {
+		grpcListener, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port: %v", err)
+		}
+	}
+
 	// Start the monitoring server in a goroutine to allow main to listen for signals.
-	go startMonitoringServer(ctx, logger, reg, dtb, cfg.Port)
+	geocodeProvider := &currentProvider{provider: geoProvider}
+	if cfg.HealthEnabled {
+		addr := net.JoinHostPort(cfg.HealthAddr, strconv.Itoa(cfg.Port))
+		mux := newMonitoringMux(ctx, logger, reg, dtb, repo, healthProbe, geocodeProvider)
+		go startMonitoringServer(ctx, logger, mux, addr)
+	} else {
+		logger.InfoContext(ctx,
+			"Monitoring server disabled, health, metrics, reprocess, skip and geocode endpoints are unavailable")
+	}
+
+	// The queued notifications are still posted on shutdown, unless the second signal aborts them.
+	webhookDone := make(chan struct{})
+	go func() {
+		defer close(webhookDone)
+		if notifier != nil {
+			notifier.Run(ctx)
+		}
+	}()
 
-	go geoService.Run(ctx)
+	serviceDone := make(chan struct{})
+	go func() {
+		defer close(serviceDone)
+		geoService.RunUntil(ctx, drainCtx.Done())
+	}()
 
-	// Wait for the context to be canceled (e.g., by Ctrl+C).
-	<-ctx.Done()
+	// Start the gRPC server for synchronous geocoding requests on the listener opened before the service.
+	var grpcServer *grpcapi.Server
+	grpcDone := make(chan struct{})
+	if grpcListener != nil {
+		grpcServer = grpcapi.NewServer(geoProvider, logger)
+		go func() {
+			defer close(grpcDone)
+			if serveErr := grpcapi.Serve(drainCtx, logger, grpcServer, grpcListener); serveErr != nil {
+				logger.ErrorContext(ctx, "gRPC server stopped with error", "error", serveErr)
+			}
+		}()
+	} else {
+		close(grpcDone)
+		logger.InfoContext(ctx, "gRPC server disabled")
+	}
+
+	// Rebuild the providers on SIGHUP, so API keys can be rotated and providers switched without a restart.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go reloadProviders(ctx, logger, hup, appMetrics, recording,
+		func(reloaded *config.Config, provider geocoding.Provider, routed map[string]geocoding.Provider) {
+			geoService.SetProviders(provider, reloaded.ProviderType, routed)
+			if grpcServer != nil {
+				grpcServer.SetProvider(provider)
+			}
+			geocodeProvider.Set(provider)
+			if healthProbe != nil {
+				healthProbe.SetProvider(provider)
+			}
+		})
+
+	// Wait for the first shutdown signal (e.g., Ctrl+C).
+	<-drainCtx.Done()
 
 	// Log that a shutdown signal has been received.
-	logger.InfoContext(ctx, "Shutdown signal received. Stopping application...")
+	logger.InfoContext(ctx,
+		"Shutdown signal received. Finishing the current batch, send the signal again to stop immediately...")
+
+	// Wait for the current batch and in-flight gRPC requests to complete.
+	<-serviceDone
+	<-grpcDone
+	if notifier != nil {
+		notifier.Close()
+	}
+	<-webhookDone
 
 	// Log graceful shutdown completion.
 	logger.InfoContext(ctx, "Application stopped gracefully.")
 }
 
-// startMonitoringServer starts an HTTP server that provides health check and metrics endpoints.
-// It listens on the specified port and logs the server's status and any errors encountered.
-//
-// Parameters:
-// - ctx: A context.Context for managing cancellation and timeouts.
-// - log: A logger for logging server events and errors.
-// - reg: A registry with Prometheus collectors.
-// - dtb: A pgxpool connector for database methods (ping)
-// - port: The port number on which the server will listen.
-func startMonitoringServer(
+// shutdownContexts returns the contexts of a two-phase shutdown, derived from parent: drain is canceled
+// on the first signal received on signals, to stop starting new work, and force is canceled on the second
+// one, to abort the work in progress. Calling cancel releases both contexts.
+func shutdownContexts(
+	parent context.Context,
+	signals <-chan os.Signal,
+) (drain context.Context, force context.Context, cancel context.CancelFunc) {
+	drain, cancelDrain := context.WithCancel(parent)
+	force, cancelForce := context.WithCancel(parent)
+
+	go func() {
+		for _, phase := range []context.CancelFunc{cancelDrain, cancelForce} {
+			select {
+			case <-signals:
+				phase()
+			case <-force.Done():
+				return
+			}
+		}
+	}()
+
+	return drain, force, func() {
+		cancelForce()
+		cancelDrain()
+	}
+}
+
+// newProviders creates the default geocoding provider and the additional providers that tasks can be routed to.
+// Provider request retries are counted in appMetrics by provider and reason, and so are the searches
+// of each Nominatim geocoding call. The provider requests are recorded to recording unless it is nil.
+func newProviders(
+	ctx context.Context,
+	cfg *config.Config,
+	logger *slog.Logger,
+	appMetrics *metrics.Metrics,
+	recording io.Writer,
+) (geocoding.Provider, map[string]geocoding.Provider, error) {
+	providerConfig := newProviderConfig(cfg, logger)
+	providerConfig.Recording = recording
+	providerConfig.OnRetry = func(provider geocoding.ProviderType, reason string) {
+		appMetrics.ProviderRetries.WithLabelValues(string(provider), reason).Inc()
+	}
+	providerConfig.OnFallback = func(attempts int) {
+		appMetrics.NominatimFallbacks.Observe(float64(attempts))
+	}
+
+	provider, info, err := geocoding.NewProviderWithInfo(providerConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.DebugContext(ctx, "Geocoding provider created", "type", info.Name, "reverse", info.SupportsReverse,
+		"batch", info.SupportsBatch, "rate_limited", info.RateLimited)
+
+	routed, err := newRoutedProviders(cfg, providerConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider, err = withRoutingRules(cfg, logger, provider, routed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider, err = withRegionCentroids(cfg, logger, provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return provider, routed, nil
+}
+
+// withRoutingRules returns a provider dispatching addresses by the routing rules of the configuration
+// to the default and the routed providers, or provider itself if no rules file is configured.
+func withRoutingRules(
+	cfg *config.Config,
+	logger *slog.Logger,
+	provider geocoding.Provider,
+	routed map[string]geocoding.Provider,
+) (geocoding.Provider, error) {
+	if cfg.RoutingRules == "" {
+		return provider, nil
+	}
+
+	rules, err := geocoding.LoadRoutingRules(cfg.RoutingRules)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := maps.Clone(routed)
+	providers[cfg.ProviderType] = provider
+
+	routing, err := geocoding.NewRoutingProvider(rules, providers, provider, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return routing, nil
+}
+
+// withRegionCentroids returns the provider followed by a region centroid fallback if a centroid file
+// is configured, so addresses the provider can't find get the centroid of their district or region.
+func withRegionCentroids(
+	cfg *config.Config,
+	logger *slog.Logger,
+	provider geocoding.Provider,
+) (geocoding.Provider, error) {
+	if cfg.RegionCentroids == "" {
+		return provider, nil
+	}
+
+	centroids, err := geocoding.LoadRegionCentroids(cfg.RegionCentroids)
+	if err != nil {
+		return nil, err
+	}
+
+	return geocoding.NewChainProvider(logger, provider, geocoding.NewRegionCentroidProvider(centroids, logger)), nil
+}
+
+// newProviderConfig returns the settings of the default geocoding provider.
+func newProviderConfig(cfg *config.Config, logger *slog.Logger) geocoding.ProviderConfig {
+	providerConfig := geocoding.ProviderConfig{
+		Type:            geocoding.ProviderType(cfg.ProviderType),
+		APIKey:          cfg.APIKey,
+		RequestTimeout:  cfg.RequestTimeout,
+		HTTPTimeout:     cfg.HTTPTimeout,
+		MinPrecision:    cfg.MinPrecision,
+		DisableFallback: cfg.DisableFallback,
+		PostalFallback:  cfg.PostalFallback,
+		Structured:      cfg.StructuredSearch,
+		ResultLimit:     cfg.ResultLimit,
+		ResultHint:      cfg.ResultHint,
+		Language:        cfg.Language,
+		Region:          cfg.GoogleRegion,
+		Components:      cfg.GoogleComponents,
+		Logger:          logger,
+		ExtraParams:     cfg.ExtraParams,
+		ExtraHeaders:    cfg.ExtraHeaders,
+		Retries:         cfg.ProviderRetries,
+		RetryBackoff:    cfg.RetryBackoff,
+		ProxyURL:        cfg.ProxyURL,
+	}
+	providerConfig.RateLimit = providerRateLimit(cfg, providerConfig.Type)
+
+	return providerConfig
+}
+
+// reloadProviders reloads the configuration on every signal received on hup until ctx is done,
+// and passes the providers rebuilt from it to apply. Only the provider settings take effect,
+// other settings still require a restart. If the configuration is invalid or a provider can't be
+// created, the error is logged and the current providers are kept.
+func reloadProviders(
 	ctx context.Context,
 	log *slog.Logger,
-	reg *prometheus.Registry,
-	dtb *pgxpool.Pool,
-	port int,
+	hup <-chan os.Signal,
+	appMetrics *metrics.Metrics,
+	recording io.Writer,
+	apply func(cfg *config.Config, provider geocoding.Provider, routed map[string]geocoding.Provider),
 ) {
-	http.HandleFunc("/healthz", func(writer http.ResponseWriter, _ *http.Request) {
-		log.DebugContext(ctx, "Performing health checks...")
-		status, body := http.StatusOK, "OK"
-		if err := dtb.Ping(ctx); err != nil {
-			status, body = http.StatusServiceUnavailable, "DB ping failed"
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
 		}
-		writer.WriteHeader(status)
-		_, err := writer.Write([]byte(body))
+
+		log.InfoContext(ctx, "Reload signal received, rebuilding geocoding providers...")
+		cfg, err := config.Reload()
 		if err != nil {
-			log.ErrorContext(ctx, "failed to write reply", "error", err)
+			log.ErrorContext(ctx, "Failed to reload configuration, keeping current providers", "error", err)
+			continue
 		}
 
-		log.DebugContext(ctx, "Health checks completed", "status", http.StatusOK)
+		provider, routed, err := newProviders(ctx, cfg, log, appMetrics, recording)
+		if err != nil {
+			log.ErrorContext(ctx, "Failed to rebuild geocoding providers, keeping current providers", "error", err)
+			continue
+		}
+
+		apply(cfg, provider, routed)
+		log.InfoContext(ctx, "Geocoding providers reloaded", "type", cfg.ProviderType,
+			"routed", len(routed))
+	}
+}
+
+// observeDBOperation returns an observer recording the database operations in appMetrics.
+func observeDBOperation(appMetrics *metrics.Metrics) repository.OperationObserver {
+	return func(operation, outcome string, elapsed time.Duration) {
+		appMetrics.DBOperationSeconds.WithLabelValues(operation, outcome).Observe(elapsed.Seconds())
+	}
+}
+
+// newWebhookNotifier creates the notifier posting completed tasks to the configured webhook.
+// The outcome of every notification is counted in appMetrics.
+func newWebhookNotifier(cfg *config.Config, logger *slog.Logger, appMetrics *metrics.Metrics) *webhook.Notifier {
+	return webhook.NewNotifier(webhook.Config{
+		URL:       cfg.WebhookURL,
+		Secret:    cfg.WebhookSecret,
+		QueueSize: cfg.WebhookQueueSize,
+		Retries:   cfg.WebhookRetries,
+		Logger:    logger,
+		OnOutcome: func(outcome string) {
+			appMetrics.WebhookEvents.WithLabelValues(outcome).Inc()
+		},
 	})
-	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+}
+
+// newDatabase connects to the database at DATABASE_URL if it is set, or at the discrete DB_* settings otherwise.
+// Either way, the connection pool is tuned with the DB_* pool settings.
+func newDatabase(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	poolOpts := poolOptions(cfg.Database)
+	if cfg.DatabaseURL != "" {
+		return repository.NewDatabaseFromURL(ctx, cfg.DatabaseURL, poolOpts...)
+	}
 
-	log.InfoContext(ctx, "Starting monitoring server", "port", port)
+	return repository.NewDatabase(
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name,
+		poolOpts...,
+	)
+}
+
+// poolOptions returns the connection pool tuning of the database configuration.
+func poolOptions(db config.PostgresConfig) []repository.PoolOption {
+	return []repository.PoolOption{
+		repository.WithMaxConns(db.MaxConns),
+		repository.WithMinConns(db.MinConns),
+		repository.WithMaxConnLifetime(db.Lifetime),
+		repository.WithMaxConnIdleTime(db.IdleTime),
+	}
+}
+
+// providerRateLimit returns the configured global rate limit of the provider type,
+// or zero to use the provider's default.
+func providerRateLimit(cfg *config.Config, providerType geocoding.ProviderType) int {
+	switch providerType {
+	case geocoding.ProviderTypeGoogle:
+		return cfg.GoogleRateLimit
+	case geocoding.ProviderTypeLocationIQ:
+		return cfg.LocationIQLimit
+	default:
+		return 0
+	}
+}
+
+// newRoutedProviders creates the additional providers that tasks can be routed to, keyed by provider type.
+// Each provider shares the settings of base, with its own type, API key and rate limit.
+func newRoutedProviders(cfg *config.Config, base geocoding.ProviderConfig) (map[string]geocoding.Provider, error) {
+	providers := make(map[string]geocoding.Provider, len(cfg.RoutedProviders))
+	for providerType, apiKey := range cfg.RoutedProviders {
+		routedConfig := base
+		routedConfig.Type = geocoding.ProviderType(providerType)
+		routedConfig.APIKey = apiKey
+		routedConfig.RateLimit = providerRateLimit(cfg, routedConfig.Type)
+		// Extra params and headers are specific to the API of the default provider
+		routedConfig.ExtraParams, routedConfig.ExtraHeaders = nil, nil
+
+		provider, err := geocoding.NewProvider(routedConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s provider: %w", providerType, err)
+		}
+		providers[providerType] = provider
+	}
+
+	return providers, nil
+}
+
+// pinger is the part of the database connection used by the readiness check.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// startMonitoringServer starts an HTTP server serving the given monitoring handler.
+// It listens on the specified address and logs the server's status and any errors encountered.
+func startMonitoringServer(ctx context.Context, log *slog.Logger, handler http.Handler, addr string) {
+	log.InfoContext(ctx, "Starting monitoring server", "addr", addr)
 	readTimeout := 5
 	writeTimeout := 10
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
-		Handler:      http.DefaultServeMux,
+		Addr:         addr,
+		Handler:      handler,
 		ReadTimeout:  time.Duration(readTimeout) * time.Second,
 		WriteTimeout: time.Duration(writeTimeout) * time.Second,
 	}
@@ -152,15 +597,420 @@ func startMonitoringServer(
 	}
 }
 
+// newMonitoringMux returns a private mux with the liveness, readiness, version, metrics, reprocessing,
+// skip and geocode endpoints, so that nothing registered on http.DefaultServeMux by a dependency is exposed
+// by the monitoring server.
+//
+// Parameters:
+// - ctx: A context.Context for managing cancellation and timeouts.
+// - log: A logger for logging server events and errors.
+// - reg: A registry with Prometheus collectors.
+// - dtb: A database connection used by the readiness check (ping)
+// - repo: A repository used to reset failed tasks for reprocessing and to skip tasks
+// - probe: A cached geocoding provider health probe used by the readiness check (nil disables it)
+// - provider: The default geocoding provider used to geocode addresses on demand
+func newMonitoringMux(
+	ctx context.Context,
+	log *slog.Logger,
+	reg *prometheus.Registry,
+	dtb pinger,
+	repo repository.Interface,
+	probe *geocoding.HealthProbe,
+	provider *currentProvider,
+) *http.ServeMux {
+	mux := http.NewServeMux()
+	// Liveness only reports that the process serves requests, so a database or provider outage
+	// makes the pod unready instead of getting it restarted.
+	mux.HandleFunc("/healthz", func(writer http.ResponseWriter, _ *http.Request) {
+		if _, err := writer.Write([]byte("OK\n")); err != nil {
+			log.ErrorContext(ctx, "failed to write reply", "error", err)
+		}
+	})
+	mux.HandleFunc("/ready", readyHandler(log, dtb, probe))
+	mux.HandleFunc("/version", versionHandler(log))
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/reprocess", reprocessHandler(log, repo))
+	mux.HandleFunc("/skip", skipHandler(log, repo))
+	mux.HandleFunc("/geocode", geocodeHandler(log, provider))
+
+	return mux
+}
+
+// readyHandler returns a readiness handler that reports 503 Service Unavailable until the database
+// is reachable and the geocoding provider health probe passes, with a status line for each check.
+// A nil probe disables the provider check.
+func readyHandler(log *slog.Logger, dtb pinger, probe *geocoding.HealthProbe) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		log.DebugContext(ctx, "Performing readiness checks...")
+		status, body := http.StatusOK, "DB: OK\n"
+		if err := dtb.Ping(ctx); err != nil {
+			log.WarnContext(ctx, "Database ping failed", "error", err)
+			status, body = http.StatusServiceUnavailable, "DB: ping failed\n"
+		}
+		if probe != nil {
+			if err := probe.Check(ctx); err != nil {
+				log.WarnContext(ctx, "Geocoding provider health check failed", "error", err)
+				status, body = http.StatusServiceUnavailable, body+"Provider: unavailable\n"
+			} else {
+				body += "Provider: OK\n"
+			}
+		}
+		writer.WriteHeader(status)
+		if _, err := writer.Write([]byte(body)); err != nil {
+			log.ErrorContext(ctx, "failed to write reply", "error", err)
+		}
+
+		log.DebugContext(ctx, "Readiness checks completed", "status", status)
+	}
+}
+
+// versionHandler returns a handler that replies with the build information of the running binary as JSON.
+func versionHandler(log *slog.Logger) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(writer).Encode(version.Get()); err != nil {
+			log.ErrorContext(req.Context(), "failed to write reply", "error", err)
+		}
+	}
+}
+
+// reprocessRequest is the body of a POST /reprocess request.
+// Exactly one of TaskIDs and AllFailed must be set.
+type reprocessRequest struct {
+	TaskIDs   []int `json:"task_ids"`   // TaskIDs are the tasks whose geocoding attempts are reset
+	AllFailed bool  `json:"all_failed"` // AllFailed resets every task that exhausted its geocoding attempts
+}
+
+// reprocessResponse is the body of a successful POST /reprocess reply.
+type reprocessResponse struct {
+	Reset int64 `json:"reset"` // Reset is the number of tasks queued for geocoding again
+}
+
+// reprocessHandler returns a handler that resets the geocoding attempts of failed tasks,
+// so they are geocoded again on the next poll (e.g. after fixing addresses or switching providers).
+func reprocessHandler(log *slog.Logger, repo repository.Interface) http.HandlerFunc {
+	const maxBodyBytes = 1 << 20
+
+	return func(writer http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		if req.Method != http.MethodPost {
+			writer.Header().Set("Allow", http.MethodPost)
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body reprocessRequest
+		if err := json.NewDecoder(http.MaxBytesReader(writer, req.Body, maxBodyBytes)).Decode(&body); err != nil {
+			http.Error(writer, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if body.AllFailed == (len(body.TaskIDs) > 0) {
+			http.Error(writer, "either task_ids or all_failed must be set", http.StatusBadRequest)
+			return
+		}
+
+		var (
+			reset int64
+			err   error
+		)
+		if body.AllFailed {
+			reset, err = repo.ResetFailedGeocodingAttempts(ctx)
+		} else {
+			reset, err = repo.ResetGeocodingAttempts(ctx, body.TaskIDs)
+		}
+		if err != nil {
+			log.ErrorContext(ctx, "Failed to reset tasks for reprocessing", "error", err)
+			http.Error(writer, "failed to reset tasks", http.StatusInternalServerError)
+			return
+		}
+
+		log.InfoContext(ctx, "Tasks reset for reprocessing", "reset", reset, "all_failed", body.AllFailed)
+
+		writer.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(writer).Encode(reprocessResponse{Reset: reset}); err != nil {
+			log.ErrorContext(ctx, "failed to write reply", "error", err)
+		}
+	}
+}
+
+// skipRequest is the body of a POST /skip request.
+type skipRequest struct {
+	TaskIDs []int `json:"task_ids"` // TaskIDs are the tasks whose geocoding is cancelled
+}
+
+// skipResponse is the body of a successful POST /skip reply.
+type skipResponse struct {
+	Skipped int64 `json:"skipped"` // Skipped is the number of tasks flagged to be skipped
+}
+
+// skipHandler returns a handler that flags tasks to be skipped, so they are no longer geocoded
+// (e.g. after a wrong data import) when ATLAS_TASK_SKIP is enabled.
+func skipHandler(log *slog.Logger, repo repository.Interface) http.HandlerFunc {
+	const maxBodyBytes = 1 << 20
+
+	return func(writer http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		if req.Method != http.MethodPost {
+			writer.Header().Set("Allow", http.MethodPost)
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body skipRequest
+		if err := json.NewDecoder(http.MaxBytesReader(writer, req.Body, maxBodyBytes)).Decode(&body); err != nil {
+			http.Error(writer, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if len(body.TaskIDs) == 0 {
+			http.Error(writer, "task_ids must be set", http.StatusBadRequest)
+			return
+		}
+
+		skipped, err := repo.SkipTaskGeocoding(ctx, body.TaskIDs)
+		if err != nil {
+			log.ErrorContext(ctx, "Failed to skip task geocoding", "error", err)
+			http.Error(writer, "failed to skip tasks", http.StatusInternalServerError)
+			return
+		}
+
+		log.InfoContext(ctx, "Tasks skipped", "skipped", skipped)
+
+		writer.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(writer).Encode(skipResponse{Skipped: skipped}); err != nil {
+			log.ErrorContext(ctx, "failed to write reply", "error", err)
+		}
+	}
+}
+
+// maxGeocodeCandidates is the largest number of candidates a GET /geocode request can ask for.
+const maxGeocodeCandidates = geocoding.MaxNominatimResultLimit
+
+// currentProvider holds the default geocoding provider, which is replaced when the providers are reloaded.
+type currentProvider struct {
+	mu       sync.RWMutex
+	provider geocoding.Provider
+}
+
+// Get returns the provider that serves new requests.
+func (cp *currentProvider) Get() geocoding.Provider {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+	return cp.provider
+}
+
+// Set replaces the provider that serves new requests. Requests in flight finish with the previous provider.
+func (cp *currentProvider) Set(provider geocoding.Provider) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.provider = provider
+}
+
+// geocodeCandidate is one of the matches of a GET /geocode reply.
+type geocodeCandidate struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	Precision        string  `json:"precision,omitempty"`
+	FallbackLevel    int     `json:"fallback_level"`
+	FormattedAddress string  `json:"formatted_address,omitempty"`
+	PlaceID          string  `json:"place_id,omitempty"`
+	Provider         string  `json:"provider,omitempty"`
+	Confidence       float64 `json:"confidence,omitempty"`
+}
+
+// geocodeResponse is the body of a successful GET /geocode reply.
+type geocodeResponse struct {
+	Address    string             `json:"address"`    // Address is the geocoded address, as requested
+	Candidates []geocodeCandidate `json:"candidates"` // Candidates are the matches, best first
+}
+
+// geoJSONMediaType is the media type of a GeoJSON document (RFC 7946).
+const geoJSONMediaType = "application/geo+json"
+
+// geocodeFeature is one of the matches of a GET /geocode reply in GeoJSON, a Feature with a Point geometry.
+type geocodeFeature struct {
+	Type       string                   `json:"type"`
+	Geometry   json.RawMessage          `json:"geometry"`
+	Properties geocodeFeatureProperties `json:"properties"`
+}
+
+// geocodeFeatureProperties are the properties of a geocodeFeature, the match details besides the coordinates.
+type geocodeFeatureProperties struct {
+	Address          string  `json:"address"`
+	Precision        string  `json:"precision,omitempty"`
+	FallbackLevel    int     `json:"fallback_level"`
+	FormattedAddress string  `json:"formatted_address,omitempty"`
+	PlaceID          string  `json:"place_id,omitempty"`
+	Provider         string  `json:"provider,omitempty"`
+	Confidence       float64 `json:"confidence,omitempty"`
+}
+
+// geocodeFeatureCollection is the body of a successful GET /geocode reply in GeoJSON.
+type geocodeFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geocodeFeature `json:"features"`
+}
+
+// geocodeHandler returns a handler that geocodes the address query parameter with the default provider,
+// without touching the database, to check how an address resolves. It replies with the best match, or with
+// up to the number of matches given by the candidates query parameter for an ambiguous address, if the
+// provider can return several (Nominatim and Google); other providers return a single match.
+// A request accepting application/geo+json is replied with a GeoJSON FeatureCollection of the matches.
+// An address the provider can't find is replied with 404 Not Found, and NaN or infinite coordinates,
+// which JSON can't represent, with 502 Bad Gateway.
+func geocodeHandler(log *slog.Logger, provider *currentProvider) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		if req.Method != http.MethodGet {
+			writer.Header().Set("Allow", http.MethodGet)
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := req.URL.Query()
+		address := query.Get("address")
+		if strings.TrimSpace(address) == "" {
+			http.Error(writer, "address must be set", http.StatusBadRequest)
+			return
+		}
+		limit := 1
+		if value := query.Get("candidates"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxGeocodeCandidates {
+				http.Error(writer, fmt.Sprintf("candidates must be between 1 and %d", maxGeocodeCandidates),
+					http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+
+		results, err := geocoding.GeocodeCandidates(ctx, provider.Get(), address, limit)
+		if err == nil && len(results) == 0 {
+			err = errors.New("geocoding provider returned no coordinates")
+		}
+		if err == nil && !finiteResults(results) {
+			// JSON can't represent NaN or infinite coordinates, so they are the provider's fault
+			err = errors.New("geocoding provider returned non-finite coordinates")
+		}
+		if err != nil {
+			log.WarnContext(ctx, "Geocode request failed", "address", address, "error", err)
+			status := geocodeErrorStatus(err)
+			http.Error(writer, http.StatusText(status), status)
+			return
+		}
+
+		if acceptsGeoJSON(req) {
+			writeGeoJSON(ctx, log, writer, address, results)
+			return
+		}
+
+		reply := geocodeResponse{Address: address, Candidates: make([]geocodeCandidate, len(results))}
+		for i, result := range results {
+			reply.Candidates[i] = geocodeCandidate{
+				Latitude:         result.Latitude,
+				Longitude:        result.Longitude,
+				Precision:        string(result.Precision),
+				FallbackLevel:    result.FallbackLevel,
+				FormattedAddress: result.FormattedAddress,
+				PlaceID:          result.PlaceID,
+				Provider:         result.Provider,
+				Confidence:       result.Confidence,
+			}
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(writer).Encode(reply); err != nil {
+			log.ErrorContext(ctx, "failed to write reply", "error", err)
+		}
+	}
+}
+
+// acceptsGeoJSON reports whether the Accept header of the request lists the GeoJSON media type.
+func acceptsGeoJSON(req *http.Request) bool {
+	for _, value := range req.Header.Values("Accept") {
+		for accepted := range strings.SplitSeq(value, ",") {
+			mediaType, _, err := mime.ParseMediaType(accepted)
+			if err == nil && mediaType == geoJSONMediaType {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// finiteResults reports whether the coordinates of every match are neither NaN nor infinite.
+func finiteResults(results []models.GeocodeResult) bool {
+	for _, result := range results {
+		for _, coordinate := range []float64{result.Latitude, result.Longitude} {
+			if math.IsNaN(coordinate) || math.IsInf(coordinate, 0) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// writeGeoJSON replies to a GET /geocode request with the matches as a GeoJSON FeatureCollection.
+func writeGeoJSON(
+	ctx context.Context, log *slog.Logger, writer http.ResponseWriter, address string, results []models.GeocodeResult,
+) {
+	reply := geocodeFeatureCollection{Type: "FeatureCollection", Features: make([]geocodeFeature, len(results))}
+	for i, result := range results {
+		reply.Features[i] = geocodeFeature{
+			Type:     "Feature",
+			Geometry: result.GeoJSON(),
+			Properties: geocodeFeatureProperties{
+				Address:          address,
+				Precision:        string(result.Precision),
+				FallbackLevel:    result.FallbackLevel,
+				FormattedAddress: result.FormattedAddress,
+				PlaceID:          result.PlaceID,
+				Provider:         result.Provider,
+				Confidence:       result.Confidence,
+			},
+		}
+	}
+
+	writer.Header().Set("Content-Type", geoJSONMediaType)
+	if err := json.NewEncoder(writer).Encode(reply); err != nil {
+		log.ErrorContext(ctx, "failed to write reply", "error", err)
+	}
+}
+
+// geocodeErrorStatus maps a provider error to the HTTP status of a GET /geocode reply.
+func geocodeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, geocoding.ErrEmptyResponse),
+		errors.Is(err, geocoding.ErrNominatimEmptyResponse),
+		errors.Is(err, geocoding.ErrVisicomEmptyResponse),
+		errors.Is(err, geocoding.ErrHereEmptyResponse),
+		errors.Is(err, geocoding.ErrBingEmptyResponse),
+		errors.Is(err, geocoding.ErrRegionCentroidNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
 // setupLogger initializes and returns a logger based on the environment provided.
-func setupLogger(env string) *slog.Logger {
+// A non-empty level, such as "debug" or "warn", overrides the level of the environment,
+// while the handler still follows the environment.
+func setupLogger(env, level string) *slog.Logger {
 	var log *slog.Logger
 
 	switch env {
 	case envLocal:
 		log = slog.New(
 			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-				Level:     slog.LevelDebug,
+				Level:     logLevel(slog.LevelDebug, level),
 				AddSource: true,
 				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
 					return a
@@ -170,7 +1020,7 @@ func setupLogger(env string) *slog.Logger {
 	case envDev:
 		log = slog.New(
 			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-				Level:     slog.LevelInfo,
+				Level:     logLevel(slog.LevelInfo, level),
 				AddSource: false,
 				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
 					return a
@@ -180,7 +1030,7 @@ func setupLogger(env string) *slog.Logger {
 	case envProd:
 		log = slog.New(
 			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-				Level:     slog.LevelWarn,
+				Level:     logLevel(slog.LevelWarn, level),
 				AddSource: false,
 				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
 					if a.Key == slog.TimeKey {
@@ -193,7 +1043,7 @@ func setupLogger(env string) *slog.Logger {
 	default:
 		log = slog.New(
 			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-				Level:     slog.LevelError,
+				Level:     logLevel(slog.LevelError, level),
 				AddSource: false,
 				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
 					if a.Key == slog.TimeKey {
@@ -211,3 +1061,86 @@ func setupLogger(env string) *slog.Logger {
 
 	return log
 }
+
+// logLevel returns the level named by override, or fallback if override is empty or not a level name.
+func logLevel(fallback slog.Level, override string) slog.Level {
+	if override == "" {
+		return fallback
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(override)); err != nil {
+		return fallback
+	}
+
+	return level
+}
+
+// setupAuditLogger returns an audit sink writing JSON records to the given destination.
+// An empty destination disables auditing, "stdout" writes to standard output,
+// and any other value is treated as a file path opened in append mode.
+func setupAuditLogger(dest string) (service.AuditLogger, error) {
+	const filePerm = 0o640
+
+	var out io.Writer
+	switch dest {
+	case "":
+		return service.NopAuditLogger{}, nil
+	case "stdout":
+		out = os.Stdout
+	default:
+		file, err := os.OpenFile(dest, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePerm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log file: %w", err)
+		}
+		out = file
+	}
+
+	return service.NewSlogAuditLogger(slog.New(slog.NewJSONHandler(out, nil))), nil
+}
+
+// setupFailureLog returns a failure log appending JSON records to the file at path,
+// or nil if path is empty and the failure log is disabled.
+func setupFailureLog(path string) (service.FailureLog, error) {
+	const filePerm = 0o640
+
+	if path == "" {
+		return nil, nil //nolint:nilnil // a nil failure log disables it
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open failure log file: %w", err)
+	}
+
+	return service.NewJSONFailureLog(file), nil
+}
+
+// setupRecording returns the file at path, opened in append mode, that provider requests and responses are
+// recorded to, or nil if path is empty and recording is disabled. The file is shared by all providers,
+// including those rebuilt on reload.
+func setupRecording(path string) (io.Writer, error) {
+	const filePerm = 0o600
+
+	if path == "" {
+		return nil, nil //nolint:nilnil // a nil recording disables it
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open provider recording file: %w", err)
+	}
+
+	return file, nil
+}
+
+// instanceID returns an identifier of this service instance for task claims.
+// In Kubernetes the hostname is the pod name; the process ID keeps it unique on a shared host.
+func instanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "atlas"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
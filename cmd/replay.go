@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/UnknownOlympus/atlas/internal/config"
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/service"
+)
+
+// replayCommand is the subcommand that geocodes the addresses of the failure log again and exits.
+// It shares the exit codes of the geocode subcommand: exitGeocoded if every address is geocoded now.
+const replayCommand = "replay-failures"
+
+// replayMain runs the replay-failures subcommand with the loaded configuration and returns its exit code.
+// An interrupt signal cancels the replay in progress.
+func replayMain(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return runReplayCommand(ctx, config.MustLoad(), args, os.Stdout, os.Stderr, newGeocodeProvider)
+}
+
+// runReplayCommand geocodes the addresses of the failure log at ATLAS_FAILURE_LOG, or the one given with --log,
+// with the provider of cfg, or the one chosen with --provider, and prints the outcome of each address to stdout,
+// followed by a summary. An address that failed several times is geocoded once, in the language of its task if
+// it has one. Addresses are prepared like in the geocoding loop unless --raw is set, and structured addresses are
+// geocoded field by field. Neither the database nor any server is touched, so a new provider or configuration
+// can be tried on the failures offline.
+// It returns the exit code of the subcommand.
+func runReplayCommand(
+	ctx context.Context,
+	cfg *config.Config,
+	args []string,
+	stdout, stderr io.Writer,
+	build providerBuilder,
+) int {
+	flags := flag.NewFlagSet(replayCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: atlas %s [flags]\n\n", replayCommand)
+		flags.PrintDefaults()
+	}
+	logPath := flags.String("log", cfg.FailureLog, "failure log to replay instead of ATLAS_FAILURE_LOG")
+	providerType := flags.String("provider", cfg.ProviderType,
+		"geocoding provider type to use instead of ATLAS_PROVIDER_TYPE")
+	raw := flags.Bool("raw", false, "send the addresses as is, without normalization, prefix and template")
+	verbose := flags.Bool("verbose", false, "log the provider requests at debug level")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitGeocoded
+		}
+		return exitUsage
+	}
+
+	if *logPath == "" {
+		fmt.Fprintln(stderr, "a failure log to replay is required")
+		flags.Usage()
+		return exitUsage
+	}
+
+	records, err := readFailureLog(*logPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level}))
+
+	provider, err := build(withProviderType(cfg, *providerType), logger)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to create %s provider: %v\n", *providerType, err)
+		return exitFailed
+	}
+
+	type replayKey struct{ language, address string }
+	replayed := make(map[replayKey]bool, len(records))
+	var geocoded, failed int
+	for _, record := range records {
+		address := record.Address
+		if record.Structured != nil {
+			address = record.Structured.String()
+		} else if !*raw {
+			address = service.PrepareAddress(service.UkrainianAddressNormalizer{}, cfg.AddrPrefix,
+				cfg.AddressTemplate, address)
+		}
+		key := replayKey{language: record.Language, address: address}
+		if replayed[key] {
+			continue
+		}
+		replayed[key] = true
+
+		result, replayErr := replayFailure(geocoding.WithLanguage(ctx, record.Language), provider, record.Structured,
+			address)
+		if replayErr != nil {
+			failed++
+			fmt.Fprintf(stdout, "failed   %q: %v (was: %s)\n", address, replayErr, record.Error)
+			continue
+		}
+
+		geocoded++
+		fmt.Fprintf(stdout, "geocoded %q: %f,%f (level %d)\n", address, result.Latitude, result.Longitude,
+			result.FallbackLevel)
+	}
+
+	fmt.Fprintf(stdout, "replayed %d addresses with %s: %d geocoded, %d failed\n", len(replayed), *providerType,
+		geocoded, failed)
+
+	if failed > 0 {
+		return exitFailed
+	}
+
+	return exitGeocoded
+}
+
+// readFailureLog reads the failure records of the failure log file at path.
+func readFailureLog(path string) ([]service.FailureRecord, error) {
+	file, err := os.Open(path) //nolint:gosec // the path is set by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to open failure log: %w", err)
+	}
+	defer file.Close()
+
+	return service.ReadFailureLog(file)
+}
+
+// replayFailure geocodes the address of a failure record, field by field if it is structured.
+func replayFailure(
+	ctx context.Context,
+	provider geocoding.Provider,
+	structured *models.StructuredAddress,
+	address string,
+) (*models.GeocodeResult, error) {
+	var result *models.GeocodeResult
+	var err error
+	if structured != nil {
+		result, err = geocoding.GeocodeStructured(ctx, provider, *structured)
+	} else {
+		result, err = geocoding.GeocodeDetailed(ctx, provider, address)
+	}
+	if err == nil && result == nil {
+		err = errors.New("geocoding provider returned no coordinates")
+	}
+
+	return result, err
+}
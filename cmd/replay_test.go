@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFailureLog writes the failure log lines to a temporary file and returns its path.
+func writeFailureLog(t *testing.T, lines ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "failures.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+
+	return path
+}
+
+func TestRunReplayCommand(t *testing.T) {
+	path := writeFailureLog(t,
+		`{"task_id":1,"address":"село Грабовець, вулиця Польова, 3","provider":"google","error":"not found"}`,
+		`{"task_id":2,"address":"Невідоме","provider":"google","error":"not found"}`,
+		// The same address failing again is replayed once
+		`{"task_id":3,"address":"село Грабовець, вулиця Польова, 3","provider":"google","error":"not found"}`,
+	)
+	cfg := &config.Config{ProviderType: "nominatim", AddressTemplate: "{address}, Україна", FailureLog: path}
+
+	t.Run("replays every address once", func(t *testing.T) {
+		stub := &nominatimStub{responses: map[string]string{
+			"с. Грабовець, вул. Польова, 3, Україна": `[{"lat":"49.1234","lon":"24.5678"}]`,
+		}}
+		var stdout, stderr bytes.Buffer
+
+		code := runReplayCommand(t.Context(), cfg, nil, &stdout, &stderr, nominatimBuilder(stub))
+
+		assert.Equal(t, exitFailed, code, stderr.String())
+		assert.Contains(t, stdout.String(),
+			`geocoded "с. Грабовець, вул. Польова, 3, Україна": 49.123400,24.567800 (level 0)`)
+		assert.Contains(t, stdout.String(), `failed   "Невідоме, Україна"`)
+		assert.Contains(t, stdout.String(), "(was: not found)")
+		assert.Contains(t, stdout.String(), "replayed 2 addresses with nominatim: 1 geocoded, 1 failed\n")
+	})
+
+	t.Run("every address geocoded", func(t *testing.T) {
+		stub := &nominatimStub{responses: map[string]string{"Невідоме": `[{"lat":"50.1","lon":"30.1"}]`}}
+		onlyUnknown := writeFailureLog(t, `{"task_id":2,"address":"Невідоме","provider":"google","error":"x"}`)
+		var stdout, stderr bytes.Buffer
+
+		code := runReplayCommand(t.Context(), cfg, []string{"--log", onlyUnknown, "--raw"}, &stdout, &stderr,
+			nominatimBuilder(stub))
+
+		require.Equal(t, exitGeocoded, code, stderr.String())
+		assert.Equal(t, []string{"Невідоме"}, stub.queries)
+		assert.Contains(t, stdout.String(), "1 geocoded, 0 failed")
+	})
+
+	t.Run("structured address", func(t *testing.T) {
+		stub := &nominatimStub{responses: map[string]string{"Київ, Хрещатик, 1": `[{"lat":"50.45","lon":"30.52"}]`}}
+		structured := writeFailureLog(t,
+			`{"task_id":4,"structured":{"city":"Київ","street":"Хрещатик","house_number":"1"},"error":"x"}`)
+		var stdout, stderr bytes.Buffer
+
+		code := runReplayCommand(t.Context(), cfg, []string{"--log", structured}, &stdout, &stderr,
+			nominatimBuilder(stub))
+
+		require.Equal(t, exitGeocoded, code, stderr.String())
+		assert.Contains(t, stdout.String(), `geocoded "Київ, Хрещатик, 1": 50.450000,30.520000`)
+	})
+
+	t.Run("missing log", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := runReplayCommand(t.Context(), &config.Config{ProviderType: "nominatim"}, nil, &stdout, &stderr,
+			nominatimBuilder(&nominatimStub{}))
+
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr.String(), "a failure log to replay is required")
+	})
+
+	t.Run("unreadable log", func(t *testing.T) {
+		invalid := writeFailureLog(t, `{"task_id":`)
+		var stdout, stderr bytes.Buffer
+
+		code := runReplayCommand(t.Context(), cfg, []string{"--log", invalid}, &stdout, &stderr,
+			nominatimBuilder(&nominatimStub{}))
+
+		assert.Equal(t, exitFailed, code)
+		assert.Empty(t, stdout.String())
+		assert.Contains(t, stderr.String(), "failed to decode failure record on line 1")
+	})
+}
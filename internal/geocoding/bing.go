@@ -0,0 +1,250 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"golang.org/x/time/rate"
+)
+
+// BingBaseURL -- Bing Maps Locations REST API endpoint.
+const BingBaseURL = "https://dev.virtualearth.net/REST/v1/Locations"
+
+// BingProvider implements geocoding using Bing Maps Locations REST API.
+type BingProvider struct {
+	client  HTTPClient    // HTTP client for making requests
+	baseURL string        // Base URL for the Bing Locations endpoint
+	apiKey  string        // Bing Maps key
+	log     *slog.Logger  // Logger for logging operations
+	limiter *rate.Limiter // Rate limiter
+	timeout time.Duration // Overall deadline for a single Geocode call
+}
+
+// BingOption configures optional behavior of the BingProvider.
+type BingOption func(*BingProvider)
+
+// WithBingRequestTimeout sets the overall deadline for a single Geocode call.
+func WithBingRequestTimeout(timeout time.Duration) BingOption {
+	return func(bp *BingProvider) {
+		bp.timeout = timeout
+	}
+}
+
+// Common errors for Bing provider.
+var (
+	ErrBingEmptyResponse = errors.New("bing API returned empty response")
+	ErrBingEmptyAddress  = errors.New("bing provider got empty address")
+	ErrBingInvalidCoords = errors.New("bing API returned invalid coordinates")
+	ErrBingUnauthorized  = errors.New("bing API unauthorized (invalid API key)")
+)
+
+// Bing API response (simplified for geocoding use-case).
+type bingResponse struct {
+	ResourceSets []struct {
+		Resources []struct {
+			EntityType string `json:"entityType"` // Entity type, e.g. "Address", "RoadBlock", "PopulatedPlace"
+			Confidence string `json:"confidence"` // Match confidence: "High", "Medium" or "Low"
+			Address    struct {
+				FormattedAddress string `json:"formattedAddress"` // Formatted address of the resource
+			} `json:"address"`
+			Point struct {
+				Coordinates []float64 `json:"coordinates"` // [lat, lon], unlike Visicom's [lon, lat]
+			} `json:"point"`
+		} `json:"resources"`
+	} `json:"resourceSets"`
+}
+
+// bingPrecision maps the entityType of a Bing resource to a precision level.
+func bingPrecision(entityType string) models.Precision {
+	switch entityType {
+	case "Address":
+		return models.PrecisionRooftop
+	case "RoadBlock", "RoadIntersection", "Road":
+		return models.PrecisionStreet
+	case "PopulatedPlace", "Neighborhood", "Postcode1":
+		return models.PrecisionLocality
+	case "AdminDivision1", "AdminDivision2", "CountryRegion":
+		return models.PrecisionRegion
+	default:
+		return models.PrecisionApproximate
+	}
+}
+
+// bingConfidence maps the confidence of a Bing resource to a score between 0 and 1.
+// Bing only reports three levels, so the scores are coarse.
+func bingConfidence(confidence string) float64 {
+	switch confidence {
+	case "High":
+		return 1
+	case "Medium":
+		return 0.5
+	case "Low":
+		return 0.25
+	default:
+		return 0
+	}
+}
+
+// NewBingProvider creates a new Bing geocoding provider using the shared HTTP transport.
+func NewBingProvider(apiKey string, rateLimit int, log *slog.Logger, opts ...BingOption) *BingProvider {
+	return NewBingProviderWithClient(
+		newHTTPClient(nil, DefaultHTTPTimeout),
+		apiKey,
+		rate.NewLimiter(rate.Limit(rateLimit), rateLimit),
+		log,
+		opts...,
+	)
+}
+
+// NewBingProviderWithClient allows injecting custom HTTP client.
+func NewBingProviderWithClient(
+	client HTTPClient,
+	apiKey string,
+	limiter *rate.Limiter,
+	log *slog.Logger,
+	opts ...BingOption,
+) *BingProvider {
+	bp := &BingProvider{
+		client:  client,
+		baseURL: BingBaseURL,
+		apiKey:  apiKey,
+		log:     log,
+		limiter: limiter,
+		timeout: DefaultRequestTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(bp)
+	}
+
+	return bp
+}
+
+// Geocode converts address into geographic coordinates using Bing Maps API.
+func (bp *BingProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	result, err := bp.GeocodeDetailed(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and also returns the formatted address
+// and confidence of the matched resource.
+func (bp *BingProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
+	// Bound the whole call, including the rate limiter wait
+	ctx, cancel := context.WithTimeout(ctx, bp.timeout)
+	defer cancel()
+
+	// Rate limit
+	if err := bp.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	bp.log.DebugContext(ctx, "Geocoding using Bing", "address", address)
+
+	if address == "" {
+		return nil, ErrBingEmptyAddress
+	}
+
+	reqURL, err := url.Parse(bp.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	query := reqURL.Query()
+	query.Set("q", address)
+	query.Set("maxResults", "1")
+	query.Set("culture", "uk-UA")
+	query.Set("key", bp.apiKey)
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Headers
+	req.Header.Set("Accept", "application/json")
+
+	if err := spendRequestBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := bp.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute geocoding request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// continue
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrBingUnauthorized
+	case http.StatusTooManyRequests:
+		bp.log.WarnContext(ctx, "Bing API rate limit exceeded", "address", address)
+		return nil, &RateLimitError{Provider: string(ProviderTypeBing)}
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		bp.log.ErrorContext(ctx, "Bing API error", "status", resp.StatusCode, "body", string(body))
+		return nil, &StatusError{Provider: "bing", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	bp.log.DebugContext(ctx, "Bing raw response", "body", string(body))
+
+	var result bingResponse
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode bing response: %w", err)
+	}
+
+	if len(result.ResourceSets) == 0 || len(result.ResourceSets[0].Resources) == 0 {
+		return nil, ErrBingEmptyResponse
+	}
+
+	resource := result.ResourceSets[0].Resources[0]
+	if len(resource.Point.Coordinates) != 2 {
+		return nil, ErrBingInvalidCoords
+	}
+
+	// Bing returns [lat, lon]
+	lat := resource.Point.Coordinates[0]
+	lon := resource.Point.Coordinates[1]
+
+	bp.log.InfoContext(ctx, "Bing found result", "address", address, "lat", lat, "lon", lon)
+
+	return &models.GeocodeResult{
+		Coordinates: models.Coordinates{
+			Latitude:  lat,
+			Longitude: lon,
+			Precision: bingPrecision(resource.EntityType),
+		},
+		FormattedAddress: resource.Address.FormattedAddress,
+		Provider:         string(ProviderTypeBing),
+		Confidence:       bingConfidence(resource.Confidence),
+	}, nil
+}
+
+// HealthCheck verifies that the Bing API is reachable and the API key is valid
+// by geocoding a well-known address.
+func (bp *BingProvider) HealthCheck(ctx context.Context) error {
+	if _, err := bp.Geocode(ctx, healthCheckAddress); err != nil {
+		return fmt.Errorf("bing health check failed: %w", err)
+	}
+
+	return nil
+}
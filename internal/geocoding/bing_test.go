@@ -0,0 +1,189 @@
+package geocoding_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestBingProvider_Geocode(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	apiKey := "test-api-key"
+	defaultRL := rate.NewLimiter(rate.Inf, 0)
+
+	t.Run("successfull geocoding", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				// Verify request parameters
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Contains(t, req.URL.String(), geocoding.BingBaseURL)
+				assert.Equal(t, "Київ, Хрещатик, 1", req.URL.Query().Get("q"))
+				assert.Equal(t, apiKey, req.URL.Query().Get("key"))
+				assert.Equal(t, "1", req.URL.Query().Get("maxResults"))
+				assert.Equal(t, "application/json", req.Header.Get("Accept"))
+
+				responseBody := `{"resourceSets":[{"resources":[{"entityType":"Address",` +
+					`"point":{"type":"Point","coordinates":[50.4501,30.5234]}}]}]}`
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewBingProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Київ, Хрещатик, 1")
+
+		require.NoError(t, err)
+		require.NotNil(t, coords)
+		// Bing returns [lat, lon], so the first element is the latitude
+		assert.InEpsilon(t, 50.4501, coords.Latitude, 0.0001)
+		assert.InEpsilon(t, 30.5234, coords.Longitude, 0.0001)
+		assert.Equal(t, models.PrecisionRooftop, coords.Precision)
+	})
+
+	t.Run("empty resource sets", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resourceSets":[]}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewBingProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Nowhere")
+
+		require.ErrorIs(t, err, geocoding.ErrBingEmptyResponse)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("empty resources", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resourceSets":[{"resources":[]}]}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewBingProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Nowhere")
+
+		require.ErrorIs(t, err, geocoding.ErrBingEmptyResponse)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("invalid coordinates", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				responseBody := `{"resourceSets":[{"resources":[{"point":{"coordinates":[50.4501]}}]}]}`
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewBingProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrBingInvalidCoords)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusUnauthorized,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"statusCode":401}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewBingProviderWithClient(mockClient, "bad-key", defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrBingUnauthorized)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("empty address", func(t *testing.T) {
+		provider := geocoding.NewBingProviderWithClient(&mockHTTPClient{}, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "")
+
+		require.ErrorIs(t, err, geocoding.ErrBingEmptyAddress)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(bytes.NewBufferString(`Too Many Requests`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewBingProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+		assert.Nil(t, coords)
+	})
+}
+
+func TestBingProvider_GeocodeDetailed(t *testing.T) {
+	tests := []struct {
+		confidence string
+		expected   float64
+	}{
+		{confidence: "High", expected: 1},
+		{confidence: "Medium", expected: 0.5},
+		{confidence: "Low", expected: 0.25},
+		{confidence: "", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run("confidence "+tt.confidence, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(_ *http.Request) (*http.Response, error) {
+					responseBody := `{"resourceSets":[{"resources":[{"entityType":"Address",` +
+						`"confidence":"` + tt.confidence + `",` +
+						`"address":{"formattedAddress":"вулиця Хрещатик, 1, Київ"},` +
+						`"point":{"coordinates":[50.4501,30.5234]}}]}]}`
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewBingProviderWithClient(
+				mockClient, "test-api-key", rate.NewLimiter(rate.Inf, 0), slog.Default(),
+			)
+			result, err := provider.GeocodeDetailed(t.Context(), "Київ, Хрещатик, 1")
+
+			require.NoError(t, err)
+			assert.Equal(t, &models.GeocodeResult{
+				Coordinates:      models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop},
+				FormattedAddress: "вулиця Хрещатик, 1, Київ",
+				Provider:         "bing",
+				Confidence:       tt.expected,
+			}, result)
+		})
+	}
+}
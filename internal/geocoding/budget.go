@@ -0,0 +1,66 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrRequestBudgetExhausted is returned instead of sending a request once the request budget carried
+// by the context is spent. Like a rate limit it is not caused by the address, so callers should retry
+// the address later instead of counting it as a failed attempt.
+var ErrRequestBudgetExhausted = errors.New("geocoding request budget exhausted")
+
+// RequestBudget caps the number of upstream requests made with a context carrying it (see WithRequestBudget),
+// across all providers and all their fallback requests. It is safe for concurrent use.
+type RequestBudget struct {
+	remaining atomic.Int64
+}
+
+// NewRequestBudget returns a budget allowing limit upstream requests.
+func NewRequestBudget(limit int) *RequestBudget {
+	budget := &RequestBudget{}
+	budget.remaining.Store(int64(limit))
+
+	return budget
+}
+
+// Spend takes one request from the budget, and reports false if none was left.
+func (b *RequestBudget) Spend() bool {
+	return b.remaining.Add(-1) >= 0
+}
+
+// Remaining returns the number of requests left in the budget.
+func (b *RequestBudget) Remaining() int {
+	return int(max(b.remaining.Load(), 0))
+}
+
+// Exhausted reports whether no request is left in the budget.
+func (b *RequestBudget) Exhausted() bool {
+	return b.remaining.Load() <= 0
+}
+
+// requestBudgetKey is the context key of the request budget.
+type requestBudgetKey struct{}
+
+// WithRequestBudget returns a copy of ctx carrying the budget, which the providers spend from
+// before each geocoding request made with the context.
+func WithRequestBudget(ctx context.Context, budget *RequestBudget) context.Context {
+	return context.WithValue(ctx, requestBudgetKey{}, budget)
+}
+
+// RequestBudgetFrom returns the request budget carried by ctx, or nil if there is none.
+func RequestBudgetFrom(ctx context.Context) *RequestBudget {
+	budget, _ := ctx.Value(requestBudgetKey{}).(*RequestBudget)
+	return budget
+}
+
+// spendRequestBudget takes one request from the budget carried by ctx, if any,
+// and returns ErrRequestBudgetExhausted if none was left.
+func spendRequestBudget(ctx context.Context) error {
+	if budget := RequestBudgetFrom(ctx); budget != nil && !budget.Spend() {
+		return ErrRequestBudgetExhausted
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package geocoding_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestBudget(t *testing.T) {
+	budget := geocoding.NewRequestBudget(2)
+
+	assert.True(t, budget.Spend())
+	assert.False(t, budget.Exhausted())
+	assert.True(t, budget.Spend())
+	assert.True(t, budget.Exhausted())
+	assert.False(t, budget.Spend(), "no request must be left")
+	assert.Equal(t, 0, budget.Remaining())
+}
+
+func TestRequestBudgetFrom(t *testing.T) {
+	assert.Nil(t, geocoding.RequestBudgetFrom(context.Background()))
+
+	budget := geocoding.NewRequestBudget(1)
+	ctx := geocoding.WithRequestBudget(context.Background(), budget)
+
+	assert.Same(t, budget, geocoding.RequestBudgetFrom(ctx))
+}
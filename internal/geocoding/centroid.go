@@ -0,0 +1,126 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// ErrRegionCentroidNotFound is returned by RegionCentroidProvider when the address names no district
+// or region of its centroid table.
+var ErrRegionCentroidNotFound = errors.New("no region centroid found for the address")
+
+// regionPattern matches a region component, e.g. "Київська обл." or "Львівська область", capturing the name.
+var regionPattern = regexp.MustCompile(`(?i)^(.+?)\s+(?:обл\.?|область)$`)
+
+// districtPattern matches a district component, e.g. "Бучанський р-н" or "Бучанський район", capturing the name.
+var districtPattern = regexp.MustCompile(`(?i)^(.+?)\s+(?:р-н|район)$`)
+
+// RegionCentroidProvider geocodes an address to the centroid of the district or region it names,
+// looked up in a local table. It makes no requests, so it is meant as the last link of a ChainProvider,
+// for addresses that no other provider could find. Its results have region precision.
+type RegionCentroidProvider struct {
+	centroids map[string]models.Coordinates // centroids are keyed by regionKey of the district or region
+	log       *slog.Logger                  // Logger for logging operations
+}
+
+// NewRegionCentroidProvider creates a provider returning the centroids of the table, keyed by district
+// or region name, e.g. "Бучанський район" or "Київська обл.". Keys are matched case-insensitively,
+// and the "обл." and "р-н" abbreviations match the full words.
+func NewRegionCentroidProvider(centroids map[string]models.Coordinates, log *slog.Logger) *RegionCentroidProvider {
+	keyed := make(map[string]models.Coordinates, len(centroids))
+	for name, coords := range centroids {
+		coords.Precision = models.PrecisionRegion
+		keyed[regionKey(name)] = coords
+	}
+
+	return &RegionCentroidProvider{centroids: keyed, log: log}
+}
+
+// regionCentroid is a centroid in a region centroid file.
+type regionCentroid struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// LoadRegionCentroids reads a table of centroids from a JSON file mapping district or region names
+// to their coordinates, e.g. {"Київська область": {"latitude": 50.05, "longitude": 30.77}}.
+func LoadRegionCentroids(path string) (map[string]models.Coordinates, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the path is set by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read region centroids: %w", err)
+	}
+
+	var file map[string]regionCentroid
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode region centroids: %w", err)
+	}
+
+	centroids := make(map[string]models.Coordinates, len(file))
+	for name, centroid := range file {
+		if centroid.Latitude < -90 || centroid.Latitude > 90 || centroid.Longitude < -180 || centroid.Longitude > 180 {
+			return nil, fmt.Errorf("invalid region centroid of %q: coordinates out of range", name)
+		}
+		centroids[name] = models.Coordinates{Latitude: centroid.Latitude, Longitude: centroid.Longitude}
+	}
+
+	return centroids, nil
+}
+
+// Geocode returns the centroid of the most specific district or region named by the address
+// that is in the table, or ErrRegionCentroidNotFound if there is none.
+func (rp *RegionCentroidProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	for _, region := range extractRegions(address) {
+		if coords, ok := rp.centroids[region]; ok {
+			rp.log.InfoContext(ctx, "Using region centroid", "address", address, "region", region)
+			return &coords, nil
+		}
+	}
+
+	return nil, ErrRegionCentroidNotFound
+}
+
+// HealthCheck always succeeds, since the centroids are looked up locally.
+func (rp *RegionCentroidProvider) HealthCheck(_ context.Context) error {
+	return nil
+}
+
+// extractRegions returns the keys of the districts and regions named by the comma-separated components
+// of the address, districts first since they are more specific.
+func extractRegions(address string) []string {
+	var districts, regions []string
+	for component := range strings.SplitSeq(address, ",") {
+		component = strings.Join(strings.Fields(component), " ")
+
+		switch {
+		case districtPattern.MatchString(component):
+			districts = append(districts, regionKey(component))
+		case regionPattern.MatchString(component):
+			regions = append(regions, regionKey(component))
+		}
+	}
+
+	return append(districts, regions...)
+}
+
+// regionKey returns the lookup key of a district or region name: lowercased, with single spaces,
+// and with the "обл." and "р-н" abbreviations spelled out.
+func regionKey(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+
+	if match := districtPattern.FindStringSubmatch(name); match != nil {
+		return match[1] + " район"
+	}
+	if match := regionPattern.FindStringSubmatch(name); match != nil {
+		return match[1] + " область"
+	}
+
+	return name
+}
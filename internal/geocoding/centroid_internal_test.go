@@ -0,0 +1,66 @@
+package geocoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected []string
+	}{
+		{
+			name:     "district before region",
+			address:  "Київська обл., Бучанський р-н, с. Гора, вул. Центральна, 1",
+			expected: []string{"бучанський район", "київська область"},
+		},
+		{
+			name:     "full words and extra spaces",
+			address:  "с. Грабовець,  Стрийський   район, Львівська  область",
+			expected: []string{"стрийський район", "львівська область"},
+		},
+		{
+			name:     "region without a dot",
+			address:  "м. Одеса, Одеська обл",
+			expected: []string{"одеська область"},
+		},
+		{
+			name:     "no district or region",
+			address:  "м. Київ, вул. Хрещатик, 1",
+			expected: nil,
+		},
+		{
+			name:     "settlement named like a region is ignored",
+			address:  "с. Обласне, вул. Польова, 3",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extractRegions(tt.address))
+		})
+	}
+}
+
+func TestRegionKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   string
+		expected string
+	}{
+		{name: "abbreviated region", region: "Київська обл.", expected: "київська область"},
+		{name: "full region", region: "КИЇВСЬКА ОБЛАСТЬ", expected: "київська область"},
+		{name: "abbreviated district", region: "Бучанський р-н", expected: "бучанський район"},
+		{name: "other name is lowercased", region: "  Автономна Республіка  Крим", expected: "автономна республіка крим"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, regionKey(tt.region))
+		})
+	}
+}
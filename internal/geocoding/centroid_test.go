@@ -0,0 +1,103 @@
+package geocoding_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegionCentroidProvider_Geocode(t *testing.T) {
+	ctx := t.Context()
+	provider := geocoding.NewRegionCentroidProvider(map[string]models.Coordinates{
+		"Київська обл.":    {Latitude: 50.0529, Longitude: 30.7667},
+		"Бучанський район": {Latitude: 50.5436, Longitude: 30.2123},
+	}, slog.Default())
+
+	tests := []struct {
+		name     string
+		address  string
+		expected *models.Coordinates
+	}{
+		{
+			name:     "district is preferred over region",
+			address:  "Київська область, Бучанський р-н, с. Гора",
+			expected: &models.Coordinates{Latitude: 50.5436, Longitude: 30.2123, Precision: models.PrecisionRegion},
+		},
+		{
+			name:     "unknown district falls back to region",
+			address:  "Київська обл., Обухівський р-н, с. Германівка",
+			expected: &models.Coordinates{Latitude: 50.0529, Longitude: 30.7667, Precision: models.PrecisionRegion},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coords, err := provider.Geocode(ctx, tt.address)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, coords)
+		})
+	}
+
+	t.Run("unknown region", func(t *testing.T) {
+		coords, err := provider.Geocode(ctx, "Львівська обл., м. Львів")
+
+		require.ErrorIs(t, err, geocoding.ErrRegionCentroidNotFound)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("address without region", func(t *testing.T) {
+		coords, err := provider.Geocode(ctx, "м. Київ, вул. Хрещатик, 1")
+
+		require.ErrorIs(t, err, geocoding.ErrRegionCentroidNotFound)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("health check", func(t *testing.T) {
+		assert.NoError(t, provider.HealthCheck(ctx))
+	})
+}
+
+func TestLoadRegionCentroids(t *testing.T) {
+	writeFile := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "centroids.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("valid file", func(t *testing.T) {
+		path := writeFile(t, `{"Київська область": {"latitude": 50.0529, "longitude": 30.7667}}`)
+
+		centroids, err := geocoding.LoadRegionCentroids(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]models.Coordinates{
+			"Київська область": {Latitude: 50.0529, Longitude: 30.7667},
+		}, centroids)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := geocoding.LoadRegionCentroids(filepath.Join(t.TempDir(), "missing.json"))
+
+		require.ErrorContains(t, err, "failed to read region centroids")
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := geocoding.LoadRegionCentroids(writeFile(t, `["Київська область"]`))
+
+		require.ErrorContains(t, err, "failed to decode region centroids")
+	})
+
+	t.Run("out of range coordinates", func(t *testing.T) {
+		_, err := geocoding.LoadRegionCentroids(writeFile(t, `{"Київська область": {"latitude": 300, "longitude": 30}}`))
+
+		require.ErrorContains(t, err, "coordinates out of range")
+	})
+}
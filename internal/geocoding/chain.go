@@ -0,0 +1,177 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// ChainProvider geocodes an address with each of its providers in turn, until one of them finds it.
+// Only a provider that found nothing hands the address on to the next one; any other error, such as
+// a rate limit or a timeout, is returned as is, so the task is retried instead of getting a coarser result.
+type ChainProvider struct {
+	providers []Provider   // providers are tried in order, the first one is the primary provider
+	log       *slog.Logger // Logger for logging operations
+}
+
+// NewChainProvider creates a provider trying providers in the given order.
+func NewChainProvider(log *slog.Logger, providers ...Provider) *ChainProvider {
+	return &ChainProvider{providers: providers, log: log}
+}
+
+// Geocode geocodes the address with the first provider of the chain that finds it.
+// If none does, the not found error of the last provider is returned.
+func (cp *ChainProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	result, err := cp.GeocodeDetailed(ctx, address)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and returns the metadata of the match
+// if the provider that found it reports any.
+func (cp *ChainProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
+	return geocodeChain(ctx, cp, address, func(provider Provider) (*models.GeocodeResult, error) {
+		return GeocodeDetailed(ctx, provider, address)
+	})
+}
+
+// GeocodeStructured geocodes the structured address with the first provider of the chain that finds it,
+// by its fields if the provider implements StructuredGeocoder and by its free-form text otherwise.
+func (cp *ChainProvider) GeocodeStructured(
+	ctx context.Context,
+	address models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	return geocodeChain(ctx, cp, address.String(), func(provider Provider) (*models.GeocodeResult, error) {
+		return GeocodeStructured(ctx, provider, address)
+	})
+}
+
+// GeocodeCandidates returns up to limit matches for the address from the first provider of the chain
+// that finds it, several if that provider implements CandidateGeocoder.
+func (cp *ChainProvider) GeocodeCandidates(
+	ctx context.Context,
+	address string,
+	limit int,
+) ([]models.GeocodeResult, error) {
+	return geocodeChain(ctx, cp, address, func(provider Provider) ([]models.GeocodeResult, error) {
+		results, err := GeocodeCandidates(ctx, provider, address, limit)
+		if err == nil && len(results) == 0 {
+			results = nil
+		}
+
+		return results, err
+	})
+}
+
+// GeocodeBatch geocodes the addresses with each provider of the chain in turn, with a single batch call if
+// the provider implements BatchProvider and one by one otherwise. Like Geocode, only the addresses a provider
+// found nothing for are handed on to the next one, and each address keeps its own result or error.
+func (cp *ChainProvider) GeocodeBatch(
+	ctx context.Context,
+	addresses []string,
+) ([]*models.Coordinates, []error) {
+	coords := make([]*models.Coordinates, len(addresses))
+	errs := make([]error, len(addresses))
+
+	pending := make([]int, len(addresses)) // pending are the indices of the addresses not found so far
+	for i := range pending {
+		pending[i] = i
+	}
+	for i, provider := range cp.providers {
+		batch := make([]string, len(pending))
+		for j, idx := range pending {
+			batch[j] = addresses[idx]
+		}
+
+		batchCoords, batchErrs := GeocodeBatch(ctx, provider, batch)
+		next := pending[:0]
+		for j, idx := range pending {
+			coords[idx], errs[idx] = batchCoords[j], batchErrs[j]
+			if batchCoords[j] == nil && (batchErrs[j] == nil || isNotFound(batchErrs[j])) {
+				next = append(next, idx)
+			}
+		}
+
+		pending = next
+		if len(pending) == 0 {
+			break
+		}
+		if i < len(cp.providers)-1 {
+			cp.log.DebugContext(ctx, "Provider found nothing for some addresses, trying the next one in the chain",
+				"addresses", len(pending), "provider", i)
+		}
+	}
+
+	return coords, errs
+}
+
+// SupportsBatch reports whether the primary provider of the chain, which geocodes every address of a batch,
+// has a native batch API.
+func (cp *ChainProvider) SupportsBatch() bool {
+	return SupportsBatch(cp.providers[0])
+}
+
+// ReverseGeocode converts the coordinates into an address with the primary provider of the chain.
+// It returns ErrReverseGeocodingUnsupported if the primary provider can't reverse geocode.
+func (cp *ChainProvider) ReverseGeocode(ctx context.Context, coords models.Coordinates) (string, error) {
+	return ReverseGeocode(ctx, cp.providers[0], coords)
+}
+
+// geocodeChain calls geocode with each provider of the chain in turn, until one of them returns a non-nil
+// result. Only a provider that found nothing hands the address on to the next one; any other error is returned
+// as is. If no provider finds the address, the not found error of the last provider is returned.
+func geocodeChain[T *models.GeocodeResult | []models.GeocodeResult](
+	ctx context.Context,
+	cp *ChainProvider,
+	address string,
+	geocode func(provider Provider) (T, error),
+) (T, error) {
+	var lastErr error
+	for i, provider := range cp.providers {
+		result, err := geocode(provider)
+		if err == nil && result != nil {
+			return result, nil
+		}
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+
+		lastErr = err
+		if i < len(cp.providers)-1 {
+			cp.log.DebugContext(ctx, "Provider found nothing, trying the next one in the chain",
+				"address", address, "provider", i, "error", err)
+		}
+	}
+
+	return nil, lastErr
+}
+
+// HealthCheck verifies that every provider of the chain is able to serve requests.
+func (cp *ChainProvider) HealthCheck(ctx context.Context) error {
+	for i, provider := range cp.providers {
+		if err := provider.HealthCheck(ctx); err != nil {
+			if i == 0 {
+				return err
+			}
+			return fmt.Errorf("chained provider %d health check failed: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// isNotFound reports whether err means that a provider found nothing for the address.
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrEmptyResponse) ||
+		errors.Is(err, ErrNominatimEmptyResponse) ||
+		errors.Is(err, ErrVisicomEmptyResponse) ||
+		errors.Is(err, ErrHereEmptyResponse) ||
+		errors.Is(err, ErrBingEmptyResponse) ||
+		errors.Is(err, ErrRegionCentroidNotFound)
+}
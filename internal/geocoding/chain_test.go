@@ -0,0 +1,196 @@
+package geocoding_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainProvider_Geocode(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	kyiv := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop}
+	region := &models.Coordinates{Latitude: 50.0529, Longitude: 30.7667, Precision: models.PrecisionRegion}
+
+	t.Run("first match is returned", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(kyiv, nil).Once()
+
+		coords, err := geocoding.NewChainProvider(logger, primary, fallback).Geocode(ctx, "Kyiv")
+
+		require.NoError(t, err)
+		assert.Equal(t, kyiv, coords)
+		fallback.AssertNotCalled(t, "Geocode")
+	})
+
+	t.Run("not found is handed to the next provider", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrNominatimEmptyResponse).Once()
+		fallback.On("Geocode", ctx, "Kyiv").Return(region, nil).Once()
+
+		coords, err := geocoding.NewChainProvider(logger, primary, fallback).Geocode(ctx, "Kyiv")
+
+		require.NoError(t, err)
+		assert.Equal(t, region, coords)
+	})
+
+	t.Run("other errors are returned", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrRateLimited).Once()
+
+		coords, err := geocoding.NewChainProvider(logger, primary, fallback).Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+		assert.Nil(t, coords)
+		fallback.AssertNotCalled(t, "Geocode")
+	})
+
+	t.Run("nothing found returns the last error", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrNominatimEmptyResponse).Once()
+		fallback.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrRegionCentroidNotFound).Once()
+
+		coords, err := geocoding.NewChainProvider(logger, primary, fallback).Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrRegionCentroidNotFound)
+		assert.Nil(t, coords)
+	})
+}
+
+func TestChainProvider_OptionalInterfaces(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	address := models.StructuredAddress{City: "м. Київ", Street: "вул. Хрещатик", HouseNumber: "1"}
+	region := &models.Coordinates{Latitude: 50.0529, Longitude: 30.7667, Precision: models.PrecisionRegion}
+
+	var chain geocoding.Provider = geocoding.NewChainProvider(logger, mocks.NewProvider(t))
+	assert.Implements(t, (*geocoding.StructuredGeocoder)(nil), chain)
+	assert.Implements(t, (*geocoding.CandidateGeocoder)(nil), chain)
+	assert.Implements(t, (*geocoding.ReverseGeocoder)(nil), chain)
+	assert.Implements(t, (*geocoding.BatchProvider)(nil), chain)
+
+	t.Run("structured addresses are looked up by field", func(t *testing.T) {
+		primary := &structuredProvider{Provider: mocks.NewProvider(t)}
+		fallback := mocks.NewProvider(t)
+
+		result, err := geocoding.NewChainProvider(logger, primary, fallback).GeocodeStructured(ctx, address)
+
+		require.NoError(t, err)
+		assert.InDelta(t, 50.45, result.Latitude, 0)
+		assert.Equal(t, []models.StructuredAddress{address}, primary.addresses)
+	})
+
+	t.Run("a structured address not found is handed to the next provider", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, address.String()).Return(nil, geocoding.ErrNominatimEmptyResponse).Once()
+		fallback.On("Geocode", ctx, address.String()).Return(region, nil).Once()
+
+		result, err := geocoding.NewChainProvider(logger, primary, fallback).GeocodeStructured(ctx, address)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.GeocodeResult{Coordinates: *region}, result)
+	})
+
+	t.Run("candidates come from the primary provider", func(t *testing.T) {
+		expected := []models.GeocodeResult{
+			{Coordinates: models.Coordinates{Latitude: 50.45, Longitude: 30.52}},
+			{Coordinates: models.Coordinates{Latitude: 50.05, Longitude: 30.77}},
+		}
+		primary := &candidatesProvider{Provider: mocks.NewProvider(t), results: expected}
+
+		results, err := geocoding.NewChainProvider(logger, primary, mocks.NewProvider(t)).
+			GeocodeCandidates(ctx, "Kyiv", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, results)
+	})
+
+	t.Run("candidates not found are handed to the next provider", func(t *testing.T) {
+		primary := &candidatesProvider{Provider: mocks.NewProvider(t)}
+		fallback := mocks.NewProvider(t)
+		fallback.On("Geocode", ctx, "Kyiv").Return(region, nil).Once()
+
+		results, err := geocoding.NewChainProvider(logger, primary, fallback).GeocodeCandidates(ctx, "Kyiv", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.GeocodeResult{{Coordinates: *region}}, results)
+	})
+
+	t.Run("reverse geocoding uses the primary provider", func(t *testing.T) {
+		primary := &reverseProvider{Provider: mocks.NewProvider(t), address: "вул. Хрещатик, 1, Київ"}
+		coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+		reversed, err := geocoding.NewChainProvider(logger, primary, mocks.NewProvider(t)).ReverseGeocode(ctx, coords)
+
+		require.NoError(t, err)
+		assert.Equal(t, "вул. Хрещатик, 1, Київ", reversed)
+
+		_, err = geocoding.NewChainProvider(logger, mocks.NewProvider(t)).ReverseGeocode(ctx, coords)
+		require.ErrorIs(t, err, geocoding.ErrReverseGeocodingUnsupported)
+	})
+
+	t.Run("only batch addresses not found are handed to the next provider", func(t *testing.T) {
+		kyiv := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+		primary := &batchProvider{Provider: mocks.NewProvider(t), results: map[string]*models.Coordinates{"Kyiv": kyiv}}
+		fallback := &batchProvider{Provider: mocks.NewProvider(t), results: map[string]*models.Coordinates{"Lviv": region}}
+
+		coords, errs := geocoding.NewChainProvider(logger, primary, fallback).
+			GeocodeBatch(ctx, []string{"Kyiv", "Lviv", "Nowhere"})
+
+		assert.Equal(t, []*models.Coordinates{kyiv, region, nil}, coords)
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		require.ErrorIs(t, errs[2], geocoding.ErrEmptyResponse)
+		assert.Equal(t, [][]string{{"Kyiv", "Lviv", "Nowhere"}}, primary.calls)
+		assert.Equal(t, [][]string{{"Lviv", "Nowhere"}}, fallback.calls)
+	})
+
+	t.Run("batch errors other than not found are returned as is", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrRateLimited).Once()
+		primary.On("Geocode", ctx, "Lviv").Return(nil, geocoding.ErrNominatimEmptyResponse).Once()
+		fallback.On("Geocode", ctx, "Lviv").Return(region, nil).Once()
+
+		coords, errs := geocoding.NewChainProvider(logger, primary, fallback).GeocodeBatch(ctx, []string{"Kyiv", "Lviv"})
+
+		assert.Equal(t, []*models.Coordinates{nil, region}, coords)
+		require.ErrorIs(t, errs[0], geocoding.ErrRateLimited)
+		require.NoError(t, errs[1])
+	})
+}
+
+func TestChainProvider_HealthCheck(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("healthy", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("HealthCheck", ctx).Return(nil).Once()
+		fallback.On("HealthCheck", ctx).Return(nil).Once()
+
+		assert.NoError(t, geocoding.NewChainProvider(slog.Default(), primary, fallback).HealthCheck(ctx))
+	})
+
+	t.Run("unhealthy fallback", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		primary.On("HealthCheck", ctx).Return(nil).Once()
+		fallback.On("HealthCheck", ctx).Return(assert.AnError).Once()
+
+		err := geocoding.NewChainProvider(slog.Default(), primary, fallback).HealthCheck(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "chained provider 1 health check failed")
+	})
+}
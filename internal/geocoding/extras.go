@@ -0,0 +1,116 @@
+package geocoding
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// protectedParams are the query parameters that extra params can't set: the API key of each provider
+// and the response format the providers parse.
+var protectedParams = []string{"key", "apiKey", "format"}
+
+// protectedHeaders are the headers that extra headers can't set: credentials and the headers
+// the providers rely on to receive and identify their responses.
+var protectedHeaders = []string{"Authorization", "Accept", "Host", "Content-Type"}
+
+// validateExtras returns an error if the extra params or headers of the configuration set
+// a protected parameter or header.
+func validateExtras(config ProviderConfig) error {
+	for name := range config.ExtraParams {
+		if name == "" || isProtected(protectedParams, name) {
+			return fmt.Errorf("extra param %q can't be set for the %s provider", name, config.Type)
+		}
+	}
+	for name := range config.ExtraHeaders {
+		if name == "" || isProtected(protectedHeaders, name) {
+			return fmt.Errorf("extra header %q can't be set for the %s provider", name, config.Type)
+		}
+	}
+
+	return nil
+}
+
+// isProtected reports whether name is one of the protected names, ignoring case.
+func isProtected(protected []string, name string) bool {
+	return slices.ContainsFunc(protected, func(candidate string) bool {
+		return strings.EqualFold(candidate, name)
+	})
+}
+
+// extrasTransport adds extra query parameters and headers to every request sent through it.
+// A parameter or header the provider already set on the request is left as is, so the extras
+// can't replace the address, the API key or any other value the provider depends on.
+type extrasTransport struct {
+	base    http.RoundTripper
+	params  map[string]string
+	headers map[string]string
+}
+
+// RoundTrip sends a copy of the request with the extras added through the base transport.
+func (t *extrasTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	if len(t.params) > 0 {
+		query := req.URL.Query()
+		for name, value := range t.params {
+			if !query.Has(name) {
+				query.Set(name, value)
+			}
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	for name, value := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// providerHTTPClient creates the HTTP client of the configured provider, adding the extra params
+// and headers of the configuration to its requests if there are any, recording them if a recording
+// is configured, and retrying failed requests if retries are configured. The configured HTTP client, if any, is used instead of a new one,
+// which otherwise sends the requests through the configured proxy if there is one.
+func providerHTTPClient(config ProviderConfig) *http.Client {
+	client := newHTTPClient(proxiedTransport(config.Transport, config.ProxyURL), config.HTTPTimeout)
+	if config.HTTPClient != nil {
+		custom := *config.HTTPClient
+		if custom.Transport == nil {
+			custom.Transport = http.DefaultTransport
+		}
+		client = &custom
+	}
+
+	if len(config.ExtraParams) > 0 || len(config.ExtraHeaders) > 0 {
+		client.Transport = &extrasTransport{
+			base:    client.Transport,
+			params:  config.ExtraParams,
+			headers: config.ExtraHeaders,
+		}
+	}
+
+	// Recorded below the retries, so that every attempt is recorded with the extras it was sent with
+	if config.Recording != nil {
+		client.Transport = NewRecordingRoundTripper(client.Transport, config.Recording)
+	}
+
+	if config.Retries > 0 {
+		backoff := config.RetryBackoff
+		if backoff <= 0 {
+			backoff = DefaultRetryBackoff
+		}
+		client.Transport = &retryTransport{
+			base:     client.Transport,
+			retries:  config.Retries,
+			backoff:  backoff,
+			provider: config.Type,
+			onRetry:  config.OnRetry,
+		}
+	}
+
+	return client
+}
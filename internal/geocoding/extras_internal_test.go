@@ -0,0 +1,160 @@
+package geocoding
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider_ExtrasProtected(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		headers map[string]string
+	}{
+		{name: "api key param", params: map[string]string{"key": "other-key"}},
+		{name: "HERE api key param", params: map[string]string{"apikey": "other-key"}},
+		{name: "format param", params: map[string]string{"Format": "xml"}},
+		{name: "empty param name", params: map[string]string{"": "1"}},
+		{name: "authorization header", headers: map[string]string{"authorization": "Bearer token"}},
+		{name: "accept header", headers: map[string]string{"Accept": "text/html"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(ProviderConfig{
+				Type: ProviderTypeNominatim, ExtraParams: tt.params, ExtraHeaders: tt.headers, Logger: slog.Default(),
+			})
+
+			require.ErrorContains(t, err, "can't be set for the nominatim provider")
+			assert.Nil(t, provider)
+		})
+	}
+}
+
+func TestNewProvider_Extras(t *testing.T) {
+	t.Run("nominatim", func(t *testing.T) {
+		var request *http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"lat":"50.4501","lon":"30.5234","display_name":"Київ"}]`))
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(ProviderConfig{
+			Type:         ProviderTypeNominatim,
+			ExtraParams:  map[string]string{"extratags": "1", "q": "Львів", "limit": "50"},
+			ExtraHeaders: map[string]string{"X-Client": "atlas", "User-Agent": "other-agent"},
+			Logger:       slog.Default(),
+		})
+		require.NoError(t, err)
+		nominatim := provider.(*NominatimProvider)
+		nominatim.baseURL = server.URL
+
+		coords, err := nominatim.Geocode(t.Context(), "Київ")
+
+		require.NoError(t, err)
+		require.NotNil(t, coords)
+		require.NotNil(t, request)
+		query := request.URL.Query()
+		assert.Equal(t, "1", query.Get("extratags"))
+		assert.Equal(t, "json", query.Get("format"))
+		// Parameters and headers set by the provider are not replaced by the extras
+		assert.Equal(t, "Київ", query.Get("q"))
+		assert.Equal(t, "1", query.Get("limit"))
+		assert.Equal(t, "atlas", request.Header.Get("X-Client"))
+		assert.Equal(t, nominatim.userAgent, request.Header.Get("User-Agent"))
+	})
+
+	t.Run("visicom keeps its api key", func(t *testing.T) {
+		var request *http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(ProviderConfig{
+			Type:        ProviderTypeVisicom,
+			APIKey:      "test-key",
+			ExtraParams: map[string]string{"categories": "adr_address", "text": "Львів"},
+			Logger:      slog.Default(),
+		})
+		require.NoError(t, err)
+		visicom := provider.(*VisicomProvider)
+		visicom.baseURL = server.URL
+
+		_, _ = visicom.Geocode(t.Context(), "Київ")
+
+		require.NotNil(t, request)
+		query := request.URL.Query()
+		assert.Equal(t, "adr_address", query.Get("categories"))
+		assert.Equal(t, "test-key", query.Get("key"))
+		assert.Equal(t, "Київ", query.Get("text"))
+	})
+
+	t.Run("no extras keep the plain transport", func(t *testing.T) {
+		provider, err := NewProvider(ProviderConfig{Type: ProviderTypeNominatim, Logger: slog.Default()})
+		require.NoError(t, err)
+
+		assert.Same(t, sharedTransport, httpTransport(t, provider.(*NominatimProvider).client))
+	})
+}
+
+func TestNewProvider_HTTPClient(t *testing.T) {
+	var requests []*http.Request
+	custom := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		body := `{"status":"OK","results":[{"geometry":{"location":{"lat":50.4501,"lng":30.5234},` +
+			`"location_type":"ROOFTOP"}}]}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})}
+
+	t.Run("google", func(t *testing.T) {
+		requests = nil
+		provider, err := NewProvider(ProviderConfig{
+			Type:       ProviderTypeGoogle,
+			APIKey:     "test-key",
+			HTTPClient: custom,
+			Logger:     slog.Default(),
+		})
+		require.NoError(t, err)
+
+		coords, err := provider.Geocode(t.Context(), "Київ")
+
+		require.NoError(t, err)
+		require.NotNil(t, coords)
+		assert.InDelta(t, 50.4501, coords.Latitude, 0.0001)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "maps.googleapis.com", requests[0].URL.Host)
+		assert.Equal(t, "test-key", requests[0].URL.Query().Get("key"))
+	})
+
+	t.Run("wrapped by retries without modifying the client", func(t *testing.T) {
+		client := providerHTTPClient(ProviderConfig{Type: ProviderTypeHere, HTTPClient: custom, Retries: 2})
+
+		retry, ok := client.Transport.(*retryTransport)
+		require.True(t, ok)
+		assert.IsType(t, roundTripFunc(nil), retry.base)
+		assert.IsType(t, roundTripFunc(nil), custom.Transport)
+	})
+
+	t.Run("default transport", func(t *testing.T) {
+		client := providerHTTPClient(ProviderConfig{HTTPClient: &http.Client{}, Retries: 1})
+
+		retry, ok := client.Transport.(*retryTransport)
+		require.True(t, ok)
+		assert.Equal(t, http.DefaultTransport, retry.base)
+	})
+}
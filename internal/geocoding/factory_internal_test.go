@@ -0,0 +1,213 @@
+package geocoding
+
+import (
+	"encoding/base64"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit int
+		expected  int
+	}{
+		{name: "zero falls back to default", rateLimit: 0, expected: DefaultGoogleRateLimit},
+		{name: "negative falls back to default", rateLimit: -1, expected: DefaultGoogleRateLimit},
+		{name: "configured limit is used as is", rateLimit: 10, expected: 10},
+		// Previously 50/60 workers truncated to 0, which the client treated as unlimited.
+		{name: "limit lower than worker count is kept", rateLimit: 5, expected: 5},
+		{name: "limit is not divided by worker count", rateLimit: 50, expected: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, googleRateLimit(tt.rateLimit))
+		})
+	}
+}
+
+func TestPrimaryLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages string
+		expected  string
+	}{
+		{name: "list uses first language", languages: "uk,en", expected: "uk"},
+		{name: "spaces are trimmed", languages: " de , en", expected: "de"},
+		{name: "single language", languages: "en", expected: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, primaryLanguage(tt.languages))
+		})
+	}
+}
+
+func TestNewProvider_Transport(t *testing.T) {
+	logger := slog.Default()
+	custom := NewTransport()
+
+	tests := []struct {
+		name      string
+		transport *http.Transport
+		expected  *http.Transport
+	}{
+		{name: "shared transport by default", transport: nil, expected: sharedTransport},
+		{name: "custom transport is used", transport: custom, expected: custom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nominatim, err := NewProvider(ProviderConfig{
+				Type: ProviderTypeNominatim, Transport: tt.transport, Logger: logger,
+			})
+			require.NoError(t, err)
+			assert.Same(t, tt.expected, httpTransport(t, nominatim.(*NominatimProvider).client))
+
+			visicom, err := NewProvider(ProviderConfig{
+				Type: ProviderTypeVisicom, APIKey: "test-key", Transport: tt.transport, Logger: logger,
+			})
+			require.NoError(t, err)
+			assert.Same(t, tt.expected, httpTransport(t, visicom.(*VisicomProvider).client))
+		})
+	}
+}
+
+func TestNewProvider_HTTPTimeout(t *testing.T) {
+	logger := slog.Default()
+
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{name: "default timeout", timeout: 0, expected: DefaultHTTPTimeout},
+		{name: "configured timeout", timeout: 30 * time.Second, expected: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nominatim, err := NewProvider(ProviderConfig{
+				Type: ProviderTypeNominatim, HTTPTimeout: tt.timeout, Logger: logger,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, httpClientOf(t, nominatim.(*NominatimProvider).client).Timeout)
+
+			visicom, err := NewProvider(ProviderConfig{
+				Type: ProviderTypeVisicom, APIKey: "test-key", HTTPTimeout: tt.timeout, Logger: logger,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, httpClientOf(t, visicom.(*VisicomProvider).client).Timeout)
+		})
+	}
+}
+
+func TestNewProvider_HTTPTimeoutSlowServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Respond well after the client timeout, unless the client gives up first
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(ProviderConfig{
+		Type: ProviderTypeNominatim, HTTPTimeout: 50 * time.Millisecond, Logger: slog.Default(),
+	})
+	require.NoError(t, err)
+	nominatim := provider.(*NominatimProvider)
+	nominatim.baseURL = server.URL
+
+	start := time.Now()
+	_, err = nominatim.Geocode(t.Context(), "Київ")
+
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNewProvider_ProxyURL(t *testing.T) {
+	var request *http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"lat":"50.4501","lon":"30.5234","display_name":"Київ"}]`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	proxyURL.User = url.UserPassword("atlas", "secret")
+
+	t.Run("requests go through the proxy", func(t *testing.T) {
+		provider, err := NewProvider(ProviderConfig{Type: ProviderTypeNominatim, ProxyURL: proxyURL, Logger: slog.Default()})
+		require.NoError(t, err)
+		nominatim := provider.(*NominatimProvider)
+		nominatim.baseURL = "http://nominatim.invalid/search"
+
+		coords, err := nominatim.Geocode(t.Context(), "Київ")
+
+		require.NoError(t, err)
+		require.NotNil(t, coords)
+		require.NotNil(t, request)
+		// A proxied request names the origin server, which is never contacted directly
+		assert.Equal(t, "nominatim.invalid", request.Host)
+		assert.Equal(t, "Київ", request.URL.Query().Get("q"))
+		credentials := base64.StdEncoding.EncodeToString([]byte("atlas:secret"))
+		assert.Equal(t, "Basic "+credentials, request.Header.Get("Proxy-Authorization"))
+	})
+
+	t.Run("transports are copied", func(t *testing.T) {
+		custom := NewTransport()
+		custom.Proxy = nil
+
+		for _, transport := range []*http.Transport{nil, custom} {
+			provider, err := NewProvider(ProviderConfig{
+				Type: ProviderTypeVisicom, APIKey: "test-key", Transport: transport, ProxyURL: proxyURL,
+				Logger: slog.Default(),
+			})
+			require.NoError(t, err)
+			proxied := httpTransport(t, provider.(*VisicomProvider).client)
+
+			assert.NotSame(t, sharedTransport, proxied)
+			assert.NotSame(t, custom, proxied)
+			used, err := proxied.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api.visicom.ua"}})
+			require.NoError(t, err)
+			assert.Equal(t, proxyURL, used)
+		}
+		assert.Nil(t, custom.Proxy)
+	})
+}
+
+// httpClientOf returns the provider's HTTP client.
+func httpClientOf(t *testing.T, client HTTPClient) *http.Client {
+	t.Helper()
+
+	httpClient, ok := client.(*http.Client)
+	require.True(t, ok)
+
+	return httpClient
+}
+
+// httpTransport returns the transport of the provider's HTTP client.
+func httpTransport(t *testing.T, client HTTPClient) *http.Transport {
+	t.Helper()
+
+	transport, ok := httpClientOf(t, client).Transport.(*http.Transport)
+	require.True(t, ok)
+
+	return transport
+}
@@ -44,6 +44,31 @@ func TestNewProvider(t *testing.T) {
 		assert.Contains(t, err.Error(), "API key is required for Google provider")
 	})
 
+	t.Run("create Google provider with component filters", func(t *testing.T) {
+		provider, err := geocoding.NewProvider(geocoding.ProviderConfig{
+			Type:       geocoding.ProviderTypeGoogle,
+			APIKey:     "test-api-key",
+			Region:     "ua",
+			Components: map[string]string{"country": "ua", "administrative_area": "Київська область"},
+			Logger:     logger,
+		})
+
+		require.NoError(t, err)
+		assert.NotNil(t, provider)
+	})
+
+	t.Run("create Google provider with an unsupported component filter fails", func(t *testing.T) {
+		provider, err := geocoding.NewProvider(geocoding.ProviderConfig{
+			Type:       geocoding.ProviderTypeGoogle,
+			APIKey:     "test-api-key",
+			Components: map[string]string{"city": "Київ"},
+			Logger:     logger,
+		})
+
+		require.EqualError(t, err, `unsupported Google Maps component filter: "city"`)
+		assert.Nil(t, provider)
+	})
+
 	t.Run("create Google provider with rate limit", func(t *testing.T) {
 		config := geocoding.ProviderConfig{
 			Type:      geocoding.ProviderTypeGoogle,
@@ -101,6 +126,62 @@ func TestNewProvider(t *testing.T) {
 		require.NotNil(t, provider)
 	})
 
+	t.Run("create Nominatim provider with min precision", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:         geocoding.ProviderTypeNominatim,
+			MinPrecision: "house",
+			Logger:       logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.NoError(t, err)
+		require.NotNil(t, provider)
+	})
+
+	t.Run("create Nominatim provider with invalid min precision", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:         geocoding.ProviderTypeNominatim,
+			MinPrecision: "apartment",
+			Logger:       logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.Error(t, err)
+		assert.Nil(t, provider)
+		assert.Contains(t, err.Error(), "unknown nominatim precision")
+	})
+
+	t.Run("create Nominatim provider with best-match selection", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:        geocoding.ProviderTypeNominatim,
+			ResultLimit: 5,
+			ResultHint:  "50.4501,30.5234",
+			Logger:      logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.NoError(t, err)
+		require.NotNil(t, provider)
+	})
+
+	t.Run("create Nominatim provider with invalid result hint", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:        geocoding.ProviderTypeNominatim,
+			ResultLimit: 5,
+			ResultHint:  "kyiv",
+			Logger:      logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.Error(t, err)
+		assert.Nil(t, provider)
+		assert.Contains(t, err.Error(), "invalid result hint")
+	})
+
 	t.Run("create Visicom provider successfully", func(t *testing.T) {
 		config := geocoding.ProviderConfig{
 			Type:      geocoding.ProviderTypeVisicom,
@@ -143,6 +224,85 @@ func TestNewProvider(t *testing.T) {
 		require.NotNil(t, provider)
 	})
 
+	t.Run("create HERE provider successfully", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:   geocoding.ProviderTypeHere,
+			APIKey: "test-api-key",
+			Logger: logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.NoError(t, err)
+		_, ok := provider.(*geocoding.HereProvider)
+		assert.True(t, ok, "expected provider to be *HereProvider")
+	})
+
+	t.Run("create HERE provider without API key", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:   geocoding.ProviderTypeHere,
+			Logger: logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.Error(t, err)
+		require.Nil(t, provider)
+	})
+
+	t.Run("create LocationIQ provider successfully", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:      geocoding.ProviderTypeLocationIQ,
+			APIKey:    "test-api-key",
+			RateLimit: 10,
+			Logger:    logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.NoError(t, err)
+		_, ok := provider.(*geocoding.LocationIQProvider)
+		assert.True(t, ok, "expected provider to be *LocationIQProvider")
+	})
+
+	t.Run("create LocationIQ provider without API key", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:   geocoding.ProviderTypeLocationIQ,
+			Logger: logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.Error(t, err)
+		require.Nil(t, provider)
+	})
+
+	t.Run("create Bing provider successfully", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:   geocoding.ProviderTypeBing,
+			APIKey: "test-api-key",
+			Logger: logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.NoError(t, err)
+		_, ok := provider.(*geocoding.BingProvider)
+		assert.True(t, ok, "expected provider to be *BingProvider")
+	})
+
+	t.Run("create Bing provider without API key", func(t *testing.T) {
+		config := geocoding.ProviderConfig{
+			Type:   geocoding.ProviderTypeBing,
+			Logger: logger,
+		}
+
+		provider, err := geocoding.NewProvider(config)
+
+		require.Error(t, err)
+		require.Nil(t, provider)
+	})
+
 	t.Run("unsupported provider type", func(t *testing.T) {
 		config := geocoding.ProviderConfig{
 			Type:   geocoding.ProviderType("unsupported"),
@@ -170,9 +330,73 @@ func TestNewProvider(t *testing.T) {
 	})
 }
 
+func TestNewProviderWithInfo(t *testing.T) {
+	tests := []struct {
+		providerType geocoding.ProviderType
+		expected     geocoding.ProviderInfo
+	}{
+		{
+			providerType: geocoding.ProviderTypeGoogle,
+			expected: geocoding.ProviderInfo{
+				Name: geocoding.ProviderTypeGoogle, SupportsReverse: true, RateLimited: true,
+			},
+		},
+		{
+			providerType: geocoding.ProviderTypeNominatim,
+			expected:     geocoding.ProviderInfo{Name: geocoding.ProviderTypeNominatim, SupportsReverse: true},
+		},
+		{
+			providerType: geocoding.ProviderTypeVisicom,
+			expected:     geocoding.ProviderInfo{Name: geocoding.ProviderTypeVisicom, RateLimited: true},
+		},
+		{
+			providerType: geocoding.ProviderTypeHere,
+			expected:     geocoding.ProviderInfo{Name: geocoding.ProviderTypeHere, RateLimited: true},
+		},
+		{
+			providerType: geocoding.ProviderTypeLocationIQ,
+			expected:     geocoding.ProviderInfo{Name: geocoding.ProviderTypeLocationIQ, RateLimited: true},
+		},
+		{
+			providerType: geocoding.ProviderTypeBing,
+			expected:     geocoding.ProviderInfo{Name: geocoding.ProviderTypeBing, RateLimited: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.providerType), func(t *testing.T) {
+			provider, info, err := geocoding.NewProviderWithInfo(geocoding.ProviderConfig{
+				Type:   tt.providerType,
+				APIKey: "test-api-key",
+				Logger: slog.Default(),
+			})
+
+			require.NoError(t, err)
+			require.NotNil(t, provider)
+			assert.Equal(t, tt.expected, info)
+			assert.False(t, info.SupportsBatch, "no built-in provider has a native batch API")
+			assert.Equal(t, geocoding.SupportsBatch(provider), info.SupportsBatch)
+		})
+	}
+
+	t.Run("unsupported provider type", func(t *testing.T) {
+		provider, info, err := geocoding.NewProviderWithInfo(geocoding.ProviderConfig{
+			Type:   geocoding.ProviderType("unsupported"),
+			Logger: slog.Default(),
+		})
+
+		require.ErrorContains(t, err, "unsupported provider type: unsupported")
+		assert.Nil(t, provider)
+		assert.Equal(t, geocoding.ProviderInfo{}, info)
+	})
+}
+
 func TestProviderType_Constants(t *testing.T) {
 	// Verify that provider type constants are correctly defined
 	assert.Equal(t, "google", string(geocoding.ProviderTypeGoogle))
 	assert.Equal(t, "nominatim", string(geocoding.ProviderTypeNominatim))
 	assert.Equal(t, "visicom", string(geocoding.ProviderTypeVisicom))
+	assert.Equal(t, "here", string(geocoding.ProviderTypeHere))
+	assert.Equal(t, "locationiq", string(geocoding.ProviderTypeLocationIQ))
+	assert.Equal(t, "bing", string(geocoding.ProviderTypeBing))
 }
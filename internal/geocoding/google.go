@@ -14,12 +14,47 @@ import (
 // and a logger for logging purposes. It is used to interact with the
 // Google Maps geocoding services.
 type GoogleProvider struct {
-	client GoogleAPIClient // client is the Google Maps API client
-	log    *slog.Logger    // log is the logger for logging operations
+	client   GoogleAPIClient // client is the Google Maps API client
+	log      *slog.Logger    // log is the logger for logging operations
+	language string          // language of the results, empty uses the Google Maps default
+
+	region     string                    // region code results are biased toward, empty for no bias
+	components map[maps.Component]string // components filter the results, nil for no filter
 }
 
+// GoogleOption configures optional behavior of the GoogleProvider.
+type GoogleOption func(*GoogleProvider)
+
+// WithGoogleLanguage sets the language of the results, sent as the language request parameter.
+// A comma-separated preference list is reduced to its first language, e.g. "uk,en" to "uk",
+// since Google Maps accepts a single language code. A language carried by the request context,
+// see WithLanguage, takes precedence and is reduced the same way.
+func WithGoogleLanguage(language string) GoogleOption {
+	return func(gp *GoogleProvider) {
+		gp.language = primaryLanguage(language)
+	}
+}
+
+// WithGoogleRegion biases the results toward the region, a ccTLD code such as "ua", sent as the region
+// request parameter. A better match outside the region is still returned.
+func WithGoogleRegion(region string) GoogleOption {
+	return func(gp *GoogleProvider) {
+		gp.region = region
+	}
+}
+
+// WithGoogleComponents restricts the results to those matching every component filter, e.g. the country "ua",
+// sent as the components request parameter, so an ambiguous village name isn't matched in another country.
+func WithGoogleComponents(components map[maps.Component]string) GoogleOption {
+	return func(gp *GoogleProvider) {
+		gp.components = components
+	}
+}
+
+// GoogleAPIClient defines the subset of the Google Maps client used by the provider.
 type GoogleAPIClient interface {
 	Geocode(ctx context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error)
+	ReverseGeocode(ctx context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error)
 }
 
 // ErrEmptyResponse is returned when the Google Maps API responds with an empty result.
@@ -28,8 +63,14 @@ var ErrEmptyResponse = errors.New("get empty response from Google Maps API")
 // NewGoogleProvider initializes a new GoogleProvider with the given API key, logger, and number of workers.
 // It creates a Google Maps client with rate limiting based on the number of workers.
 // Returns a pointer to the GoogleProvider and an error if the client initialization fails.
-func NewGoogleProvider(client GoogleAPIClient, log *slog.Logger) *GoogleProvider {
-	return &GoogleProvider{client: client, log: log}
+func NewGoogleProvider(client GoogleAPIClient, log *slog.Logger, opts ...GoogleOption) *GoogleProvider {
+	gp := &GoogleProvider{client: client, log: log}
+
+	for _, opt := range opts {
+		opt(gp)
+	}
+
+	return gp
 }
 
 // Geocode takes a context and an address string as input, and returns the geographical coordinates
@@ -37,9 +78,58 @@ func NewGoogleProvider(client GoogleAPIClient, log *slog.Logger) *GoogleProvider
 // It logs the geocoding request and handles any errors that may occur during the process.
 // If the address cannot be geocoded or if the response is empty, it returns an appropriate error.
 func (gp *GoogleProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	result, err := gp.GeocodeDetailed(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and also returns the place_id
+// and formatted_address of the result, used to deduplicate places and link them to Google Maps.
+func (gp *GoogleProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
 	gp.log.DebugContext(ctx, "Geocoding using Google Maps", "address", address)
 
-	req := maps.GeocodingRequest{Address: address}
+	results, err := gp.geocode(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &results[0], nil
+}
+
+// GeocodeCandidates geocodes the address like GeocodeDetailed, but returns up to limit of the results
+// in Google's order instead of the first one. A limit below 1 returns a single result.
+// Google Maps has no result limit parameter, so the results are truncated after the request.
+func (gp *GoogleProvider) GeocodeCandidates(
+	ctx context.Context,
+	address string,
+	limit int,
+) ([]models.GeocodeResult, error) {
+	gp.log.DebugContext(ctx, "Geocoding candidates using Google Maps", "address", address, "limit", limit)
+
+	results, err := gp.geocode(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[:min(max(limit, 1), len(results))], nil
+}
+
+// geocode sends a geocoding request for the address and converts all of its results, in Google's order.
+// It returns ErrEmptyResponse if there are none.
+func (gp *GoogleProvider) geocode(ctx context.Context, address string) ([]models.GeocodeResult, error) {
+	if err := spendRequestBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	req := maps.GeocodingRequest{
+		Address:    address,
+		Language:   primaryLanguage(requestLanguage(ctx, gp.language)),
+		Region:     gp.region,
+		Components: gp.components,
+	}
 	geocodeResponse, err := gp.client.Geocode(ctx, &req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to geocode address: %w", err)
@@ -48,7 +138,65 @@ func (gp *GoogleProvider) Geocode(ctx context.Context, address string) (*models.
 	if len(geocodeResponse) == 0 {
 		return nil, ErrEmptyResponse
 	}
-	coords := geocodeResponse[0].Geometry.Location
 
-	return &models.Coordinates{Longitude: coords.Lng, Latitude: coords.Lat}, nil
+	results := make([]models.GeocodeResult, len(geocodeResponse))
+	for i, match := range geocodeResponse {
+		geometry := match.Geometry
+		results[i] = models.GeocodeResult{
+			Coordinates: models.Coordinates{
+				Longitude: geometry.Location.Lng,
+				Latitude:  geometry.Location.Lat,
+				Precision: googlePrecision(geometry.LocationType),
+			},
+			PlaceID:          match.PlaceID,
+			FormattedAddress: match.FormattedAddress,
+			Provider:         string(ProviderTypeGoogle),
+		}
+	}
+
+	return results, nil
+}
+
+// googlePrecision maps the Google Maps location_type of a result to a precision level.
+func googlePrecision(locationType string) models.Precision {
+	switch locationType {
+	case "ROOFTOP":
+		return models.PrecisionRooftop
+	case "RANGE_INTERPOLATED", "GEOMETRIC_CENTER":
+		return models.PrecisionStreet
+	default:
+		return models.PrecisionApproximate
+	}
+}
+
+// ReverseGeocode converts geographic coordinates into a formatted address
+// using the Google Maps Geocoding API.
+func (gp *GoogleProvider) ReverseGeocode(ctx context.Context, coords models.Coordinates) (string, error) {
+	gp.log.DebugContext(ctx, "Reverse geocoding using Google Maps", "lat", coords.Latitude, "lon", coords.Longitude)
+
+	req := maps.GeocodingRequest{
+		LatLng:   &maps.LatLng{Lat: coords.Latitude, Lng: coords.Longitude},
+		Language: primaryLanguage(requestLanguage(ctx, gp.language)),
+	}
+	geocodeResponse, err := gp.client.ReverseGeocode(ctx, &req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reverse geocode coordinates: %w", err)
+	}
+
+	if len(geocodeResponse) == 0 {
+		return "", ErrEmptyResponse
+	}
+
+	return geocodeResponse[0].FormattedAddress, nil
+}
+
+// HealthCheck verifies that the Google Maps API is reachable and the API key is valid
+// by geocoding a well-known address.
+func (gp *GoogleProvider) HealthCheck(ctx context.Context) error {
+	req := maps.GeocodingRequest{Address: healthCheckAddress}
+	if _, err := gp.client.Geocode(ctx, &req); err != nil {
+		return fmt.Errorf("google maps health check failed: %w", err)
+	}
+
+	return nil
 }
@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
 	"github.com/UnknownOlympus/atlas/test/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"googlemaps.github.io/maps"
 )
@@ -46,7 +48,7 @@ func TestGeocode(t *testing.T) {
 		address := "1600 Amphitheatre Parkway, Mountain View, CA"
 		req := &maps.GeocodingRequest{Address: address}
 		mockReponse := []maps.GeocodingResult{
-			{Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 37.42, Lng: -122.08}}},
+			{Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 37.42, Lng: -122.08}, LocationType: "ROOFTOP"}},
 		}
 
 		mockClient.On("Geocode", ctx, req).Return(mockReponse, nil).Once()
@@ -57,6 +59,229 @@ func TestGeocode(t *testing.T) {
 		require.NotNil(t, coords)
 		require.InEpsilon(t, 37.42, coords.Latitude, 0.01)
 		require.InEpsilon(t, -122.08, coords.Longitude, 0.01)
+		assert.Equal(t, models.PrecisionRooftop, coords.Precision)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestGoogleProvider_GeocodeDetailed(t *testing.T) {
+	mockClient := mocks.NewGoogleAPIClient(t)
+	provider := geocoding.NewGoogleProvider(mockClient, slog.Default())
+	ctx := t.Context()
+
+	t.Run("api returns error", func(t *testing.T) {
+		req := &maps.GeocodingRequest{Address: "some invalid place"}
+
+		mockClient.On("Geocode", ctx, req).Return(nil, assert.AnError).Once()
+
+		result, err := provider.GeocodeDetailed(ctx, "some invalid place")
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("result carries place id and formatted address", func(t *testing.T) {
+		address := "1600 Amphitheatre Parkway, Mountain View, CA"
+		req := &maps.GeocodingRequest{Address: address}
+		mockReponse := []maps.GeocodingResult{{
+			Geometry:         maps.AddressGeometry{Location: maps.LatLng{Lat: 37.42, Lng: -122.08}, LocationType: "ROOFTOP"},
+			PlaceID:          "ChIJ2eUgeAK6j4ARbn5u_wAGqWA",
+			FormattedAddress: "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+		}}
+
+		mockClient.On("Geocode", ctx, req).Return(mockReponse, nil).Once()
+
+		result, err := provider.GeocodeDetailed(ctx, address)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.GeocodeResult{
+			Coordinates:      models.Coordinates{Latitude: 37.42, Longitude: -122.08, Precision: models.PrecisionRooftop},
+			PlaceID:          "ChIJ2eUgeAK6j4ARbn5u_wAGqWA",
+			FormattedAddress: "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+			Provider:         "google",
+		}, result)
+		assert.True(t, result.HasPlace())
+	})
+}
+
+func TestGoogleProvider_GeocodeCandidates(t *testing.T) {
+	mockClient := mocks.NewGoogleAPIClient(t)
+	provider := geocoding.NewGoogleProvider(mockClient, slog.Default())
+	ctx := t.Context()
+	address := "Springfield"
+	req := &maps.GeocodingRequest{Address: address}
+	mockResponse := []maps.GeocodingResult{
+		{
+			Geometry:         maps.AddressGeometry{Location: maps.LatLng{Lat: 39.78, Lng: -89.65}, LocationType: "APPROXIMATE"},
+			PlaceID:          "place-il",
+			FormattedAddress: "Springfield, IL, USA",
+		},
+		{
+			Geometry:         maps.AddressGeometry{Location: maps.LatLng{Lat: 37.21, Lng: -93.29}, LocationType: "APPROXIMATE"},
+			PlaceID:          "place-mo",
+			FormattedAddress: "Springfield, MO, USA",
+		},
+		{
+			Geometry:         maps.AddressGeometry{Location: maps.LatLng{Lat: 42.10, Lng: -72.59}, LocationType: "APPROXIMATE"},
+			PlaceID:          "place-ma",
+			FormattedAddress: "Springfield, MA, USA",
+		},
+	}
+
+	t.Run("returns up to limit results in order", func(t *testing.T) {
+		mockClient.On("Geocode", ctx, req).Return(mockResponse, nil).Once()
+
+		results, err := provider.GeocodeCandidates(ctx, address, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.GeocodeResult{
+			{
+				Coordinates:      models.Coordinates{Latitude: 39.78, Longitude: -89.65, Precision: models.PrecisionApproximate},
+				PlaceID:          "place-il",
+				FormattedAddress: "Springfield, IL, USA",
+				Provider:         "google",
+			},
+			{
+				Coordinates:      models.Coordinates{Latitude: 37.21, Longitude: -93.29, Precision: models.PrecisionApproximate},
+				PlaceID:          "place-mo",
+				FormattedAddress: "Springfield, MO, USA",
+				Provider:         "google",
+			},
+		}, results)
+	})
+
+	t.Run("returns all results below the limit", func(t *testing.T) {
+		mockClient.On("Geocode", ctx, req).Return(mockResponse, nil).Once()
+
+		results, err := provider.GeocodeCandidates(ctx, address, 5)
+
+		require.NoError(t, err)
+		assert.Len(t, results, 3)
+	})
+
+	t.Run("empty response", func(t *testing.T) {
+		mockClient.On("Geocode", ctx, req).Return([]maps.GeocodingResult{}, nil).Once()
+
+		results, err := provider.GeocodeCandidates(ctx, address, 5)
+
+		require.ErrorIs(t, err, geocoding.ErrEmptyResponse)
+		assert.Nil(t, results)
+	})
+}
+
+func TestGoogleProvider_Language(t *testing.T) {
+	mockClient := mocks.NewGoogleAPIClient(t)
+	provider := geocoding.NewGoogleProvider(mockClient, slog.Default(), geocoding.WithGoogleLanguage("de,en"))
+	ctx := t.Context()
+
+	// Google Maps accepts a single language, so only the first preference is sent
+	req := &maps.GeocodingRequest{Address: "Berlin", Language: "de"}
+	mockReponse := []maps.GeocodingResult{{Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 52.52}}}}
+
+	mockClient.On("Geocode", ctx, req).Return(mockReponse, nil).Once()
+
+	_, err := provider.Geocode(ctx, "Berlin")
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGoogleProvider_RequestLanguage(t *testing.T) {
+	mockClient := mocks.NewGoogleAPIClient(t)
+	provider := geocoding.NewGoogleProvider(mockClient, slog.Default(), geocoding.WithGoogleLanguage("uk"))
+	ctx := geocoding.WithLanguage(t.Context(), "ru,uk")
+
+	// The language of the request takes precedence, reduced to its first preference
+	req := &maps.GeocodingRequest{Address: "Москва", Language: "ru"}
+	mockReponse := []maps.GeocodingResult{{Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 55.75}}}}
+
+	mockClient.On("Geocode", ctx, req).Return(mockReponse, nil).Once()
+
+	_, err := provider.Geocode(ctx, "Москва")
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGoogleProvider_RegionAndComponents(t *testing.T) {
+	mockClient := mocks.NewGoogleAPIClient(t)
+	provider := geocoding.NewGoogleProvider(mockClient, slog.Default(),
+		geocoding.WithGoogleRegion("ua"),
+		geocoding.WithGoogleComponents(map[maps.Component]string{maps.ComponentCountry: "ua"}),
+	)
+	ctx := t.Context()
+
+	// Only results in Ukraine are accepted, so the village isn't matched to a namesake abroad
+	req := &maps.GeocodingRequest{
+		Address:    "с. Грабовець",
+		Region:     "ua",
+		Components: map[maps.Component]string{maps.ComponentCountry: "ua"},
+	}
+	mockReponse := []maps.GeocodingResult{{Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 49.95}}}}
+
+	mockClient.On("Geocode", ctx, req).Return(mockReponse, nil).Once()
+
+	_, err := provider.Geocode(ctx, "с. Грабовець")
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGoogleProvider_HealthCheck(t *testing.T) {
+	mockClient := mocks.NewGoogleAPIClient(t)
+	provider := geocoding.NewGoogleProvider(mockClient, slog.Default())
+	ctx := t.Context()
+
+	t.Run("healthy", func(t *testing.T) {
+		mockClient.On("Geocode", ctx, mock.Anything).Return([]maps.GeocodingResult{}, nil).Once()
+
+		require.NoError(t, provider.HealthCheck(ctx))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("api returns error", func(t *testing.T) {
+		mockClient.On("Geocode", ctx, mock.Anything).Return(nil, assert.AnError).Once()
+
+		err := provider.HealthCheck(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestGoogleProvider_ReverseGeocode(t *testing.T) {
+	mockClient := mocks.NewGoogleAPIClient(t)
+	provider := geocoding.NewGoogleProvider(mockClient, slog.Default())
+	ctx := t.Context()
+	coords := models.Coordinates{Latitude: 37.42, Longitude: -122.08}
+	req := &maps.GeocodingRequest{LatLng: &maps.LatLng{Lat: 37.42, Lng: -122.08}}
+
+	t.Run("successful reverse geocoding", func(t *testing.T) {
+		mockResponse := []maps.GeocodingResult{{FormattedAddress: "1600 Amphitheatre Pkwy, Mountain View, CA"}}
+		mockClient.On("ReverseGeocode", ctx, req).Return(mockResponse, nil).Once()
+
+		address, err := provider.ReverseGeocode(ctx, coords)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1600 Amphitheatre Pkwy, Mountain View, CA", address)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("api return empty response", func(t *testing.T) {
+		mockClient.On("ReverseGeocode", ctx, req).Return(nil, nil).Once()
+
+		_, err := provider.ReverseGeocode(ctx, coords)
+
+		require.ErrorIs(t, err, geocoding.ErrEmptyResponse)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("api returns error", func(t *testing.T) {
+		mockClient.On("ReverseGeocode", ctx, req).Return(nil, assert.AnError).Once()
+
+		_, err := provider.ReverseGeocode(ctx, coords)
+
+		require.ErrorIs(t, err, assert.AnError)
 		mockClient.AssertExpectations(t)
 	})
 }
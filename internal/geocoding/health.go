@@ -0,0 +1,56 @@
+package geocoding
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// healthCheckAddress is a well-known address used by providers that have no
+// dedicated status endpoint and must be probed with a real geocoding request.
+const healthCheckAddress = "Київ, Україна"
+
+// HealthProbe wraps a Provider health check and caches its result for a
+// configured TTL, so frequent /ready calls don't hammer the upstream API.
+type HealthProbe struct {
+	provider Provider      // provider is the geocoding provider to probe
+	ttl      time.Duration // ttl is how long a probe result stays valid
+
+	mu        sync.Mutex // mu guards the cached result below
+	checkedAt time.Time  // checkedAt is the time of the last probe
+	lastErr   error      // lastErr is the result of the last probe
+}
+
+// NewHealthProbe creates a new HealthProbe for the given provider.
+// The probe result is cached for the given TTL.
+func NewHealthProbe(provider Provider, ttl time.Duration) *HealthProbe {
+	return &HealthProbe{provider: provider, ttl: ttl}
+}
+
+// Check returns the cached result of the last provider health check,
+// or performs a new check if the cached result has expired.
+// Concurrent callers wait for the in-flight probe instead of starting their own.
+func (hp *HealthProbe) Check(ctx context.Context) error {
+	hp.mu.Lock()
+	defer hp.mu.Unlock()
+
+	if !hp.checkedAt.IsZero() && time.Since(hp.checkedAt) < hp.ttl {
+		return hp.lastErr
+	}
+
+	hp.lastErr = hp.provider.HealthCheck(ctx)
+	hp.checkedAt = time.Now()
+
+	return hp.lastErr
+}
+
+// SetProvider replaces the probed provider and discards the cached result,
+// so the next check probes the new provider.
+func (hp *HealthProbe) SetProvider(provider Provider) {
+	hp.mu.Lock()
+	defer hp.mu.Unlock()
+
+	hp.provider = provider
+	hp.checkedAt = time.Time{}
+	hp.lastErr = nil
+}
@@ -0,0 +1,64 @@
+package geocoding_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthProbe_Check(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("result is cached within TTL", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("HealthCheck", mock.Anything).Return(nil).Once()
+
+		probe := geocoding.NewHealthProbe(mockProvider, time.Minute)
+
+		require.NoError(t, probe.Check(ctx))
+		require.NoError(t, probe.Check(ctx))
+		mockProvider.AssertNumberOfCalls(t, "HealthCheck", 1)
+	})
+
+	t.Run("error is cached within TTL", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("HealthCheck", mock.Anything).Return(assert.AnError).Once()
+
+		probe := geocoding.NewHealthProbe(mockProvider, time.Minute)
+
+		require.ErrorIs(t, probe.Check(ctx), assert.AnError)
+		require.ErrorIs(t, probe.Check(ctx), assert.AnError)
+		mockProvider.AssertNumberOfCalls(t, "HealthCheck", 1)
+	})
+
+	t.Run("probe is repeated after TTL expires", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("HealthCheck", mock.Anything).Return(assert.AnError).Once()
+		mockProvider.On("HealthCheck", mock.Anything).Return(nil).Once()
+
+		probe := geocoding.NewHealthProbe(mockProvider, time.Nanosecond)
+
+		require.ErrorIs(t, probe.Check(ctx), assert.AnError)
+		time.Sleep(time.Millisecond)
+		require.NoError(t, probe.Check(ctx))
+		mockProvider.AssertNumberOfCalls(t, "HealthCheck", 2)
+	})
+
+	t.Run("replaced provider is probed immediately", func(t *testing.T) {
+		oldProvider := mocks.NewProvider(t)
+		oldProvider.On("HealthCheck", mock.Anything).Return(assert.AnError).Once()
+		newProvider := mocks.NewProvider(t)
+		newProvider.On("HealthCheck", mock.Anything).Return(nil).Once()
+
+		probe := geocoding.NewHealthProbe(oldProvider, time.Minute)
+
+		require.ErrorIs(t, probe.Check(ctx), assert.AnError)
+		probe.SetProvider(newProvider)
+		require.NoError(t, probe.Check(ctx))
+	})
+}
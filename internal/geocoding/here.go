@@ -0,0 +1,231 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"golang.org/x/time/rate"
+)
+
+// HereBaseURL -- HERE Geocoding & Search API v7 geocode endpoint.
+const HereBaseURL = "https://geocode.search.hereapi.com/v1/geocode"
+
+// HereProvider implements geocoding using HERE Geocoding & Search API v7.
+type HereProvider struct {
+	client  HTTPClient    // HTTP client for making requests
+	baseURL string        // Base URL for the HERE geocode endpoint
+	apiKey  string        // API key with geocoding access
+	log     *slog.Logger  // Logger for logging operations
+	limiter *rate.Limiter // Rate limiter
+	timeout time.Duration // Overall deadline for a single Geocode call
+}
+
+// HereOption configures optional behavior of the HereProvider.
+type HereOption func(*HereProvider)
+
+// WithHereRequestTimeout sets the overall deadline for a single Geocode call.
+func WithHereRequestTimeout(timeout time.Duration) HereOption {
+	return func(hp *HereProvider) {
+		hp.timeout = timeout
+	}
+}
+
+// Common errors for HERE provider.
+var (
+	ErrHereEmptyResponse = errors.New("here API returned empty response")
+	ErrHereEmptyAddress  = errors.New("here provider got empty address")
+	ErrHereUnauthorized  = errors.New("here API unauthorized (invalid API key)")
+)
+
+// HERE API response (simplified for geocoding use-case).
+type hereResponse struct {
+	Items []struct {
+		ID         string `json:"id"`         // HERE place identifier
+		ResultType string `json:"resultType"` // Result type, e.g. "houseNumber", "street", "locality"
+		Address    struct {
+			Label string `json:"label"` // Formatted address of the item
+		} `json:"address"`
+		Scoring struct {
+			QueryScore float64 `json:"queryScore"` // How well the item matches the query, between 0 and 1
+		} `json:"scoring"`
+		Position struct {
+			Lat float64 `json:"lat"` // Latitude
+			Lng float64 `json:"lng"` // Longitude
+		} `json:"position"`
+	} `json:"items"`
+}
+
+// herePrecision maps the resultType of a HERE item to a precision level.
+func herePrecision(resultType string) models.Precision {
+	switch resultType {
+	case "houseNumber", "place":
+		return models.PrecisionRooftop
+	case "street", "intersection":
+		return models.PrecisionStreet
+	case "locality":
+		return models.PrecisionLocality
+	case "administrativeArea":
+		return models.PrecisionRegion
+	default:
+		return models.PrecisionApproximate
+	}
+}
+
+// NewHereProvider creates a new HERE geocoding provider using the shared HTTP transport.
+func NewHereProvider(apiKey string, rateLimit int, log *slog.Logger, opts ...HereOption) *HereProvider {
+	return NewHereProviderWithClient(
+		newHTTPClient(nil, DefaultHTTPTimeout),
+		apiKey,
+		rate.NewLimiter(rate.Limit(rateLimit), rateLimit),
+		log,
+		opts...,
+	)
+}
+
+// NewHereProviderWithClient allows injecting custom HTTP client.
+func NewHereProviderWithClient(
+	client HTTPClient,
+	apiKey string,
+	limiter *rate.Limiter,
+	log *slog.Logger,
+	opts ...HereOption,
+) *HereProvider {
+	hp := &HereProvider{
+		client:  client,
+		baseURL: HereBaseURL,
+		apiKey:  apiKey,
+		log:     log,
+		limiter: limiter,
+		timeout: DefaultRequestTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(hp)
+	}
+
+	return hp
+}
+
+// Geocode converts address into geographic coordinates using HERE API.
+func (hp *HereProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	result, err := hp.GeocodeDetailed(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and also returns the id, address label
+// and query score of the matched item.
+func (hp *HereProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
+	// Bound the whole call, including the rate limiter wait
+	ctx, cancel := context.WithTimeout(ctx, hp.timeout)
+	defer cancel()
+
+	// Rate limit
+	if err := hp.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	hp.log.DebugContext(ctx, "Geocoding using HERE", "address", address)
+
+	if address == "" {
+		return nil, ErrHereEmptyAddress
+	}
+
+	reqURL, err := url.Parse(hp.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	query := reqURL.Query()
+	query.Set("q", address)
+	query.Set("limit", "1")
+	query.Set("lang", "uk-UA")
+	query.Set("apiKey", hp.apiKey)
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Headers
+	req.Header.Set("Accept", "application/json")
+
+	if err := spendRequestBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := hp.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute geocoding request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// continue
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrHereUnauthorized
+	case http.StatusTooManyRequests:
+		hp.log.WarnContext(ctx, "HERE API rate limit exceeded", "address", address)
+		return nil, &RateLimitError{Provider: string(ProviderTypeHere)}
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		hp.log.ErrorContext(ctx, "HERE API error", "status", resp.StatusCode, "body", string(body))
+		return nil, &StatusError{Provider: "here", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	hp.log.DebugContext(ctx, "HERE raw response", "body", string(body))
+
+	var result hereResponse
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode here response: %w", err)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, ErrHereEmptyResponse
+	}
+
+	item := result.Items[0]
+	position := item.Position
+
+	hp.log.InfoContext(ctx, "HERE found result", "address", address, "lat", position.Lat, "lon", position.Lng)
+
+	return &models.GeocodeResult{
+		Coordinates: models.Coordinates{
+			Latitude:  position.Lat,
+			Longitude: position.Lng,
+			Precision: herePrecision(item.ResultType),
+		},
+		PlaceID:          item.ID,
+		FormattedAddress: item.Address.Label,
+		Provider:         string(ProviderTypeHere),
+		Confidence:       item.Scoring.QueryScore,
+	}, nil
+}
+
+// HealthCheck verifies that the HERE API is reachable and the API key is valid
+// by geocoding a well-known address.
+func (hp *HereProvider) HealthCheck(ctx context.Context) error {
+	if _, err := hp.Geocode(ctx, healthCheckAddress); err != nil {
+		return fmt.Errorf("here health check failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package geocoding_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestHereProvider_Geocode(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	apiKey := "test-api-key"
+	defaultRL := rate.NewLimiter(rate.Inf, 0)
+
+	t.Run("successfull geocoding", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				// Verify request parameters
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Contains(t, req.URL.String(), geocoding.HereBaseURL)
+				assert.Equal(t, "Київ, Хрещатик, 1", req.URL.Query().Get("q"))
+				assert.Equal(t, apiKey, req.URL.Query().Get("apiKey"))
+				assert.Equal(t, "1", req.URL.Query().Get("limit"))
+				assert.Equal(t, "application/json", req.Header.Get("Accept"))
+
+				responseBody := `{"items":[{"title":"Хрещатик 1","resultType":"houseNumber",` +
+					`"position":{"lat":50.4501,"lng":30.5234}}]}`
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewHereProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Київ, Хрещатик, 1")
+
+		require.NoError(t, err)
+		require.NotNil(t, coords)
+		assert.InEpsilon(t, 50.4501, coords.Latitude, 0.0001)
+		assert.InEpsilon(t, 30.5234, coords.Longitude, 0.0001)
+		assert.Equal(t, models.PrecisionRooftop, coords.Precision)
+	})
+
+	t.Run("empty response", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"items":[]}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewHereProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Nowhere")
+
+		require.ErrorIs(t, err, geocoding.ErrHereEmptyResponse)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusUnauthorized,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"error":"Unauthorized"}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewHereProviderWithClient(mockClient, "bad-key", defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrHereUnauthorized)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("empty address", func(t *testing.T) {
+		provider := geocoding.NewHereProviderWithClient(&mockHTTPClient{}, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "")
+
+		require.ErrorIs(t, err, geocoding.ErrHereEmptyAddress)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(bytes.NewBufferString(`Too Many Requests`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewHereProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+		assert.Nil(t, coords)
+	})
+}
+
+func TestHereProvider_GeocodeDetailed(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(_ *http.Request) (*http.Response, error) {
+			responseBody := `{"items":[{"id":"here:af:streetsection:abc","title":"Хрещатик 1",` +
+				`"resultType":"houseNumber","address":{"label":"вулиця Хрещатик 1, Київ, Україна"},` +
+				`"scoring":{"queryScore":0.92},"position":{"lat":50.4501,"lng":30.5234}}]}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+			}, nil
+		},
+	}
+
+	provider := geocoding.NewHereProviderWithClient(
+		mockClient, "test-api-key", rate.NewLimiter(rate.Inf, 0), slog.Default(),
+	)
+	result, err := provider.GeocodeDetailed(t.Context(), "Київ, Хрещатик, 1")
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.GeocodeResult{
+		Coordinates:      models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop},
+		PlaceID:          "here:af:streetsection:abc",
+		FormattedAddress: "вулиця Хрещатик 1, Київ, Україна",
+		Provider:         "here",
+		Confidence:       0.92,
+	}, result)
+}
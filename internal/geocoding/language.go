@@ -0,0 +1,32 @@
+package geocoding
+
+import "context"
+
+// languageKey is the context key of the preferred result language.
+type languageKey struct{}
+
+// WithLanguage returns a copy of ctx carrying the preferred result language of the requests made with it,
+// e.g. "uk" or "ru,uk", which overrides the language configured for the provider. An empty language
+// returns ctx as is. Providers without a language setting ignore it.
+func WithLanguage(ctx context.Context, language string) context.Context {
+	if language == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// LanguageFrom returns the preferred result language carried by ctx, or an empty string if there is none.
+func LanguageFrom(ctx context.Context) string {
+	language, _ := ctx.Value(languageKey{}).(string)
+	return language
+}
+
+// requestLanguage returns the preferred result language carried by ctx, or fallback if there is none.
+func requestLanguage(ctx context.Context, fallback string) string {
+	if language := LanguageFrom(ctx); language != "" {
+		return language
+	}
+
+	return fallback
+}
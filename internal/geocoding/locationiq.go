@@ -0,0 +1,200 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"golang.org/x/time/rate"
+)
+
+// LocationIQBaseURL -- LocationIQ search endpoint (Nominatim-compatible).
+const LocationIQBaseURL = "https://us1.locationiq.com/v1/search.php"
+
+// LocationIQProvider implements geocoding using LocationIQ, a hosted and keyed Nominatim.
+// Its responses match Nominatim's, so it shares the Nominatim response parsing and returns
+// ErrNominatimEmptyResponse and ErrNominatimInvalidCoords for empty and invalid results.
+type LocationIQProvider struct {
+	client   HTTPClient    // HTTP client for making requests
+	baseURL  string        // Base URL for the LocationIQ search endpoint
+	apiKey   string        // API key (access token)
+	log      *slog.Logger  // Logger for logging operations
+	limiter  *rate.Limiter // Rate limiter
+	timeout  time.Duration // Overall deadline for a single Geocode call
+	language string        // Preferred result languages, sent as accept-language
+}
+
+// LocationIQOption configures optional behavior of the LocationIQProvider.
+type LocationIQOption func(*LocationIQProvider)
+
+// WithLocationIQRequestTimeout sets the overall deadline for a single Geocode call.
+func WithLocationIQRequestTimeout(timeout time.Duration) LocationIQOption {
+	return func(lp *LocationIQProvider) {
+		lp.timeout = timeout
+	}
+}
+
+// WithLocationIQLanguage sets the preferred languages of the results, as a comma-separated
+// list of language codes in order of preference. The default is DefaultLanguage.
+// A language carried by the request context, see WithLanguage, takes precedence.
+func WithLocationIQLanguage(language string) LocationIQOption {
+	return func(lp *LocationIQProvider) {
+		lp.language = language
+	}
+}
+
+// ErrLocationIQUnauthorized is returned when LocationIQ rejects the API key.
+var ErrLocationIQUnauthorized = errors.New("locationiq API unauthorized (invalid API key)")
+
+// NewLocationIQProvider creates a new LocationIQ geocoding provider using the shared HTTP transport.
+func NewLocationIQProvider(
+	apiKey string,
+	rateLimit int,
+	log *slog.Logger,
+	opts ...LocationIQOption,
+) *LocationIQProvider {
+	return NewLocationIQProviderWithClient(
+		newHTTPClient(nil, DefaultHTTPTimeout),
+		apiKey,
+		rate.NewLimiter(rate.Limit(rateLimit), rateLimit),
+		log,
+		opts...,
+	)
+}
+
+// NewLocationIQProviderWithClient allows injecting custom HTTP client.
+func NewLocationIQProviderWithClient(
+	client HTTPClient,
+	apiKey string,
+	limiter *rate.Limiter,
+	log *slog.Logger,
+	opts ...LocationIQOption,
+) *LocationIQProvider {
+	lp := &LocationIQProvider{
+		client:   client,
+		baseURL:  LocationIQBaseURL,
+		apiKey:   apiKey,
+		log:      log,
+		limiter:  limiter,
+		timeout:  DefaultRequestTimeout,
+		language: DefaultLanguage,
+	}
+
+	for _, opt := range opts {
+		opt(lp)
+	}
+
+	return lp
+}
+
+// Geocode converts address into geographic coordinates using LocationIQ API.
+func (lp *LocationIQProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	result, err := lp.GeocodeDetailed(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and also returns the display name
+// of the matched place as its formatted address.
+func (lp *LocationIQProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
+	// Bound the whole call, including the rate limiter wait
+	ctx, cancel := context.WithTimeout(ctx, lp.timeout)
+	defer cancel()
+
+	// Rate limit
+	if err := lp.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit exceeded: %w", err)
+	}
+
+	lp.log.DebugContext(ctx, "Geocoding using LocationIQ", "address", address)
+
+	reqURL, err := url.Parse(lp.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	query := reqURL.Query()
+	query.Set("key", lp.apiKey)
+	query.Set("q", address)
+	query.Set("format", "json")
+	query.Set("limit", "1")
+	query.Set("addressdetails", "1")
+	query.Set("accept-language", requestLanguage(ctx, lp.language))
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Headers
+	req.Header.Set("Accept", "application/json")
+
+	if err := spendRequestBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := lp.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute geocoding request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// continue
+	case http.StatusNotFound:
+		// LocationIQ responds with 404 "Unable to geocode" when nothing matches
+		return nil, ErrNominatimEmptyResponse
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrLocationIQUnauthorized
+	case http.StatusTooManyRequests:
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		lp.log.WarnContext(ctx, "LocationIQ API rate limit exceeded", "address", address, "retry_after", retryAfter)
+		return nil, &RateLimitError{Provider: string(ProviderTypeLocationIQ), RetryAfter: retryAfter}
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		lp.log.ErrorContext(ctx, "LocationIQ API error", "status", resp.StatusCode, "body", string(body))
+		return nil, &StatusError{Provider: "locationiq", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	lp.log.DebugContext(ctx, "LocationIQ raw response", "body", string(body))
+
+	result, err := decodeNominatimSearch(body)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := result.geocodeResult(ProviderTypeLocationIQ)
+	if err != nil {
+		return nil, err
+	}
+
+	lp.log.InfoContext(ctx, "LocationIQ found result", "address", address, "lat", match.Latitude, "lon", match.Longitude)
+
+	return match, nil
+}
+
+// HealthCheck verifies that the LocationIQ API is reachable and the API key is valid
+// by geocoding a well-known address.
+func (lp *LocationIQProvider) HealthCheck(ctx context.Context) error {
+	if _, err := lp.Geocode(ctx, healthCheckAddress); err != nil {
+		return fmt.Errorf("locationiq health check failed: %w", err)
+	}
+
+	return nil
+}
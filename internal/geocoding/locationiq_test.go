@@ -0,0 +1,133 @@
+package geocoding_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestLocationIQProvider_Geocode(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	apiKey := "test-api-key"
+	defaultRL := rate.NewLimiter(rate.Inf, 0)
+
+	t.Run("successfull keyed geocoding", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				// Verify request parameters
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Contains(t, req.URL.String(), geocoding.LocationIQBaseURL)
+				assert.Equal(t, apiKey, req.URL.Query().Get("key"))
+				assert.Equal(t, "Київ, Хрещатик, 1", req.URL.Query().Get("q"))
+				assert.Equal(t, "json", req.URL.Query().Get("format"))
+				assert.Equal(t, "1", req.URL.Query().Get("limit"))
+
+				// Same response shape as Nominatim
+				responseBody := `[{"lat":"50.4501","lon":"30.5234","display_name":"Хрещатик, 1, Київ"}]`
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewLocationIQProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Київ, Хрещатик, 1")
+
+		require.NoError(t, err)
+		require.NotNil(t, coords)
+		assert.InEpsilon(t, 50.4501, coords.Latitude, 0.0001)
+		assert.InEpsilon(t, 30.5234, coords.Longitude, 0.0001)
+	})
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    error
+	}{
+		{
+			name:       "empty result list",
+			statusCode: http.StatusOK,
+			body:       `[]`,
+			wantErr:    geocoding.ErrNominatimEmptyResponse,
+		},
+		{
+			name:       "not found status",
+			statusCode: http.StatusNotFound,
+			body:       `{"error":"Unable to geocode"}`,
+			wantErr:    geocoding.ErrNominatimEmptyResponse,
+		},
+		{
+			name:       "invalid coordinates",
+			statusCode: http.StatusOK,
+			body:       `[{"lat":"invalid","lon":"30.5234"}]`,
+			wantErr:    geocoding.ErrNominatimInvalidCoords,
+		},
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"error":"Invalid key"}`,
+			wantErr:    geocoding.ErrLocationIQUnauthorized,
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			body:       `{"error":"Rate Limited Second"}`,
+			wantErr:    geocoding.ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(_ *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewLocationIQProviderWithClient(mockClient, apiKey, defaultRL, logger)
+			coords, err := provider.Geocode(ctx, "Kyiv")
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, coords)
+		})
+	}
+}
+
+func TestLocationIQProvider_GeocodeDetailed(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(_ *http.Request) (*http.Response, error) {
+			responseBody := `[{"lat":"50.4501","lon":"30.5234","addresstype":"building",` +
+				`"display_name":"Хрещатик, 1, Київ"}]`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+			}, nil
+		},
+	}
+
+	provider := geocoding.NewLocationIQProviderWithClient(
+		mockClient, "test-api-key", rate.NewLimiter(rate.Inf, 0), slog.Default(),
+	)
+	result, err := provider.GeocodeDetailed(t.Context(), "Київ, Хрещатик, 1")
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.GeocodeResult{
+		Coordinates:      models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop},
+		FormattedAddress: "Хрещатик, 1, Київ",
+		Provider:         "locationiq",
+	}, result)
+}
@@ -1,6 +1,7 @@
 package geocoding
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,7 +10,11 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/models"
@@ -23,6 +28,165 @@ type NominatimProvider struct {
 	log     *slog.Logger // Logger for logging operations
 	// userAgent is required by Nominatim usage policy
 	userAgent string
+	// timeout bounds the whole Geocode call, including all fallback requests
+	timeout time.Duration
+	// minPrecision is the coarsest result precision that is accepted
+	minPrecision NominatimPrecision
+	// disableFallback restricts Geocode to the full-address lookup
+	disableFallback bool
+	// language is the preferred result language list, sent as accept-language
+	language string
+	// postalCodeFallback adds a postal code lookup as the last fallback level
+	postalCodeFallback bool
+	// structuredSearch tries a structured search of the parsed address before the free-form one
+	structuredSearch bool
+	// resultLimit is the number of results requested per search
+	resultLimit int
+	// selector picks the match among the results of a search
+	selector ResultSelector
+	// onFallback is called with the number of searches sent by each Geocode call, nil disables it
+	onFallback FallbackObserver
+
+	mu           sync.Mutex // mu guards backoffUntil
+	backoffUntil time.Time  // backoffUntil is the end of the Retry-After window of the last 429 response
+}
+
+// NominatimOption configures optional behavior of the NominatimProvider.
+type NominatimOption func(*NominatimProvider)
+
+// WithNominatimRequestTimeout sets the overall deadline for a single Geocode call.
+func WithNominatimRequestTimeout(timeout time.Duration) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.timeout = timeout
+	}
+}
+
+// WithNominatimMinPrecision rejects results coarser than the given precision,
+// e.g. an administrative-area centroid when house-level precision is required.
+func WithNominatimMinPrecision(precision NominatimPrecision) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.minPrecision = precision
+	}
+}
+
+// WithNominatimDisableFallback makes Geocode perform only the full-address lookup and return
+// ErrNominatimEmptyResponse on a miss, instead of falling back to coarser address variations
+// (e.g. a village centroid) that may be mistaken for a precise location.
+func WithNominatimDisableFallback(disable bool) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.disableFallback = disable
+	}
+}
+
+// WithNominatimLanguage sets the preferred languages of the results, as a comma-separated
+// list of language codes in order of preference (e.g. "de,en"). The default is DefaultLanguage.
+// A language carried by the request context, see WithLanguage, takes precedence.
+func WithNominatimLanguage(language string) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.language = language
+	}
+}
+
+// WithNominatimPostalCodeFallback makes Geocode, as a last resort after all address variations failed,
+// look up the 5-digit postal code found in the address, so that the centroid of its delivery area
+// is returned instead of no result. Addresses without a postal code are not affected.
+func WithNominatimPostalCodeFallback(enable bool) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.postalCodeFallback = enable
+	}
+}
+
+// WithNominatimStructuredSearch makes Geocode first split the address into a settlement, a street and a house
+// number by their Ukrainian type markers ("с.", "м.", "вул." etc.) and look it up with a structured search,
+// which Nominatim matches more reliably than free-form text. If the address can't be split, or the structured
+// search finds nothing, the free-form fallback sequence follows as usual.
+func WithNominatimStructuredSearch(enable bool) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.structuredSearch = enable
+	}
+}
+
+// WithNominatimResultLimit makes each search request up to limit results instead of only the top one,
+// letting the result selector (see WithNominatimResultSelector) choose among them. The limit is clamped
+// to between 1 and MaxNominatimResultLimit.
+func WithNominatimResultLimit(limit int) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.resultLimit = min(max(limit, 1), MaxNominatimResultLimit)
+	}
+}
+
+// WithNominatimResultSelector sets how the match is picked among the results of a search.
+// The default is FirstResultSelector, which keeps Nominatim's own ranking.
+func WithNominatimResultSelector(selector ResultSelector) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.selector = selector
+	}
+}
+
+// FallbackObserver is called after each Nominatim Geocode call with the number of searches it sent,
+// the full address and its fallback variations, whether or not one of them found the address.
+type FallbackObserver func(attempts int)
+
+// WithNominatimFallbackObserver sets the observer of the number of searches sent by each Geocode call,
+// which measures the extra upstream load generated by the address fallbacks.
+func WithNominatimFallbackObserver(observer FallbackObserver) NominatimOption {
+	return func(np *NominatimProvider) {
+		np.onFallback = observer
+	}
+}
+
+// NominatimPrecision is the precision level of a Nominatim result, from coarsest to finest.
+type NominatimPrecision int
+
+const (
+	// NominatimPrecisionAny accepts any result, including region centroids. It disables filtering.
+	NominatimPrecisionAny NominatimPrecision = iota
+	// NominatimPrecisionSettlement requires a city, town, village or finer result.
+	NominatimPrecisionSettlement
+	// NominatimPrecisionStreet requires a street or finer result.
+	NominatimPrecisionStreet
+	// NominatimPrecisionHouse requires a house or building result.
+	NominatimPrecisionHouse
+)
+
+// nominatimPrecisionNames maps configuration values to precision levels.
+var nominatimPrecisionNames = map[string]NominatimPrecision{
+	"":           NominatimPrecisionAny,
+	"any":        NominatimPrecisionAny,
+	"settlement": NominatimPrecisionSettlement,
+	"street":     NominatimPrecisionStreet,
+	"house":      NominatimPrecisionHouse,
+}
+
+// ParseNominatimPrecision parses a precision level name: "any" (or empty), "settlement", "street" or "house".
+func ParseNominatimPrecision(name string) (NominatimPrecision, error) {
+	precision, ok := nominatimPrecisionNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return NominatimPrecisionAny, fmt.Errorf("unknown nominatim precision: %s", name)
+	}
+
+	return precision, nil
+}
+
+// nominatimAddressTypePrecision maps Nominatim address types to precision levels.
+// Address types not listed here (state, district, country, etc.) are treated as NominatimPrecisionAny.
+var nominatimAddressTypePrecision = map[string]NominatimPrecision{
+	"house":             NominatimPrecisionHouse,
+	"building":          NominatimPrecisionHouse,
+	"amenity":           NominatimPrecisionHouse,
+	"shop":              NominatimPrecisionHouse,
+	"office":            NominatimPrecisionHouse,
+	"road":              NominatimPrecisionStreet,
+	"square":            NominatimPrecisionStreet,
+	"city":              NominatimPrecisionSettlement,
+	"town":              NominatimPrecisionSettlement,
+	"village":           NominatimPrecisionSettlement,
+	"hamlet":            NominatimPrecisionSettlement,
+	"isolated_dwelling": NominatimPrecisionSettlement,
+	"suburb":            NominatimPrecisionSettlement,
+	"city_district":     NominatimPrecisionSettlement,
+	"quarter":           NominatimPrecisionSettlement,
+	"neighbourhood":     NominatimPrecisionSettlement,
 }
 
 // HTTPClient defines the interface for making HTTP requests.
@@ -33,74 +197,239 @@ type HTTPClient interface {
 
 // nominatimResponse represents the JSON response from Nominatim API.
 type nominatimResponse struct {
-	Lat string `json:"lat"` // Latitude as string
-	Lon string `json:"lon"` // Longitude as string
+	Lat         string            `json:"lat"`          // Latitude as string
+	Lon         string            `json:"lon"`          // Longitude as string
+	AddressType string            `json:"addresstype"`  // Type of the matched place, e.g. "village", "road", "house"
+	Address     map[string]string `json:"address"`      // Address breakdown, requested with addressdetails=1
+	DisplayName string            `json:"display_name"` // Formatted address of the matched place
+}
+
+// precision returns the precision level of the result. It is derived from the address type,
+// falling back to the finest component present in the address breakdown.
+func (r nominatimResponse) precision() NominatimPrecision {
+	if precision, ok := nominatimAddressTypePrecision[r.AddressType]; ok {
+		return precision
+	}
+
+	switch {
+	case r.Address["house_number"] != "":
+		return NominatimPrecisionHouse
+	case r.Address["road"] != "":
+		return NominatimPrecisionStreet
+	case r.Address["city"] != "", r.Address["town"] != "", r.Address["village"] != "", r.Address["hamlet"] != "":
+		return NominatimPrecisionSettlement
+	default:
+		return NominatimPrecisionAny
+	}
+}
+
+// nominatimReverseResponse represents the JSON response from Nominatim reverse endpoint.
+type nominatimReverseResponse struct {
+	DisplayName string `json:"display_name"` // Formatted address of the place
+	Error       string `json:"error"`        // Error is set when no place was found
+}
+
+// nominatimStatusResponse represents the JSON response from Nominatim status endpoint.
+type nominatimStatusResponse struct {
+	Status  int    `json:"status"`  // Status is 0 when the service is operational
+	Message string `json:"message"` // Message describes the service state
+}
+
+// nominatimCountry is the country addresses are geocoded in. It is sent with postal code lookups, as postal
+// codes are only unique within a country, and preferred by the best-match result selection.
+const nominatimCountry = "ua"
+
+// postalCodePattern matches a standalone 5-digit Ukrainian postal code, e.g. "79000" in "79000, м. Львів".
+var postalCodePattern = regexp.MustCompile(`(?:^|[^0-9])([0-9]{5})(?:[^0-9]|$)`)
+
+// extractPostalCode returns the first 5-digit postal code found in the address.
+func extractPostalCode(address string) (string, bool) {
+	match := postalCodePattern.FindStringSubmatch(address)
+	if match == nil {
+		return "", false
+	}
+
+	return match[1], true
+}
+
+// nominatimSearch is a single search request of the fallback sequence.
+type nominatimSearch struct {
+	variation string     // variation is the address variation or postal code that is looked up
+	params    url.Values // params are the search parameters that identify the place
+	level     int        // level is the fallback level of the search, 0 for the full address
 }
 
 // Common errors for Nominatim provider.
 var (
 	ErrNominatimEmptyResponse = errors.New("nominatim API returned empty response")
 	ErrNominatimInvalidCoords = errors.New("nominatim API returned invalid coordinates")
+	ErrNominatimUnhealthy     = errors.New("nominatim API reported unhealthy status")
 )
 
 // NewNominatimProvider creates a new Nominatim geocoding provider.
-// Uses the public Nominatim API endpoint by default.
-func NewNominatimProvider(log *slog.Logger) *NominatimProvider {
-	const timeout = 10
-	return &NominatimProvider{
-		client: &http.Client{
-			Timeout: timeout * time.Second,
-		},
+// Uses the public Nominatim API endpoint and the shared HTTP transport by default.
+func NewNominatimProvider(log *slog.Logger, opts ...NominatimOption) *NominatimProvider {
+	return NewNominatimProviderWithClient(newHTTPClient(nil, DefaultHTTPTimeout), log, opts...)
+}
+
+// NewNominatimProviderWithClient creates a Nominatim provider with a custom HTTP client.
+// Useful for testing with mocked HTTP clients.
+func NewNominatimProviderWithClient(client HTTPClient, log *slog.Logger, opts ...NominatimOption) *NominatimProvider {
+	np := &NominatimProvider{
+		client:  client,
 		baseURL: "https://nominatim.openstreetmap.org/search",
 		log:     log,
 		// User-Agent MUST include valid contact info per Nominatim usage policy:
 		// https://operations.osmfoundation.org/policies/nominatim/
-		userAgent: "Atlas-Geocoding-Service/1.0 (https://github.com/UnknownOlympus/atlas)",
+		userAgent:   "Atlas-Geocoding-Service/1.0 (https://github.com/UnknownOlympus/atlas)",
+		timeout:     DefaultRequestTimeout,
+		language:    DefaultLanguage,
+		resultLimit: 1,
+		selector:    FirstResultSelector{},
 	}
-}
 
-// NewNominatimProviderWithClient creates a Nominatim provider with a custom HTTP client.
-// Useful for testing with mocked HTTP clients.
-func NewNominatimProviderWithClient(client HTTPClient, log *slog.Logger) *NominatimProvider {
-	return &NominatimProvider{
-		client:    client,
-		baseURL:   "https://nominatim.openstreetmap.org/search",
-		log:       log,
-		userAgent: "Atlas-Geocoding-Service/1.0 (https://github.com/UnknownOlympus/atlas)",
+	for _, opt := range opts {
+		opt(np)
 	}
+
+	return np
 }
 
 // Geocode converts an address to geographic coordinates using the Nominatim API.
 // It respects Nominatim's usage policy by including a User-Agent header.
 //
 // Uses a progressive fallback strategy for rural addresses:
+// 0. Try a structured search of the parsed address, if enabled with WithNominatimStructuredSearch
 // 1. Try full address with house number
 // 2. Try address without house number (e.g., "с. Грабовець, вул. Польова")
 // 3. Try village/town name only (e.g., "с. Грабовець")
 // 4. Try district level
+// 5. Try the postal code, if enabled with WithNominatimPostalCodeFallback
+//
+// The whole fallback sequence is bounded by the provider request timeout; once it expires,
+// the in-flight request is canceled and the remaining fallbacks are not attempted.
+// The fallbacks can be disabled with WithNominatimDisableFallback.
 //
 // Note: Nominatim has a rate limit of 1 request/second for fair use.
 // For production use with high volume, consider self-hosting Nominatim or using a commercial provider.
 func (np *NominatimProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	result, err := np.GeocodeDetailed(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and also returns the display name
+// of the matched place as its formatted address.
+func (np *NominatimProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
 	np.log.DebugContext(ctx, "Geocoding using Nominatim", "address", address)
 
-	// Generate address fallback variations
-	addressVariations := np.generateAddressFallbacks(address)
+	return np.search(ctx, address, np.fallbackSearches(address))
+}
+
+// GeocodeStructured geocodes a structured address like GeocodeDetailed geocodes its free-form text,
+// but starts with a structured search of its city, street and house number if it has a city and a street.
+// The settlement type is dropped from the city and the street type is spelled out, as OSM names them.
+// The structured search is tried whether or not it is enabled with WithNominatimStructuredSearch.
+func (np *NominatimProvider) GeocodeStructured(
+	ctx context.Context,
+	address models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	text := address.String()
+	np.log.DebugContext(ctx, "Geocoding structured address using Nominatim", "address", text)
+
+	searches := np.freeFormSearches(text)
+	if structured, ok := structuredAddressOf(address); ok {
+		searches = append([]nominatimSearch{{variation: text, params: structured.params()}}, searches...)
+	}
+
+	return np.search(ctx, text, searches)
+}
+
+// GeocodeCandidates geocodes the address like GeocodeDetailed, but returns up to limit results of the first
+// search of the fallback sequence that finds any, in Nominatim's order, instead of selecting one of them.
+// A limit below 1 requests a single result, and a limit above MaxNominatimResultLimit is capped.
+func (np *NominatimProvider) GeocodeCandidates(
+	ctx context.Context,
+	address string,
+	limit int,
+) ([]models.GeocodeResult, error) {
+	np.log.DebugContext(ctx, "Geocoding candidates using Nominatim", "address", address, "limit", limit)
+
+	candidates, err := np.searchCandidates(ctx, address, np.fallbackSearches(address),
+		min(max(limit, 1), MaxNominatimResultLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.GeocodeResult, len(candidates))
+	for i, candidate := range candidates {
+		results[i] = candidate.GeocodeResult
+	}
 
-	// Try each address variation until we get results
-	for idx, addrVariation := range addressVariations {
-		coords, err := np.geocodeSingleAddress(ctx, addrVariation)
+	return results, nil
+}
+
+// search tries the searches for the address in order until one finds a result, within the provider
+// request timeout, and returns the candidate chosen by the result selector.
+// It returns ErrNominatimEmptyResponse if no search finds a result.
+func (np *NominatimProvider) search(
+	ctx context.Context,
+	address string,
+	searches []nominatimSearch,
+) (*models.GeocodeResult, error) {
+	candidates, err := np.searchCandidates(ctx, address, searches, np.resultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := candidates[np.selector.Select(candidates)]
+	np.log.DebugContext(ctx, "Nominatim found result",
+		"lat", selected.Latitude,
+		"lon", selected.Longitude,
+		"candidates", len(candidates))
+
+	return &selected.GeocodeResult, nil
+}
+
+// searchCandidates tries the searches for the address in order until one finds a result, within the provider
+// request timeout, requesting up to limit results per search. It returns the candidates of that search,
+// with their fallback level set, or ErrNominatimEmptyResponse if no search finds a result.
+func (np *NominatimProvider) searchCandidates(
+	ctx context.Context,
+	address string,
+	searches []nominatimSearch,
+	limit int,
+) ([]Candidate, error) {
+	ctx, cancel := context.WithTimeout(ctx, np.timeout)
+	defer cancel()
+
+	var attempts int
+	if np.onFallback != nil {
+		defer func() { np.onFallback(attempts) }()
+	}
+
+	// Try each search until we get results
+	for idx, search := range searches {
+		attempts++
+		candidates, err := np.geocodeSearch(ctx, search.params, limit)
 		if err == nil {
 			// Success! Log which fallback level worked
-			if idx == 0 {
-				np.log.DebugContext(ctx, "Geocoded with full address", "address", addrVariation)
+			if search.level == 0 {
+				np.log.DebugContext(ctx, "Geocoded with full address", "address", search.variation,
+					"structured", search.params.Has("street"))
 			} else {
 				np.log.InfoContext(ctx, "Geocoded using fallback address",
 					"original", address,
-					"fallback", addrVariation,
-					"fallback_level", idx)
+					"fallback", search.variation,
+					"fallback_level", search.level)
+			}
+			for i := range candidates {
+				candidates[i].FallbackLevel = search.level
 			}
-			return coords, nil
+			return candidates, nil
 		}
 
 		// If it's not an empty response error, return immediately (API error, invalid coords, etc.)
@@ -110,8 +439,9 @@ func (np *NominatimProvider) Geocode(ctx context.Context, address string) (*mode
 
 		// Empty response - try next fallback
 		np.log.DebugContext(ctx, "Address variation returned no results, trying fallback",
-			"variation", addrVariation,
-			"fallback_level", idx)
+			"variation", search.variation,
+			"fallback_level", search.level,
+			"search", idx)
 	}
 
 	// All fallbacks exhausted
@@ -121,11 +451,60 @@ func (np *NominatimProvider) Geocode(ctx context.Context, address string) (*mode
 		"address",
 		address,
 		"variations_tried",
-		len(addressVariations),
+		len(searches),
 	)
 	return nil, ErrNominatimEmptyResponse
 }
 
+// fallbackSearches returns the searches of the fallback sequence for the address: a structured search of
+// the full address if enabled and the address can be parsed, followed by its free-form searches.
+// Both searches of the full address are at fallback level 0.
+func (np *NominatimProvider) fallbackSearches(address string) []nominatimSearch {
+	searches := np.freeFormSearches(address)
+	if !np.structuredSearch {
+		return searches
+	}
+
+	structured, ok := parseStructuredAddress(address)
+	if !ok {
+		return searches
+	}
+
+	return append([]nominatimSearch{{variation: address, params: structured.params()}}, searches...)
+}
+
+// freeFormSearches returns a free-form search for each variation of the address, and a postal code search
+// if enabled and the address contains one. Only the full address is searched if fallbacks are disabled.
+func (np *NominatimProvider) freeFormSearches(address string) []nominatimSearch {
+	variations := np.generateAddressFallbacks(address)
+	if np.disableFallback {
+		variations = variations[:1]
+	}
+
+	searches := make([]nominatimSearch, 0, len(variations)+1)
+	for level, variation := range variations {
+		searches = append(searches, nominatimSearch{
+			variation: variation,
+			params:    url.Values{"q": {variation}},
+			level:     level,
+		})
+	}
+
+	if !np.postalCodeFallback || np.disableFallback {
+		return searches
+	}
+
+	if postalCode, ok := extractPostalCode(address); ok {
+		searches = append(searches, nominatimSearch{
+			variation: postalCode,
+			params:    url.Values{"postalcode": {postalCode}, "country": {nominatimCountry}},
+			level:     len(variations),
+		})
+	}
+
+	return searches
+}
+
 // generateAddressFallbacks creates a list of progressively simpler address variations.
 func (np *NominatimProvider) generateAddressFallbacks(address string) []string {
 	if address == "" {
@@ -173,8 +552,19 @@ func (np *NominatimProvider) generateAddressFallbacks(address string) []string {
 	return variations
 }
 
-// geocodeSingleAddress performs a single geocoding request without fallback logic.
-func (np *NominatimProvider) geocodeSingleAddress(ctx context.Context, address string) (*models.Coordinates, error) {
+// geocodeSearch performs a single geocoding request with the search parameters, without fallback logic,
+// requesting up to limit results. It returns the usable results as candidates, in Nominatim's order.
+func (np *NominatimProvider) geocodeSearch(ctx context.Context, params url.Values, limit int) ([]Candidate, error) {
+	// Don't send requests while the server-requested backoff is in effect
+	if err := np.checkBackoff(); err != nil {
+		return nil, err
+	}
+
+	// Every fallback search is a request of its own, spent from the request budget, if any
+	if err := spendRequestBudget(ctx); err != nil {
+		return nil, err
+	}
+
 	// Build request URL with query parameters
 	reqURL, err := url.Parse(np.baseURL)
 	if err != nil {
@@ -182,11 +572,15 @@ func (np *NominatimProvider) geocodeSingleAddress(ctx context.Context, address s
 	}
 
 	query := reqURL.Query()
-	query.Set("q", address)
+	for key, values := range params {
+		query[key] = values
+	}
+	language := requestLanguage(ctx, np.language)
 	query.Set("format", "json")
-	query.Set("limit", "1")               // Only need the top result
-	query.Set("addressdetails", "1")      // Include detailed address breakdown for better matching
-	query.Set("accept-language", "uk,en") // Prefer Ukrainian, fallback to English
+	query.Set("addressdetails", "1")       // Include detailed address breakdown for better matching
+	query.Set("accept-language", language) // Preferred result languages
+	// Request as many candidates as the caller chooses from
+	query.Set("limit", strconv.Itoa(limit))
 	reqURL.RawQuery = query.Encode()
 
 	np.log.DebugContext(ctx, "Nominatim request URL", "url", reqURL.String())
@@ -199,7 +593,7 @@ func (np *NominatimProvider) geocodeSingleAddress(ctx context.Context, address s
 
 	// Set required headers per Nominatim usage policy
 	req.Header.Set("User-Agent", np.userAgent)
-	req.Header.Set("Accept-Language", "uk,en") // Prefer Ukrainian results
+	req.Header.Set("Accept-Language", language)
 
 	// Execute request
 	resp, err := np.client.Do(req)
@@ -209,10 +603,13 @@ func (np *NominatimProvider) geocodeSingleAddress(ctx context.Context, address s
 	defer resp.Body.Close()
 
 	// Check HTTP status
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, np.handleRateLimit(ctx, resp)
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		np.log.ErrorContext(ctx, "Nominatim API error", "status", resp.StatusCode, "body", string(body))
-		return nil, fmt.Errorf("nominatim API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Provider: "nominatim", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Read response body
@@ -225,9 +622,65 @@ func (np *NominatimProvider) geocodeSingleAddress(ctx context.Context, address s
 	np.log.DebugContext(ctx, "Nominatim raw response", "body", string(body))
 
 	// Parse response
+	results, err := decodeNominatimResults(body)
+	if err != nil {
+		if !errors.Is(err, ErrNominatimEmptyResponse) {
+			np.log.ErrorContext(ctx, "Failed to parse Nominatim response", "error", err, "body", string(body))
+		}
+		return nil, err
+	}
+
+	return np.candidates(ctx, results)
+}
+
+// candidates converts the search results into candidates for the result selector, in Nominatim's order.
+// Results coarser than required are dropped, and so are results with invalid coordinates; if no result
+// is left, the reason the first result was dropped is returned.
+func (np *NominatimProvider) candidates(ctx context.Context, results []nominatimResponse) ([]Candidate, error) {
+	candidates := make([]Candidate, 0, len(results))
+	var firstErr error
+	for _, result := range results {
+		// Treat results coarser than required as not found, so the caller handles them like an empty response
+		if precision := result.precision(); precision < np.minPrecision {
+			np.log.DebugContext(ctx, "Nominatim result is too imprecise",
+				"address_type", result.AddressType,
+				"precision", precision,
+				"min_precision", np.minPrecision)
+			firstErr = cmp.Or(firstErr, ErrNominatimEmptyResponse)
+			continue
+		}
+
+		geocoded, err := result.geocodeResult(ProviderTypeNominatim)
+		if err != nil {
+			firstErr = cmp.Or(firstErr, err)
+			continue
+		}
+		candidates = append(candidates, Candidate{GeocodeResult: *geocoded, CountryCode: result.Address["country_code"]})
+	}
+
+	if len(candidates) == 0 {
+		return nil, firstErr
+	}
+
+	return candidates, nil
+}
+
+// decodeNominatimSearch decodes a Nominatim-compatible search response and returns its top result.
+// It is shared by the providers that speak the Nominatim API (Nominatim and LocationIQ).
+func decodeNominatimSearch(body []byte) (nominatimResponse, error) {
+	results, err := decodeNominatimResults(body)
+	if err != nil {
+		return nominatimResponse{}, err
+	}
+
+	return results[0], nil
+}
+
+// decodeNominatimResults decodes a Nominatim-compatible search response and returns all of its results,
+// or ErrNominatimEmptyResponse if there are none.
+func decodeNominatimResults(body []byte) ([]nominatimResponse, error) {
 	var results []nominatimResponse
-	if err = json.Unmarshal(body, &results); err != nil {
-		np.log.ErrorContext(ctx, "Failed to parse Nominatim response", "error", err, "body", string(body))
+	if err := json.Unmarshal(body, &results); err != nil {
 		return nil, fmt.Errorf("failed to decode nominatim response: %w", err)
 	}
 
@@ -236,19 +689,195 @@ func (np *NominatimProvider) geocodeSingleAddress(ctx context.Context, address s
 		return nil, ErrNominatimEmptyResponse
 	}
 
-	np.log.DebugContext(ctx, "Nominatim found result", "lat", results[0].Lat, "lon", results[0].Lon)
+	return results, nil
+}
 
-	// Parse coordinates
+// coordinates parses the string coordinates of the result.
+func (r nominatimResponse) coordinates() (*models.Coordinates, error) {
 	var lat, lon float64
-	if _, err = fmt.Sscanf(results[0].Lat, "%f", &lat); err != nil {
-		return nil, fmt.Errorf("%w: invalid latitude: %s", ErrNominatimInvalidCoords, results[0].Lat)
+	if _, err := fmt.Sscanf(r.Lat, "%f", &lat); err != nil {
+		return nil, fmt.Errorf("%w: invalid latitude: %s", ErrNominatimInvalidCoords, r.Lat)
 	}
-	if _, err = fmt.Sscanf(results[0].Lon, "%f", &lon); err != nil {
-		return nil, fmt.Errorf("%w: invalid longitude: %s", ErrNominatimInvalidCoords, results[0].Lon)
+	if _, err := fmt.Sscanf(r.Lon, "%f", &lon); err != nil {
+		return nil, fmt.Errorf("%w: invalid longitude: %s", ErrNominatimInvalidCoords, r.Lon)
 	}
 
 	return &models.Coordinates{
 		Latitude:  lat,
 		Longitude: lon,
+		Precision: r.modelPrecision(),
+	}, nil
+}
+
+// geocodeResult converts the result into coordinates along with its display name,
+// attributed to the given provider.
+func (r nominatimResponse) geocodeResult(provider ProviderType) (*models.GeocodeResult, error) {
+	coords, err := r.coordinates()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GeocodeResult{
+		Coordinates:      *coords,
+		FormattedAddress: r.DisplayName,
+		Provider:         string(provider),
 	}, nil
 }
+
+// modelPrecision maps the precision level of the result to the provider-agnostic precision.
+func (r nominatimResponse) modelPrecision() models.Precision {
+	switch r.precision() {
+	case NominatimPrecisionHouse:
+		return models.PrecisionRooftop
+	case NominatimPrecisionStreet:
+		return models.PrecisionStreet
+	case NominatimPrecisionSettlement:
+		return models.PrecisionLocality
+	case NominatimPrecisionAny:
+		// A known but coarser address type is an administrative area centroid
+		if r.AddressType != "" {
+			return models.PrecisionRegion
+		}
+	}
+
+	return models.PrecisionApproximate
+}
+
+// checkBackoff returns a RateLimitError if the Retry-After window of a previous 429 response hasn't passed yet.
+func (np *NominatimProvider) checkBackoff() error {
+	np.mu.Lock()
+	defer np.mu.Unlock()
+
+	if remaining := time.Until(np.backoffUntil); remaining > 0 {
+		return &RateLimitError{Provider: string(ProviderTypeNominatim), RetryAfter: remaining}
+	}
+
+	return nil
+}
+
+// handleRateLimit records the Retry-After window of a 429 response, so that subsequent requests
+// back off until it passes, and returns the corresponding RateLimitError.
+func (np *NominatimProvider) handleRateLimit(ctx context.Context, resp *http.Response) error {
+	now := time.Now()
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+
+	np.log.WarnContext(ctx, "Nominatim API rate limit exceeded", "retry_after", retryAfter)
+
+	if retryAfter > 0 {
+		np.mu.Lock()
+		if until := now.Add(retryAfter); until.After(np.backoffUntil) {
+			np.backoffUntil = until
+		}
+		np.mu.Unlock()
+	}
+
+	return &RateLimitError{Provider: string(ProviderTypeNominatim), RetryAfter: retryAfter}
+}
+
+// ReverseGeocode converts geographic coordinates into an address using the Nominatim reverse endpoint,
+// which lives next to the search endpoint.
+func (np *NominatimProvider) ReverseGeocode(ctx context.Context, coords models.Coordinates) (string, error) {
+	np.log.DebugContext(ctx, "Reverse geocoding using Nominatim", "lat", coords.Latitude, "lon", coords.Longitude)
+
+	ctx, cancel := context.WithTimeout(ctx, np.timeout)
+	defer cancel()
+
+	reqURL, err := url.Parse(np.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+	reqURL.Path = path.Join(path.Dir(reqURL.Path), "reverse")
+
+	if err = np.checkBackoff(); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(coords.Latitude, 'f', -1, 64))
+	query.Set("lon", strconv.FormatFloat(coords.Longitude, 'f', -1, 64))
+	query.Set("format", "json")
+	language := requestLanguage(ctx, np.language)
+	query.Set("accept-language", language)
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", np.userAgent)
+	req.Header.Set("Accept-Language", language)
+
+	resp, err := np.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute reverse geocoding request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", np.handleRateLimit(ctx, resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		np.log.ErrorContext(ctx, "Nominatim API error", "status", resp.StatusCode, "body", string(body))
+		return "", &StatusError{Provider: "nominatim", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result nominatimReverseResponse
+	if err = json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode nominatim response: %w", err)
+	}
+
+	if result.Error != "" || result.DisplayName == "" {
+		return "", ErrNominatimEmptyResponse
+	}
+
+	return result.DisplayName, nil
+}
+
+// HealthCheck queries the Nominatim status endpoint, which lives next to the search endpoint,
+// and returns an error if the service is unreachable or reports a non-OK status.
+func (np *NominatimProvider) HealthCheck(ctx context.Context) error {
+	reqURL, err := url.Parse(np.baseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse base URL: %w", err)
+	}
+	reqURL.Path = path.Join(path.Dir(reqURL.Path), "status")
+	reqURL.RawQuery = url.Values{"format": []string{"json"}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", np.userAgent)
+
+	resp, err := np.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute status request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d: %s", ErrNominatimUnhealthy, resp.StatusCode, string(body))
+	}
+
+	var status nominatimStatusResponse
+	if err = json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("failed to decode nominatim status response: %w", err)
+	}
+
+	if status.Status != 0 {
+		return fmt.Errorf("%w: %s", ErrNominatimUnhealthy, status.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package geocoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractPostalCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+		found    bool
+	}{
+		{name: "leading postal code", address: "79000, м. Львів, пл. Ринок, 1", expected: "79000", found: true},
+		{name: "trailing postal code", address: "м. Київ, вул. Хрещатик, 22, 01001", expected: "01001", found: true},
+		{name: "postal code without separator", address: "індекс 80383 с. Грабовець", expected: "80383", found: true},
+		{name: "no postal code", address: "с. Грабовець, вул. Польова, 3"},
+		{name: "shorter number is not a postal code", address: "м. Львів, вул. Городоцька, 1234"},
+		{name: "longer number is not a postal code", address: "м. Львів, тел. 0322555555"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postalCode, found := extractPostalCode(tt.address)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, postalCode)
+		})
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -42,7 +45,7 @@ func TestNominatimProvider_Geocode(t *testing.T) {
 				)
 
 				// Return mock response
-				responseBody := `[{"lat":"37.4224764","lon":"-122.0842499"}]`
+				responseBody := `[{"lat":"37.4224764","lon":"-122.0842499","addresstype":"building"}]`
 				return &http.Response{
 					StatusCode: http.StatusOK,
 					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
@@ -57,6 +60,7 @@ func TestNominatimProvider_Geocode(t *testing.T) {
 		require.NotNil(t, coords)
 		assert.InEpsilon(t, 37.4224764, coords.Latitude, 0.0001)
 		assert.InEpsilon(t, -122.0842499, coords.Longitude, 0.0001)
+		assert.Equal(t, models.PrecisionRooftop, coords.Precision)
 	})
 
 	t.Run("empty response from API", func(t *testing.T) {
@@ -81,9 +85,9 @@ func TestNominatimProvider_Geocode(t *testing.T) {
 	t.Run("HTTP error status", func(t *testing.T) {
 		mockClient := &mockHTTPClient{
 			doFunc: func(_ *http.Request) (*http.Response, error) {
-				responseBody := `{"error":"Rate limit exceeded"}`
+				responseBody := `{"error":"Service unavailable"}`
 				return &http.Response{
-					StatusCode: http.StatusTooManyRequests,
+					StatusCode: http.StatusServiceUnavailable,
 					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
 				}, nil
 			},
@@ -92,9 +96,9 @@ func TestNominatimProvider_Geocode(t *testing.T) {
 		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
 		coords, err := provider.Geocode(ctx, "some address")
 
-		require.Error(t, err)
+		require.ErrorIs(t, err, geocoding.ErrServerError)
 		require.Nil(t, coords)
-		assert.Contains(t, err.Error(), "nominatim API returned status 429")
+		assert.Contains(t, err.Error(), "nominatim API returned status 503")
 	})
 
 	t.Run("invalid JSON response", func(t *testing.T) {
@@ -300,6 +304,114 @@ func TestNominatimProvider_AddressFallback(t *testing.T) {
 	})
 }
 
+func TestNominatimProvider_RequestTimeout(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	requestCount := 0
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			requestCount++
+			// First request (full address) returns empty to trigger the fallback
+			if requestCount == 1 {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[]`)),
+				}, nil
+			}
+
+			// Second request hangs until the provider deadline cancels it
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		},
+	}
+
+	provider := geocoding.NewNominatimProviderWithClient(
+		mockClient, logger, geocoding.WithNominatimRequestTimeout(50*time.Millisecond),
+	)
+	coords, err := provider.Geocode(ctx, "с. Грабовець, вул. Польова, 3")
+
+	require.Error(t, err)
+	require.Nil(t, coords)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, requestCount, "remaining fallbacks must not be attempted after the deadline")
+}
+
+func TestNominatimProvider_ReverseGeocode(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+	coords := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+
+	t.Run("successful reverse geocoding", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "/reverse", req.URL.Path)
+				assert.Equal(t, "50.4501", req.URL.Query().Get("lat"))
+				assert.Equal(t, "30.5234", req.URL.Query().Get("lon"))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"display_name":"Хрещатик, Київ, Україна"}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		address, err := provider.ReverseGeocode(ctx, coords)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Хрещатик, Київ, Україна", address)
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"error":"Unable to geocode"}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		_, err := provider.ReverseGeocode(ctx, coords)
+
+		require.ErrorIs(t, err, geocoding.ErrNominatimEmptyResponse)
+	})
+
+	t.Run("HTTP error status", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       io.NopCloser(bytes.NewBufferString(`unavailable`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		_, err := provider.ReverseGeocode(ctx, coords)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nominatim API returned status 503")
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(bytes.NewBufferString(`rate limited`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		_, err := provider.ReverseGeocode(ctx, coords)
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+	})
+}
+
 func TestNewNominatimProvider(t *testing.T) {
 	logger := slog.Default()
 
@@ -307,3 +419,875 @@ func TestNewNominatimProvider(t *testing.T) {
 
 	require.NotNil(t, provider)
 }
+
+func TestNominatimProvider_HealthCheck(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	t.Run("healthy status", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "/status", req.URL.Path)
+				assert.Equal(t, "json", req.URL.Query().Get("format"))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"status":0,"message":"OK"}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+
+		require.NoError(t, provider.HealthCheck(ctx))
+	})
+
+	t.Run("unhealthy status", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"status":700,"message":"Database connection failed"}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+
+		require.ErrorIs(t, provider.HealthCheck(ctx), geocoding.ErrNominatimUnhealthy)
+	})
+
+	t.Run("HTTP client returns error", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return nil, assert.AnError
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+
+		require.ErrorIs(t, provider.HealthCheck(ctx), assert.AnError)
+	})
+}
+
+func TestNominatimProvider_RateLimited(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	t.Run("retry-after in seconds backs off subsequent requests", func(t *testing.T) {
+		requestCount := 0
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				requestCount++
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Header:     http.Header{"Retry-After": []string{"120"}},
+					Body:       io.NopCloser(bytes.NewBufferString(`rate limited`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		coords, err := provider.Geocode(ctx, "с. Грабовець, вул. Польова, 3")
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+		require.Nil(t, coords)
+		assert.Equal(t, 1, requestCount, "fallbacks must not be attempted after a 429")
+
+		var rateLimitErr *geocoding.RateLimitError
+		require.ErrorAs(t, err, &rateLimitErr)
+		assert.Equal(t, 120*time.Second, rateLimitErr.RetryAfter)
+
+		// The Retry-After window is still active, so no request is sent
+		_, err = provider.Geocode(ctx, "Київ")
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+		assert.Equal(t, 1, requestCount)
+	})
+
+	t.Run("retry-after as HTTP date", func(t *testing.T) {
+		retryAt := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Header:     http.Header{"Retry-After": []string{retryAt}},
+					Body:       io.NopCloser(bytes.NewBufferString(``)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		_, err := provider.Geocode(ctx, "Київ")
+
+		var rateLimitErr *geocoding.RateLimitError
+		require.ErrorAs(t, err, &rateLimitErr)
+		assert.Greater(t, rateLimitErr.RetryAfter, 59*time.Minute)
+	})
+
+	t.Run("without retry-after the next request is sent", func(t *testing.T) {
+		requestCount := 0
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				requestCount++
+				if requestCount == 1 {
+					return &http.Response{
+						StatusCode: http.StatusTooManyRequests,
+						Body:       io.NopCloser(bytes.NewBufferString(``)),
+					}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[{"lat":"50.4501","lon":"30.5234"}]`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		_, err := provider.Geocode(ctx, "Київ")
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+
+		coords, err := provider.Geocode(ctx, "Київ")
+		require.NoError(t, err)
+		require.NotNil(t, coords)
+		assert.Equal(t, 2, requestCount)
+	})
+}
+
+func TestNominatimProvider_MinPrecision(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	tests := []struct {
+		name         string
+		minPrecision geocoding.NominatimPrecision
+		responseBody string
+		wantErr      error
+	}{
+		{
+			name:         "filtering disabled accepts administrative centroid",
+			minPrecision: geocoding.NominatimPrecisionAny,
+			responseBody: `[{"lat":"49.5","lon":"25.5","addresstype":"state",` +
+				`"address":{"state":"Тернопільська область"}}]`,
+		},
+		{
+			name:         "house precision accepts house result",
+			minPrecision: geocoding.NominatimPrecisionHouse,
+			responseBody: `[{"lat":"50.4501","lon":"30.5234","addresstype":"building",` +
+				`"address":{"house_number":"1","road":"Хрещатик","city":"Київ"}}]`,
+		},
+		{
+			name:         "house precision rejects village centroid",
+			minPrecision: geocoding.NominatimPrecisionHouse,
+			responseBody: `[{"lat":"49.5","lon":"25.5","addresstype":"village","address":{"village":"Грабовець"}}]`,
+			wantErr:      geocoding.ErrNominatimEmptyResponse,
+		},
+		{
+			name:         "street precision falls back to address details",
+			minPrecision: geocoding.NominatimPrecisionStreet,
+			responseBody: `[{"lat":"49.5","lon":"25.5",` +
+				`"address":{"road":"вул. Польова","village":"Грабовець"}}]`,
+		},
+		{
+			name:         "settlement precision rejects result without address type",
+			minPrecision: geocoding.NominatimPrecisionSettlement,
+			responseBody: `[{"lat":"49.5","lon":"25.5"}]`,
+			wantErr:      geocoding.ErrNominatimEmptyResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(_ *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(tt.responseBody)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewNominatimProviderWithClient(
+				mockClient, logger, geocoding.WithNominatimMinPrecision(tt.minPrecision),
+			)
+			coords, err := provider.Geocode(ctx, "Київ")
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, coords)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, coords)
+		})
+	}
+}
+
+func TestParseNominatimPrecision(t *testing.T) {
+	tests := map[string]geocoding.NominatimPrecision{
+		"":           geocoding.NominatimPrecisionAny,
+		"any":        geocoding.NominatimPrecisionAny,
+		"settlement": geocoding.NominatimPrecisionSettlement,
+		"Street":     geocoding.NominatimPrecisionStreet,
+		" house ":    geocoding.NominatimPrecisionHouse,
+	}
+
+	for name, want := range tests {
+		precision, err := geocoding.ParseNominatimPrecision(name)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, precision, name)
+	}
+
+	_, err := geocoding.ParseNominatimPrecision("apartment")
+	require.Error(t, err)
+}
+
+func TestNominatimProvider_RequestBudget(t *testing.T) {
+	logger := slog.Default()
+
+	requestCount := 0
+	mockClient := &mockHTTPClient{
+		doFunc: func(_ *http.Request) (*http.Response, error) {
+			requestCount++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`[]`)),
+			}, nil
+		},
+	}
+
+	provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+	budget := geocoding.NewRequestBudget(2)
+	ctx := geocoding.WithRequestBudget(context.Background(), budget)
+
+	coords, err := provider.Geocode(ctx, "с. Грабовець, вул. Польова, 3")
+
+	require.ErrorIs(t, err, geocoding.ErrRequestBudgetExhausted)
+	assert.Nil(t, coords)
+	assert.Equal(t, 2, requestCount, "fallbacks must stop once the budget is spent")
+	assert.True(t, budget.Exhausted())
+}
+
+func TestNominatimProvider_StructuredSearch(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+	const address = "с. Грабовець, вул. Польова, 3"
+
+	t.Run("structured search matches", func(t *testing.T) {
+		var queries []url.Values
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				queries = append(queries, req.URL.Query())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[{"lat":"49.1234","lon":"24.5678"}]`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(
+			mockClient, logger, geocoding.WithNominatimStructuredSearch(true),
+		)
+		result, err := provider.GeocodeDetailed(ctx, address)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.FallbackLevel)
+		require.Len(t, queries, 1)
+		assert.Equal(t, "3 вулиця Польова", queries[0].Get("street"))
+		assert.Equal(t, "Грабовець", queries[0].Get("city"))
+		assert.Equal(t, "ua", queries[0].Get("country"))
+		assert.False(t, queries[0].Has("q"), "a structured search must not be combined with a free-form query")
+	})
+
+	t.Run("empty structured search falls back to the free-form address", func(t *testing.T) {
+		var queries []url.Values
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				queries = append(queries, req.URL.Query())
+				body := `[]`
+				if req.URL.Query().Get("q") == address {
+					body = `[{"lat":"49.1234","lon":"24.5678"}]`
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(
+			mockClient, logger, geocoding.WithNominatimStructuredSearch(true),
+		)
+		result, err := provider.GeocodeDetailed(ctx, address)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.FallbackLevel, "the free-form full address is still fallback level 0")
+		require.Len(t, queries, 2)
+		assert.True(t, queries[0].Has("street"))
+		assert.Equal(t, address, queries[1].Get("q"))
+	})
+
+	t.Run("unparsed address uses the free-form search only", func(t *testing.T) {
+		var queries []url.Values
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				queries = append(queries, req.URL.Query())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[{"lat":"50.4501","lon":"30.5234"}]`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(
+			mockClient, logger, geocoding.WithNominatimStructuredSearch(true),
+		)
+		_, err := provider.GeocodeDetailed(ctx, "Київ, Хрещатик 22")
+
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		assert.Equal(t, "Київ, Хрещатик 22", queries[0].Get("q"))
+	})
+}
+
+func TestNominatimProvider_GeocodeStructured(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+	address := models.StructuredAddress{
+		PostalCode: "80383", City: "с. Грабовець", Street: "вул. Польова", HouseNumber: "3",
+	}
+
+	t.Run("structured search matches", func(t *testing.T) {
+		var queries []url.Values
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				queries = append(queries, req.URL.Query())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[{"lat":"49.1234","lon":"24.5678"}]`)),
+				}, nil
+			},
+		}
+
+		// The structured search doesn't need to be enabled for structured addresses
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		result, err := provider.GeocodeStructured(ctx, address)
+
+		require.NoError(t, err)
+		assert.InDelta(t, 49.1234, result.Latitude, 0)
+		require.Len(t, queries, 1)
+		assert.Equal(t, "3 вулиця Польова", queries[0].Get("street"))
+		assert.Equal(t, "Грабовець", queries[0].Get("city"))
+		assert.False(t, queries[0].Has("q"))
+	})
+
+	t.Run("empty structured search falls back to the free-form text", func(t *testing.T) {
+		var queries []url.Values
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				queries = append(queries, req.URL.Query())
+				body := `[]`
+				if req.URL.Query().Get("q") == "80383, с. Грабовець, вул. Польова" {
+					body = `[{"lat":"49.1234","lon":"24.5678"}]`
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		result, err := provider.GeocodeStructured(ctx, address)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.FallbackLevel)
+		require.Len(t, queries, 3)
+		assert.True(t, queries[0].Has("street"))
+		assert.Equal(t, "80383, с. Грабовець, вул. Польова, 3", queries[1].Get("q"))
+		assert.Equal(t, "80383, с. Грабовець, вул. Польова", queries[2].Get("q"))
+	})
+
+	t.Run("address without a street uses the free-form search only", func(t *testing.T) {
+		var queries []url.Values
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				queries = append(queries, req.URL.Query())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[{"lat":"49.1234","lon":"24.5678"}]`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		_, err := provider.GeocodeStructured(ctx, models.StructuredAddress{City: "с. Грабовець"})
+
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		assert.Equal(t, "с. Грабовець", queries[0].Get("q"))
+	})
+}
+
+func TestNominatimProvider_DisableFallback(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	requestCount := 0
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			requestCount++
+			assert.Equal(t, "с. Грабовець, вул. Польова, 3", req.URL.Query().Get("q"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`[]`)),
+			}, nil
+		},
+	}
+
+	provider := geocoding.NewNominatimProviderWithClient(
+		mockClient, logger, geocoding.WithNominatimDisableFallback(true),
+	)
+	coords, err := provider.Geocode(ctx, "с. Грабовець, вул. Польова, 3")
+
+	require.ErrorIs(t, err, geocoding.ErrNominatimEmptyResponse)
+	assert.Nil(t, coords)
+	assert.Equal(t, 1, requestCount, "only the full address must be looked up")
+}
+
+func TestNominatimProvider_FallbackObserver(t *testing.T) {
+	const address = "с. Грабовець, вул. Польова, 3"
+
+	tests := []struct {
+		name     string
+		foundAt  string // foundAt is the search that finds the address, empty finds nothing
+		failAt   string // failAt is the search that gets a server error
+		opts     []geocoding.NominatimOption
+		expected int
+	}{
+		{name: "found with the full address", foundAt: address, expected: 1},
+		{name: "found with the last variation", foundAt: "с. Грабовець", expected: 3},
+		{name: "all variations exhausted", expected: 3},
+		{name: "server error stops the fallbacks", failAt: "с. Грабовець, вул. Польова", expected: 2},
+		{
+			name:     "fallbacks disabled",
+			opts:     []geocoding.NominatimOption{geocoding.WithNominatimDisableFallback(true)},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestCount := 0
+			mockClient := &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					requestCount++
+					status, body := http.StatusOK, `[]`
+					switch req.URL.Query().Get("q") {
+					case tt.foundAt:
+						body = `[{"lat":"49.1234","lon":"24.5678","addresstype":"village"}]`
+					case tt.failAt:
+						status = http.StatusInternalServerError
+					}
+					return &http.Response{StatusCode: status, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+				},
+			}
+
+			var observed []int
+			opts := append([]geocoding.NominatimOption{
+				geocoding.WithNominatimFallbackObserver(func(attempts int) {
+					observed = append(observed, attempts)
+				}),
+			}, tt.opts...)
+			provider := geocoding.NewNominatimProviderWithClient(mockClient, slog.Default(), opts...)
+
+			_, _ = provider.Geocode(t.Context(), address)
+
+			assert.Equal(t, []int{tt.expected}, observed)
+			assert.Equal(t, tt.expected, requestCount, "every search sent must be counted")
+		})
+	}
+}
+
+func TestNominatimProvider_PostalCodeFallback(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	const address = "80383, с. Грабовець, вул. Польова, 3"
+
+	t.Run("postal code is looked up after all address variations", func(t *testing.T) {
+		requestCount := 0
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				requestCount++
+				query := req.URL.Query()
+
+				body := `[]`
+				if query.Get("q") == "" {
+					assert.Equal(t, "80383", query.Get("postalcode"))
+					assert.Equal(t, "ua", query.Get("country"))
+					body = `[{"lat":"49.1234","lon":"24.5678","addresstype":"postcode"}]`
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(body)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(
+			mockClient, logger, geocoding.WithNominatimPostalCodeFallback(true),
+		)
+		result, err := provider.GeocodeDetailed(ctx, address)
+
+		require.NoError(t, err)
+		assert.InEpsilon(t, 49.1234, result.Latitude, 0.0001)
+		assert.Equal(t, 5, requestCount, "the postal code must be the last of 5 fallback levels")
+		assert.Equal(t, 4, result.FallbackLevel)
+	})
+
+	t.Run("address without postal code", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.NotEmpty(t, req.URL.Query().Get("q"))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[]`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(
+			mockClient, logger, geocoding.WithNominatimPostalCodeFallback(true),
+		)
+		_, err := provider.Geocode(ctx, "с. Грабовець, вул. Польова, 3")
+
+		require.ErrorIs(t, err, geocoding.ErrNominatimEmptyResponse)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Empty(t, req.URL.Query().Get("postalcode"))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`[]`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+		_, err := provider.Geocode(ctx, address)
+
+		require.ErrorIs(t, err, geocoding.ErrNominatimEmptyResponse)
+	})
+}
+
+func TestNominatimProvider_Precision(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	tests := []struct {
+		name         string
+		responseBody string
+		expected     models.Precision
+	}{
+		{name: "house", responseBody: `[{"lat":"1","lon":"2","addresstype":"house"}]`, expected: models.PrecisionRooftop},
+		{name: "road", responseBody: `[{"lat":"1","lon":"2","addresstype":"road"}]`, expected: models.PrecisionStreet},
+		{
+			name:         "village",
+			responseBody: `[{"lat":"1","lon":"2","addresstype":"village"}]`,
+			expected:     models.PrecisionLocality,
+		},
+		{name: "state", responseBody: `[{"lat":"1","lon":"2","addresstype":"state"}]`, expected: models.PrecisionRegion},
+		{name: "unknown", responseBody: `[{"lat":"1","lon":"2"}]`, expected: models.PrecisionApproximate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(_ *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(tt.responseBody)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewNominatimProviderWithClient(mockClient, logger)
+			coords, err := provider.Geocode(ctx, "Київ")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, coords.Precision)
+		})
+	}
+}
+
+func TestNominatimProvider_ResultLimit(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	// Nominatim ranks the Polish village first, the Ukrainian house second and a Ukrainian village last
+	const body = `[` +
+		`{"lat":"50.1","lon":"23.1","addresstype":"village","address":{"country_code":"pl"}},` +
+		`{"lat":"49.5","lon":"25.5","addresstype":"house","address":{"country_code":"ua"}},` +
+		`{"lat":"49.6","lon":"25.6","addresstype":"village","address":{"country_code":"ua"}}]`
+
+	tests := []struct {
+		name          string
+		opts          []geocoding.NominatimOption
+		expectedLimit string
+		expectedLat   float64
+	}{
+		{name: "top result by default", expectedLimit: "1", expectedLat: 50.1},
+		{
+			name:          "first result selector keeps Nominatim's ranking",
+			opts:          []geocoding.NominatimOption{geocoding.WithNominatimResultLimit(3)},
+			expectedLimit: "3",
+			expectedLat:   50.1,
+		},
+		{
+			name: "best match selector picks the finest result in the country",
+			opts: []geocoding.NominatimOption{
+				geocoding.WithNominatimResultLimit(3),
+				geocoding.WithNominatimResultSelector(geocoding.BestMatchSelector{Country: "ua"}),
+			},
+			expectedLimit: "3",
+			expectedLat:   49.5,
+		},
+		{
+			name:          "limit is capped",
+			opts:          []geocoding.NominatimOption{geocoding.WithNominatimResultLimit(100)},
+			expectedLimit: "40",
+			expectedLat:   50.1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, tt.expectedLimit, req.URL.Query().Get("limit"))
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(body)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewNominatimProviderWithClient(mockClient, logger, tt.opts...)
+			result, err := provider.Geocode(ctx, "Грабовець")
+
+			require.NoError(t, err)
+			assert.InEpsilon(t, tt.expectedLat, result.Latitude, 0.0001)
+		})
+	}
+}
+
+func TestNominatimProvider_ResultLimitDropsRejectedCandidates(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+
+	t.Run("imprecise and invalid results are skipped", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				body := `[{"lat":"49.1","lon":"25.1","addresstype":"state"},` +
+					`{"lat":"invalid","lon":"25.2","addresstype":"house"},` +
+					`{"lat":"49.3","lon":"25.3","addresstype":"road"}]`
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(body)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger,
+			geocoding.WithNominatimResultLimit(3),
+			geocoding.WithNominatimMinPrecision(geocoding.NominatimPrecisionSettlement),
+			geocoding.WithNominatimDisableFallback(true),
+		)
+		result, err := provider.Geocode(ctx, "Грабовець")
+
+		require.NoError(t, err)
+		assert.InEpsilon(t, 49.3, result.Latitude, 0.0001)
+	})
+
+	t.Run("first rejection is returned when no result is left", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				body := `[{"lat":"invalid","lon":"25.1","addresstype":"house"},` +
+					`{"lat":"49.2","lon":"25.2","addresstype":"state"}]`
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(body)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, logger,
+			geocoding.WithNominatimResultLimit(2),
+			geocoding.WithNominatimMinPrecision(geocoding.NominatimPrecisionSettlement),
+			geocoding.WithNominatimDisableFallback(true),
+		)
+		_, err := provider.Geocode(ctx, "Грабовець")
+
+		require.ErrorIs(t, err, geocoding.ErrNominatimInvalidCoords)
+	})
+}
+
+func TestNominatimProvider_Language(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+
+	tests := []struct {
+		name     string
+		opts     []geocoding.NominatimOption
+		language string
+		expected string
+	}{
+		{name: "default language", expected: "uk,en"},
+		{
+			name:     "configured language",
+			opts:     []geocoding.NominatimOption{geocoding.WithNominatimLanguage("de,en")},
+			expected: "de,en",
+		},
+		{
+			name:     "request language overrides the configured one",
+			opts:     []geocoding.NominatimOption{geocoding.WithNominatimLanguage("de,en")},
+			language: "ru,uk",
+			expected: "ru,uk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, tt.expected, req.URL.Query().Get("accept-language"))
+					assert.Equal(t, tt.expected, req.Header.Get("Accept-Language"))
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(`[{"lat":"52.52","lon":"13.40"}]`)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewNominatimProviderWithClient(mockClient, logger, tt.opts...)
+			_, err := provider.Geocode(geocoding.WithLanguage(ctx, tt.language), "Berlin")
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNominatimProvider_GeocodeDetailed(t *testing.T) {
+	ctx := t.Context()
+	mockClient := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			// Only the fallback without the house number matches
+			body := `[]`
+			if req.URL.Query().Get("q") == "с. Грабовець, вул. Польова" {
+				body = `[{"lat":"49.1234","lon":"24.5678","addresstype":"road",` +
+					`"display_name":"вулиця Польова, Грабовець, Україна"}]`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+			}, nil
+		},
+	}
+
+	provider := geocoding.NewNominatimProviderWithClient(mockClient, slog.Default())
+	result, err := provider.GeocodeDetailed(ctx, "с. Грабовець, вул. Польова, 3")
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.GeocodeResult{
+		Coordinates: models.Coordinates{
+			Latitude: 49.1234, Longitude: 24.5678, Precision: models.PrecisionStreet, FallbackLevel: 1,
+		},
+		FormattedAddress: "вулиця Польова, Грабовець, Україна",
+		Provider:         "nominatim",
+	}, result)
+}
+
+func TestNominatimProvider_GeocodeCandidates(t *testing.T) {
+	ctx := t.Context()
+	const body = `[` +
+		`{"lat":"49.5","lon":"25.5","addresstype":"village","display_name":"Грабовець, Тернопільська область"},` +
+		`{"lat":"bad","lon":"25.6","addresstype":"village"},` +
+		`{"lat":"49.1","lon":"24.5","addresstype":"village","display_name":"Грабовець, Львівська область"}]`
+
+	tests := []struct {
+		name          string
+		limit         int
+		expectedLimit string
+	}{
+		{name: "requested limit", limit: 5, expectedLimit: "5"},
+		{name: "limit is capped", limit: 100, expectedLimit: "40"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, tt.expectedLimit, req.URL.Query().Get("limit"))
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(body)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewNominatimProviderWithClient(mockClient, slog.Default())
+			results, err := provider.GeocodeCandidates(ctx, "Грабовець", tt.limit)
+
+			require.NoError(t, err)
+			assert.Equal(t, []models.GeocodeResult{
+				{
+					Coordinates:      models.Coordinates{Latitude: 49.5, Longitude: 25.5, Precision: models.PrecisionLocality},
+					FormattedAddress: "Грабовець, Тернопільська область",
+					Provider:         "nominatim",
+				},
+				{
+					Coordinates:      models.Coordinates{Latitude: 49.1, Longitude: 24.5, Precision: models.PrecisionLocality},
+					FormattedAddress: "Грабовець, Львівська область",
+					Provider:         "nominatim",
+				},
+			}, results, "results with invalid coordinates are dropped")
+		})
+	}
+
+	t.Run("candidates of the fallback that matches", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				body := `[]`
+				if req.URL.Query().Get("q") == "с. Грабовець, вул. Польова" {
+					body = `[{"lat":"49.1234","lon":"24.5678","addresstype":"road"},` +
+						`{"lat":"49.2","lon":"24.6","addresstype":"road"}]`
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(body)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, slog.Default())
+		results, err := provider.GeocodeCandidates(ctx, "с. Грабовець, вул. Польова, 3", 2)
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, result := range results {
+			assert.Equal(t, 1, result.FallbackLevel)
+		}
+	})
+
+	t.Run("no result", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(*http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`[]`))}, nil
+			},
+		}
+
+		provider := geocoding.NewNominatimProviderWithClient(mockClient, slog.Default(),
+			geocoding.WithNominatimDisableFallback(true))
+		results, err := provider.GeocodeCandidates(ctx, "Nowhere", 5)
+
+		require.ErrorIs(t, err, geocoding.ErrNominatimEmptyResponse)
+		assert.Nil(t, results)
+	})
+}
@@ -2,6 +2,9 @@ package geocoding
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/models"
 )
@@ -9,6 +12,159 @@ import (
 // Provider is an interface that defines a method for geocoding an address.
 // The Geocode method takes a context and an address string as input,
 // and returns the corresponding coordinates and an error if any occurs.
+// The HealthCheck method performs a lightweight probe of the upstream API
+// and returns an error if the provider is not able to serve requests.
 type Provider interface {
 	Geocode(ctx context.Context, address string) (*models.Coordinates, error)
+	HealthCheck(ctx context.Context) error
 }
+
+// ReverseGeocoder is an optional interface implemented by providers
+// that can convert geographic coordinates into an address.
+type ReverseGeocoder interface {
+	ReverseGeocode(ctx context.Context, coords models.Coordinates) (string, error)
+}
+
+// ErrReverseGeocodingUnsupported is returned by ReverseGeocode if the provider doesn't implement ReverseGeocoder.
+var ErrReverseGeocodingUnsupported = errors.New("provider does not support reverse geocoding")
+
+// ReverseGeocode converts the coordinates into an address with the provider's ReverseGeocode if it implements
+// ReverseGeocoder, and returns ErrReverseGeocodingUnsupported otherwise.
+func ReverseGeocode(ctx context.Context, provider Provider, coords models.Coordinates) (string, error) {
+	if reverser, ok := provider.(ReverseGeocoder); ok {
+		return reverser.ReverseGeocode(ctx, coords)
+	}
+
+	return "", ErrReverseGeocodingUnsupported
+}
+
+// DetailedGeocoder is an optional interface implemented by providers that report metadata
+// about a match, such as a place identifier, a formatted address and a confidence score, along with its coordinates.
+type DetailedGeocoder interface {
+	GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error)
+}
+
+// GeocodeDetailed geocodes the address with the provider's GeocodeDetailed if it implements DetailedGeocoder,
+// and wraps the result of Geocode without metadata otherwise. A nil result is returned as nil.
+func GeocodeDetailed(ctx context.Context, provider Provider, address string) (*models.GeocodeResult, error) {
+	if detailed, ok := provider.(DetailedGeocoder); ok {
+		return detailed.GeocodeDetailed(ctx, address)
+	}
+
+	coords, err := provider.Geocode(ctx, address)
+	if err != nil || coords == nil {
+		return nil, err
+	}
+
+	return &models.GeocodeResult{Coordinates: *coords}, nil
+}
+
+// CandidateGeocoder is an optional interface implemented by providers that can return several
+// matches for an ambiguous address, best first, instead of a single one.
+type CandidateGeocoder interface {
+	GeocodeCandidates(ctx context.Context, address string, limit int) ([]models.GeocodeResult, error)
+}
+
+// GeocodeCandidates returns up to limit matches for the address with the provider's GeocodeCandidates
+// if it implements CandidateGeocoder, and the single result of GeocodeDetailed otherwise.
+// A nil result is returned as an empty slice.
+func GeocodeCandidates(
+	ctx context.Context,
+	provider Provider,
+	address string,
+	limit int,
+) ([]models.GeocodeResult, error) {
+	if candidates, ok := provider.(CandidateGeocoder); ok {
+		return candidates.GeocodeCandidates(ctx, address, limit)
+	}
+
+	result, err := GeocodeDetailed(ctx, provider, address)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	return []models.GeocodeResult{*result}, nil
+}
+
+// StructuredGeocoder is an optional interface implemented by providers that can look up
+// an address by its separate fields rather than by free-form text.
+type StructuredGeocoder interface {
+	GeocodeStructured(ctx context.Context, address models.StructuredAddress) (*models.GeocodeResult, error)
+}
+
+// GeocodeStructured geocodes the address with the provider's GeocodeStructured if it implements
+// StructuredGeocoder, and geocodes its free-form text with GeocodeDetailed otherwise.
+func GeocodeStructured(
+	ctx context.Context,
+	provider Provider,
+	address models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	if structured, ok := provider.(StructuredGeocoder); ok {
+		return structured.GeocodeStructured(ctx, address)
+	}
+
+	return GeocodeDetailed(ctx, provider, address.String())
+}
+
+// BatchProvider is an optional interface implemented by providers with a native batch geocoding API.
+// The returned slices have the same length and order as the input addresses,
+// so a failure of one address does not affect the others.
+type BatchProvider interface {
+	GeocodeBatch(ctx context.Context, addresses []string) ([]*models.Coordinates, []error)
+}
+
+// ErrBatchResultMismatch is returned by GeocodeBatch for every address if a native batch API returned
+// a number of results that doesn't match the number of addresses, so no result can be trusted to be its own.
+var ErrBatchResultMismatch = errors.New("batch geocoding returned mismatched results")
+
+// GeocodeBatch geocodes all addresses using the provider's native batch API if it implements BatchProvider,
+// and falls back to geocoding the addresses one by one otherwise. The returned slices always have the same
+// length as addresses.
+func GeocodeBatch(ctx context.Context, provider Provider, addresses []string) ([]*models.Coordinates, []error) {
+	if batcher, ok := provider.(BatchProvider); ok {
+		coords, errs := batcher.GeocodeBatch(ctx, addresses)
+		if len(coords) == len(addresses) && len(errs) == len(addresses) {
+			return coords, errs
+		}
+
+		mismatch := fmt.Errorf("%w: %d addresses, %d coordinates, %d errors",
+			ErrBatchResultMismatch, len(addresses), len(coords), len(errs))
+		errs = make([]error, len(addresses))
+		for i := range errs {
+			errs[i] = mismatch
+		}
+
+		return make([]*models.Coordinates, len(addresses)), errs
+	}
+
+	coords := make([]*models.Coordinates, len(addresses))
+	errs := make([]error, len(addresses))
+	for i, address := range addresses {
+		coords[i], errs[i] = provider.Geocode(ctx, address)
+	}
+
+	return coords, errs
+}
+
+// BatchForwarder is an optional interface implemented by providers that implement BatchProvider by forwarding
+// the addresses to the providers they wrap, such as ChainProvider and RoutingProvider, which may well geocode
+// them one by one. SupportsBatch reports whether the wrapped providers have a native batch API.
+type BatchForwarder interface {
+	SupportsBatch() bool
+}
+
+// SupportsBatch reports whether GeocodeBatch geocodes addresses with a native batch API of the provider,
+// rather than one by one: whether it implements BatchProvider, and for a BatchForwarder, whether the providers
+// it forwards to do.
+func SupportsBatch(provider Provider) bool {
+	if forwarder, ok := provider.(BatchForwarder); ok {
+		return forwarder.SupportsBatch()
+	}
+	_, ok := provider.(BatchProvider)
+
+	return ok
+}
+
+// DefaultRequestTimeout is the default overall deadline for a single Geocode call,
+// including all fallback requests made by the provider.
+const DefaultRequestTimeout = 15 * time.Second
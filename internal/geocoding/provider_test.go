@@ -0,0 +1,287 @@
+package geocoding_test
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchProvider is a provider mock with a native batch API returning preset results per address,
+// and ErrEmptyResponse for the others. With short set, it returns one result less than there are addresses.
+type batchProvider struct {
+	*mocks.Provider
+
+	results map[string]*models.Coordinates
+	short   bool
+	calls   [][]string
+}
+
+func (bp *batchProvider) GeocodeBatch(_ context.Context, addresses []string) ([]*models.Coordinates, []error) {
+	bp.calls = append(bp.calls, addresses)
+	coords := make([]*models.Coordinates, len(addresses))
+	errs := make([]error, len(addresses))
+	for i, address := range addresses {
+		if result, ok := bp.results[address]; ok {
+			coords[i] = result
+		} else {
+			errs[i] = geocoding.ErrEmptyResponse
+		}
+	}
+	if bp.short {
+		return coords[1:], errs[1:]
+	}
+
+	return coords, errs
+}
+
+func TestGeocodeBatch(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("falls back to a loop without native batch support", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(kyivCoords, nil).Once()
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, assert.AnError).Once()
+
+		coords, errs := geocoding.GeocodeBatch(ctx, mockProvider, []string{"Kyiv", "Nowhere"})
+
+		require.Len(t, coords, 2)
+		require.Len(t, errs, 2)
+		assert.Equal(t, kyivCoords, coords[0])
+		require.NoError(t, errs[0])
+		assert.Nil(t, coords[1])
+		require.ErrorIs(t, errs[1], assert.AnError)
+	})
+
+	t.Run("uses native batch support", func(t *testing.T) {
+		provider := &batchProvider{Provider: mocks.NewProvider(t)}
+
+		coords, errs := geocoding.GeocodeBatch(ctx, provider, []string{"Kyiv", "Lviv"})
+
+		assert.Len(t, coords, 2)
+		assert.Len(t, errs, 2)
+		assert.Len(t, provider.calls, 1)
+	})
+
+	t.Run("mismatched native results fail every address", func(t *testing.T) {
+		kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+		provider := &batchProvider{
+			Provider: mocks.NewProvider(t),
+			results:  map[string]*models.Coordinates{"Kyiv": kyivCoords, "Lviv": kyivCoords},
+			short:    true,
+		}
+
+		coords, errs := geocoding.GeocodeBatch(ctx, provider, []string{"Kyiv", "Lviv"})
+
+		assert.Equal(t, []*models.Coordinates{nil, nil}, coords)
+		require.Len(t, errs, 2)
+		require.ErrorIs(t, errs[0], geocoding.ErrBatchResultMismatch)
+		require.ErrorIs(t, errs[1], geocoding.ErrBatchResultMismatch)
+	})
+}
+
+func TestSupportsBatch(t *testing.T) {
+	batcher := &batchProvider{Provider: mocks.NewProvider(t)}
+	plain := mocks.NewProvider(t)
+	logger := slog.Default()
+
+	assert.True(t, geocoding.SupportsBatch(batcher))
+	assert.False(t, geocoding.SupportsBatch(plain))
+
+	// Decorators report the capability of the providers they forward batches to
+	assert.True(t, geocoding.SupportsBatch(geocoding.NewChainProvider(logger, batcher, plain)))
+	assert.False(t, geocoding.SupportsBatch(geocoding.NewChainProvider(logger, plain, batcher)))
+
+	rules := []geocoding.RoutingRule{{Pattern: regexp.MustCompile(`Lviv`), Provider: "here"}}
+	routing, err := geocoding.NewRoutingProvider(rules, map[string]geocoding.Provider{"here": batcher}, batcher, logger)
+	require.NoError(t, err)
+	assert.True(t, geocoding.SupportsBatch(routing))
+
+	routing, err = geocoding.NewRoutingProvider(rules, map[string]geocoding.Provider{"here": plain}, batcher, logger)
+	require.NoError(t, err)
+	assert.False(t, geocoding.SupportsBatch(routing))
+}
+
+// detailedProvider is a provider mock that reports match metadata.
+type detailedProvider struct {
+	*mocks.Provider
+
+	result *models.GeocodeResult
+}
+
+func (dp *detailedProvider) GeocodeDetailed(_ context.Context, _ string) (*models.GeocodeResult, error) {
+	return dp.result, nil
+}
+
+func TestGeocodeDetailed(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("wraps the coordinates of a provider without metadata", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(kyivCoords, nil).Once()
+
+		result, err := geocoding.GeocodeDetailed(ctx, mockProvider, "Kyiv")
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.GeocodeResult{Coordinates: *kyivCoords}, result)
+	})
+
+	t.Run("keeps a nil result", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, assert.AnError).Once()
+
+		result, err := geocoding.GeocodeDetailed(ctx, mockProvider, "Nowhere")
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("uses the provider's metadata", func(t *testing.T) {
+		expected := &models.GeocodeResult{
+			Coordinates: models.Coordinates{Latitude: 50.45, Longitude: 30.52},
+			PlaceID:     "place-1",
+			Provider:    "here",
+			Confidence:  0.9,
+		}
+		provider := &detailedProvider{Provider: mocks.NewProvider(t), result: expected}
+
+		result, err := geocoding.GeocodeDetailed(ctx, provider, "Kyiv")
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+}
+
+// candidatesProvider is a provider mock that returns several matches.
+type candidatesProvider struct {
+	*mocks.Provider
+
+	results []models.GeocodeResult
+}
+
+func (cp *candidatesProvider) GeocodeCandidates(
+	_ context.Context,
+	_ string,
+	limit int,
+) ([]models.GeocodeResult, error) {
+	return cp.results[:min(limit, len(cp.results))], nil
+}
+
+func TestGeocodeCandidates(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("wraps the single match of a provider without candidates", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(kyivCoords, nil).Once()
+
+		results, err := geocoding.GeocodeCandidates(ctx, mockProvider, "Kyiv", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.GeocodeResult{{Coordinates: *kyivCoords}}, results)
+	})
+
+	t.Run("returns no match for a nil result", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, assert.AnError).Once()
+
+		results, err := geocoding.GeocodeCandidates(ctx, mockProvider, "Nowhere", 5)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, results)
+	})
+
+	t.Run("uses the provider's candidates", func(t *testing.T) {
+		expected := []models.GeocodeResult{
+			{Coordinates: models.Coordinates{Latitude: 50.45, Longitude: 30.52}},
+			{Coordinates: models.Coordinates{Latitude: 50.05, Longitude: 30.77}},
+		}
+		provider := &candidatesProvider{Provider: mocks.NewProvider(t), results: expected}
+
+		results, err := geocoding.GeocodeCandidates(ctx, provider, "Kyiv", 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, results)
+	})
+}
+
+// structuredProvider is a provider mock that looks up structured addresses.
+type structuredProvider struct {
+	*mocks.Provider
+
+	addresses []models.StructuredAddress
+}
+
+func (sp *structuredProvider) GeocodeStructured(
+	_ context.Context,
+	address models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	sp.addresses = append(sp.addresses, address)
+	return &models.GeocodeResult{Coordinates: models.Coordinates{Latitude: 50.45, Longitude: 30.52}}, nil
+}
+
+func TestGeocodeStructured(t *testing.T) {
+	ctx := t.Context()
+	address := models.StructuredAddress{City: "м. Київ", Street: "вул. Хрещатик", HouseNumber: "1"}
+
+	t.Run("geocodes the free-form text without structured support", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+		mockProvider.On("Geocode", ctx, "м. Київ, вул. Хрещатик, 1").Return(kyivCoords, nil).Once()
+
+		result, err := geocoding.GeocodeStructured(ctx, mockProvider, address)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.GeocodeResult{Coordinates: *kyivCoords}, result)
+	})
+
+	t.Run("uses structured support", func(t *testing.T) {
+		provider := &structuredProvider{Provider: mocks.NewProvider(t)}
+
+		result, err := geocoding.GeocodeStructured(ctx, provider, address)
+
+		require.NoError(t, err)
+		assert.InDelta(t, 50.45, result.Latitude, 0)
+		assert.Equal(t, []models.StructuredAddress{address}, provider.addresses)
+	})
+}
+
+// reverseProvider is a provider mock that converts coordinates into a fixed address.
+type reverseProvider struct {
+	*mocks.Provider
+
+	address string
+}
+
+func (rp *reverseProvider) ReverseGeocode(_ context.Context, _ models.Coordinates) (string, error) {
+	return rp.address, nil
+}
+
+func TestReverseGeocode(t *testing.T) {
+	ctx := t.Context()
+	coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("uses reverse geocoding support", func(t *testing.T) {
+		provider := &reverseProvider{Provider: mocks.NewProvider(t), address: "вул. Хрещатик, 1, Київ"}
+
+		address, err := geocoding.ReverseGeocode(ctx, provider, coords)
+
+		require.NoError(t, err)
+		assert.Equal(t, "вул. Хрещатик, 1, Київ", address)
+	})
+
+	t.Run("unsupported without reverse geocoding support", func(t *testing.T) {
+		address, err := geocoding.ReverseGeocode(ctx, mocks.NewProvider(t), coords)
+
+		require.ErrorIs(t, err, geocoding.ErrReverseGeocodingUnsupported)
+		assert.Empty(t, address)
+	})
+}
@@ -0,0 +1,60 @@
+package geocoding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRateLimited is returned when the provider rejects a request because of its rate limit.
+// Such a failure is not caused by the address, so callers should retry the request later
+// instead of counting it as a failed attempt.
+var ErrRateLimited = errors.New("geocoding provider rate limit exceeded")
+
+// RateLimitError describes a rate-limited (HTTP 429) provider response.
+// It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	Provider   string        // Provider is the name of the provider that rejected the request
+	RetryAfter time.Duration // RetryAfter is the server-requested delay, zero if not provided
+}
+
+// Error returns a description of the rate limit, including the requested delay if known.
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s: %s, retry after %s", e.Provider, ErrRateLimited, e.RetryAfter)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Provider, ErrRateLimited)
+}
+
+// Unwrap returns ErrRateLimited, so that errors.Is(err, ErrRateLimited) matches.
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date. It returns zero for missing, invalid or past values.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
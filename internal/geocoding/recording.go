@@ -0,0 +1,143 @@
+package geocoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// redacted replaces the values of secret query parameters and headers in recordings.
+const redacted = "REDACTED"
+
+// maxRecordedBody caps the recorded part of a response body, so that an unexpectedly large response
+// doesn't blow up the recording.
+const maxRecordedBody = 64 << 10
+
+// secretParams are the query parameters holding the API key of a provider, e.g. "key" for Google
+// and Visicom and "apiKey" for HERE. They are matched ignoring case.
+var secretParams = []string{"key", "apiKey", "api_key", "token", "access_token"}
+
+// Recording is an HTTP exchange with a provider written by RecordingRoundTripper, with secrets redacted.
+type Recording struct {
+	Time     time.Time           `json:"time"`
+	Method   string              `json:"method"`
+	URL      string              `json:"url"`
+	Header   map[string][]string `json:"header,omitempty"`
+	Status   int                 `json:"status,omitempty"`
+	Body     string              `json:"body,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Duration time.Duration       `json:"duration"`
+}
+
+// RecordingRoundTripper wraps an HTTP transport and writes every request URL and header and the matching
+// response body as a JSON Recording line, for offline diagnosis of why an address failed. The API key
+// query parameters, credentials and API key headers are redacted. Each recording is written with a single
+// Write call, so a file opened in append mode can be shared by several round trippers.
+type RecordingRoundTripper struct {
+	base http.RoundTripper
+	out  io.Writer
+}
+
+// NewRecordingRoundTripper returns a RecordingRoundTripper sending requests through base, or
+// http.DefaultTransport if it is nil, and writing the recordings to out.
+func NewRecordingRoundTripper(base http.RoundTripper, out io.Writer) *RecordingRoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &RecordingRoundTripper{base: base, out: out}
+}
+
+// RoundTrip sends the request through the base transport and records it with the response.
+// The response body is read to be recorded and replaced with a copy, so the caller reads it as usual.
+// A failed write of the recording doesn't fail the request.
+func (t *RecordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	recording := Recording{
+		Time:   start,
+		Method: req.Method,
+		URL:    redactURL(req),
+		Header: redactHeader(req.Header),
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	recording.Duration = time.Since(start)
+	if err != nil {
+		recording.Error = err.Error()
+		t.write(recording)
+		return nil, err
+	}
+
+	recording.Status = resp.StatusCode
+	body, readErr := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	var replay io.Reader = bytes.NewReader(body)
+	if readErr != nil {
+		recording.Error = readErr.Error()
+		replay = io.MultiReader(replay, errReader{readErr})
+	}
+	resp.Body = io.NopCloser(replay)
+	recording.Body = string(body[:min(len(body), maxRecordedBody)])
+	t.write(recording)
+
+	return resp, nil
+}
+
+// write writes the recording as a JSON line.
+func (t *RecordingRoundTripper) write(recording Recording) {
+	line, err := json.Marshal(recording)
+	if err != nil {
+		return
+	}
+	_, _ = t.out.Write(append(line, '\n'))
+}
+
+// redactURL returns the URL of the request with the values of secret query parameters replaced
+// and its user information, e.g. proxy credentials, removed.
+func redactURL(req *http.Request) string {
+	redactedURL := *req.URL
+	query := redactedURL.Query()
+	for name := range query {
+		if isProtected(secretParams, name) {
+			query[name] = []string{redacted}
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+	redactedURL.User = nil
+
+	return redactedURL.String()
+}
+
+// redactHeader returns a copy of the header with the values of credentials and API key headers,
+// e.g. X-Api-Key or X-Goog-Api-Key, replaced.
+func redactHeader(header http.Header) map[string][]string {
+	if len(header) == 0 {
+		return nil
+	}
+
+	redactedHeader := make(map[string][]string, len(header))
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "authorization") || strings.Contains(lower, "key") ||
+			strings.Contains(lower, "token") || strings.Contains(lower, "secret") || lower == "cookie" {
+			redactedHeader[name] = []string{redacted}
+			continue
+		}
+		redactedHeader[name] = values
+	}
+
+	return redactedHeader
+}
+
+// errReader is a reader failing with err, so that the copy of a response body whose read failed
+// fails like the original body.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,93 @@
+package geocoding
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Reasons a provider request is retried, used as the "reason" label of the provider retries metric.
+const (
+	RetryReasonTimeout     = "timeout"
+	RetryReasonRateLimited = "429"
+	RetryReasonServerError = "5xx"
+)
+
+// DefaultRetryBackoff is the delay before the first retry of a provider request when none is configured.
+const DefaultRetryBackoff = 500 * time.Millisecond
+
+// RetryObserver is called before each retry of a request to a provider, with the reason of the retry.
+type RetryObserver func(provider ProviderType, reason string)
+
+// retryTransport resends a request that timed out or got a 429 or 5xx response, up to retries times,
+// waiting backoff before the first retry and doubling the delay after each one.
+// A 429 response asking for a longer delay than the next backoff is returned as is, so the provider
+// honors it instead of blocking the worker.
+type retryTransport struct {
+	base     http.RoundTripper
+	retries  int
+	backoff  time.Duration
+	provider ProviderType
+	onRetry  RetryObserver
+}
+
+// RoundTrip sends the request through the base transport, retrying it while it fails with a retryable reason.
+// Only GET requests are retried, since they have no body to replay.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+
+		reason := retryReason(req, resp, err)
+		if reason == "" || attempt == t.retries || req.Method != http.MethodGet || !retryAfterWithin(resp, backoff) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+
+		if t.onRetry != nil {
+			t.onRetry(t.provider, reason)
+		}
+		backoff *= 2
+	}
+}
+
+// retryReason returns the reason to retry a request that got resp or err, or an empty string if it must not
+// be retried. A timeout is only retried if it isn't the deadline of the request itself.
+func retryReason(req *http.Request, resp *http.Response, err error) string {
+	if err != nil {
+		var netErr net.Error
+		if req.Context().Err() == nil && errors.As(err, &netErr) && netErr.Timeout() {
+			return RetryReasonTimeout
+		}
+		return ""
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return RetryReasonRateLimited
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return RetryReasonServerError
+	default:
+		return ""
+	}
+}
+
+// retryAfterWithin reports whether the Retry-After delay of the response, if any, is not longer than backoff.
+func retryAfterWithin(resp *http.Response, backoff time.Duration) bool {
+	if resp == nil {
+		return true
+	}
+
+	return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()) <= backoff
+}
@@ -0,0 +1,232 @@
+package geocoding
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timeoutError is a network error reporting a timeout, like a dial or response header timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// roundTripFunc adapts a function to an http.RoundTripper.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// statusServer responds to each request with the next status of statuses, and with 200 once they run out.
+func statusServer(t *testing.T, statuses []int, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		i := int(requests.Add(1)) - 1
+		if i < len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[i])
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		retryAfter       string
+		expectedStatus   int
+		expectedRequests int32
+		expectedRetries  []string
+	}{
+		{
+			name:             "success first try",
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 1,
+		},
+		{
+			name:             "server errors then success",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 3,
+			expectedRetries:  []string{RetryReasonServerError, RetryReasonServerError},
+		},
+		{
+			name:             "rate limited then success",
+			statuses:         []int{http.StatusTooManyRequests},
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+			expectedRetries:  []string{RetryReasonRateLimited},
+		},
+		{
+			name:             "rate limited with a long retry after",
+			statuses:         []int{http.StatusTooManyRequests},
+			retryAfter:       "60",
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedRequests: 1,
+		},
+		{
+			name: "retries exhausted",
+			statuses: []int{
+				http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError,
+			},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedRequests: 3,
+			expectedRetries:  []string{RetryReasonServerError, RetryReasonServerError},
+		},
+		{
+			name:             "client error",
+			statuses:         []int{http.StatusBadRequest},
+			expectedStatus:   http.StatusBadRequest,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := statusServer(t, tt.statuses, tt.retryAfter)
+
+			var retries []string
+			transport := &retryTransport{
+				base:     http.DefaultTransport,
+				retries:  2,
+				backoff:  time.Millisecond,
+				provider: ProviderTypeHere,
+				onRetry: func(provider ProviderType, reason string) {
+					assert.Equal(t, ProviderTypeHere, provider)
+					retries = append(retries, reason)
+				},
+			}
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedRequests, requests.Load())
+			assert.Equal(t, tt.expectedRetries, retries)
+		})
+	}
+}
+
+func TestRetryTransport_Timeout(t *testing.T) {
+	var attempts int
+	transport := &retryTransport{
+		base: roundTripFunc(func(_ *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, timeoutError{}
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		retries: 1,
+		backoff: time.Millisecond,
+	}
+
+	var retries []string
+	transport.onRetry = func(_ ProviderType, reason string) {
+		retries = append(retries, reason)
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://example.invalid", nil)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []string{RetryReasonTimeout}, retries)
+}
+
+func TestRetryTransport_NotRetried(t *testing.T) {
+	t.Run("post request", func(t *testing.T) {
+		server, requests := statusServer(t, []int{http.StatusServiceUnavailable}, "")
+		transport := &retryTransport{base: http.DefaultTransport, retries: 2, backoff: time.Millisecond}
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("canceled during backoff", func(t *testing.T) {
+		server, requests := statusServer(t, []int{http.StatusServiceUnavailable}, "")
+		transport := &retryTransport{base: http.DefaultTransport, retries: 2, backoff: time.Hour}
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, resp)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
+
+func TestNewProvider_Retries(t *testing.T) {
+	server, requests := statusServer(t, []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, "")
+	reg := prometheus.NewRegistry()
+	appMetrics := metrics.NewMetrics(reg)
+
+	provider, err := NewProvider(ProviderConfig{
+		Type:         ProviderTypeNominatim,
+		Retries:      3,
+		RetryBackoff: time.Millisecond,
+		OnRetry: func(provider ProviderType, reason string) {
+			appMetrics.ProviderRetries.WithLabelValues(string(provider), reason).Inc()
+		},
+		Logger: slog.Default(),
+	})
+	require.NoError(t, err)
+	nominatim := provider.(*NominatimProvider)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := nominatim.client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.ProviderRetries.WithLabelValues("nominatim", "5xx")), 0)
+	assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.ProviderRetries.WithLabelValues("nominatim", "429")), 0)
+	assert.Equal(t, 2, testutil.CollectAndCount(appMetrics.ProviderRetries))
+}
+
+func TestNewProvider_RetriesDefaultBackoff(t *testing.T) {
+	client := providerHTTPClient(ProviderConfig{Type: ProviderTypeHere, Retries: 2})
+
+	transport, ok := client.Transport.(*retryTransport)
+	require.True(t, ok)
+	assert.Equal(t, DefaultRetryBackoff, transport.backoff)
+	assert.Equal(t, 2, transport.retries)
+}
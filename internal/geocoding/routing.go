@@ -0,0 +1,229 @@
+package geocoding
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// RoutingRule sends the addresses matching Pattern to the provider of type Provider.
+type RoutingRule struct {
+	Pattern  *regexp.Regexp // Pattern is matched against the whole address sent to the provider
+	Provider string         // Provider is the type of the provider geocoding the matching addresses
+}
+
+// route is a routing rule bound to its provider.
+type route struct {
+	pattern      *regexp.Regexp
+	providerType string
+	provider     Provider
+}
+
+// RoutingProvider geocodes each address with the provider of the first rule whose pattern matches it,
+// e.g. city addresses with a precise paid provider and rural ones with a free provider,
+// and with the default provider if no rule matches.
+type RoutingProvider struct {
+	routes   []route      // routes are tried in the order of the rules
+	fallback Provider     // fallback geocodes the addresses that match no rule
+	log      *slog.Logger // Logger for logging operations
+}
+
+// NewRoutingProvider creates a provider dispatching addresses by the rules to the providers of the given types,
+// and to fallback if no rule matches. It returns an error if a rule names a provider type that isn't given.
+func NewRoutingProvider(
+	rules []RoutingRule,
+	providers map[string]Provider,
+	fallback Provider,
+	log *slog.Logger,
+) (*RoutingProvider, error) {
+	routes := make([]route, 0, len(rules))
+	for i, rule := range rules {
+		provider, ok := providers[rule.Provider]
+		if !ok {
+			return nil, fmt.Errorf("routing rule %d: provider %q is not configured", i+1, rule.Provider)
+		}
+		routes = append(routes, route{pattern: rule.Pattern, providerType: rule.Provider, provider: provider})
+	}
+
+	return &RoutingProvider{routes: routes, fallback: fallback, log: log}, nil
+}
+
+// routingRulesFile is the content of a routing rules file.
+type routingRulesFile struct {
+	Rules []struct {
+		Pattern  string `yaml:"pattern"`
+		Provider string `yaml:"provider"`
+	} `yaml:"rules"`
+}
+
+// LoadRoutingRules reads routing rules from a YAML file listing a pattern and a provider type per rule,
+// in the order they are tried, e.g.
+//
+//	rules:
+//	  - pattern: '(?i)м\. ?Київ,'
+//	    provider: google
+//	  - pattern: '(?i)обл(\.|асть)'
+//	    provider: nominatim
+//
+// Patterns use the RE2 syntax of the regexp package, where \b only matches ASCII word boundaries,
+// so it can't delimit Cyrillic words.
+func LoadRoutingRules(path string) ([]RoutingRule, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the path is set by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing rules: %w", err)
+	}
+
+	var file routingRulesFile
+	if err = yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode routing rules: %w", err)
+	}
+
+	rules := make([]RoutingRule, 0, len(file.Rules))
+	for i, rule := range file.Rules {
+		if rule.Provider == "" {
+			return nil, fmt.Errorf("routing rule %d: provider is required", i+1)
+		}
+
+		pattern, compileErr := regexp.Compile(rule.Pattern)
+		if compileErr != nil {
+			return nil, fmt.Errorf("routing rule %d: invalid pattern: %w", i+1, compileErr)
+		}
+		rules = append(rules, RoutingRule{Pattern: pattern, Provider: rule.Provider})
+	}
+
+	return rules, nil
+}
+
+// Geocode geocodes the address with the provider of the first rule matching it, or the default provider.
+func (rp *RoutingProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	return rp.providerFor(ctx, address).Geocode(ctx, address)
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and returns the metadata of the match
+// if the chosen provider reports any.
+func (rp *RoutingProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
+	return GeocodeDetailed(ctx, rp.providerFor(ctx, address), address)
+}
+
+// GeocodeStructured geocodes the structured address with the provider of the first rule matching its free-form
+// text, or the default provider, by its fields if that provider implements StructuredGeocoder.
+func (rp *RoutingProvider) GeocodeStructured(
+	ctx context.Context,
+	address models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	return GeocodeStructured(ctx, rp.providerFor(ctx, address.String()), address)
+}
+
+// GeocodeCandidates returns up to limit matches for the address from the provider of the first rule matching it,
+// or the default provider, several if that provider implements CandidateGeocoder.
+func (rp *RoutingProvider) GeocodeCandidates(
+	ctx context.Context,
+	address string,
+	limit int,
+) ([]models.GeocodeResult, error) {
+	return GeocodeCandidates(ctx, rp.providerFor(ctx, address), address, limit)
+}
+
+// GeocodeBatch geocodes the addresses with a batch call to each of the providers the rules choose for them,
+// or the default provider, so each address keeps its own result or error.
+func (rp *RoutingProvider) GeocodeBatch(
+	ctx context.Context,
+	addresses []string,
+) ([]*models.Coordinates, []error) {
+	coords := make([]*models.Coordinates, len(addresses))
+	errs := make([]error, len(addresses))
+
+	// The addresses are split by the type of the provider chosen for them, empty for the default provider
+	var types []string
+	batches := make(map[string][]int)
+	providers := make(map[string]Provider)
+	for i, address := range addresses {
+		providerType, provider := rp.routeFor(ctx, address)
+		if _, ok := batches[providerType]; !ok {
+			types = append(types, providerType)
+			providers[providerType] = provider
+		}
+		batches[providerType] = append(batches[providerType], i)
+	}
+
+	for _, providerType := range types {
+		batch := make([]string, len(batches[providerType]))
+		for j, idx := range batches[providerType] {
+			batch[j] = addresses[idx]
+		}
+
+		batchCoords, batchErrs := GeocodeBatch(ctx, providers[providerType], batch)
+		for j, idx := range batches[providerType] {
+			coords[idx], errs[idx] = batchCoords[j], batchErrs[j]
+		}
+	}
+
+	return coords, errs
+}
+
+// SupportsBatch reports whether the default provider and the provider of every rule have a native batch API,
+// so that no address of a batch is geocoded one by one.
+func (rp *RoutingProvider) SupportsBatch() bool {
+	if !SupportsBatch(rp.fallback) {
+		return false
+	}
+	for _, r := range rp.routes {
+		if !SupportsBatch(r.provider) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ReverseGeocode converts the coordinates into an address with the default provider, since the rules match
+// addresses. It returns ErrReverseGeocodingUnsupported if the default provider can't reverse geocode.
+func (rp *RoutingProvider) ReverseGeocode(ctx context.Context, coords models.Coordinates) (string, error) {
+	return ReverseGeocode(ctx, rp.fallback, coords)
+}
+
+// HealthCheck verifies that the default provider and the provider of every rule are able to serve requests.
+func (rp *RoutingProvider) HealthCheck(ctx context.Context) error {
+	if err := rp.fallback.HealthCheck(ctx); err != nil {
+		return err
+	}
+
+	checked := make(map[string]bool, len(rp.routes))
+	for _, r := range rp.routes {
+		if checked[r.providerType] {
+			continue
+		}
+		checked[r.providerType] = true
+
+		if err := r.provider.HealthCheck(ctx); err != nil {
+			return fmt.Errorf("routed provider %s health check failed: %w", r.providerType, err)
+		}
+	}
+
+	return nil
+}
+
+// providerFor returns the provider of the first rule matching the address, or the default provider.
+func (rp *RoutingProvider) providerFor(ctx context.Context, address string) Provider {
+	_, provider := rp.routeFor(ctx, address)
+	return provider
+}
+
+// routeFor returns the type and the provider of the first rule matching the address,
+// or an empty type and the default provider.
+func (rp *RoutingProvider) routeFor(ctx context.Context, address string) (string, Provider) {
+	for _, r := range rp.routes {
+		if r.pattern.MatchString(address) {
+			rp.log.DebugContext(ctx, "Address matched a routing rule", "address", address,
+				"pattern", r.pattern.String(), "provider", r.providerType)
+			return r.providerType, r.provider
+		}
+	}
+
+	return "", rp.fallback
+}
@@ -0,0 +1,286 @@
+package geocoding_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingProvider_Geocode(t *testing.T) {
+	ctx := t.Context()
+	coords := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop}
+	rules := []geocoding.RoutingRule{
+		{Pattern: regexp.MustCompile(`(?i)м\. ?Київ,`), Provider: "google"},
+		{Pattern: regexp.MustCompile(`(?i)обл(\.|асть)`), Provider: "nominatim"},
+		{Pattern: regexp.MustCompile(`(?i)Київ`), Provider: "here"},
+	}
+
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+	}{
+		{name: "first rule", address: "м. Київ, вул. Хрещатик, 1", expected: "google"},
+		{name: "first matching rule wins", address: "Київська обл., с. Гора", expected: "nominatim"},
+		{name: "later rule", address: "Київ, вул. Хрещатик, 1", expected: "here"},
+		{name: "no rule matches", address: "м. Львів, пл. Ринок, 1", expected: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers := map[string]*mocks.Provider{
+				"google":    mocks.NewProvider(t),
+				"nominatim": mocks.NewProvider(t),
+				"here":      mocks.NewProvider(t),
+				"default":   mocks.NewProvider(t),
+			}
+			providers[tt.expected].On("Geocode", ctx, tt.address).Return(coords, nil).Once()
+
+			routing, err := geocoding.NewRoutingProvider(rules, map[string]geocoding.Provider{
+				"google":    providers["google"],
+				"nominatim": providers["nominatim"],
+				"here":      providers["here"],
+			}, providers["default"], slog.Default())
+			require.NoError(t, err)
+
+			result, err := routing.Geocode(ctx, tt.address)
+
+			require.NoError(t, err)
+			assert.Equal(t, coords, result)
+		})
+	}
+}
+
+func TestRoutingProvider_OptionalInterfaces(t *testing.T) {
+	ctx := t.Context()
+	rules := []geocoding.RoutingRule{{Pattern: regexp.MustCompile(`(?i)м\. ?Київ,`), Provider: "google"}}
+	kyiv := models.StructuredAddress{City: "м. Київ", Street: "вул. Хрещатик", HouseNumber: "1"}
+	lviv := models.StructuredAddress{City: "м. Львів", Street: "пл. Ринок", HouseNumber: "1"}
+	candidates := []models.GeocodeResult{
+		{Coordinates: models.Coordinates{Latitude: 50.45, Longitude: 30.52}},
+		{Coordinates: models.Coordinates{Latitude: 50.05, Longitude: 30.77}},
+	}
+
+	newRouting := func(t *testing.T, google, fallback geocoding.Provider) *geocoding.RoutingProvider {
+		t.Helper()
+		routing, err := geocoding.NewRoutingProvider(rules, map[string]geocoding.Provider{"google": google},
+			fallback, slog.Default())
+		require.NoError(t, err)
+
+		return routing
+	}
+
+	var routing geocoding.Provider = newRouting(t, mocks.NewProvider(t), mocks.NewProvider(t))
+	assert.Implements(t, (*geocoding.StructuredGeocoder)(nil), routing)
+	assert.Implements(t, (*geocoding.CandidateGeocoder)(nil), routing)
+	assert.Implements(t, (*geocoding.ReverseGeocoder)(nil), routing)
+	assert.Implements(t, (*geocoding.BatchProvider)(nil), routing)
+
+	t.Run("structured addresses are routed by their text", func(t *testing.T) {
+		google := &structuredProvider{Provider: mocks.NewProvider(t)}
+		fallback := &structuredProvider{Provider: mocks.NewProvider(t)}
+		routing := newRouting(t, google, fallback)
+
+		_, err := routing.GeocodeStructured(ctx, kyiv)
+		require.NoError(t, err)
+		_, err = routing.GeocodeStructured(ctx, lviv)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.StructuredAddress{kyiv}, google.addresses)
+		assert.Equal(t, []models.StructuredAddress{lviv}, fallback.addresses)
+	})
+
+	t.Run("candidates come from the routed provider", func(t *testing.T) {
+		google := &candidatesProvider{Provider: mocks.NewProvider(t), results: candidates}
+		routing := newRouting(t, google, mocks.NewProvider(t))
+
+		results, err := routing.GeocodeCandidates(ctx, kyiv.String(), 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, candidates, results)
+	})
+
+	t.Run("candidates of an unmatched address come from the default provider", func(t *testing.T) {
+		fallback := &candidatesProvider{Provider: mocks.NewProvider(t), results: candidates}
+		routing := newRouting(t, mocks.NewProvider(t), fallback)
+
+		results, err := routing.GeocodeCandidates(ctx, lviv.String(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, candidates[:1], results)
+	})
+
+	t.Run("reverse geocoding uses the default provider", func(t *testing.T) {
+		fallback := &reverseProvider{Provider: mocks.NewProvider(t), address: "вул. Хрещатик, 1, Київ"}
+		coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+		address, err := newRouting(t, mocks.NewProvider(t), fallback).ReverseGeocode(ctx, coords)
+
+		require.NoError(t, err)
+		assert.Equal(t, "вул. Хрещатик, 1, Київ", address)
+
+		_, err = newRouting(t, mocks.NewProvider(t), mocks.NewProvider(t)).ReverseGeocode(ctx, coords)
+		require.ErrorIs(t, err, geocoding.ErrReverseGeocodingUnsupported)
+	})
+
+	t.Run("batch addresses are split by route and keep their order", func(t *testing.T) {
+		kyivCoords := &candidates[0].Coordinates
+		lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+		google := &batchProvider{
+			Provider: mocks.NewProvider(t),
+			results:  map[string]*models.Coordinates{kyiv.String(): kyivCoords},
+		}
+		fallback := &batchProvider{
+			Provider: mocks.NewProvider(t),
+			results:  map[string]*models.Coordinates{lviv.String(): lvivCoords},
+		}
+
+		coords, errs := newRouting(t, google, fallback).
+			GeocodeBatch(ctx, []string{lviv.String(), kyiv.String(), "Nowhere"})
+
+		assert.Equal(t, []*models.Coordinates{lvivCoords, kyivCoords, nil}, coords)
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		require.ErrorIs(t, errs[2], geocoding.ErrEmptyResponse)
+		assert.Equal(t, [][]string{{kyiv.String()}}, google.calls)
+		assert.Equal(t, [][]string{{lviv.String(), "Nowhere"}}, fallback.calls)
+	})
+}
+
+func TestRoutingProvider_GeocodeError(t *testing.T) {
+	ctx := t.Context()
+	google := mocks.NewProvider(t)
+	fallback := mocks.NewProvider(t)
+	google.On("Geocode", ctx, "м. Київ").Return(nil, geocoding.ErrEmptyResponse).Once()
+
+	routing, err := geocoding.NewRoutingProvider(
+		[]geocoding.RoutingRule{{Pattern: regexp.MustCompile(`Київ`), Provider: "google"}},
+		map[string]geocoding.Provider{"google": google},
+		fallback,
+		slog.Default(),
+	)
+	require.NoError(t, err)
+
+	coords, err := routing.Geocode(ctx, "м. Київ")
+
+	require.ErrorIs(t, err, geocoding.ErrEmptyResponse)
+	assert.Nil(t, coords)
+	fallback.AssertNotCalled(t, "Geocode")
+}
+
+func TestNewRoutingProvider_UnknownProvider(t *testing.T) {
+	routing, err := geocoding.NewRoutingProvider(
+		[]geocoding.RoutingRule{{Pattern: regexp.MustCompile(`Київ`), Provider: "bing"}},
+		map[string]geocoding.Provider{},
+		mocks.NewProvider(t),
+		slog.Default(),
+	)
+
+	require.EqualError(t, err, `routing rule 1: provider "bing" is not configured`)
+	assert.Nil(t, routing)
+}
+
+func TestRoutingProvider_HealthCheck(t *testing.T) {
+	ctx := t.Context()
+	rules := []geocoding.RoutingRule{
+		{Pattern: regexp.MustCompile(`Київ`), Provider: "google"},
+		{Pattern: regexp.MustCompile(`Львів`), Provider: "google"},
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		google := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		fallback.On("HealthCheck", ctx).Return(nil).Once()
+		google.On("HealthCheck", ctx).Return(nil).Once()
+
+		routing, err := geocoding.NewRoutingProvider(rules, map[string]geocoding.Provider{"google": google},
+			fallback, slog.Default())
+		require.NoError(t, err)
+
+		require.NoError(t, routing.HealthCheck(ctx))
+	})
+
+	t.Run("routed provider unhealthy", func(t *testing.T) {
+		google := mocks.NewProvider(t)
+		fallback := mocks.NewProvider(t)
+		fallback.On("HealthCheck", ctx).Return(nil).Once()
+		google.On("HealthCheck", ctx).Return(assert.AnError).Once()
+
+		routing, err := geocoding.NewRoutingProvider(rules, map[string]geocoding.Provider{"google": google},
+			fallback, slog.Default())
+		require.NoError(t, err)
+
+		err = routing.HealthCheck(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "routed provider google health check failed")
+	})
+}
+
+func TestLoadRoutingRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `
+rules:
+  - pattern: '(?i)м\. ?Київ,'
+    provider: google
+  - pattern: '(?i)обл(\.|асть)'
+    provider: nominatim
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	rules, err := geocoding.LoadRoutingRules(path)
+
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "google", rules[0].Provider)
+	assert.True(t, rules[0].Pattern.MatchString("м. Київ, вул. Хрещатик, 1"))
+	assert.Equal(t, "nominatim", rules[1].Provider)
+	assert.True(t, rules[1].Pattern.MatchString("Львівська область, с. Зимна Вода"))
+}
+
+func TestLoadRoutingRules_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "invalid YAML", content: "rules: [", expected: "failed to decode routing rules"},
+		{
+			name:     "invalid pattern",
+			content:  "rules:\n  - pattern: '(Київ'\n    provider: google\n",
+			expected: "routing rule 1: invalid pattern",
+		},
+		{
+			name:     "missing provider",
+			content:  "rules:\n  - pattern: 'Київ'\n",
+			expected: "routing rule 1: provider is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			rules, err := geocoding.LoadRoutingRules(path)
+
+			require.ErrorContains(t, err, tt.expected)
+			assert.Nil(t, rules)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		rules, err := geocoding.LoadRoutingRules(filepath.Join(t.TempDir(), "missing.yaml"))
+
+		require.ErrorContains(t, err, "failed to read routing rules")
+		assert.Nil(t, rules)
+	})
+}
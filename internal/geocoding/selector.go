@@ -0,0 +1,115 @@
+package geocoding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// MaxNominatimResultLimit is the largest number of results the Nominatim search API returns for a single search.
+const MaxNominatimResultLimit = 40
+
+// Candidate is one of the results returned by a provider for a single search.
+type Candidate struct {
+	models.GeocodeResult
+	CountryCode string // CountryCode is the lowercase ISO 3166-1 alpha-2 code of the match, empty if unknown.
+}
+
+// ResultSelector picks the best match among the candidates of a single search, so providers can fetch
+// several results for an ambiguous address instead of taking the first one blindly.
+type ResultSelector interface {
+	// Select returns the index of the chosen candidate. Candidates are in the provider's order
+	// and never empty.
+	Select(candidates []Candidate) int
+}
+
+// FirstResultSelector selects the first candidate, i.e. the provider's own best match.
+type FirstResultSelector struct{}
+
+// Select returns the index of the first candidate.
+func (FirstResultSelector) Select([]Candidate) int {
+	return 0
+}
+
+// BestMatchSelector ranks the candidates and selects the best one. Candidates within Country come first,
+// then finer precisions (a house beats a street, a street beats a village), then candidates closer to Hint.
+// Ties keep the provider's order. An empty Country or a nil Hint disables that criterion.
+type BestMatchSelector struct {
+	Country string              // Country is the preferred lowercase ISO 3166-1 alpha-2 country code.
+	Hint    *models.Coordinates // Hint is a point near the expected matches, e.g. the center of the service area.
+}
+
+// Select returns the index of the best ranked candidate.
+func (s BestMatchSelector) Select(candidates []Candidate) int {
+	best := 0
+	for idx := 1; idx < len(candidates); idx++ {
+		if s.better(candidates[idx], candidates[best]) {
+			best = idx
+		}
+	}
+
+	return best
+}
+
+// better reports whether candidate a ranks strictly above candidate b.
+func (s BestMatchSelector) better(a, b Candidate) bool {
+	if s.Country != "" {
+		aIn, bIn := strings.EqualFold(a.CountryCode, s.Country), strings.EqualFold(b.CountryCode, s.Country)
+		if aIn != bIn {
+			return aIn
+		}
+	}
+
+	if aRank, bRank := precisionRank(a.Precision), precisionRank(b.Precision); aRank != bRank {
+		return aRank > bRank
+	}
+
+	if s.Hint != nil {
+		return a.DistanceTo(*s.Hint) < b.DistanceTo(*s.Hint)
+	}
+
+	return false
+}
+
+// precisionRank orders the precision levels from the least (0) to the most precise.
+func precisionRank(precision models.Precision) int {
+	switch precision {
+	case models.PrecisionRooftop:
+		return 4
+	case models.PrecisionStreet:
+		return 3
+	case models.PrecisionLocality:
+		return 2
+	case models.PrecisionRegion:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseResultHint parses a hint point given as "latitude,longitude", e.g. "50.4501,30.5234".
+// An empty hint returns nil.
+func ParseResultHint(hint string) (*models.Coordinates, error) {
+	if strings.TrimSpace(hint) == "" {
+		return nil, nil //nolint:nilnil // no hint is not an error
+	}
+
+	lat, lon, ok := strings.Cut(hint, ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid result hint %q: expected latitude,longitude", hint)
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil, fmt.Errorf("invalid result hint %q: invalid latitude", hint)
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("invalid result hint %q: invalid longitude", hint)
+	}
+
+	return &models.Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
@@ -0,0 +1,142 @@
+package geocoding_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// candidate returns a search candidate at the point with the given precision and country.
+func candidate(lat, lon float64, precision models.Precision, country string) geocoding.Candidate {
+	return geocoding.Candidate{
+		GeocodeResult: models.GeocodeResult{
+			Coordinates: models.Coordinates{Latitude: lat, Longitude: lon, Precision: precision},
+		},
+		CountryCode: country,
+	}
+}
+
+func TestFirstResultSelector(t *testing.T) {
+	candidates := []geocoding.Candidate{
+		candidate(50.45, 30.52, models.PrecisionLocality, "ua"),
+		candidate(49.84, 24.03, models.PrecisionRooftop, "ua"),
+	}
+
+	assert.Equal(t, 0, geocoding.FirstResultSelector{}.Select(candidates))
+}
+
+func TestBestMatchSelector(t *testing.T) {
+	kyiv := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+
+	tests := []struct {
+		name       string
+		selector   geocoding.BestMatchSelector
+		candidates []geocoding.Candidate
+		expected   int
+	}{
+		{
+			name:       "single candidate",
+			selector:   geocoding.BestMatchSelector{Country: "ua", Hint: kyiv},
+			candidates: []geocoding.Candidate{candidate(50.45, 30.52, models.PrecisionLocality, "pl")},
+			expected:   0,
+		},
+		{
+			name:     "finer precision wins",
+			selector: geocoding.BestMatchSelector{},
+			candidates: []geocoding.Candidate{
+				candidate(50.45, 30.52, models.PrecisionLocality, ""),
+				candidate(50.44, 30.51, models.PrecisionRooftop, ""),
+				candidate(50.43, 30.50, models.PrecisionStreet, ""),
+			},
+			expected: 1,
+		},
+		{
+			name:     "candidate within the country wins over a finer one abroad",
+			selector: geocoding.BestMatchSelector{Country: "ua"},
+			candidates: []geocoding.Candidate{
+				candidate(52.23, 21.01, models.PrecisionRooftop, "pl"),
+				candidate(50.45, 30.52, models.PrecisionStreet, "ua"),
+			},
+			expected: 1,
+		},
+		{
+			name:     "country code is case-insensitive",
+			selector: geocoding.BestMatchSelector{Country: "ua"},
+			candidates: []geocoding.Candidate{
+				candidate(52.23, 21.01, models.PrecisionStreet, "pl"),
+				candidate(50.45, 30.52, models.PrecisionStreet, "UA"),
+			},
+			expected: 1,
+		},
+		{
+			name:     "closest to the hint breaks precision ties",
+			selector: geocoding.BestMatchSelector{Hint: kyiv},
+			candidates: []geocoding.Candidate{
+				candidate(49.84, 24.03, models.PrecisionLocality, "ua"),
+				candidate(50.45, 30.52, models.PrecisionLocality, "ua"),
+				candidate(46.48, 30.72, models.PrecisionLocality, "ua"),
+			},
+			expected: 1,
+		},
+		{
+			name:     "precision outranks the hint",
+			selector: geocoding.BestMatchSelector{Hint: kyiv},
+			candidates: []geocoding.Candidate{
+				candidate(50.45, 30.52, models.PrecisionLocality, "ua"),
+				candidate(49.84, 24.03, models.PrecisionStreet, "ua"),
+			},
+			expected: 1,
+		},
+		{
+			name:     "ties keep the provider's order",
+			selector: geocoding.BestMatchSelector{Country: "ua"},
+			candidates: []geocoding.Candidate{
+				candidate(49.84, 24.03, models.PrecisionStreet, "ua"),
+				candidate(50.45, 30.52, models.PrecisionStreet, "ua"),
+			},
+			expected: 0,
+		},
+		{
+			name:     "unknown precision ranks last",
+			selector: geocoding.BestMatchSelector{},
+			candidates: []geocoding.Candidate{
+				candidate(50.45, 30.52, models.PrecisionApproximate, ""),
+				candidate(50.45, 30.52, models.PrecisionRegion, ""),
+			},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.selector.Select(tt.candidates))
+		})
+	}
+}
+
+func TestParseResultHint(t *testing.T) {
+	t.Run("valid hint", func(t *testing.T) {
+		hint, err := geocoding.ParseResultHint(" 50.4501, 30.5234 ")
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}, hint)
+	})
+
+	t.Run("empty hint", func(t *testing.T) {
+		hint, err := geocoding.ParseResultHint("")
+
+		require.NoError(t, err)
+		assert.Nil(t, hint)
+	})
+
+	for _, hint := range []string{"50.4501", "north,30.5234", "50.4501,east", "91,30", "50,181"} {
+		t.Run("invalid hint "+hint, func(t *testing.T) {
+			_, err := geocoding.ParseResultHint(hint)
+
+			require.ErrorContains(t, err, "invalid result hint")
+		})
+	}
+}
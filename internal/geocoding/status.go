@@ -0,0 +1,29 @@
+package geocoding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrServerError is matched by the errors of provider responses with a 5xx status, which tell that the
+// provider is overloaded or down rather than that the request is wrong.
+var ErrServerError = errors.New("geocoding provider server error")
+
+// StatusError describes a provider response with an unexpected HTTP status.
+// It matches ErrServerError with errors.Is if the status is 5xx.
+type StatusError struct {
+	Provider   string // Provider is the name of the provider that sent the response
+	StatusCode int    // StatusCode is the HTTP status of the response
+	Body       string // Body is the body of the response, often an error message
+}
+
+// Error returns a description of the response, including its status and body.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s API returned status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Is reports whether the status is 5xx when target is ErrServerError.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrServerError && e.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,117 @@
+package geocoding
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// structuredAddress is a free-form Ukrainian address split into the fields of a Nominatim structured search.
+type structuredAddress struct {
+	city        string // city is the settlement name without its type, e.g. "Грабовець"
+	street      string // street is the street name with its full type, e.g. "вулиця Польова"
+	houseNumber string // houseNumber is the house number, e.g. "12а" or "5/2", empty if the address has none
+}
+
+// params returns the search parameters of the structured search. Nominatim expects the house number
+// in front of the street name.
+func (sa structuredAddress) params() url.Values {
+	street := sa.street
+	if sa.houseNumber != "" {
+		street = sa.houseNumber + " " + sa.street
+	}
+
+	return url.Values{"street": {street}, "city": {sa.city}, "country": {nominatimCountry}}
+}
+
+// settlementPattern matches a settlement component, e.g. "с. Грабовець" or "місто Київ", capturing the name.
+var settlementPattern = regexp.MustCompile(
+	`(?i)^(?:селище міського типу|смт|селище|село|с|місто|м)(?:\.\s*|\s+)(.+)$`,
+)
+
+// streetPattern matches a street component, e.g. "вул. Польова" or "просп. Свободи 28", capturing the type
+// and the rest of the component.
+var streetPattern = regexp.MustCompile(
+	`(?i)^(вулиця|вул|провулок|пров|проспект|просп|пр-т|бульвар|бульв|б-р|площа|пл)(?:\.\s*|\s+)(.+)$`,
+)
+
+// streetTypes maps the street type variants matched by streetPattern to the full type used in OSM names.
+var streetTypes = map[string]string{
+	"вулиця":   "вулиця",
+	"вул":      "вулиця",
+	"провулок": "провулок",
+	"пров":     "провулок",
+	"проспект": "проспект",
+	"просп":    "проспект",
+	"пр-т":     "проспект",
+	"бульвар":  "бульвар",
+	"бульв":    "бульвар",
+	"б-р":      "бульвар",
+	"площа":    "площа",
+	"пл":       "площа",
+}
+
+// houseNumberPattern matches a house number component, e.g. "3", "буд. 12а" or "5/2", capturing the number.
+var houseNumberPattern = regexp.MustCompile(
+	`(?i)^(?:(?:будинок|буд)(?:\.\s*|\s+))?(\d+(?:\s*\p{L})?(?:\s*[/-]\s*\d+\p{L}?)?)$`,
+)
+
+// trailingHouseNumberPattern matches a street name followed by its house number, e.g. "Польова 3".
+var trailingHouseNumberPattern = regexp.MustCompile(`^(.*\S)\s+(\d+\p{L}?(?:[/-]\d+\p{L}?)?)$`)
+
+// parseStructuredAddress splits a free-form Ukrainian address into a settlement, a street and a house number,
+// using the settlement ("с.", "смт", "м.") and street ("вул.", "пров.", "просп." etc.) type markers of its
+// comma-separated components. The house number is either a component of its own after the street, or
+// the end of the street component. Other components, such as the region or a postal code, are left out.
+// It reports false unless both a settlement and a street were found.
+func parseStructuredAddress(address string) (structuredAddress, bool) {
+	var parsed structuredAddress
+
+	for component := range strings.SplitSeq(address, ",") {
+		component = strings.Join(strings.Fields(component), " ")
+
+		if match := streetPattern.FindStringSubmatch(component); match != nil && parsed.street == "" {
+			name := match[2]
+			if number := trailingHouseNumberPattern.FindStringSubmatch(name); number != nil {
+				name, parsed.houseNumber = number[1], number[2]
+			}
+			parsed.street = streetTypes[strings.ToLower(match[1])] + " " + name
+			continue
+		}
+
+		if match := settlementPattern.FindStringSubmatch(component); match != nil {
+			// The last settlement is the most specific one, e.g. a village after its district center
+			parsed.city = match[1]
+			continue
+		}
+
+		if match := houseNumberPattern.FindStringSubmatch(component); match != nil &&
+			parsed.street != "" && parsed.houseNumber == "" {
+			parsed.houseNumber = strings.Join(strings.Fields(match[1]), "")
+		}
+	}
+
+	return parsed, parsed.city != "" && parsed.street != ""
+}
+
+// structuredAddressOf converts the fields of a stored structured address into those of a structured search,
+// dropping the settlement type from the city and spelling out the street type like parseStructuredAddress.
+// Fields without a known type are used as is. It reports false unless the address has a city and a street.
+func structuredAddressOf(address models.StructuredAddress) (structuredAddress, bool) {
+	sa := structuredAddress{
+		city:        strings.Join(strings.Fields(address.City), " "),
+		street:      strings.Join(strings.Fields(address.Street), " "),
+		houseNumber: strings.Join(strings.Fields(address.HouseNumber), ""),
+	}
+
+	if match := settlementPattern.FindStringSubmatch(sa.city); match != nil {
+		sa.city = match[1]
+	}
+	if match := streetPattern.FindStringSubmatch(sa.street); match != nil {
+		sa.street = streetTypes[strings.ToLower(match[1])] + " " + match[2]
+	}
+
+	return sa, sa.city != "" && sa.street != ""
+}
@@ -0,0 +1,141 @@
+package geocoding
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStructuredAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected structuredAddress
+		parsed   bool
+	}{
+		{
+			name:     "village street and house",
+			address:  "с. Грабовець, вул. Польова, 3",
+			expected: structuredAddress{city: "Грабовець", street: "вулиця Польова", houseNumber: "3"},
+			parsed:   true,
+		},
+		{
+			name:     "city with postal code",
+			address:  "79000, м. Львів, пл. Ринок, 1",
+			expected: structuredAddress{city: "Львів", street: "площа Ринок", houseNumber: "1"},
+			parsed:   true,
+		},
+		{
+			name:     "region and district are left out",
+			address:  "Івано-Франківська обл., Тисменицький р-н, смт Лисець, вул. Шевченка 12а",
+			expected: structuredAddress{city: "Лисець", street: "вулиця Шевченка", houseNumber: "12а"},
+			parsed:   true,
+		},
+		{
+			name:     "house number with a building marker and a fraction",
+			address:  "м. Одеса, просп. Шевченка, буд. 4/2",
+			expected: structuredAddress{city: "Одеса", street: "проспект Шевченка", houseNumber: "4/2"},
+			parsed:   true,
+		},
+		{
+			name:     "full type words and a spaced letter",
+			address:  "місто Харків,  вулиця  Сумська, 25 Б",
+			expected: structuredAddress{city: "Харків", street: "вулиця Сумська", houseNumber: "25Б"},
+			parsed:   true,
+		},
+		{
+			name:     "street name starting with a number",
+			address:  "м. Тернопіль, вул. 15 Квітня, 6",
+			expected: structuredAddress{city: "Тернопіль", street: "вулиця 15 Квітня", houseNumber: "6"},
+			parsed:   true,
+		},
+		{
+			name:     "street before the settlement",
+			address:  "вул. Героїв УПА 73, м. Львів",
+			expected: structuredAddress{city: "Львів", street: "вулиця Героїв УПА", houseNumber: "73"},
+			parsed:   true,
+		},
+		{
+			name:     "street without a house number",
+			address:  "м. Дніпро, пров. Універсальний",
+			expected: structuredAddress{city: "Дніпро", street: "провулок Універсальний"},
+			parsed:   true,
+		},
+		{
+			name:     "no type markers",
+			address:  "Київ, Хрещатик 22",
+			expected: structuredAddress{},
+		},
+		{
+			name:     "settlement only",
+			address:  "с. Грабовець",
+			expected: structuredAddress{city: "Грабовець"},
+		},
+		{
+			name:     "street without a settlement",
+			address:  "вул. Польова, 3",
+			expected: structuredAddress{street: "вулиця Польова", houseNumber: "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, ok := parseStructuredAddress(tt.address)
+
+			assert.Equal(t, tt.parsed, ok)
+			assert.Equal(t, tt.expected, parsed)
+		})
+	}
+}
+
+func TestStructuredAddress_Params(t *testing.T) {
+	withHouse := structuredAddress{city: "Грабовець", street: "вулиця Польова", houseNumber: "3"}
+	withoutHouse := structuredAddress{city: "Дніпро", street: "провулок Універсальний"}
+
+	assert.Equal(t,
+		url.Values{"street": {"3 вулиця Польова"}, "city": {"Грабовець"}, "country": {"ua"}},
+		withHouse.params())
+	assert.Equal(t,
+		url.Values{"street": {"провулок Універсальний"}, "city": {"Дніпро"}, "country": {"ua"}},
+		withoutHouse.params())
+}
+
+func TestStructuredAddressOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  models.StructuredAddress
+		expected structuredAddress
+		ok       bool
+	}{
+		{
+			name: "types are converted",
+			address: models.StructuredAddress{
+				Region: "Львівська обл.", City: "с.  Грабовець", Street: "вул. Польова", HouseNumber: "12 а",
+			},
+			expected: structuredAddress{city: "Грабовець", street: "вулиця Польова", houseNumber: "12а"},
+			ok:       true,
+		},
+		{
+			name:     "fields without a type are used as is",
+			address:  models.StructuredAddress{City: "Київ", Street: "Хрещатик"},
+			expected: structuredAddress{city: "Київ", street: "Хрещатик"},
+			ok:       true,
+		},
+		{
+			name:     "missing street",
+			address:  models.StructuredAddress{City: "м. Київ", HouseNumber: "1"},
+			expected: structuredAddress{city: "Київ", houseNumber: "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa, ok := structuredAddressOf(tt.address)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, sa)
+		})
+	}
+}
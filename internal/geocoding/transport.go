@@ -0,0 +1,70 @@
+package geocoding
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultHTTPTimeout bounds a single HTTP request to a provider API when no timeout is configured.
+const DefaultHTTPTimeout = 10 * time.Second
+
+// sharedTransport is the HTTP transport used by all providers unless ProviderConfig.Transport is set.
+// Sharing it lets concurrent workers reuse keep-alive connections instead of opening new TLS sessions.
+var sharedTransport = NewTransport()
+
+// NewTransport returns an HTTP transport tuned for many concurrent requests to a single provider host.
+func NewTransport() *http.Transport {
+	const (
+		maxIdleConns        = 100
+		maxIdleConnsPerHost = 20
+		idleConnTimeout     = 90 * time.Second
+		dialTimeout         = 5 * time.Second
+		keepAlive           = 30 * time.Second
+		tlsHandshakeTimeout = 5 * time.Second
+	)
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: keepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		IdleConnTimeout:     idleConnTimeout,
+		TLSHandshakeTimeout: tlsHandshakeTimeout,
+	}
+}
+
+// proxiedTransport returns a copy of the transport, or of the shared transport if it is nil, sending requests
+// through the proxy at proxyURL instead of the proxy of the environment. It returns transport as is if proxyURL
+// is nil. Copying leaves the connections of providers without the proxy untouched.
+func proxiedTransport(transport *http.Transport, proxyURL *url.URL) *http.Transport {
+	if proxyURL == nil {
+		return transport
+	}
+	if transport == nil {
+		transport = sharedTransport
+	}
+
+	proxied := transport.Clone()
+	proxied.Proxy = http.ProxyURL(proxyURL)
+
+	return proxied
+}
+
+// newHTTPClient creates an HTTP client for a provider using the given transport,
+// or the shared transport if transport is nil. A non-positive timeout uses DefaultHTTPTimeout.
+func newHTTPClient(transport *http.Transport, timeout time.Duration) *http.Client {
+	if transport == nil {
+		transport = sharedTransport
+	}
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,96 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// ErrVerificationMismatch is returned by VerifyingProvider when the verifier's result is further
+// from the primary result than the allowed distance, so neither result can be trusted.
+var ErrVerificationMismatch = errors.New("geocoding providers disagree on the location")
+
+// VerifyingProvider decorates a primary provider and cross-checks its low-confidence results
+// with a second provider. A result is low-confidence if it is coarser than a rooftop match,
+// has no reported precision, or was matched with an address fallback.
+// Such a result is only returned if the verifier's result lies within the maximum distance of it.
+type VerifyingProvider struct {
+	primary     Provider     // primary is the provider whose results are returned
+	verifier    Provider     // verifier is the provider used to cross-check low-confidence results
+	maxDistance float64      // maxDistance is the allowed distance between both results, in meters
+	log         *slog.Logger // Logger for logging operations
+}
+
+// NewVerifyingProvider creates a provider that returns the results of primary,
+// verifying low-confidence results with verifier. maxDistance is in meters.
+func NewVerifyingProvider(primary, verifier Provider, maxDistance float64, log *slog.Logger) *VerifyingProvider {
+	return &VerifyingProvider{
+		primary:     primary,
+		verifier:    verifier,
+		maxDistance: maxDistance,
+		log:         log,
+	}
+}
+
+// Geocode geocodes the address with the primary provider and, if the result is low-confidence,
+// with the verifier. It returns an error wrapping ErrVerificationMismatch if the results are too far
+// apart, and the verifier's error if it fails, so the task is retried instead of storing a doubtful result.
+func (vp *VerifyingProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	result, err := vp.GeocodeDetailed(ctx, address)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed verifies the result like Geocode, and returns the primary provider's metadata
+// of the match if it reports any.
+func (vp *VerifyingProvider) GeocodeDetailed(ctx context.Context, address string) (*models.GeocodeResult, error) {
+	result, err := GeocodeDetailed(ctx, vp.primary, address)
+	if err != nil || result == nil || !needsVerification(&result.Coordinates) {
+		return result, err
+	}
+
+	vp.log.DebugContext(ctx, "Verifying low-confidence result", "address", address,
+		"precision", result.Precision, "fallback_level", result.FallbackLevel)
+
+	verified, err := vp.verifier.Geocode(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify geocoding result: %w", err)
+	}
+	if verified == nil {
+		return nil, fmt.Errorf("failed to verify geocoding result: %w", ErrVerificationMismatch)
+	}
+
+	distance := result.DistanceTo(*verified)
+	if distance > vp.maxDistance {
+		vp.log.WarnContext(ctx, "Geocoding providers disagree", "address", address,
+			"distance_m", distance, "max_distance_m", vp.maxDistance)
+		return nil, fmt.Errorf("%w: results are %.0f m apart, at most %.0f m allowed",
+			ErrVerificationMismatch, distance, vp.maxDistance)
+	}
+
+	return result, nil
+}
+
+// HealthCheck verifies that both the primary provider and the verifier are able to serve requests.
+func (vp *VerifyingProvider) HealthCheck(ctx context.Context) error {
+	if err := vp.primary.HealthCheck(ctx); err != nil {
+		return err
+	}
+
+	if err := vp.verifier.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("verifier health check failed: %w", err)
+	}
+
+	return nil
+}
+
+// needsVerification reports whether a result is low-confidence and must be cross-checked.
+func needsVerification(coords *models.Coordinates) bool {
+	return coords.Precision != models.PrecisionRooftop || coords.FallbackLevel > 0
+}
@@ -0,0 +1,178 @@
+package geocoding_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyingProvider_Geocode(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	const maxDistance = 1000
+
+	coarse := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionLocality}
+	nearby := &models.Coordinates{Latitude: 50.4547, Longitude: 30.5238, Precision: models.PrecisionStreet}
+	lviv := &models.Coordinates{Latitude: 49.8397, Longitude: 24.0297, Precision: models.PrecisionLocality}
+
+	t.Run("high-confidence result is not verified", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		rooftop := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop}
+		primary.On("Geocode", ctx, "Kyiv").Return(rooftop, nil).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.NoError(t, err)
+		assert.Equal(t, rooftop, coords)
+		verifier.AssertNotCalled(t, "Geocode")
+	})
+
+	t.Run("fallback match is verified", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		fallback := &models.Coordinates{
+			Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop, FallbackLevel: 1,
+		}
+		primary.On("Geocode", ctx, "Kyiv").Return(fallback, nil).Once()
+		verifier.On("Geocode", ctx, "Kyiv").Return(nearby, nil).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.NoError(t, err)
+		assert.Equal(t, fallback, coords)
+	})
+
+	t.Run("agreeing results return the primary result", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(coarse, nil).Once()
+		verifier.On("Geocode", ctx, "Kyiv").Return(nearby, nil).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.NoError(t, err)
+		assert.Equal(t, coarse, coords)
+	})
+
+	t.Run("disagreeing results return a mismatch error", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(coarse, nil).Once()
+		verifier.On("Geocode", ctx, "Kyiv").Return(lviv, nil).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrVerificationMismatch)
+		assert.Nil(t, coords)
+	})
+
+	t.Run("verifier failure is returned", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(coarse, nil).Once()
+		verifier.On("Geocode", ctx, "Kyiv").Return(nil, assert.AnError).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to verify geocoding result")
+		assert.Nil(t, coords)
+	})
+
+	t.Run("verifier rate limit is kept", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(coarse, nil).Once()
+		verifier.On("Geocode", ctx, "Kyiv").Return(nil, &geocoding.RateLimitError{Provider: "here"}).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		_, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+	})
+
+	t.Run("verifier without result is a mismatch", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(coarse, nil).Once()
+		verifier.On("Geocode", ctx, "Kyiv").Return(nil, nil).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		_, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrVerificationMismatch)
+	})
+
+	t.Run("primary failure skips the verifier", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("Geocode", ctx, "Kyiv").Return(nil, assert.AnError).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, maxDistance, logger)
+		_, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, assert.AnError)
+		verifier.AssertNotCalled(t, "Geocode")
+	})
+}
+
+func TestVerifyingProvider_HealthCheck(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+
+	t.Run("both providers healthy", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("HealthCheck", ctx).Return(nil).Once()
+		verifier.On("HealthCheck", ctx).Return(nil).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, 1000, logger)
+
+		require.NoError(t, provider.HealthCheck(ctx))
+	})
+
+	t.Run("unhealthy verifier", func(t *testing.T) {
+		primary := mocks.NewProvider(t)
+		verifier := mocks.NewProvider(t)
+		primary.On("HealthCheck", ctx).Return(nil).Once()
+		verifier.On("HealthCheck", ctx).Return(assert.AnError).Once()
+
+		provider := geocoding.NewVerifyingProvider(primary, verifier, 1000, logger)
+		err := provider.HealthCheck(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "verifier health check failed")
+	})
+}
+
+func TestVerifyingProvider_GeocodeDetailed(t *testing.T) {
+	ctx := t.Context()
+	primary := &detailedProvider{
+		Provider: mocks.NewProvider(t),
+		result: &models.GeocodeResult{
+			Coordinates:      models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionLocality},
+			FormattedAddress: "Київ, Україна",
+			Provider:         "nominatim",
+		},
+	}
+	verifier := mocks.NewProvider(t)
+	nearby := &models.Coordinates{Latitude: 50.4547, Longitude: 30.5238, Precision: models.PrecisionStreet}
+	verifier.On("Geocode", ctx, "Kyiv").Return(nearby, nil).Once()
+
+	provider := geocoding.NewVerifyingProvider(primary, verifier, 1000, slog.Default())
+	result, err := provider.GeocodeDetailed(ctx, "Kyiv")
+
+	require.NoError(t, err)
+	assert.Equal(t, primary.result, result)
+}
@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/models"
@@ -25,6 +28,17 @@ type VisicomProvider struct {
 	apiKey  string        // API key with geocoding access
 	log     *slog.Logger  // Logger for logging operations
 	limiter *rate.Limiter // Rate limiter
+	timeout time.Duration // Overall deadline for a single Geocode call
+}
+
+// VisicomOption configures optional behavior of the VisicomProvider.
+type VisicomOption func(*VisicomProvider)
+
+// WithVisicomRequestTimeout sets the overall deadline for a single Geocode call.
+func WithVisicomRequestTimeout(timeout time.Duration) VisicomOption {
+	return func(vp *VisicomProvider) {
+		vp.timeout = timeout
+	}
 }
 
 // Common errors for Visicom provider.
@@ -36,41 +50,94 @@ var (
 )
 
 // Visicom API response (simplified for geocoding use-case).
+// The coordinates are decoded as raw values, so a malformed coordinate is reported as ErrVisicomInvalidCoords
+// instead of failing to decode the whole response.
 type visicomResponse struct {
-	Geometry struct {
-		Coordinates []float64 `json:"coordinates"` // [lon, lat]
-	} `json:"geo_centroid"`
+	ID       string `json:"id"` // Visicom feature identifier
+	Geometry *struct {
+		Coordinates []json.RawMessage `json:"coordinates"` // [lon, lat]
+	} `json:"geo_centroid"` // nil if nothing was found
+	Properties visicomProperties `json:"properties"`
 }
 
-// NewVisicomProvider creates a new Visicom geocoding provider.
-func NewVisicomProvider(apiKey string, rateLimit int, log *slog.Logger) *VisicomProvider {
-	const timeout = 10
+// visicomProperties are the properties of a Visicom feature. A house is named by its number, with its street
+// and settlement in separate properties, while streets and settlements are named in full.
+type visicomProperties struct {
+	Categories string `json:"categories"`  // Feature category, e.g. "adr_address", "adr_street", "adm_settlement"
+	Name       string `json:"name"`        // Name of the feature, e.g. "1", "Хрещатик" or "Київ"
+	StreetType string `json:"street_type"` // Type of the street of a house, e.g. "вул."
+	Street     string `json:"street"`      // Name of the street of a house
+	Settlement string `json:"settlement"`  // Name of the settlement of a house or street
+}
 
-	return &VisicomProvider{
-		client: &http.Client{
-			Timeout: timeout * time.Second,
-		},
-		baseURL: VisicomBaseURL,
-		apiKey:  apiKey,
-		log:     log,
-		limiter: rate.NewLimiter(rate.Limit(rateLimit), rateLimit),
+// formattedAddress joins the street, name and settlement of the feature into an address,
+// e.g. "вул. Хрещатик, 1, Київ", leaving out the properties the feature doesn't have.
+func (p visicomProperties) formattedAddress() string {
+	var parts []string
+	if p.Street != "" {
+		parts = append(parts, strings.TrimSpace(p.StreetType+" "+p.Street))
+	}
+	if p.Name != "" {
+		parts = append(parts, p.Name)
+	}
+	if p.Settlement != "" && p.Settlement != p.Name {
+		parts = append(parts, p.Settlement)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// visicomPrecision maps the category of a Visicom feature to a precision level.
+// Address features ("adr_*") are houses and streets, administrative features ("adm_*")
+// are settlements or larger areas represented by their centroid.
+func visicomPrecision(category string) models.Precision {
+	switch {
+	case category == "adr_address":
+		return models.PrecisionRooftop
+	case category == "adr_street":
+		return models.PrecisionStreet
+	case category == "adm_settlement":
+		return models.PrecisionLocality
+	case strings.HasPrefix(category, "adm_"):
+		return models.PrecisionRegion
+	default:
+		return models.PrecisionApproximate
 	}
 }
 
+// NewVisicomProvider creates a new Visicom geocoding provider using the shared HTTP transport.
+func NewVisicomProvider(apiKey string, rateLimit int, log *slog.Logger, opts ...VisicomOption) *VisicomProvider {
+	return NewVisicomProviderWithClient(
+		newHTTPClient(nil, DefaultHTTPTimeout),
+		apiKey,
+		rate.NewLimiter(rate.Limit(rateLimit), rateLimit),
+		log,
+		opts...,
+	)
+}
+
 // NewVisicomProviderWithClient allows injecting custom HTTP client.
 func NewVisicomProviderWithClient(
 	client HTTPClient,
 	apiKey string,
 	limiter *rate.Limiter,
 	log *slog.Logger,
+	opts ...VisicomOption,
 ) *VisicomProvider {
-	return &VisicomProvider{
+	vp := &VisicomProvider{
 		client:  client,
 		baseURL: VisicomBaseURL,
 		apiKey:  apiKey,
 		log:     log,
 		limiter: limiter,
+		timeout: DefaultRequestTimeout,
 	}
+
+	for _, opt := range opts {
+		opt(vp)
+	}
+
+	return vp
 }
 
 // Geocode converts address into geographic coordinates using Visicom API.
@@ -78,7 +145,23 @@ func (vp *VisicomProvider) Geocode(
 	ctx context.Context,
 	address string,
 ) (*models.Coordinates, error) {
-	const coordsListLength = 2
+	result, err := vp.GeocodeDetailed(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Coordinates, nil
+}
+
+// GeocodeDetailed geocodes the address like Geocode, and also returns the id and the address of the matched
+// feature. Visicom reports no confidence for its features.
+func (vp *VisicomProvider) GeocodeDetailed(
+	ctx context.Context,
+	address string,
+) (*models.GeocodeResult, error) {
+	// Bound the whole call, including the rate limiter wait
+	ctx, cancel := context.WithTimeout(ctx, vp.timeout)
+	defer cancel()
 
 	// Rate limit
 	if err := vp.limiter.Wait(ctx); err != nil {
@@ -117,6 +200,10 @@ func (vp *VisicomProvider) Geocode(
 	// Headers
 	req.Header.Set("Accept", "application/json")
 
+	if err := spendRequestBudget(ctx); err != nil {
+		return nil, err
+	}
+
 	resp, err := vp.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute geocoding request: %w", err)
@@ -128,10 +215,13 @@ func (vp *VisicomProvider) Geocode(
 		// continue
 	case http.StatusUnauthorized, http.StatusForbidden:
 		return nil, ErrVisicomUnathorized
+	case http.StatusTooManyRequests:
+		vp.log.WarnContext(ctx, "Visicom API rate limit exceeded", "address", address)
+		return nil, &RateLimitError{Provider: string(ProviderTypeVisicom)}
 	default:
 		body, _ := io.ReadAll(resp.Body)
 		vp.log.ErrorContext(ctx, "Visicom API error", "status", resp.StatusCode, "body", string(body))
-		return nil, fmt.Errorf("visicom API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Provider: "visicom", StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -146,22 +236,63 @@ func (vp *VisicomProvider) Geocode(
 		return nil, fmt.Errorf("failed to decode visicom response: %w", err)
 	}
 
-	coords := result.Geometry.Coordinates
-	if len(coords) == 0 {
+	if result.Geometry == nil {
 		return nil, ErrVisicomEmptyResponse
 	}
 
-	if len(coords) != coordsListLength {
-		return nil, ErrVisicomInvalidCoords
+	lat, lon, err := visicomCoordinates(result.Geometry.Coordinates)
+	if err != nil {
+		vp.log.WarnContext(ctx, "Visicom returned invalid coordinates", "address", address, "error", err)
+		return nil, err
 	}
 
-	lon := coords[0]
-	lat := coords[1]
-
 	vp.log.InfoContext(ctx, "Visicom found result", "address", address, "lat", lat, "lon", lon)
 
-	return &models.Coordinates{
-		Latitude:  lat,
-		Longitude: lon,
+	return &models.GeocodeResult{
+		Coordinates: models.Coordinates{
+			Latitude:  lat,
+			Longitude: lon,
+			Precision: visicomPrecision(result.Properties.Categories),
+		},
+		PlaceID:          result.ID,
+		FormattedAddress: result.Properties.formattedAddress(),
+		Provider:         string(ProviderTypeVisicom),
 	}, nil
 }
+
+// visicomCoordinates returns the latitude and longitude of a [lon, lat] centroid. It returns
+// ErrVisicomInvalidCoords if the centroid doesn't hold exactly two finite numbers, e.g. when its coordinates
+// are null, or if they are out of the latitude and longitude ranges.
+func visicomCoordinates(coords []json.RawMessage) (float64, float64, error) {
+	const coordsListLength = 2
+
+	if len(coords) != coordsListLength {
+		return 0, 0, fmt.Errorf("%w: got %d values instead of [lon, lat]", ErrVisicomInvalidCoords, len(coords))
+	}
+
+	var lonLat [coordsListLength]float64
+	for i, raw := range coords {
+		value, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return 0, 0, fmt.Errorf("%w: %s is not a number", ErrVisicomInvalidCoords, raw)
+		}
+		lonLat[i] = value
+	}
+
+	lon, lat := lonLat[0], lonLat[1]
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("%w: latitude %v, longitude %v out of range", ErrVisicomInvalidCoords, lat, lon)
+	}
+
+	return lat, lon, nil
+}
+
+// HealthCheck verifies that the Visicom API is reachable and the API key is valid
+// by geocoding a well-known address.
+func (vp *VisicomProvider) HealthCheck(ctx context.Context) error {
+	if _, err := vp.Geocode(ctx, healthCheckAddress); err != nil {
+		return fmt.Errorf("visicom health check failed: %w", err)
+	}
+
+	return nil
+}
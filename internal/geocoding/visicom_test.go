@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/time/rate"
@@ -33,7 +34,8 @@ func TestVisicomProvider_Geocoe(t *testing.T) {
 				assert.Equal(t, "application/json", req.Header.Get("Accept"))
 
 				// Return ,ock response
-				responseBody := `{"geo_centroid":{"coordinates":[-122.0842499,37.4224764]}}`
+				responseBody := `{"geo_centroid":{"coordinates":[-122.0842499,37.4224764]},` +
+					`"properties":{"categories":"adr_address"}}`
 				return &http.Response{
 					StatusCode: http.StatusOK,
 					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
@@ -48,6 +50,7 @@ func TestVisicomProvider_Geocoe(t *testing.T) {
 		require.NotNil(t, coords)
 		assert.InEpsilon(t, 37.4224764, coords.Latitude, 0.0001)
 		assert.InEpsilon(t, -122.0842499, coords.Longitude, 0.0001)
+		assert.Equal(t, models.PrecisionRooftop, coords.Precision)
 	})
 
 	t.Run("empty response", func(t *testing.T) {
@@ -86,6 +89,58 @@ func TestVisicomProvider_Geocoe(t *testing.T) {
 		assert.ErrorIs(t, err, geocoding.ErrVisicomInvalidCoords)
 	})
 
+	t.Run("malformed coordinates", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body string
+		}{
+			{name: "null coordinates", body: `{"geo_centroid":{"coordinates":null}}`},
+			{name: "missing coordinates", body: `{"geo_centroid":{}}`},
+			{name: "NaN string", body: `{"geo_centroid":{"coordinates":["NaN",50.45]}}`},
+			{name: "null value", body: `{"geo_centroid":{"coordinates":[30.52,null]}}`},
+			{name: "overflowing value", body: `{"geo_centroid":{"coordinates":[1e999,50.45]}}`},
+			{name: "latitude out of range", body: `{"geo_centroid":{"coordinates":[30.52,95]}}`},
+			{name: "longitude out of range", body: `{"geo_centroid":{"coordinates":[-190,50.45]}}`},
+			{name: "too many values", body: `{"geo_centroid":{"coordinates":[30.52,50.45,0]}}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockClient := &mockHTTPClient{
+					doFunc: func(_ *http.Request) (*http.Response, error) {
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+						}, nil
+					},
+				}
+
+				provider := geocoding.NewVisicomProviderWithClient(mockClient, apiKey, defaultRL, logger)
+				coords, err := provider.Geocode(ctx, "bad coords")
+
+				assert.Nil(t, coords)
+				assert.ErrorIs(t, err, geocoding.ErrVisicomInvalidCoords)
+			})
+		}
+	})
+
+	t.Run("null centroid", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"geo_centroid":null}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewVisicomProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "some address")
+
+		assert.Nil(t, coords)
+		assert.ErrorIs(t, err, geocoding.ErrVisicomEmptyResponse)
+	})
+
 	t.Run("Unathorized", func(t *testing.T) {
 		mockClient := &mockHTTPClient{
 			doFunc: func(_ *http.Request) (*http.Response, error) {
@@ -124,6 +179,24 @@ func TestVisicomProvider_Geocoe(t *testing.T) {
 		assert.ErrorContains(t, err, "rate limit exceeded")
 	})
 
+	t.Run("request timeout", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			},
+		}
+
+		provider := geocoding.NewVisicomProviderWithClient(
+			mockClient, apiKey, defaultRL, logger, geocoding.WithVisicomRequestTimeout(50*time.Millisecond),
+		)
+		coords, err := provider.Geocode(ctx, "some address")
+
+		require.Error(t, err)
+		assert.Nil(t, coords)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
 	t.Run("empty address", func(t *testing.T) {
 		mockClient := &mockHTTPClient{
 			doFunc: func(_ *http.Request) (*http.Response, error) {
@@ -141,4 +214,127 @@ func TestVisicomProvider_Geocoe(t *testing.T) {
 		assert.Nil(t, coords)
 		assert.ErrorIs(t, err, geocoding.ErrVisicomEmptyAddress)
 	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(bytes.NewBufferString(`Too Many Requests`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewVisicomProviderWithClient(mockClient, apiKey, defaultRL, logger)
+		coords, err := provider.Geocode(ctx, "Kyiv")
+
+		require.ErrorIs(t, err, geocoding.ErrRateLimited)
+		require.Nil(t, coords)
+	})
+}
+
+func TestVisicomProvider_HealthCheck(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	defaultRL := rate.NewLimiter(rate.Inf, 0)
+
+	t.Run("healthy", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"geo_centroid":{"coordinates":[30.52,50.45]}}`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewVisicomProviderWithClient(mockClient, "test-api-key", defaultRL, logger)
+
+		require.NoError(t, provider.HealthCheck(ctx))
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusUnauthorized,
+					Body:       io.NopCloser(bytes.NewBufferString(`unathorized`)),
+				}, nil
+			},
+		}
+
+		provider := geocoding.NewVisicomProviderWithClient(mockClient, "bad-key", defaultRL, logger)
+
+		require.ErrorIs(t, provider.HealthCheck(ctx), geocoding.ErrVisicomUnathorized)
+	})
+}
+
+func TestVisicomProvider_GeocodeDetailed(t *testing.T) {
+	mockClient := &mockHTTPClient{
+		doFunc: func(_ *http.Request) (*http.Response, error) {
+			responseBody := `{"id":"ADR3K8NRI8","geo_centroid":{"coordinates":[30.5234,50.4501]},` +
+				`"properties":{"categories":"adr_address","name":"1","street_type":"вул.","street":"Хрещатик",` +
+				`"settlement":"Київ"}}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+			}, nil
+		},
+	}
+
+	provider := geocoding.NewVisicomProviderWithClient(
+		mockClient, "test-api-key", rate.NewLimiter(rate.Inf, 0), slog.Default(),
+	)
+	result, err := provider.GeocodeDetailed(t.Context(), "Київ, Хрещатик, 1")
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.GeocodeResult{
+		Coordinates:      models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop},
+		PlaceID:          "ADR3K8NRI8",
+		FormattedAddress: "вул. Хрещатик, 1, Київ",
+		Provider:         "visicom",
+	}, result)
+}
+
+func TestVisicomProvider_FormattedAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties string
+		expected   string
+	}{
+		{
+			name:       "street",
+			properties: `{"categories":"adr_street","name":"вулиця Хрещатик","settlement":"Київ"}`,
+			expected:   "вулиця Хрещатик, Київ",
+		},
+		{
+			name:       "settlement",
+			properties: `{"categories":"adm_settlement","name":"Київ","settlement":"Київ"}`,
+			expected:   "Київ",
+		},
+		{name: "no name", properties: `{"categories":"adr_address"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(_ *http.Request) (*http.Response, error) {
+					responseBody := `{"id":"ID","geo_centroid":{"coordinates":[30.5234,50.4501]},` +
+						`"properties":` + tt.properties + `}`
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+					}, nil
+				},
+			}
+
+			provider := geocoding.NewVisicomProviderWithClient(
+				mockClient, "test-api-key", rate.NewLimiter(rate.Inf, 0), slog.Default(),
+			)
+			result, err := provider.GeocodeDetailed(t.Context(), "Київ")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.FormattedAddress)
+		})
+	}
 }
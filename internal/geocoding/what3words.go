@@ -0,0 +1,127 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// What3WordsBaseURL -- what3words convert-to-3wa endpoint.
+const What3WordsBaseURL = "https://api.what3words.com/v3/convert-to-3wa"
+
+// ErrWhat3WordsUnauthorized is returned when what3words rejects the API key.
+var ErrWhat3WordsUnauthorized = errors.New("what3words API unauthorized (invalid API key)")
+
+// ErrWhat3WordsEmptyResponse is returned when what3words responds without a 3 word address.
+var ErrWhat3WordsEmptyResponse = errors.New("what3words API returned no words")
+
+// What3WordsClient converts coordinates into what3words addresses, e.g. "filled.count.soap",
+// for field apps that locate places by their 3 word address. It isn't a geocoding provider:
+// it only runs on coordinates that were already geocoded.
+type What3WordsClient struct {
+	client  HTTPClient    // HTTP client for making requests
+	baseURL string        // Base URL for the convert-to-3wa endpoint
+	apiKey  string        // API key
+	log     *slog.Logger  // Logger for logging operations
+	timeout time.Duration // Overall deadline for a single conversion
+}
+
+// what3wordsResponse is the part of a convert-to-3wa response that is used,
+// the words on success and the error otherwise.
+type what3wordsResponse struct {
+	Words string `json:"words"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewWhat3WordsClient creates a new what3words client using the shared HTTP transport.
+func NewWhat3WordsClient(apiKey string, log *slog.Logger) *What3WordsClient {
+	return NewWhat3WordsClientWithClient(newHTTPClient(nil, DefaultHTTPTimeout), apiKey, log)
+}
+
+// NewWhat3WordsClientWithClient allows injecting custom HTTP client.
+func NewWhat3WordsClientWithClient(client HTTPClient, apiKey string, log *slog.Logger) *What3WordsClient {
+	return &What3WordsClient{
+		client:  client,
+		baseURL: What3WordsBaseURL,
+		apiKey:  apiKey,
+		log:     log,
+		timeout: DefaultRequestTimeout,
+	}
+}
+
+// ConvertTo3wa returns the 3 word address of the square containing the coordinates.
+func (wc *What3WordsClient) ConvertTo3wa(ctx context.Context, coords models.Coordinates) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, wc.timeout)
+	defer cancel()
+
+	reqURL, err := url.Parse(wc.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	query := reqURL.Query()
+	query.Set("key", wc.apiKey)
+	query.Set("coordinates", strconv.FormatFloat(coords.Latitude, 'f', -1, 64)+","+
+		strconv.FormatFloat(coords.Longitude, 'f', -1, 64))
+	query.Set("format", "json")
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := wc.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute what3words request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// continue
+	case http.StatusUnauthorized:
+		return "", ErrWhat3WordsUnauthorized
+	case http.StatusTooManyRequests:
+		return "", &RateLimitError{
+			Provider:   "what3words",
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	default:
+		return "", &StatusError{Provider: "what3words", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result what3wordsResponse
+	if err = json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode what3words response: %w", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("what3words API error %s: %s", result.Error.Code, result.Error.Message)
+	}
+	if result.Words == "" {
+		return "", ErrWhat3WordsEmptyResponse
+	}
+
+	wc.log.DebugContext(ctx, "what3words found words", "lat", coords.Latitude, "lon", coords.Longitude,
+		"words", result.Words)
+
+	return result.Words, nil
+}
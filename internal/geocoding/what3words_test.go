@@ -0,0 +1,133 @@
+package geocoding_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhat3WordsClient_ConvertTo3wa(t *testing.T) {
+	ctx := t.Context()
+	logger := slog.Default()
+	apiKey := "test-api-key"
+	coords := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+
+	t.Run("successfull conversion", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Contains(t, req.URL.String(), geocoding.What3WordsBaseURL)
+				assert.Equal(t, apiKey, req.URL.Query().Get("key"))
+				assert.Equal(t, "50.4501,30.5234", req.URL.Query().Get("coordinates"))
+
+				responseBody := `{"words":"filled.count.soap","language":"en","nearestPlace":"Kyiv"}`
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+				}, nil
+			},
+		}
+
+		client := geocoding.NewWhat3WordsClientWithClient(mockClient, apiKey, logger)
+		words, err := client.ConvertTo3wa(ctx, coords)
+
+		require.NoError(t, err)
+		assert.Equal(t, "filled.count.soap", words)
+	})
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    error
+		errContain string
+	}{
+		{
+			name:       "invalid API key",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"error":{"code":"InvalidKey","message":"Authentication failed; invalid API key"}}`,
+			wantErr:    geocoding.ErrWhat3WordsUnauthorized,
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			wantErr:    geocoding.ErrRateLimited,
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusBadGateway,
+			body:       "bad gateway",
+			wantErr:    geocoding.ErrServerError,
+		},
+		{
+			name:       "quota exceeded",
+			statusCode: http.StatusPaymentRequired,
+			body:       `{"error":{"code":"QuotaExceeded","message":"Quota Exceeded"}}`,
+			errContain: "what3words API returned status 402",
+		},
+		{
+			name:       "error in the response",
+			statusCode: http.StatusOK,
+			body:       `{"error":{"code":"BadCoordinates","message":"latitude must be >=-90 and <= 90"}}`,
+			errContain: "what3words API error BadCoordinates",
+		},
+		{
+			name:       "no words",
+			statusCode: http.StatusOK,
+			body:       `{}`,
+			wantErr:    geocoding.ErrWhat3WordsEmptyResponse,
+		},
+		{
+			name:       "invalid JSON",
+			statusCode: http.StatusOK,
+			body:       `{"words":`,
+			errContain: "failed to decode what3words response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockHTTPClient{
+				doFunc: func(*http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Header:     http.Header{},
+						Body:       io.NopCloser(bytes.NewBufferString(tt.body)),
+					}, nil
+				},
+			}
+
+			client := geocoding.NewWhat3WordsClientWithClient(mockClient, apiKey, logger)
+			words, err := client.ConvertTo3wa(ctx, coords)
+
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.errContain != "" {
+				require.ErrorContains(t, err, tt.errContain)
+			}
+			assert.Empty(t, words)
+		})
+	}
+
+	t.Run("request error", func(t *testing.T) {
+		mockClient := &mockHTTPClient{
+			doFunc: func(*http.Request) (*http.Response, error) {
+				return nil, assert.AnError
+			},
+		}
+
+		client := geocoding.NewWhat3WordsClientWithClient(mockClient, apiKey, logger)
+		_, err := client.ConvertTo3wa(ctx, coords)
+
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
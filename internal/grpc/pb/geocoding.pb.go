@@ -0,0 +1,301 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: geocoding.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// GeocodeRequest holds the address to geocode.
+type GeocodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"` // Address to geocode.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GeocodeRequest) Reset() {
+	*x = GeocodeRequest{}
+	mi := &file_geocoding_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeocodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeocodeRequest) ProtoMessage() {}
+
+func (x *GeocodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geocoding_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeocodeRequest.ProtoReflect.Descriptor instead.
+func (*GeocodeRequest) Descriptor() ([]byte, []int) {
+	return file_geocoding_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GeocodeRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+// GeocodeReply holds the coordinates found for the requested address.
+type GeocodeReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`                               // Latitude of the geographical point.
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`                             // Longitude of the geographical point.
+	FallbackLevel int32                  `protobuf:"varint,3,opt,name=fallback_level,json=fallbackLevel,proto3" json:"fallback_level,omitempty"` // Address fallback level that matched (0 is the full address).
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GeocodeReply) Reset() {
+	*x = GeocodeReply{}
+	mi := &file_geocoding_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeocodeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeocodeReply) ProtoMessage() {}
+
+func (x *GeocodeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_geocoding_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeocodeReply.ProtoReflect.Descriptor instead.
+func (*GeocodeReply) Descriptor() ([]byte, []int) {
+	return file_geocoding_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GeocodeReply) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GeocodeReply) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GeocodeReply) GetFallbackLevel() int32 {
+	if x != nil {
+		return x.FallbackLevel
+	}
+	return 0
+}
+
+// ReverseGeocodeRequest holds the coordinates to reverse geocode.
+type ReverseGeocodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`   // Latitude of the geographical point.
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"` // Longitude of the geographical point.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseGeocodeRequest) Reset() {
+	*x = ReverseGeocodeRequest{}
+	mi := &file_geocoding_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseGeocodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseGeocodeRequest) ProtoMessage() {}
+
+func (x *ReverseGeocodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geocoding_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseGeocodeRequest.ProtoReflect.Descriptor instead.
+func (*ReverseGeocodeRequest) Descriptor() ([]byte, []int) {
+	return file_geocoding_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ReverseGeocodeRequest) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *ReverseGeocodeRequest) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+// ReverseGeocodeReply holds the address found for the requested coordinates.
+type ReverseGeocodeReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"` // Formatted address of the geographical point.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseGeocodeReply) Reset() {
+	*x = ReverseGeocodeReply{}
+	mi := &file_geocoding_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseGeocodeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseGeocodeReply) ProtoMessage() {}
+
+func (x *ReverseGeocodeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_geocoding_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseGeocodeReply.ProtoReflect.Descriptor instead.
+func (*ReverseGeocodeReply) Descriptor() ([]byte, []int) {
+	return file_geocoding_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReverseGeocodeReply) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+var File_geocoding_proto protoreflect.FileDescriptor
+
+const file_geocoding_proto_rawDesc = "" +
+	"\n" +
+	"\x0fgeocoding.proto\x12\batlas.v1\"*\n" +
+	"\x0eGeocodeRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\"o\n" +
+	"\fGeocodeReply\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12%\n" +
+	"\x0efallback_level\x18\x03 \x01(\x05R\rfallbackLevel\"Q\n" +
+	"\x15ReverseGeocodeRequest\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\"/\n" +
+	"\x13ReverseGeocodeReply\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress2\xa1\x01\n" +
+	"\x10GeocodingService\x12;\n" +
+	"\aGeocode\x12\x18.atlas.v1.GeocodeRequest\x1a\x16.atlas.v1.GeocodeReply\x12P\n" +
+	"\x0eReverseGeocode\x12\x1f.atlas.v1.ReverseGeocodeRequest\x1a\x1d.atlas.v1.ReverseGeocodeReplyB5Z3github.com/UnknownOlympus/atlas/internal/grpc/pb;pbb\x06proto3"
+
+var (
+	file_geocoding_proto_rawDescOnce sync.Once
+	file_geocoding_proto_rawDescData []byte
+)
+
+func file_geocoding_proto_rawDescGZIP() []byte {
+	file_geocoding_proto_rawDescOnce.Do(func() {
+		file_geocoding_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_geocoding_proto_rawDesc), len(file_geocoding_proto_rawDesc)))
+	})
+	return file_geocoding_proto_rawDescData
+}
+
+var file_geocoding_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_geocoding_proto_goTypes = []any{
+	(*GeocodeRequest)(nil),        // 0: atlas.v1.GeocodeRequest
+	(*GeocodeReply)(nil),          // 1: atlas.v1.GeocodeReply
+	(*ReverseGeocodeRequest)(nil), // 2: atlas.v1.ReverseGeocodeRequest
+	(*ReverseGeocodeReply)(nil),   // 3: atlas.v1.ReverseGeocodeReply
+}
+var file_geocoding_proto_depIdxs = []int32{
+	0, // 0: atlas.v1.GeocodingService.Geocode:input_type -> atlas.v1.GeocodeRequest
+	2, // 1: atlas.v1.GeocodingService.ReverseGeocode:input_type -> atlas.v1.ReverseGeocodeRequest
+	1, // 2: atlas.v1.GeocodingService.Geocode:output_type -> atlas.v1.GeocodeReply
+	3, // 3: atlas.v1.GeocodingService.ReverseGeocode:output_type -> atlas.v1.ReverseGeocodeReply
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_geocoding_proto_init() }
+func file_geocoding_proto_init() {
+	if File_geocoding_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_geocoding_proto_rawDesc), len(file_geocoding_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_geocoding_proto_goTypes,
+		DependencyIndexes: file_geocoding_proto_depIdxs,
+		MessageInfos:      file_geocoding_proto_msgTypes,
+	}.Build()
+	File_geocoding_proto = out.File
+	file_geocoding_proto_goTypes = nil
+	file_geocoding_proto_depIdxs = nil
+}
@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: geocoding.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GeocodingService_Geocode_FullMethodName        = "/atlas.v1.GeocodingService/Geocode"
+	GeocodingService_ReverseGeocode_FullMethodName = "/atlas.v1.GeocodingService/ReverseGeocode"
+)
+
+// GeocodingServiceClient is the client API for GeocodingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GeocodingService exposes synchronous geocoding using the configured provider.
+type GeocodingServiceClient interface {
+	// Geocode converts an address into geographic coordinates.
+	Geocode(ctx context.Context, in *GeocodeRequest, opts ...grpc.CallOption) (*GeocodeReply, error)
+	// ReverseGeocode converts geographic coordinates into an address.
+	ReverseGeocode(ctx context.Context, in *ReverseGeocodeRequest, opts ...grpc.CallOption) (*ReverseGeocodeReply, error)
+}
+
+type geocodingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGeocodingServiceClient(cc grpc.ClientConnInterface) GeocodingServiceClient {
+	return &geocodingServiceClient{cc}
+}
+
+func (c *geocodingServiceClient) Geocode(ctx context.Context, in *GeocodeRequest, opts ...grpc.CallOption) (*GeocodeReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GeocodeReply)
+	err := c.cc.Invoke(ctx, GeocodingService_Geocode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geocodingServiceClient) ReverseGeocode(ctx context.Context, in *ReverseGeocodeRequest, opts ...grpc.CallOption) (*ReverseGeocodeReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReverseGeocodeReply)
+	err := c.cc.Invoke(ctx, GeocodingService_ReverseGeocode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GeocodingServiceServer is the server API for GeocodingService service.
+// All implementations must embed UnimplementedGeocodingServiceServer
+// for forward compatibility.
+//
+// GeocodingService exposes synchronous geocoding using the configured provider.
+type GeocodingServiceServer interface {
+	// Geocode converts an address into geographic coordinates.
+	Geocode(context.Context, *GeocodeRequest) (*GeocodeReply, error)
+	// ReverseGeocode converts geographic coordinates into an address.
+	ReverseGeocode(context.Context, *ReverseGeocodeRequest) (*ReverseGeocodeReply, error)
+	mustEmbedUnimplementedGeocodingServiceServer()
+}
+
+// UnimplementedGeocodingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGeocodingServiceServer struct{}
+
+func (UnimplementedGeocodingServiceServer) Geocode(context.Context, *GeocodeRequest) (*GeocodeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Geocode not implemented")
+}
+func (UnimplementedGeocodingServiceServer) ReverseGeocode(context.Context, *ReverseGeocodeRequest) (*ReverseGeocodeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReverseGeocode not implemented")
+}
+func (UnimplementedGeocodingServiceServer) mustEmbedUnimplementedGeocodingServiceServer() {}
+func (UnimplementedGeocodingServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeGeocodingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GeocodingServiceServer will
+// result in compilation errors.
+type UnsafeGeocodingServiceServer interface {
+	mustEmbedUnimplementedGeocodingServiceServer()
+}
+
+func RegisterGeocodingServiceServer(s grpc.ServiceRegistrar, srv GeocodingServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGeocodingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GeocodingService_ServiceDesc, srv)
+}
+
+func _GeocodingService_Geocode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GeocodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeocodingServiceServer).Geocode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GeocodingService_Geocode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeocodingServiceServer).Geocode(ctx, req.(*GeocodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GeocodingService_ReverseGeocode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReverseGeocodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeocodingServiceServer).ReverseGeocode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GeocodingService_ReverseGeocode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeocodingServiceServer).ReverseGeocode(ctx, req.(*ReverseGeocodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GeocodingService_ServiceDesc is the grpc.ServiceDesc for GeocodingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GeocodingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "atlas.v1.GeocodingService",
+	HandlerType: (*GeocodingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Geocode",
+			Handler:    _GeocodingService_Geocode_Handler,
+		},
+		{
+			MethodName: "ReverseGeocode",
+			Handler:    _GeocodingService_ReverseGeocode_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "geocoding.proto",
+}
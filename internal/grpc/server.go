@@ -0,0 +1,130 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/grpc/pb"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the GeocodingService gRPC API on top of a geocoding provider.
+// It allows other microservices to geocode addresses synchronously,
+// without going through the database polling loop.
+type Server struct {
+	pb.UnimplementedGeocodingServiceServer
+
+	mu       sync.RWMutex       // mu guards provider, which can be replaced while serving
+	provider geocoding.Provider // provider performs the actual geocoding
+	log      *slog.Logger       // log is the logger for logging operations
+}
+
+// NewServer creates a new gRPC geocoding server backed by the given provider.
+func NewServer(provider geocoding.Provider, log *slog.Logger) *Server {
+	return &Server{provider: provider, log: log}
+}
+
+// SetProvider replaces the provider that serves requests, e.g. after an API key rotation.
+// Requests in flight finish with the previous provider.
+func (s *Server) SetProvider(provider geocoding.Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider = provider
+}
+
+// currentProvider returns the provider that serves new requests.
+func (s *Server) currentProvider() geocoding.Provider {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.provider
+}
+
+// Geocode converts the requested address into geographic coordinates.
+func (s *Server) Geocode(ctx context.Context, req *pb.GeocodeRequest) (*pb.GeocodeReply, error) {
+	if req.GetAddress() == "" {
+		return nil, status.Error(codes.InvalidArgument, "address is required")
+	}
+
+	coords, err := s.currentProvider().Geocode(ctx, req.GetAddress())
+	if err != nil {
+		s.log.WarnContext(ctx, "gRPC geocode request failed", "address", req.GetAddress(), "error", err)
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GeocodeReply{
+		Latitude:      coords.Latitude,
+		Longitude:     coords.Longitude,
+		FallbackLevel: int32(coords.FallbackLevel), //nolint:gosec // fallback level is a small index
+	}, nil
+}
+
+// ReverseGeocode converts the requested coordinates into an address.
+// It returns codes.Unimplemented if the configured provider does not support reverse geocoding.
+func (s *Server) ReverseGeocode(
+	ctx context.Context,
+	req *pb.ReverseGeocodeRequest,
+) (*pb.ReverseGeocodeReply, error) {
+	coords := models.Coordinates{Latitude: req.GetLatitude(), Longitude: req.GetLongitude()}
+	address, err := geocoding.ReverseGeocode(ctx, s.currentProvider(), coords)
+	if errors.Is(err, geocoding.ErrReverseGeocodingUnsupported) {
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
+	if err != nil {
+		s.log.WarnContext(ctx, "gRPC reverse geocode request failed",
+			"lat", coords.Latitude, "lon", coords.Longitude, "error", err)
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ReverseGeocodeReply{Address: address}, nil
+}
+
+// Serve registers the server on a new gRPC server and serves requests on the listener.
+// It stops the gRPC server gracefully when the context is canceled.
+func Serve(ctx context.Context, log *slog.Logger, srv *Server, lis net.Listener) error {
+	grpcServer := grpc.NewServer()
+	pb.RegisterGeocodingServiceServer(grpcServer, srv)
+
+	go func() {
+		<-ctx.Done()
+		log.InfoContext(ctx, "Stopping gRPC server...")
+		grpcServer.GracefulStop()
+	}()
+
+	log.InfoContext(ctx, "Starting gRPC server", "addr", lis.Addr().String())
+	if err := grpcServer.Serve(lis); err != nil {
+		return fmt.Errorf("gRPC server failed: %w", err)
+	}
+
+	return nil
+}
+
+// toStatusError maps provider errors to gRPC status errors.
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, geocoding.ErrEmptyResponse),
+		errors.Is(err, geocoding.ErrNominatimEmptyResponse),
+		errors.Is(err, geocoding.ErrVisicomEmptyResponse),
+		errors.Is(err, geocoding.ErrHereEmptyResponse),
+		errors.Is(err, geocoding.ErrBingEmptyResponse),
+		errors.Is(err, geocoding.ErrRegionCentroidNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, geocoding.ErrVisicomEmptyAddress),
+		errors.Is(err, geocoding.ErrHereEmptyAddress),
+		errors.Is(err, geocoding.ErrBingEmptyAddress):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
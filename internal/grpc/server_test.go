@@ -0,0 +1,165 @@
+package grpc_test
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	grpcapi "github.com/UnknownOlympus/atlas/internal/grpc"
+	"github.com/UnknownOlympus/atlas/internal/grpc/pb"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// reverseProvider is a provider mock that also supports reverse geocoding.
+type reverseProvider struct {
+	*mocks.Provider
+
+	address string
+	err     error
+}
+
+func (rp *reverseProvider) ReverseGeocode(_ context.Context, _ models.Coordinates) (string, error) {
+	return rp.address, rp.err
+}
+
+// startServer runs a gRPC server backed by provider on an in-memory listener and returns a connected client.
+func startServer(t *testing.T, provider geocoding.Provider) pb.GeocodingServiceClient {
+	t.Helper()
+
+	return serve(t, grpcapi.NewServer(provider, slog.Default()))
+}
+
+// serve runs srv on an in-memory listener and returns a connected client.
+func serve(t *testing.T, srv *grpcapi.Server) pb.GeocodingServiceClient {
+	t.Helper()
+
+	const bufSize = 1024 * 1024
+	lis := bufconn.Listen(bufSize)
+	ctx, cancel := context.WithCancel(t.Context())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- grpcapi.Serve(ctx, slog.Default(), srv, lis)
+	}()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return pb.NewGeocodingServiceClient(conn)
+}
+
+func TestServer_Geocode(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("successful geocoding", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("Geocode", mock.Anything, "Kyiv").
+			Return(&models.Coordinates{Latitude: 50.45, Longitude: 30.52, FallbackLevel: 1}, nil).Once()
+		client := startServer(t, mockProvider)
+
+		reply, err := client.Geocode(ctx, &pb.GeocodeRequest{Address: "Kyiv"})
+
+		require.NoError(t, err)
+		assert.InEpsilon(t, 50.45, reply.GetLatitude(), 0.0001)
+		assert.InEpsilon(t, 30.52, reply.GetLongitude(), 0.0001)
+		assert.Equal(t, int32(1), reply.GetFallbackLevel())
+	})
+
+	t.Run("empty address", func(t *testing.T) {
+		client := startServer(t, mocks.NewProvider(t))
+
+		_, err := client.Geocode(ctx, &pb.GeocodeRequest{})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("address not found", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("Geocode", mock.Anything, "Nowhere").Return(nil, geocoding.ErrNominatimEmptyResponse).Once()
+		client := startServer(t, mockProvider)
+
+		_, err := client.Geocode(ctx, &pb.GeocodeRequest{Address: "Nowhere"})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		mockProvider := mocks.NewProvider(t)
+		mockProvider.On("Geocode", mock.Anything, "Kyiv").Return(nil, assert.AnError).Once()
+		client := startServer(t, mockProvider)
+
+		_, err := client.Geocode(ctx, &pb.GeocodeRequest{Address: "Kyiv"})
+
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestServer_SetProvider(t *testing.T) {
+	ctx := t.Context()
+	oldProvider := mocks.NewProvider(t)
+	newProvider := mocks.NewProvider(t)
+	newProvider.On("Geocode", mock.Anything, "Kyiv").
+		Return(&models.Coordinates{Latitude: 50.45, Longitude: 30.52}, nil).Once()
+	srv := grpcapi.NewServer(oldProvider, slog.Default())
+	client := serve(t, srv)
+
+	srv.SetProvider(newProvider)
+	reply, err := client.Geocode(ctx, &pb.GeocodeRequest{Address: "Kyiv"})
+
+	require.NoError(t, err)
+	assert.InEpsilon(t, 50.45, reply.GetLatitude(), 0.0001)
+	oldProvider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+}
+
+func TestServer_ReverseGeocode(t *testing.T) {
+	ctx := t.Context()
+	req := &pb.ReverseGeocodeRequest{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("successful reverse geocoding", func(t *testing.T) {
+		client := startServer(t, &reverseProvider{Provider: mocks.NewProvider(t), address: "Kyiv, Ukraine"})
+
+		reply, err := client.ReverseGeocode(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Kyiv, Ukraine", reply.GetAddress())
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		client := startServer(t, &reverseProvider{Provider: mocks.NewProvider(t), err: geocoding.ErrEmptyResponse})
+
+		_, err := client.ReverseGeocode(ctx, req)
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("provider does not support reverse geocoding", func(t *testing.T) {
+		client := startServer(t, mocks.NewProvider(t))
+
+		_, err := client.ReverseGeocode(ctx, req)
+
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
@@ -6,42 +6,193 @@ import (
 )
 
 // Metrics holds the metrics for monitoring the geocoding service.
-// It includes counters for tasks processed and API errors,
-// a histogram for request durations, and a gauge for active workers.
+// It includes counters for tasks processed, stored results by precision, skipped duplicate tasks, tasks deferred
+// by the request budget, tasks skipped for an invalid address, polls skipped by an overrunning batch, recovered
+// worker panics, API errors, rate-limit responses, results outside the service area, provider request retries,
+// cache lookups and negative cache hits, histograms for request, database operation and end-to-end task durations
+// and Nominatim fallback searches, gauges for active workers, pending tasks and unhealthy providers, and the build
+// information of the running binary.
 type Metrics struct {
-	TaskProcessed  *prometheus.CounterVec   // Counter for the number of tasks processed
-	APIErrors      prometheus.Counter       // Counter for the number of API errors
-	RequestSeconds *prometheus.HistogramVec // Histogram for tracking request durations
-	ActiveWorkers  prometheus.Gauge         // Gauge for the number of active workers
+	TaskProcessed       *prometheus.CounterVec   // Counter for the number of tasks processed, by status and provider
+	ResultPrecision     *prometheus.CounterVec   // Counter for the number of stored results, by precision and provider
+	APIErrors           *prometheus.CounterVec   // Counter for the number of API errors, by error class
+	RateLimited         *prometheus.CounterVec   // Counter for the number of provider rate-limit responses
+	OutsideServiceArea  *prometheus.CounterVec   // Counter for the number of results rejected outside the service area
+	ProviderRetries     *prometheus.CounterVec   // Counter for the number of provider request retries, by reason
+	RequestSeconds      *prometheus.HistogramVec // Histogram for tracking request durations
+	TaskDurationSeconds *prometheus.HistogramVec // Histogram for tracking end-to-end task durations
+	DBOperationSeconds  *prometheus.HistogramVec // Histogram for database operation durations, by operation and outcome
+	NominatimFallbacks  prometheus.Histogram     // Histogram for the number of searches per Nominatim Geocode call
+	ActiveWorkers       prometheus.Gauge         // Gauge for the number of active workers
+	PendingTasks        prometheus.Gauge         // Gauge for the number of tasks waiting to be geocoded
+	ConcurrencyLimit    prometheus.Gauge         // Gauge for the adaptive cap on provider calls in flight at once
+	ProviderUnhealthy   *prometheus.GaugeVec     // Gauge set to 1 while a provider rejects the API key, by provider
+	CacheLookups        *prometheus.CounterVec   // Counter for the number of geocoding cache lookups, by result
+	NegativeCacheHits   prometheus.Counter       // Counter for the number of addresses found cached as not found
+	DuplicateTasks      prometheus.Counter       // Counter for the number of tasks skipped as already in flight
+	BudgetExhausted     prometheus.Counter       // Counter for the number of tasks deferred by the request budget
+	InvalidAddresses    prometheus.Counter       // Counter for the number of tasks skipped for a blank address
+	PollsSkipped        prometheus.Counter       // Counter for the number of polls skipped while a batch was running
+	PollTimeouts        prometheus.Counter       // Counter for the number of polls cut short by the poll timeout
+	WorkerPanics        prometheus.Counter       // Counter for the number of task groups whose processing panicked
+	What3WordsErrors    prometheus.Counter       // Counter for the number of failed what3words conversions
+	WebhookEvents       *prometheus.CounterVec   // Counter for the number of webhook notifications, by outcome
+	BuildInfo           *prometheus.GaugeVec     // Gauge set to 1, labeled with the version of the running build
+}
+
+// nominatimFallbackBuckets covers every length of the Nominatim fallback sequence, from the full address alone
+// to a structured search, four address variations and a postal code search.
+var nominatimFallbackBuckets = prometheus.LinearBuckets(1, 1, 6)
+
+// taskDurationBuckets covers the sub-second to minutes range of end-to-end task processing.
+var taskDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Option configures the metrics created by NewMetrics.
+type Option func(*options)
+
+// options holds the settings applied by the Option functions.
+type options struct {
+	requestBuckets []float64
+}
+
+// WithRequestBuckets sets the buckets of the provider request duration histogram, in seconds,
+// so they can be sized to the latency of the provider. Empty buckets keep prometheus.DefBuckets.
+func WithRequestBuckets(buckets []float64) Option {
+	return func(o *options) {
+		if len(buckets) > 0 {
+			o.requestBuckets = buckets
+		}
+	}
 }
 
 // NewMetrics creates a new Metrics instance with the provided Prometheus Registerer.
-// It initializes counters, histograms, and gauges for tracking geocoding tasks,
-// API errors, request durations, and active workers.
+// It initializes counters, histograms, and gauges for tracking geocoding tasks, result precision,
+// skipped duplicate tasks, tasks deferred by the request budget, tasks skipped for an invalid address,
+// polls skipped by an overrunning batch, API errors, rate-limit responses, results outside the service area,
+// provider request retries, cache lookups, negative cache hits, request durations, task durations, Nominatim
+// fallback searches, active workers, pending tasks, unhealthy providers, webhook notifications and build information.
 //
 // Parameters:
 //   - reg: A Prometheus Registerer used to register the metrics.
+//   - opts: Options such as custom request duration buckets.
 //
 // Returns:
 //   - A pointer to the newly created Metrics instance.
-func NewMetrics(reg prometheus.Registerer) *Metrics {
+func NewMetrics(reg prometheus.Registerer, opts ...Option) *Metrics {
+	o := options{requestBuckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &Metrics{
 		TaskProcessed: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "atlas_tasks_processed_total",
-			Help: "Total number of processed geocoding tasks.",
-		}, []string{"status"}),
-		APIErrors: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Help: "Total number of processed geocoding tasks, by status and provider.",
+		}, []string{"status", "provider"}),
+		ResultPrecision: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "atlas_geocoding_result_precision_total",
+			Help: "Total number of geocoding results stored, by precision level and provider.",
+		}, []string{"precision", "provider"}),
+		APIErrors: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
 			Name: "atlas_provider_api_errors_total",
-			Help: "Total number of errors received from the geocoding provider API.",
-		}),
+			Help: "Total number of errors received from the geocoding provider API, by error class.",
+		}, []string{"class"}),
+		RateLimited: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "atlas_geocoding_rate_limited_total",
+			Help: "Total number of requests rejected by the geocoding provider rate limit (HTTP 429).",
+		}, []string{"provider"}),
+		OutsideServiceArea: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "atlas_outside_service_area_total",
+			Help: "Total number of geocoding results rejected because they lie outside the configured service area.",
+		}, []string{"provider"}),
+		ProviderRetries: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "atlas_geocoding_provider_retries_total",
+			Help: "Total number of retried requests to the geocoding provider API, by provider and reason (timeout, 429, 5xx).",
+		}, []string{"provider", "reason"}),
 		RequestSeconds: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "atlas_provider_request_duration_seconds",
 			Help:    "Duration of requests to the geocoding provider API.",
-			Buckets: prometheus.DefBuckets,
+			Buckets: o.requestBuckets,
 		}, []string{"provider"}),
+		TaskDurationSeconds: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "atlas_task_duration_seconds",
+			Help:    "End-to-end duration of task processing, from dequeue to the final database update.",
+			Buckets: taskDurationBuckets,
+		}, []string{"outcome"}),
+		DBOperationSeconds: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "atlas_db_operation_duration_seconds",
+			Help:    "Duration of database operations on the geocoding path, by operation and outcome.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation", "outcome"}),
+		NominatimFallbacks: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Name:    "atlas_nominatim_fallback_attempts",
+			Help:    "Number of address variations searched per Nominatim geocoding call, whether or not one was found.",
+			Buckets: nominatimFallbackBuckets,
+		}),
 		ActiveWorkers: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
 			Name: "atlas_active_workers",
 			Help: "Current number of active workers processing tasks.",
 		}),
+		PendingTasks: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "atlas_pending_tasks",
+			Help: "Number of tasks waiting to be geocoded, updated on each poll.",
+		}),
+		ConcurrencyLimit: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "atlas_concurrency_limit",
+			Help: "Current cap on provider calls in flight at once, adjusted by adaptive concurrency.",
+		}),
+		ProviderUnhealthy: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "atlas_provider_unhealthy",
+			Help: "Whether the geocoding provider rejected the API key and polls are aborted (1) or not (0), by provider.",
+		}, []string{"provider"}),
+		CacheLookups: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "atlas_geocode_cache_lookups_total",
+			Help: "Total number of geocoding cache lookups, by result (hit, miss or error).",
+		}, []string{"result"}),
+		NegativeCacheHits: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_geocode_cache_negative_hits_total",
+			Help: "Total number of geocoding cache lookups that found the address cached as not found.",
+		}),
+		DuplicateTasks: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_duplicate_tasks_skipped_total",
+			Help: "Total number of fetched tasks skipped because the same task was already being processed.",
+		}),
+		BudgetExhausted: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_request_budget_exhausted_total",
+			Help: "Total number of tasks left for the next poll because the upstream request budget of the poll ran out.",
+		}),
+		InvalidAddresses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_invalid_address_tasks_total",
+			Help: "Total number of tasks skipped without calling the provider because their address is blank.",
+		}),
+		PollsSkipped: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_polls_skipped_total",
+			Help: "Total number of polls skipped because the previous batch was still running when they became due.",
+		}),
+		PollTimeouts: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_poll_timeouts_total",
+			Help: "Total number of polls cut short by the poll timeout, their remaining tasks left for the next poll.",
+		}),
+		WorkerPanics: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_worker_panics_total",
+			Help: "Total number of task groups whose processing panicked, recovered by the worker.",
+		}),
+		What3WordsErrors: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "atlas_what3words_errors_total",
+			Help: "Total number of geocoded task groups whose coordinates could not be converted to a what3words address.",
+		}),
+		WebhookEvents: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "atlas_webhook_notifications_total",
+			Help: "Total number of webhook notifications of completed tasks, by outcome: delivered, failed or dropped.",
+		}, []string{"outcome"}),
+		BuildInfo: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "atlas_build_info",
+			Help: "Build information of the running binary, always 1.",
+		}, []string{"version", "commit", "go_version"}),
 	}
 }
+
+// SetBuildInfo publishes the build information of the running binary on the build info gauge.
+func (m *Metrics) SetBuildInfo(version, commit, goVersion string) {
+	m.BuildInfo.WithLabelValues(version, commit, goVersion).Set(1)
+}
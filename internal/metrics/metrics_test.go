@@ -1,10 +1,13 @@
 package metrics_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/UnknownOlympus/atlas/internal/metrics"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMetrics(_ *testing.T) {
@@ -12,3 +15,59 @@ func TestNewMetrics(_ *testing.T) {
 
 	_ = metrics.NewMetrics(reg)
 }
+
+func TestNewMetrics_RequestBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []metrics.Option
+		expected []float64
+	}{
+		{name: "default buckets", expected: prometheus.DefBuckets},
+		{
+			name:     "empty buckets keep the default",
+			opts:     []metrics.Option{metrics.WithRequestBuckets(nil)},
+			expected: prometheus.DefBuckets,
+		},
+		{
+			name:     "custom buckets",
+			opts:     []metrics.Option{metrics.WithRequestBuckets([]float64{0.01, 0.05, 0.1, 0.2})},
+			expected: []float64{0.01, 0.05, 0.1, 0.2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			appMetrics := metrics.NewMetrics(reg, tt.opts...)
+			appMetrics.RequestSeconds.WithLabelValues("nominatim").Observe(0.03)
+
+			families, err := reg.Gather()
+			require.NoError(t, err)
+
+			var bounds []float64
+			for _, family := range families {
+				if family.GetName() != "atlas_provider_request_duration_seconds" {
+					continue
+				}
+				for _, bucket := range family.GetMetric()[0].GetHistogram().GetBucket() {
+					bounds = append(bounds, bucket.GetUpperBound())
+				}
+			}
+			require.Equal(t, tt.expected, bounds)
+		})
+	}
+}
+
+func TestMetrics_SetBuildInfo(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	appMetrics := metrics.NewMetrics(reg)
+
+	appMetrics.SetBuildInfo("v1.2.0", "abc1234", "go1.24.5")
+
+	expected := `
+# HELP atlas_build_info Build information of the running binary, always 1.
+# TYPE atlas_build_info gauge
+atlas_build_info{commit="abc1234",go_version="go1.24.5",version="v1.2.0"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "atlas_build_info"))
+}
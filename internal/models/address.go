@@ -0,0 +1,28 @@
+package models
+
+import "strings"
+
+// StructuredAddress is an address stored as separate fields rather than free-form text,
+// e.g. in the address_json column of a task.
+type StructuredAddress struct {
+	PostalCode  string `json:"postal_code,omitempty"`  // PostalCode is the postal code, e.g. "80383"
+	Region      string `json:"region,omitempty"`       // Region is the region (oblast), e.g. "Львівська обл."
+	City        string `json:"city,omitempty"`         // City is the settlement with its type, e.g. "с. Грабовець"
+	Street      string `json:"street,omitempty"`       // Street is the street with its type, e.g. "вул. Польова"
+	HouseNumber string `json:"house_number,omitempty"` // HouseNumber is the house number, e.g. "3" or "12а"
+}
+
+// String returns the address as free-form text, joining its non-empty fields from the postal code
+// to the house number, e.g. "80383, Львівська обл., с. Грабовець, вул. Польова, 3".
+func (a StructuredAddress) String() string {
+	fields := []string{a.PostalCode, a.Region, a.City, a.Street, a.HouseNumber}
+
+	parts := make([]string, 0, len(fields))
+	for _, part := range fields {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
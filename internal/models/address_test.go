@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredAddress_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  models.StructuredAddress
+		expected string
+	}{
+		{
+			name: "all fields",
+			address: models.StructuredAddress{
+				PostalCode:  "80383",
+				Region:      "Львівська обл.",
+				City:        "с. Грабовець",
+				Street:      "вул. Польова",
+				HouseNumber: "3",
+			},
+			expected: "80383, Львівська обл., с. Грабовець, вул. Польова, 3",
+		},
+		{
+			name:     "empty fields are left out",
+			address:  models.StructuredAddress{City: "м. Київ", Street: " вул. Хрещатик ", HouseNumber: "1"},
+			expected: "м. Київ, вул. Хрещатик, 1",
+		},
+		{name: "empty", address: models.StructuredAddress{HouseNumber: " "}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.address.String())
+		})
+	}
+}
+
+func TestStructuredAddress_JSON(t *testing.T) {
+	var address models.StructuredAddress
+	payload := `{"city": "м. Київ", "street": "вул. Хрещатик", "house_number": "1", "postal_code": "01001"}`
+
+	require.NoError(t, json.Unmarshal([]byte(payload), &address))
+
+	assert.Equal(t, models.StructuredAddress{
+		PostalCode:  "01001",
+		City:        "м. Київ",
+		Street:      "вул. Хрещатик",
+		HouseNumber: "1",
+	}, address)
+}
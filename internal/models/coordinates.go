@@ -1,7 +1,21 @@
 package models
 
+// Precision describes how precisely a geocoding result matches the requested address.
+type Precision string
+
+// Precision levels, from the most to the least precise.
+const (
+	PrecisionRooftop     Precision = "rooftop"     // PrecisionRooftop is an exact house or building match.
+	PrecisionStreet      Precision = "street"      // PrecisionStreet is a street-level match.
+	PrecisionLocality    Precision = "locality"    // PrecisionLocality is a city, town or village centroid.
+	PrecisionRegion      Precision = "region"      // PrecisionRegion is a district, region or country centroid.
+	PrecisionApproximate Precision = "approximate" // PrecisionApproximate is a match of unknown precision.
+)
+
 // Coordinates represents a geographical point defined by its longitude and latitude.
 type Coordinates struct {
-	Longitude float64 // Longitude of the geographical point.
-	Latitude  float64 // Latitude of the geographical point.
+	Longitude     float64   // Longitude of the geographical point.
+	Latitude      float64   // Latitude of the geographical point.
+	FallbackLevel int       // FallbackLevel is the address fallback level that matched (0 is the full address).
+	Precision     Precision // Precision is the match quality reported by the provider.
 }
@@ -0,0 +1,25 @@
+package models
+
+import "math"
+
+// earthRadiusMeters is the mean radius of the Earth used for great-circle distances.
+const earthRadiusMeters = 6371008.8
+
+// Distance returns the great-circle distance between a and b in meters, computed with the haversine formula.
+// The Earth is treated as a sphere, so the result may differ from the geodesic distance by up to about 0.5%.
+func Distance(a, b Coordinates) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	// Rounding can push h slightly above 1 for antipodal points, where Asin would return NaN
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// DistanceTo returns the great-circle distance from c to other in meters, see Distance.
+func (c Coordinates) DistanceTo(other Coordinates) float64 {
+	return Distance(c, other)
+}
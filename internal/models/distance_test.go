@@ -0,0 +1,106 @@
+package models_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// meanEarthHalfCircumference is the distance between antipodal points on the mean Earth sphere, in meters.
+const meanEarthHalfCircumference = math.Pi * 6371008.8
+
+func TestDistance(t *testing.T) {
+	kyiv := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+	lviv := models.Coordinates{Latitude: 49.8397, Longitude: 24.0297}
+	london := models.Coordinates{Latitude: 51.5074, Longitude: -0.1278}
+	paris := models.Coordinates{Latitude: 48.8566, Longitude: 2.3522}
+	newYork := models.Coordinates{Latitude: 40.7128, Longitude: -74.0060}
+	losAngeles := models.Coordinates{Latitude: 34.0522, Longitude: -118.2437}
+	sydney := models.Coordinates{Latitude: -33.8688, Longitude: 151.2093}
+
+	tests := []struct {
+		name     string
+		a, b     models.Coordinates
+		expected float64 // expected distance in meters
+		epsilon  float64 // allowed relative error
+	}{
+		{name: "Kyiv to Lviv", a: kyiv, b: lviv, expected: 468_700, epsilon: 0.005},
+		{name: "London to Paris", a: london, b: paris, expected: 343_600, epsilon: 0.005},
+		{name: "New York to Los Angeles", a: newYork, b: losAngeles, expected: 3_936_000, epsilon: 0.005},
+		{name: "London to Sydney", a: london, b: sydney, expected: 16_994_000, epsilon: 0.005},
+		{
+			name:     "one degree of longitude on the equator",
+			a:        models.Coordinates{Latitude: 0, Longitude: 0},
+			b:        models.Coordinates{Latitude: 0, Longitude: 1},
+			expected: meanEarthHalfCircumference / 180,
+			epsilon:  1e-9,
+		},
+		{
+			name:     "across the antimeridian",
+			a:        models.Coordinates{Latitude: 0, Longitude: 179.5},
+			b:        models.Coordinates{Latitude: 0, Longitude: -179.5},
+			expected: meanEarthHalfCircumference / 180,
+			epsilon:  1e-9,
+		},
+		{
+			name:     "antipodal points on the equator",
+			a:        models.Coordinates{Latitude: 0, Longitude: 0},
+			b:        models.Coordinates{Latitude: 0, Longitude: 180},
+			expected: meanEarthHalfCircumference,
+			epsilon:  1e-9,
+		},
+		{
+			name:     "pole to pole",
+			a:        models.Coordinates{Latitude: 90, Longitude: 0},
+			b:        models.Coordinates{Latitude: -90, Longitude: 0},
+			expected: meanEarthHalfCircumference,
+			epsilon:  1e-9,
+		},
+		{
+			name:     "antipode of Kyiv",
+			a:        kyiv,
+			b:        models.Coordinates{Latitude: -kyiv.Latitude, Longitude: kyiv.Longitude - 180},
+			expected: meanEarthHalfCircumference,
+			epsilon:  1e-9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := models.Distance(tt.a, tt.b)
+
+			assert.False(t, math.IsNaN(distance))
+			assert.InEpsilon(t, tt.expected, distance, tt.epsilon)
+			assert.InDelta(t, distance, models.Distance(tt.b, tt.a), 1e-6, "distance must be symmetric")
+		})
+	}
+}
+
+func TestDistance_SamePoint(t *testing.T) {
+	points := []models.Coordinates{
+		{Latitude: 50.4501, Longitude: 30.5234},
+		{Latitude: 90, Longitude: 0},
+		{Latitude: -90, Longitude: 180},
+		{Latitude: 0, Longitude: -180},
+	}
+
+	for _, point := range points {
+		assert.InDelta(t, 0, models.Distance(point, point), 1e-6)
+	}
+}
+
+func TestDistance_IgnoresMatchDetails(t *testing.T) {
+	a := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop}
+	b := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRegion, FallbackLevel: 2}
+
+	assert.InDelta(t, 0, models.Distance(a, b), 1e-6)
+}
+
+func TestCoordinates_DistanceTo(t *testing.T) {
+	kyiv := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+	lviv := models.Coordinates{Latitude: 49.8397, Longitude: 24.0297}
+
+	assert.InDelta(t, models.Distance(kyiv, lviv), kyiv.DistanceTo(lviv), 0)
+}
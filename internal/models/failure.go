@@ -0,0 +1,13 @@
+package models
+
+// GeocodeError is a failed geocoding attempt as stored with its task: a machine-readable code
+// that failures can be grouped by, and the message of the error that caused it.
+type GeocodeError struct {
+	Code    string // Code is the class of the error, e.g. "unauthorized" or "empty_response".
+	Message string // Message is the error message.
+}
+
+// Error returns the message of the error.
+func (e GeocodeError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,48 @@
+package models
+
+// geohashAlphabet is the base32 alphabet of geohashes, which leaves out a, i, l and o.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the longest geohash returned by Geohash, about 3.7 cm by 1.9 cm.
+const MaxGeohashPrecision = 12
+
+// Geohash returns the geohash of the coordinates with precision characters, e.g. "u8vxn" for Kyiv
+// at precision 5, a cell of about 4.9 km by 4.9 km. Points sharing a geohash prefix are in the same cell,
+// so downstream systems can index them for proximity queries. Precision is clamped to 1..MaxGeohashPrecision.
+func (c Coordinates) Geohash(precision int) string {
+	precision = min(max(precision, 1), MaxGeohashPrecision)
+
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	hash := make([]byte, 0, precision)
+	even := true // bits alternate between longitude and latitude, starting with longitude
+	var char, bit int
+	for len(hash) < precision {
+		char <<= 1
+		if even {
+			if mid := (minLon + maxLon) / 2; c.Longitude >= mid {
+				char |= 1
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			if mid := (minLat + maxLat) / 2; c.Latitude >= mid {
+				char |= 1
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		even = !even
+
+		// Each character encodes 5 bits
+		if bit++; bit == 5 {
+			hash = append(hash, geohashAlphabet[char])
+			char, bit = 0, 0
+		}
+	}
+
+	return string(hash)
+}
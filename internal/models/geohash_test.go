@@ -0,0 +1,85 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoordinates_Geohash(t *testing.T) {
+	kyiv := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+
+	tests := []struct {
+		name      string
+		coords    models.Coordinates
+		precision int
+		expected  string
+	}{
+		{name: "Kyiv", coords: kyiv, precision: 5, expected: "u8vxn"},
+		{name: "Kyiv full precision", coords: kyiv, precision: 12, expected: "u8vxn84mnu28"},
+		{
+			name:      "Jutland reference point",
+			coords:    models.Coordinates{Latitude: 57.64911, Longitude: 10.40744},
+			precision: 11,
+			expected:  "u4pruydqqvj",
+		},
+		{
+			name:      "Spanish reference point",
+			coords:    models.Coordinates{Latitude: 42.6, Longitude: -5.6},
+			precision: 5,
+			expected:  "ezs42",
+		},
+		{
+			name:      "Eiffel Tower",
+			coords:    models.Coordinates{Latitude: 48.8583, Longitude: 2.2945},
+			precision: 9,
+			expected:  "u09tunqu1",
+		},
+		{
+			name:      "Statue of Liberty",
+			coords:    models.Coordinates{Latitude: 40.6892, Longitude: -74.0445},
+			precision: 7,
+			expected:  "dr5r7p4",
+		},
+		{
+			name:      "Sydney Opera House",
+			coords:    models.Coordinates{Latitude: -33.8568, Longitude: 151.2153},
+			precision: 8,
+			expected:  "r3gx2ux9",
+		},
+		{name: "origin", coords: models.Coordinates{}, precision: 12, expected: "s00000000000"},
+		{
+			name:      "south west corner",
+			coords:    models.Coordinates{Latitude: -90, Longitude: -180},
+			precision: 6,
+			expected:  "000000",
+		},
+		{
+			name:      "north east corner",
+			coords:    models.Coordinates{Latitude: 90, Longitude: 180},
+			precision: 6,
+			expected:  "zzzzzz",
+		},
+		{name: "precision below one", coords: kyiv, precision: 0, expected: "u"},
+		{name: "precision above maximum", coords: kyiv, precision: 20, expected: "u8vxn84mnu28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.coords.Geohash(tt.precision))
+		})
+	}
+}
+
+func TestCoordinates_GeohashPrefix(t *testing.T) {
+	coords := models.Coordinates{Latitude: 49.8397, Longitude: 24.0297}
+	full := coords.Geohash(models.MaxGeohashPrecision)
+
+	for precision := 1; precision <= models.MaxGeohashPrecision; precision++ {
+		hash := coords.Geohash(precision)
+		assert.Len(t, hash, precision)
+		assert.True(t, strings.HasPrefix(full, hash), "geohash %q is not a prefix of %q", hash, full)
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import "strconv"
+
+// GeoJSON returns the coordinates as a GeoJSON Point geometry (RFC 7946), e.g.
+// {"type":"Point","coordinates":[30.5234,50.4501]}. Positions are ordered longitude first.
+func (c Coordinates) GeoJSON() []byte {
+	geometry := []byte(`{"type":"Point","coordinates":[`)
+	geometry = strconv.AppendFloat(geometry, c.Longitude, 'f', -1, 64)
+	geometry = append(geometry, ',')
+	geometry = strconv.AppendFloat(geometry, c.Latitude, 'f', -1, 64)
+
+	return append(geometry, "]}"...)
+}
+
+// WKT returns the coordinates as a Well-Known Text point, e.g. POINT(30.5234 50.4501).
+// Like GeoJSON, the longitude comes first.
+func (c Coordinates) WKT() string {
+	return "POINT(" + strconv.FormatFloat(c.Longitude, 'f', -1, 64) + " " +
+		strconv.FormatFloat(c.Latitude, 'f', -1, 64) + ")"
+}
+
+// BoundingBox is the area between two latitudes and two longitudes, e.g. the area a service geocodes addresses in.
+// It doesn't cross the antimeridian, so MinLongitude is west of MaxLongitude.
+type BoundingBox struct {
+	MinLatitude  float64 // MinLatitude is the latitude of the southern edge.
+	MinLongitude float64 // MinLongitude is the longitude of the western edge.
+	MaxLatitude  float64 // MaxLatitude is the latitude of the northern edge.
+	MaxLongitude float64 // MaxLongitude is the longitude of the eastern edge.
+}
+
+// Contains reports whether the coordinates lie within the box, its edges included.
+func (b BoundingBox) Contains(c Coordinates) bool {
+	return c.Latitude >= b.MinLatitude && c.Latitude <= b.MaxLatitude &&
+		c.Longitude >= b.MinLongitude && c.Longitude <= b.MaxLongitude
+}
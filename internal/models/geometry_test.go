@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinates_GeoJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		coords   models.Coordinates
+		expected string
+	}{
+		{
+			name:     "longitude comes first",
+			coords:   models.Coordinates{Latitude: 50.4501, Longitude: 30.5234},
+			expected: `{"type":"Point","coordinates":[30.5234,50.4501]}`,
+		},
+		{
+			name:     "negative and integral values",
+			coords:   models.Coordinates{Latitude: -33, Longitude: -70.6483},
+			expected: `{"type":"Point","coordinates":[-70.6483,-33]}`,
+		},
+		{
+			name:     "match details are not serialized",
+			coords:   models.Coordinates{Latitude: 1, Longitude: 2, Precision: models.PrecisionRooftop, FallbackLevel: 1},
+			expected: `{"type":"Point","coordinates":[2,1]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.expected, string(tt.coords.GeoJSON()))
+		})
+	}
+}
+
+func TestCoordinates_GeoJSONRoundTrip(t *testing.T) {
+	coords := models.Coordinates{Latitude: 49.839683, Longitude: 24.029717}
+
+	var geometry struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	}
+	require.NoError(t, json.Unmarshal(coords.GeoJSON(), &geometry))
+
+	assert.Equal(t, "Point", geometry.Type)
+	assert.Equal(t, []float64{coords.Longitude, coords.Latitude}, geometry.Coordinates)
+}
+
+func TestCoordinates_WKT(t *testing.T) {
+	tests := []struct {
+		name     string
+		coords   models.Coordinates
+		expected string
+	}{
+		{
+			name:     "longitude comes first",
+			coords:   models.Coordinates{Latitude: 50.4501, Longitude: 30.5234},
+			expected: "POINT(30.5234 50.4501)",
+		},
+		{
+			name:     "negative values",
+			coords:   models.Coordinates{Latitude: -33.8688, Longitude: -70.6483},
+			expected: "POINT(-70.6483 -33.8688)",
+		},
+		{name: "origin", coords: models.Coordinates{}, expected: "POINT(0 0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.coords.WKT())
+		})
+	}
+}
+
+func TestBoundingBox_Contains(t *testing.T) {
+	ukraine := models.BoundingBox{MinLatitude: 44.38, MinLongitude: 22.14, MaxLatitude: 52.38, MaxLongitude: 40.23}
+
+	tests := []struct {
+		name     string
+		coords   models.Coordinates
+		expected bool
+	}{
+		{name: "inside", coords: models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}, expected: true},
+		{name: "on the edge", coords: models.Coordinates{Latitude: 44.38, Longitude: 40.23}, expected: true},
+		{name: "north", coords: models.Coordinates{Latitude: 55.7558, Longitude: 37.6173}},
+		{name: "west", coords: models.Coordinates{Latitude: 48.8566, Longitude: 2.3522}},
+		{name: "another continent", coords: models.Coordinates{Latitude: 40.7128, Longitude: -74.0060}},
+		{name: "swapped coordinates", coords: models.Coordinates{Latitude: 30.5234, Longitude: 50.4501}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ukraine.Contains(tt.coords))
+		})
+	}
+}
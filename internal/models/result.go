@@ -0,0 +1,16 @@
+package models
+
+// GeocodeResult is a geocoding match together with the metadata the provider reports about it.
+// Providers that report no metadata leave the extra fields empty.
+type GeocodeResult struct {
+	Coordinates              // Coordinates is the location of the match, including its precision.
+	PlaceID          string  // PlaceID is the provider's identifier of the matched place, e.g. a Google place_id.
+	FormattedAddress string  // FormattedAddress is the provider's formatted address of the matched place.
+	Provider         string  // Provider is the name of the provider that produced the match, e.g. "google".
+	Confidence       float64 // Confidence is the provider's match score between 0 and 1, zero if not reported.
+}
+
+// HasPlace reports whether the result carries a place identifier or a formatted address.
+func (r GeocodeResult) HasPlace() bool {
+	return r.PlaceID != "" || r.FormattedAddress != ""
+}
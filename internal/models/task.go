@@ -2,6 +2,16 @@ package models
 
 // Task represents a geocoding task with an ID and an associated address.
 type Task struct {
-	ID      int    // ID is the unique identifier for the task.
-	Address string // Address is the location to be geocoded.
+	ID                int    // ID is the unique identifier for the task.
+	Address           string // Address is the location to be geocoded.
+	PreferredProvider string // PreferredProvider is the provider the task should be geocoded with, empty for any.
+	Language          string // Language is the preferred result language of the task, empty for the default.
+
+	// Structured is the address of a task stored as separate fields, nil for a free-form address.
+	// Address then holds its free-form text.
+	Structured *StructuredAddress
+
+	// Refresh marks a task that already has coordinates and is geocoded again because they are stale.
+	// A failed refresh keeps the stored coordinates and isn't counted against the task.
+	Refresh bool
 }
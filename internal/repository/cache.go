@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultCacheTTL is how long cached coordinates stay fresh unless set with WithCacheTTL.
+const DefaultCacheTTL = 30 * 24 * time.Hour
+
+// Errors returned by LookupCachedCoordinates instead of coordinates.
+var (
+	// ErrCacheMiss is returned when the address has no fresh cache entry.
+	ErrCacheMiss = errors.New("no cached coordinates for address")
+	// ErrCachedNotFound is returned when the provider recently found nothing for the address.
+	ErrCachedNotFound = errors.New("address cached as not found by the geocoding provider")
+)
+
+// Cache defines the methods for a persistent geocoding result cache, shared by all
+// instances of the service and kept across restarts.
+type Cache interface {
+	// LookupCachedCoordinates returns the cached coordinates of the address, ErrCachedNotFound
+	// if the address is cached as not found, or ErrCacheMiss if it is not cached or its entry is stale.
+	LookupCachedCoordinates(ctx context.Context, address string) (*models.Coordinates, error)
+
+	// StoreCachedCoordinates caches the coordinates of the address returned by provider,
+	// replacing any previous entry of the address.
+	StoreCachedCoordinates(ctx context.Context, address string, coords models.Coordinates, provider string) error
+
+	// StoreCachedNotFound caches that provider found nothing for the address, unless
+	// the address is already cached with coordinates.
+	StoreCachedNotFound(ctx context.Context, address string, provider string) error
+}
+
+// WithCacheTTL sets how long cached coordinates stay fresh. Older entries are treated as
+// missing by LookupCachedCoordinates, so the address is geocoded again and the entry refreshed.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(r *Repository) {
+		r.cacheTTL = ttl
+	}
+}
+
+// WithNegativeCacheTTL enables caching that the provider found nothing for an address, for ttl.
+// While such an entry is fresh, LookupCachedCoordinates reports the address as ErrCachedNotFound,
+// so it isn't sent to the provider again. Zero, the default, disables negative caching.
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(r *Repository) {
+		r.negativeCacheTTL = ttl
+	}
+}
+
+// LookupCachedCoordinates returns the coordinates cached for the address in the geocode_cache table.
+// Entries older than the cache TTL (see WithCacheTTL) are stale and reported as ErrCacheMiss, and so are
+// not found entries older than the negative cache TTL (see WithNegativeCacheTTL). Fresh not found entries
+// are reported as ErrCachedNotFound.
+func (r *Repository) LookupCachedCoordinates(ctx context.Context, address string) (*models.Coordinates, error) {
+	query := `
+		SELECT COALESCE(latitude, 0), COALESCE(longitude, 0), COALESCE(geocoding_precision, ''), not_found
+		FROM public.geocode_cache
+		WHERE
+			address_hash = $1
+			AND created_at > NOW() - make_interval(secs => CASE WHEN not_found THEN $3 ELSE $2 END);
+	`
+
+	var (
+		coords    models.Coordinates
+		precision string
+		notFound  bool
+	)
+	err := r.db.QueryRow(ctx, query, addressHash(address), r.cacheTTL.Seconds(), r.negativeCacheTTL.Seconds()).
+		Scan(&coords.Latitude, &coords.Longitude, &precision, &notFound)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up cached coordinates: %w", err)
+	}
+	if notFound {
+		return nil, ErrCachedNotFound
+	}
+	coords.Precision = models.Precision(precision)
+
+	return &coords, nil
+}
+
+// StoreCachedCoordinates stores the coordinates of the address in the geocode_cache table.
+// An existing entry of the address, including a not found entry, is overwritten and its age is reset.
+func (r *Repository) StoreCachedCoordinates(
+	ctx context.Context,
+	address string,
+	coords models.Coordinates,
+	provider string,
+) error {
+	query := `
+		INSERT INTO public.geocode_cache (address_hash, latitude, longitude, geocoding_precision, provider, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NOW())
+		ON CONFLICT (address_hash) DO UPDATE
+		SET
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			geocoding_precision = EXCLUDED.geocoding_precision,
+			provider = EXCLUDED.provider,
+			created_at = EXCLUDED.created_at,
+			not_found = FALSE;
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		addressHash(address), coords.Latitude, coords.Longitude, string(coords.Precision), provider)
+	if err != nil {
+		return fmt.Errorf("failed to store cached coordinates: %w", err)
+	}
+
+	return nil
+}
+
+// StoreCachedNotFound records in the geocode_cache table that the provider found nothing for the address.
+// It does nothing unless negative caching is enabled with WithNegativeCacheTTL. An existing not found entry
+// of the address has its age reset, while an entry with coordinates is kept, so a positive result is never
+// replaced by a negative one.
+func (r *Repository) StoreCachedNotFound(ctx context.Context, address string, provider string) error {
+	if r.negativeCacheTTL <= 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO public.geocode_cache (address_hash, provider, not_found, created_at)
+		VALUES ($1, $2, TRUE, NOW())
+		ON CONFLICT (address_hash) DO UPDATE
+		SET
+			provider = EXCLUDED.provider,
+			created_at = EXCLUDED.created_at
+		WHERE geocode_cache.not_found;
+	`
+
+	if _, err := r.db.Exec(ctx, query, addressHash(address), provider); err != nil {
+		return fmt.Errorf("failed to store cached not found address: %w", err)
+	}
+
+	return nil
+}
+
+// addressHash returns the cache key of the address: the hex-encoded SHA-256 of the address as given,
+// so callers are expected to pass the normalized address sent to the provider.
+func addressHash(address string) string {
+	sum := sha256.Sum256([]byte(address))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,244 @@
+package repository_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheKey returns the expected geocode_cache key of the address.
+func cacheKey(address string) string {
+	sum := sha256.Sum256([]byte(address))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLookupCachedCoordinates(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	address := "київ, хрещатик, 1"
+	query := `
+		SELECT COALESCE(latitude, 0), COALESCE(longitude, 0), COALESCE(geocoding_precision, ''), not_found
+		FROM public.geocode_cache
+		WHERE
+			address_hash = $1
+			AND created_at > NOW() - make_interval(secs => CASE WHEN not_found THEN $3 ELSE $2 END);
+	`
+
+	t.Run("error - lookup query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), repository.DefaultCacheTTL.Seconds(), float64(0)).
+			WillReturnError(assert.AnError)
+
+		coords, err := repo.LookupCachedCoordinates(ctx, address)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to look up cached coordinates")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, coords)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("miss - address not cached or stale", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), repository.DefaultCacheTTL.Seconds(), float64(0)).
+			WillReturnError(pgx.ErrNoRows)
+
+		coords, err := repo.LookupCachedCoordinates(ctx, address)
+
+		require.ErrorIs(t, err, repository.ErrCacheMiss)
+		assert.Nil(t, coords)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - cached coordinates", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithCacheTTL(time.Hour))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), time.Hour.Seconds(), float64(0)).
+			WillReturnRows(pgxmock.NewRows([]string{"latitude", "longitude", "geocoding_precision", "not_found"}).
+				AddRow(50.45, 30.52, "rooftop", false))
+
+		coords, err := repo.LookupCachedCoordinates(ctx, address)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.Coordinates{Latitude: 50.45, Longitude: 30.52, Precision: models.PrecisionRooftop}, coords)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found - address cached as not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithNegativeCacheTTL(time.Hour))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), repository.DefaultCacheTTL.Seconds(), time.Hour.Seconds()).
+			WillReturnRows(pgxmock.NewRows([]string{"latitude", "longitude", "geocoding_precision", "not_found"}).
+				AddRow(0.0, 0.0, "", true))
+
+		coords, err := repo.LookupCachedCoordinates(ctx, address)
+
+		require.ErrorIs(t, err, repository.ErrCachedNotFound)
+		assert.Nil(t, coords)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStoreCachedCoordinates(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	address := "київ, хрещатик, 1"
+	coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52, Precision: models.PrecisionStreet}
+	query := `
+		INSERT INTO public.geocode_cache (address_hash, latitude, longitude, geocoding_precision, provider, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NOW())
+		ON CONFLICT (address_hash) DO UPDATE
+		SET
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			geocoding_precision = EXCLUDED.geocoding_precision,
+			provider = EXCLUDED.provider,
+			created_at = EXCLUDED.created_at,
+			not_found = FALSE;
+	`
+
+	t.Run("error - store coordinates", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), coords.Latitude, coords.Longitude, "street", "nominatim").
+			WillReturnError(assert.AnError)
+
+		err = repo.StoreCachedCoordinates(ctx, address, coords, "nominatim")
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to store cached coordinates")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store coordinates", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), coords.Latitude, coords.Longitude, "street", "nominatim").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err = repo.StoreCachedCoordinates(ctx, address, coords, "nominatim")
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStoreCachedNotFound(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	address := "київ, хрещатик, 1"
+	query := `
+		INSERT INTO public.geocode_cache (address_hash, provider, not_found, created_at)
+		VALUES ($1, $2, TRUE, NOW())
+		ON CONFLICT (address_hash) DO UPDATE
+		SET
+			provider = EXCLUDED.provider,
+			created_at = EXCLUDED.created_at
+		WHERE geocode_cache.not_found;
+	`
+
+	t.Run("disabled - negative cache TTL not set", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		err = repo.StoreCachedNotFound(ctx, address, "nominatim")
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - store not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithNegativeCacheTTL(time.Hour))
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), "nominatim").
+			WillReturnError(assert.AnError)
+
+		err = repo.StoreCachedNotFound(ctx, address, "nominatim")
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to store cached not found address")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithNegativeCacheTTL(time.Hour))
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(cacheKey(address), "nominatim").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err = repo.StoreCachedNotFound(ctx, address, "nominatim")
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
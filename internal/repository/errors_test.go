@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/UnknownOlympus/atlas/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "connection reset", err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, expected: true},
+		{name: "unexpected EOF", err: fmt.Errorf("failed to query: %w", io.ErrUnexpectedEOF), expected: true},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, expected: true},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, expected: true},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, expected: true},
+		{name: "syntax error", err: &pgconn.PgError{Code: "42601"}, expected: false},
+		{name: "undefined column", err: fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "42703"}), expected: false},
+		{name: "no rows", err: pgx.ErrNoRows, expected: false},
+		{name: "context canceled", err: context.Canceled, expected: false},
+		{name: "deadline exceeded", err: fmt.Errorf("query: %w", context.DeadlineExceeded), expected: false},
+		{name: "generic error", err: errors.New("scan failed"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, repository.IsTransient(tt.err))
+		})
+	}
+}
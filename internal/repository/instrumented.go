@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// Operations observed by InstrumentedRepository.
+const (
+	OperationFetchTasks           = "fetch_tasks"
+	OperationFetchStructuredTasks = "fetch_structured_tasks"
+	OperationUpdateCoordinates    = "update_coordinates"
+	OperationUpdateResult         = "update_result"
+	OperationUpdateGeocodeResult  = "update_geocode_result"
+	OperationIncrementFailure     = "increment_failure"
+)
+
+// Outcomes of the operations observed by InstrumentedRepository.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// OperationObserver is called by InstrumentedRepository after every observed operation
+// with its name, its outcome and how long it took.
+type OperationObserver func(operation, outcome string, elapsed time.Duration)
+
+// InstrumentedRepository wraps a repository and observes the duration and outcome of the operations
+// on the geocoding hot path: fetching tasks, storing coordinates and counting failed attempts, so that
+// a slow or failing database shows up next to the provider metrics. Other operations are passed through
+// unobserved.
+type InstrumentedRepository struct {
+	Interface
+
+	observe OperationObserver
+}
+
+// Ensure InstrumentedRepository implements Interface.
+var _ Interface = (*InstrumentedRepository)(nil)
+
+// NewInstrumentedRepository returns repo wrapped to report its operations to observe.
+func NewInstrumentedRepository(repo Interface, observe OperationObserver) *InstrumentedRepository {
+	return &InstrumentedRepository{Interface: repo, observe: observe}
+}
+
+// FetchTasksForGeocoding fetches the tasks from the wrapped repository and observes the fetch.
+func (r *InstrumentedRepository) FetchTasksForGeocoding(ctx context.Context, limit int) ([]models.Task, error) {
+	start := time.Now()
+	tasks, err := r.Interface.FetchTasksForGeocoding(ctx, limit)
+	r.record(OperationFetchTasks, start, err)
+
+	return tasks, err
+}
+
+// FetchStructuredTasksForGeocoding fetches the tasks from the wrapped repository and observes the fetch.
+func (r *InstrumentedRepository) FetchStructuredTasksForGeocoding(
+	ctx context.Context,
+	limit int,
+) ([]models.Task, error) {
+	start := time.Now()
+	tasks, err := r.Interface.FetchStructuredTasksForGeocoding(ctx, limit)
+	r.record(OperationFetchStructuredTasks, start, err)
+
+	return tasks, err
+}
+
+// UpdateTaskCoordinates stores the coordinates with the wrapped repository and observes the update.
+func (r *InstrumentedRepository) UpdateTaskCoordinates(
+	ctx context.Context,
+	taskID int,
+	coords models.Coordinates,
+) error {
+	start := time.Now()
+	err := r.Interface.UpdateTaskCoordinates(ctx, taskID, coords)
+	r.record(OperationUpdateCoordinates, start, err)
+
+	return err
+}
+
+// UpdateTaskResult stores the result with the wrapped repository and observes the update.
+func (r *InstrumentedRepository) UpdateTaskResult(ctx context.Context, taskID int, result models.GeocodeResult) error {
+	start := time.Now()
+	err := r.Interface.UpdateTaskResult(ctx, taskID, result)
+	r.record(OperationUpdateResult, start, err)
+
+	return err
+}
+
+// UpdateTaskGeocodeResult stores the cleaned address and the result with the wrapped repository
+// and observes the update.
+func (r *InstrumentedRepository) UpdateTaskGeocodeResult(
+	ctx context.Context,
+	taskID int,
+	cleanedAddress string,
+	result models.GeocodeResult,
+) error {
+	start := time.Now()
+	err := r.Interface.UpdateTaskGeocodeResult(ctx, taskID, cleanedAddress, result)
+	r.record(OperationUpdateGeocodeResult, start, err)
+
+	return err
+}
+
+// IncrementFailureCount counts the failed attempt with the wrapped repository and observes the update.
+func (r *InstrumentedRepository) IncrementFailureCount(
+	ctx context.Context,
+	taskID int,
+	failure models.GeocodeError,
+) error {
+	start := time.Now()
+	err := r.Interface.IncrementFailureCount(ctx, taskID, failure)
+	r.record(OperationIncrementFailure, start, err)
+
+	return err
+}
+
+// record reports the operation started at start, which failed if err is not nil.
+func (r *InstrumentedRepository) record(operation string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	r.observe(operation, outcome, time.Since(start))
+}
@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// observation is an operation reported to an OperationObserver.
+type observation struct {
+	operation, outcome string
+}
+
+func TestInstrumentedRepository(t *testing.T) {
+	ctx := t.Context()
+	coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	result := models.GeocodeResult{Coordinates: coords, PlaceID: "place-1"}
+	failure := models.GeocodeError{Code: "other", Message: "boom"}
+
+	mockRepo := mocks.NewInterface(t)
+	var observed []observation
+	repo := repository.NewInstrumentedRepository(mockRepo, func(operation, outcome string, elapsed time.Duration) {
+		assert.GreaterOrEqual(t, elapsed, time.Duration(0))
+		observed = append(observed, observation{operation: operation, outcome: outcome})
+	})
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+	mockRepo.On("FetchStructuredTasksForGeocoding", ctx, 100).Return(nil, assert.AnError).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, coords).Return(nil).Once()
+	mockRepo.On("UpdateTaskResult", ctx, 1, result).Return(assert.AnError).Once()
+	mockRepo.On("UpdateTaskGeocodeResult", ctx, 1, "Kyiv", result).Return(nil).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 2, failure).Return(assert.AnError).Once()
+	mockRepo.On("CountPendingTasks", ctx).Return(3, nil).Once()
+
+	tasks, err := repo.FetchTasksForGeocoding(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: 1, Address: "Kyiv"}}, tasks)
+	_, err = repo.FetchStructuredTasksForGeocoding(ctx, 100)
+	require.ErrorIs(t, err, assert.AnError)
+	require.NoError(t, repo.UpdateTaskCoordinates(ctx, 1, coords))
+	require.ErrorIs(t, repo.UpdateTaskResult(ctx, 1, result), assert.AnError)
+	require.NoError(t, repo.UpdateTaskGeocodeResult(ctx, 1, "Kyiv", result))
+	require.ErrorIs(t, repo.IncrementFailureCount(ctx, 2, failure), assert.AnError)
+	pending, err := repo.CountPendingTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pending, "other operations are passed through")
+
+	assert.Equal(t, []observation{
+		{operation: repository.OperationFetchTasks, outcome: repository.OutcomeSuccess},
+		{operation: repository.OperationFetchStructuredTasks, outcome: repository.OutcomeError},
+		{operation: repository.OperationUpdateCoordinates, outcome: repository.OutcomeSuccess},
+		{operation: repository.OperationUpdateResult, outcome: repository.OutcomeError},
+		{operation: repository.OperationUpdateGeocodeResult, outcome: repository.OutcomeSuccess},
+		{operation: repository.OperationIncrementFailure, outcome: repository.OutcomeError},
+	}, observed, "only the operations of the geocoding path are observed")
+}
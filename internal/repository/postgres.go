@@ -2,14 +2,26 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/jackc/pgx/v5"
 )
 
+// ErrGeometryDisabled is returned by UpdateTaskGeometry if no geometry column is set with WithGeometryColumn.
+var ErrGeometryDisabled = errors.New("no geometry column configured")
+
 // FetchTasksForGeocoding retrieves a list of tasks that require geocoding.
 // It returns tasks that have a NULL latitude, are not closed, have fewer than 5 geocoding attempts,
 // and have a non-empty address. The results are ordered by creation date and limited to the specified count.
+// The fetch options set with WithFetchOptions can restrict the tasks to a region, put urgent tasks first,
+// hold back tasks that failed recently and read the preferred provider of each task.
+// If task claiming is enabled, the returned tasks are claimed for this instance (see WithTaskClaim).
+// Otherwise they are read from the read database if one is set (see WithReadDatabase).
 //
 // Parameters:
 // - ctx: The context for the operation, allowing for cancellation and timeout.
@@ -19,80 +31,675 @@ import (
 // - A slice of models.Task containing the tasks that match the criteria.
 // - An error if the query fails or if there is an issue scanning the results.
 func (r *Repository) FetchTasksForGeocoding(ctx context.Context, limit int) ([]models.Task, error) {
-	var tasks []models.Task
+	return r.fetchTasks(ctx, limit, false)
+}
+
+// FetchStructuredTasksForGeocoding retrieves a list of tasks that require geocoding like FetchTasksForGeocoding,
+// but reads the address of each task from the address_json column, a JSONB object with the fields of
+// models.StructuredAddress, instead of the address column. Tasks without a structured address are not returned.
+// The structured address is scanned into Task.Structured, and its free-form text into Task.Address.
+// A task whose address_json doesn't hold an object is returned with an empty address, so that it is
+// marked as invalid instead of blocking the queue.
+func (r *Repository) FetchStructuredTasksForGeocoding(ctx context.Context, limit int) ([]models.Task, error) {
+	return r.fetchTasks(ctx, limit, true)
+}
+
+// fetchTasks retrieves the tasks that require geocoding, with their structured address if structured is set
+// and their free-form address otherwise.
+func (r *Repository) fetchTasks(ctx context.Context, limit int, structured bool) ([]models.Task, error) {
+	args := []any{limit}
+	if r.claimOwner != "" {
+		args = append(args, r.claimOwner, r.claimTTL.Seconds())
+	}
+
+	// Only placeholders are added to the query, filter values are always passed as arguments
+	filter, order, filterArgs := r.fetch.clauses(len(args) + 1)
+	args = append(args, filterArgs...)
+	addressColumn, hasAddress := "address", "address IS NOT NULL AND TRIM(address) <> ''"
+	if structured {
+		addressColumn, hasAddress = "address_json", "address_json IS NOT NULL"
+	}
+	columns := r.fetch.columns(addressColumn)
+
 	query := `
-		SELECT task_id, address
+		SELECT ` + columns + `
 		FROM public.tasks
 		WHERE
 			latitude IS NULL
 			AND is_closed = false
 			AND geocoding_attempts < 5
-			AND address IS NOT NULL AND address <> ''
-		ORDER BY created_at ASC
+			AND ` + hasAddress + filter + `
+		ORDER BY ` + order + `
 		LIMIT $1;
 	`
 
-	rows, err := r.db.Query(ctx, query, limit)
+	if r.claimOwner != "" {
+		// Rows locked by another instance's claim are skipped, and claims older than the TTL
+		// are taken over, so that tasks of a crashed instance are eventually processed.
+		query = `
+			UPDATE public.tasks
+			SET
+				locked_by = $2,
+				locked_at = NOW()
+			WHERE task_id IN (
+				SELECT task_id
+				FROM public.tasks
+				WHERE
+					latitude IS NULL
+					AND is_closed = false
+					AND geocoding_attempts < 5
+					AND ` + hasAddress + `
+					AND (locked_at IS NULL OR locked_at < NOW() - make_interval(secs => $3))` + filter + `
+				ORDER BY ` + order + `
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + columns + `;
+		`
+	}
+
+	rows, err := r.fetchDatabase().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active tasks with address: %w", err)
 	}
+
+	tasks, err := r.scanTasks(ctx, rows, structured)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.claimOwner != "" {
+		return tasks, nil
+	}
+
+	return r.written.filter(tasks), nil
+}
+
+// FetchStaleTasks retrieves a list of tasks whose coordinates were stored more than olderThan ago, so that
+// they are geocoded again in case their address changed meaning, e.g. after new buildings or renamed streets.
+// Only tasks geocoded with WithGeocodedAt enabled have a geocoded_at time and can be returned. Like
+// FetchTasksForGeocoding, it skips closed tasks, tasks without an address and tasks that exhausted their
+// attempts, and applies the region and cooldown of the fetch options, but the stalest tasks are returned first.
+// The tasks are read from the primary database without being modified, skipping rows locked by another
+// instance; a refresh that fails is postponed with PostponeStaleRefresh.
+func (r *Repository) FetchStaleTasks(ctx context.Context, olderThan time.Duration, limit int) ([]models.Task, error) {
+	args := []any{limit, olderThan.Seconds()}
+	filter, _, filterArgs := r.fetch.clauses(len(args) + 1)
+	args = append(args, filterArgs...)
+
+	query := `
+		SELECT ` + r.fetch.columns("address") + `
+		FROM public.tasks
+		WHERE
+			latitude IS NOT NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND address IS NOT NULL AND TRIM(address) <> ''
+			AND geocoded_at < NOW() - make_interval(secs => $2)` + filter + `
+		ORDER BY geocoded_at ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED;
+	`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale tasks: %w", err)
+	}
+
+	return r.scanTasks(ctx, rows, false)
+}
+
+// scanTasks reads the tasks of rows selected with the columns of the fetch options, decoding their
+// structured address if structured is set, and closes rows.
+func (r *Repository) scanTasks(ctx context.Context, rows pgx.Rows, structured bool) ([]models.Task, error) {
 	defer rows.Close()
 
+	var tasks []models.Task
 	for rows.Next() {
-		var task models.Task
-		if errScan := rows.Scan(&task.ID, &task.Address); errScan != nil {
+		var (
+			task    models.Task
+			payload []byte
+		)
+		dest := []any{&task.ID, &task.Address}
+		if structured {
+			dest[1] = &payload
+		}
+		if r.fetch.PreferredProvider {
+			dest = append(dest, &task.PreferredProvider)
+		}
+		if r.fetch.Language {
+			dest = append(dest, &task.Language)
+		}
+		if errScan := rows.Scan(dest...); errScan != nil {
 			return nil, fmt.Errorf("failed to scan active task with address: %w", errScan)
 		}
-		r.log.DebugContext(ctx, "A new active task without coordinates has been received.",
-			"ID", task.ID, "Address", task.Address)
+		if structured {
+			r.decodeStructuredAddress(ctx, &task, payload)
+		}
+		r.log.DebugContext(ctx, "A new active task has been received.", "ID", task.ID, "Address", task.Address)
 		tasks = append(tasks, task)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to read row: %w", err)
 	}
 
 	return tasks, nil
 }
 
-// UpdateTaskCoordinates updates the latitude and longitude of a task identified by taskID.
-// It sets the geocoding_error field to NULL. It returns an error if the update fails.
+// UpdateTaskCoordinates updates the latitude, longitude and match precision of a task identified by taskID.
+// An empty precision is stored as NULL. It sets the geocoding_error field to NULL, stores the geohash
+// of the coordinates if enabled with WithGeohash and their point if enabled with WithGeometryColumn,
+// and releases the claim on the task if task claiming is enabled.
+// It returns an error if the update fails.
 func (r *Repository) UpdateTaskCoordinates(ctx context.Context, taskID int, coords models.Coordinates) error {
 	query := `
 		UPDATE tasks
 		SET
 			latitude = $1,
 			longitude = $2,
-			geocoding_error = NULL
+			geocoding_precision = NULLIF($3, ''),
+			geocoding_error = NULL,
+			geocoding_error_code = NULL` + r.setGeohash(5) + r.setGeometry(1, 2) + r.setGeocodedAt() + r.releaseClaim() + `
+		WHERE
+			task_id = $4;
+	`
+
+	args := r.geohashArgs(coords, coords.Latitude, coords.Longitude, string(coords.Precision), taskID)
+	_, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update task coordinates: %w", err)
+	}
+	r.written.add(taskID)
+
+	return nil
+}
+
+// UpdateTaskResult updates the coordinates and match precision of a task identified by taskID like
+// UpdateTaskCoordinates, and also stores the provider's place identifier and formatted address.
+// Empty precision and place fields are stored as NULL.
+// It returns an error if the update fails.
+func (r *Repository) UpdateTaskResult(ctx context.Context, taskID int, result models.GeocodeResult) error {
+	query := `
+		UPDATE tasks
+		SET
+			latitude = $1,
+			longitude = $2,
+			geocoding_precision = NULLIF($3, ''),
+			geocoding_place_id = NULLIF($4, ''),
+			geocoding_address = NULLIF($5, ''),
+			geocoding_error = NULL,
+			geocoding_error_code = NULL` + r.setGeohash(7) + r.setGeometry(1, 2) + r.setGeocodedAt() + r.releaseClaim() + `
+		WHERE
+			task_id = $6;
+	`
+
+	args := r.geohashArgs(result.Coordinates,
+		result.Latitude, result.Longitude, string(result.Precision), result.PlaceID, result.FormattedAddress, taskID)
+	_, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update task geocoding result: %w", err)
+	}
+	r.written.add(taskID)
+
+	return nil
+}
+
+// UpdateTaskGeocodeResult updates the address of a task identified by taskID to cleanedAddress along with
+// its coordinates and match precision in a single statement, so that the normalized form of a successfully
+// geocoded address replaces the original one. If the result has a place identifier or a formatted address,
+// they are stored like UpdateTaskResult does; otherwise it behaves like UpdateTaskCoordinates.
+// It returns an error if the update fails.
+func (r *Repository) UpdateTaskGeocodeResult(
+	ctx context.Context,
+	taskID int,
+	cleanedAddress string,
+	result models.GeocodeResult,
+) error {
+	args := []any{cleanedAddress, result.Latitude, result.Longitude, string(result.Precision), taskID}
+	setPlace := ""
+	if result.HasPlace() {
+		setPlace = `,
+			geocoding_place_id = NULLIF($6, ''),
+			geocoding_address = NULLIF($7, '')`
+		args = append(args, result.PlaceID, result.FormattedAddress)
+	}
+
+	query := `
+		UPDATE tasks
+		SET
+			address = $1,
+			latitude = $2,
+			longitude = $3,
+			geocoding_precision = NULLIF($4, ''),
+			geocoding_error = NULL,
+			geocoding_error_code = NULL` + setPlace + r.setGeohash(len(args)+1) + r.setGeometry(2, 3) +
+		r.setGeocodedAt() + r.releaseClaim() + `
+		WHERE
+			task_id = $5;
+	`
+
+	args = r.geohashArgs(result.Coordinates, args...)
+	_, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update task address and coordinates: %w", err)
+	}
+	r.written.add(taskID)
+
+	return nil
+}
+
+// UpdateTaskGeometry stores the coordinates of a task identified by taskID as a PostGIS point in the column
+// configured with WithGeometryColumn, leaving its other columns untouched, e.g. to backfill the column for
+// tasks geocoded before it was enabled. It returns an error if the column isn't configured or the update fails.
+func (r *Repository) UpdateTaskGeometry(ctx context.Context, taskID int, coords models.Coordinates) error {
+	if r.geometryColumn == "" {
+		return ErrGeometryDisabled
+	}
+
+	query := `
+		UPDATE tasks
+		SET
+			` + r.geometryColumn + ` = ST_SetSRID(ST_MakePoint($2, $1), 4326)
 		WHERE
 			task_id = $3;
 	`
 
 	_, err := r.db.Exec(ctx, query, coords.Latitude, coords.Longitude, taskID)
 	if err != nil {
-		return fmt.Errorf("failed to update task coordinates: %w", err)
+		return fmt.Errorf("failed to update task geometry: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTaskWhat3Words stores the what3words address of the coordinates of the tasks identified by taskIDs
+// in their w3w column. It returns an error if the update fails.
+func (r *Repository) UpdateTaskWhat3Words(ctx context.Context, taskIDs []int, words string) error {
+	query := `
+		UPDATE tasks
+		SET w3w = $1
+		WHERE task_id = ANY($2);
+	`
+
+	if _, err := r.db.Exec(ctx, query, words, taskIDs); err != nil {
+		return fmt.Errorf("failed to update task what3words address: %w", err)
 	}
 
 	return nil
 }
 
 // IncrementFailureCount increments the geocoding attempt count for a specific task
-// identified by taskID and updates the associated error message. It takes a context
-// for managing request-scoped values, cancellation, and deadlines. If the update
-// operation fails, it returns an error with additional context.
-func (r *Repository) IncrementFailureCount(ctx context.Context, taskID int, errMsg string) error {
+// identified by taskID and updates the associated error message and code. It takes a context
+// for managing request-scoped values, cancellation, and deadlines. If the attempt cooldown
+// is enabled, the time of the attempt is recorded, and if task claiming is enabled,
+// the claim on the task is released. If the update operation fails,
+// it returns an error with additional context.
+func (r *Repository) IncrementFailureCount(ctx context.Context, taskID int, failure models.GeocodeError) error {
 	query := `
 		UPDATE tasks
 		SET
 			geocoding_attempts = geocoding_attempts + 1,
-			geocoding_error = $1
-		WHERE task_id = $2;
+			geocoding_error = $1,
+			geocoding_error_code = $2` + r.setLastAttempt("NOW()") + r.releaseClaim() + `
+		WHERE task_id = $3;
 	`
 
-	_, err := r.db.Exec(ctx, query, errMsg, taskID)
+	_, err := r.db.Exec(ctx, query, failure.Message, failure.Code, taskID)
 	if err != nil {
 		return fmt.Errorf("failed to update geocoding error and number of attempts: %w", err)
 	}
+	r.written.add(taskID)
+
+	return nil
+}
+
+// MarkTaskNotFound records that the address of the task identified by taskID has no match, e.g. after the provider
+// exhausted its fallbacks, by setting its geocoding_status to GeocodingStatusNotFound along with its geocoding
+// error and code. Unlike IncrementFailureCount, it raises the attempt count to the limit, so that
+// FetchTasksForGeocoding no longer returns it. The task is picked up again once its attempts are reset.
+// If the attempt cooldown is enabled, the time of the attempt is recorded, and if task claiming is enabled,
+// the claim on the task is released.
+func (r *Repository) MarkTaskNotFound(ctx context.Context, taskID int, failure models.GeocodeError) error {
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_status = $1,
+			geocoding_attempts = GREATEST(geocoding_attempts + 1, 5),
+			geocoding_error = $2,
+			geocoding_error_code = $3` + r.setLastAttempt("NOW()") + r.releaseClaim() + `
+		WHERE task_id = $4;
+	`
+
+	_, err := r.db.Exec(ctx, query, GeocodingStatusNotFound, failure.Message, failure.Code, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to mark task as not found: %w", err)
+	}
+	r.written.add(taskID)
+
+	return nil
+}
+
+// MarkInvalidAddress records the reason why the address of the task identified by taskID can't be geocoded
+// as its geocoding error and code, and raises its attempt count to the limit, so that FetchTasksForGeocoding no longer
+// returns it. The task is picked up again once its attempts are reset, e.g. after its address was fixed.
+// If task claiming is enabled, the claim on the task is released.
+func (r *Repository) MarkInvalidAddress(ctx context.Context, taskID int, reason models.GeocodeError) error {
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_attempts = GREATEST(geocoding_attempts, 5),
+			geocoding_error = $1,
+			geocoding_error_code = $2` + r.releaseClaim() + `
+		WHERE task_id = $3;
+	`
+
+	_, err := r.db.Exec(ctx, query, reason.Message, reason.Code, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to mark task address as invalid: %w", err)
+	}
+	r.written.add(taskID)
+
+	return nil
+}
+
+// PostponeStaleRefresh advances the geocoded_at time of the task identified by taskID to now, keeping its
+// coordinates, so that a refresh of its stale coordinates that failed isn't attempted again by FetchStaleTasks
+// until they are stale again. Refreshes left for the next poll, e.g. after a rate limit, aren't postponed.
+func (r *Repository) PostponeStaleRefresh(ctx context.Context, taskID int) error {
+	query := `
+		UPDATE public.tasks
+		SET geocoded_at = NOW()
+		WHERE task_id = $1;
+	`
+
+	_, err := r.db.Exec(ctx, query, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to postpone stale coordinates refresh: %w", err)
+	}
 
 	return nil
 }
+
+// ResetGeocodingAttempts zeroes the geocoding attempt count and clears the geocoding error
+// and attempt cooldown of the tasks identified by taskIDs, so that they are picked up
+// by FetchTasksForGeocoding again. The not found status is cleared as well if enabled with WithNotFoundStatus.
+// It returns the number of tasks that were reset.
+func (r *Repository) ResetGeocodingAttempts(ctx context.Context, taskIDs []int) (int64, error) {
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_attempts = 0,
+			geocoding_error = NULL,
+			geocoding_error_code = NULL` + r.setLastAttempt("NULL") + r.clearNotFound(2) + `
+		WHERE task_id = ANY($1);
+	`
+
+	tag, err := r.db.Exec(ctx, query, r.notFoundArgs(taskIDs)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset geocoding attempts: %w", err)
+	}
+	r.written.remove(taskIDs)
+
+	return tag.RowsAffected(), nil
+}
+
+// ResetFailedGeocodingAttempts resets the geocoding attempt count, error and attempt cooldown
+// of every task that has exhausted its geocoding attempts without getting coordinates, and its not found status
+// if enabled with WithNotFoundStatus. It returns the number of tasks that were reset.
+func (r *Repository) ResetFailedGeocodingAttempts(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_attempts = 0,
+			geocoding_error = NULL,
+			geocoding_error_code = NULL` + r.setLastAttempt("NULL") + r.clearNotFound(1) + `
+		WHERE
+			latitude IS NULL
+			AND geocoding_attempts >= 5;
+	`
+
+	tag, err := r.db.Exec(ctx, query, r.notFoundArgs()...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset failed geocoding attempts: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// SkipTaskGeocoding sets the geocoding_status of the tasks identified by taskIDs to GeocodingStatusSkip,
+// so that FetchTasksForGeocoding no longer returns them if FetchOptions.SkipFlagged is set. Clearing the
+// column makes them eligible again. It returns the number of tasks that were flagged.
+func (r *Repository) SkipTaskGeocoding(ctx context.Context, taskIDs []int) (int64, error) {
+	query := `
+		UPDATE tasks
+		SET geocoding_status = $1
+		WHERE task_id = ANY($2);
+	`
+
+	tag, err := r.db.Exec(ctx, query, GeocodingStatusSkip, taskIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to skip task geocoding: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ReleaseTaskClaims releases the claims of this instance on the tasks identified by taskIDs, so that tasks
+// left for the next poll without being updated, e.g. after a rate limit or in dry run, are fetched again on the
+// next poll instead of once their claim expires. Tasks updated since they were fetched already released their
+// claim, and tasks claimed by another instance are left untouched. It returns the number of released tasks,
+// and zero without a query if task claiming is disabled.
+func (r *Repository) ReleaseTaskClaims(ctx context.Context, taskIDs []int) (int64, error) {
+	if r.claimOwner == "" || len(taskIDs) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		UPDATE public.tasks
+		SET
+			locked_by = NULL,
+			locked_at = NULL
+		WHERE task_id = ANY($1) AND locked_by = $2;
+	`
+
+	tag, err := r.db.Exec(ctx, query, taskIDs, r.claimOwner)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release task claims: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// CountPendingTasks returns the number of tasks that still require geocoding,
+// using the same criteria and fetch options as FetchTasksForGeocoding. It reads from the read database if one is set.
+func (r *Repository) CountPendingTasks(ctx context.Context) (int, error) {
+	return r.countPendingTasks(ctx, false)
+}
+
+// CountPendingStructuredTasks returns the number of tasks that still require geocoding,
+// using the same criteria and fetch options as FetchStructuredTasksForGeocoding.
+// It reads from the read database if one is set.
+func (r *Repository) CountPendingStructuredTasks(ctx context.Context) (int, error) {
+	return r.countPendingTasks(ctx, true)
+}
+
+// countPendingTasks counts the tasks fetchTasks would return without a limit and without claiming,
+// with a structured address if structured is set and a free-form address otherwise.
+func (r *Repository) countPendingTasks(ctx context.Context, structured bool) (int, error) {
+	filter, _, args := r.fetch.clauses(1)
+	hasAddress := "address IS NOT NULL AND TRIM(address) <> ''"
+	if structured {
+		hasAddress = "address_json IS NOT NULL"
+	}
+
+	query := `
+		SELECT COUNT(*)
+		FROM public.tasks
+		WHERE
+			latitude IS NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND ` + hasAddress + filter + `;
+	`
+
+	var count int
+	if err := r.ReadDatabase().QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending tasks: %w", err)
+	}
+
+	return count, nil
+}
+
+// releaseClaim returns the SET clause fragment that releases the claim on a task,
+// or an empty string if task claiming is disabled and the claim columns may not exist.
+func (r *Repository) releaseClaim() string {
+	if r.claimOwner == "" {
+		return ""
+	}
+
+	return `,
+			locked_by = NULL,
+			locked_at = NULL`
+}
+
+// setLastAttempt returns the SET clause fragment that sets the time of the last failed attempt to value,
+// or an empty string if the attempt cooldown is disabled and the last_attempt_at column may not exist.
+func (r *Repository) setLastAttempt(value string) string {
+	if r.fetch.MinAttemptInterval <= 0 {
+		return ""
+	}
+
+	return `,
+			last_attempt_at = ` + value
+}
+
+// setGeohash returns the SET clause fragment that sets the geohash of a task to the query parameter param,
+// or an empty string if storing geohashes is disabled and the geohash column may not exist.
+func (r *Repository) setGeohash(param int) string {
+	if r.geohashPrecision <= 0 {
+		return ""
+	}
+
+	return `,
+			geohash = $` + strconv.Itoa(param)
+}
+
+// setGeometry returns the SET clause fragment that sets the geometry column of a task to the point of the
+// latitude and longitude query parameters, or an empty string if it is disabled and the column may not exist.
+// ST_MakePoint takes the longitude first.
+func (r *Repository) setGeometry(latParam, lonParam int) string {
+	if r.geometryColumn == "" {
+		return ""
+	}
+
+	return `,
+			` + r.geometryColumn + ` = ST_SetSRID(ST_MakePoint($` + strconv.Itoa(lonParam) + `, $` +
+		strconv.Itoa(latParam) + `), 4326)`
+}
+
+// setGeocodedAt returns the SET clause fragment that records the time coordinates were stored,
+// or an empty string if it is disabled and the geocoded_at column may not exist.
+func (r *Repository) setGeocodedAt() string {
+	if !r.geocodedAt {
+		return ""
+	}
+
+	return `,
+			geocoded_at = NOW()`
+}
+
+// clearNotFound returns the SET clause fragment that clears the geocoding_status of a task if it is the not found
+// status passed as the query parameter param, or an empty string if it is disabled and the column may not exist.
+// Other statuses, such as GeocodingStatusSkip, are left as they are.
+func (r *Repository) clearNotFound(param int) string {
+	if !r.notFoundStatus {
+		return ""
+	}
+
+	return `,
+			geocoding_status = NULLIF(geocoding_status, $` + strconv.Itoa(param) + `)`
+}
+
+// notFoundArgs returns the query arguments args followed by GeocodingStatusNotFound if clearing it is enabled,
+// matching the parameter of clearNotFound.
+func (r *Repository) notFoundArgs(args ...any) []any {
+	if !r.notFoundStatus {
+		return args
+	}
+
+	return append(args, GeocodingStatusNotFound)
+}
+
+// geohashArgs returns the query arguments args followed by the geohash of coords if storing geohashes is enabled,
+// matching the parameter of setGeohash.
+func (r *Repository) geohashArgs(coords models.Coordinates, args ...any) []any {
+	if r.geohashPrecision <= 0 {
+		return args
+	}
+
+	return append(args, coords.Geohash(r.geohashPrecision))
+}
+
+// decodeStructuredAddress decodes the address_json payload of the task into its structured address
+// and free-form text. A payload that can't be decoded is logged and leaves the address empty.
+func (r *Repository) decodeStructuredAddress(ctx context.Context, task *models.Task, payload []byte) {
+	var address models.StructuredAddress
+	if err := json.Unmarshal(payload, &address); err != nil {
+		r.log.WarnContext(ctx, "Failed to decode the structured address of a task", "task", task.ID, "error", err)
+		return
+	}
+
+	task.Structured = &address
+	task.Address = address.String()
+}
+
+// columns returns the task columns selected for the fetch options, with the address read from addressColumn.
+// The preferred_provider and language columns are only selected if they are read, so that they may not exist
+// otherwise.
+func (o FetchOptions) columns(addressColumn string) string {
+	columns := "task_id, " + addressColumn
+	if o.PreferredProvider {
+		columns += ", COALESCE(preferred_provider, '')"
+	}
+	if o.Language {
+		columns += ", COALESCE(language, '')"
+	}
+
+	return columns
+}
+
+// clauses returns the extra WHERE conditions and the ORDER BY expression for the fetch options,
+// along with the arguments of the conditions. Condition placeholders are numbered from firstArg.
+func (o FetchOptions) clauses(firstArg int) (string, string, []any) {
+	var (
+		filter string
+		args   []any
+	)
+
+	if o.Region != "" {
+		filter += `
+			AND region = $` + strconv.Itoa(firstArg+len(args))
+		args = append(args, o.Region)
+	}
+
+	if o.MinAttemptInterval > 0 {
+		filter += `
+			AND (last_attempt_at IS NULL OR last_attempt_at < NOW() - make_interval(secs => $` +
+			strconv.Itoa(firstArg+len(args)) + `))`
+		args = append(args, o.MinAttemptInterval.Seconds())
+	}
+
+	if o.SkipFlagged {
+		filter += `
+			AND geocoding_status IS DISTINCT FROM $` + strconv.Itoa(firstArg+len(args))
+		args = append(args, GeocodingStatusSkip)
+	}
+
+	order := "created_at ASC"
+	if o.ByPriority {
+		order = "priority DESC, created_at ASC"
+	}
+
+	return filter, order, args
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/models"
 	"github.com/UnknownOlympus/atlas/internal/repository"
@@ -19,11 +20,32 @@ const fetchTasksQuery = `
 		latitude IS NULL
 		AND is_closed = false
 		AND geocoding_attempts < 5
-		AND address IS NOT NULL AND address <> ''
+		AND address IS NOT NULL AND TRIM(address) <> ''
 	ORDER BY created_at ASC
 	LIMIT $1;
 `
 
+const claimTasksQuery = `
+	UPDATE public.tasks
+	SET
+		locked_by = $2,
+		locked_at = NOW()
+	WHERE task_id IN (
+		SELECT task_id
+		FROM public.tasks
+		WHERE
+			latitude IS NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND address IS NOT NULL AND TRIM(address) <> ''
+			AND (locked_at IS NULL OR locked_at < NOW() - make_interval(secs => $3))
+		ORDER BY created_at ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING task_id, address;
+`
+
 func TestFetchTasksForGeocoding(t *testing.T) {
 	t.Parallel()
 	logger := slog.Default()
@@ -121,26 +143,364 @@ func TestFetchTasksForGeocoding(t *testing.T) {
 	})
 }
 
-func TestUpdateTasCoordinates(t *testing.T) {
+func TestFetchTasksForGeocoding_Claim(t *testing.T) {
 	t.Parallel()
 	logger := slog.Default()
 	ctx := t.Context()
-	taskID := 123
-	coords := models.Coordinates{
-		Longitude: 123.123,
-		Latitude:  456.456,
+	limit := 10
+	owner := "atlas-0"
+	ttl := 30 * time.Minute
+
+	t.Run("error - claim tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithTaskClaim(owner, ttl))
+
+		mock.ExpectQuery(regexp.QuoteMeta(claimTasksQuery)).
+			WithArgs(limit, owner, ttl.Seconds()).
+			WillReturnError(assert.AnError)
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+
+		require.Nil(t, tasks)
+		require.ErrorContains(t, err, "failed to query active tasks")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - claim tasks with SKIP LOCKED", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithTaskClaim(owner, ttl))
+
+		mock.ExpectQuery(regexp.QuoteMeta(claimTasksQuery)).
+			WithArgs(limit, owner, ttl.Seconds()).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address"}).
+					AddRow(123, "valid address").
+					AddRow(124, "another address"),
+			)
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+
+		require.NoError(t, err)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, 123, tasks[0].ID)
+		assert.Equal(t, 124, tasks[1].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchTasksForGeocoding_Options(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	limit := 10
+
+	tests := []struct {
+		name  string
+		opts  []repository.Option
+		query string
+		args  []any
+	}{
+		{
+			name: "region filter",
+			opts: []repository.Option{repository.WithFetchOptions(repository.FetchOptions{Region: "Kyiv"})},
+			query: `
+				SELECT task_id, address
+				FROM public.tasks
+				WHERE
+					latitude IS NULL
+					AND is_closed = false
+					AND geocoding_attempts < 5
+					AND address IS NOT NULL AND TRIM(address) <> ''
+					AND region = $2
+				ORDER BY created_at ASC
+				LIMIT $1;
+			`,
+			args: []any{limit, "Kyiv"},
+		},
+		{
+			name: "priority ordering",
+			opts: []repository.Option{repository.WithFetchOptions(repository.FetchOptions{ByPriority: true})},
+			query: `
+				SELECT task_id, address
+				FROM public.tasks
+				WHERE
+					latitude IS NULL
+					AND is_closed = false
+					AND geocoding_attempts < 5
+					AND address IS NOT NULL AND TRIM(address) <> ''
+				ORDER BY priority DESC, created_at ASC
+				LIMIT $1;
+			`,
+			args: []any{limit},
+		},
+		{
+			name: "attempt cooldown",
+			opts: []repository.Option{
+				repository.WithFetchOptions(repository.FetchOptions{MinAttemptInterval: time.Hour}),
+			},
+			query: `
+				SELECT task_id, address
+				FROM public.tasks
+				WHERE
+					latitude IS NULL
+					AND is_closed = false
+					AND geocoding_attempts < 5
+					AND address IS NOT NULL AND TRIM(address) <> ''
+					AND (last_attempt_at IS NULL OR last_attempt_at < NOW() - make_interval(secs => $2))
+				ORDER BY created_at ASC
+				LIMIT $1;
+			`,
+			args: []any{limit, time.Hour.Seconds()},
+		},
+		{
+			name: "skipped tasks left out",
+			opts: []repository.Option{repository.WithFetchOptions(repository.FetchOptions{SkipFlagged: true})},
+			query: `
+				SELECT task_id, address
+				FROM public.tasks
+				WHERE
+					latitude IS NULL
+					AND is_closed = false
+					AND geocoding_attempts < 5
+					AND address IS NOT NULL AND TRIM(address) <> ''
+					AND geocoding_status IS DISTINCT FROM $2
+				ORDER BY created_at ASC
+				LIMIT $1;
+			`,
+			args: []any{limit, repository.GeocodingStatusSkip},
+		},
+		{
+			name: "region filter and attempt cooldown",
+			opts: []repository.Option{
+				repository.WithFetchOptions(repository.FetchOptions{Region: "Kyiv", MinAttemptInterval: time.Hour}),
+			},
+			query: `
+				SELECT task_id, address
+				FROM public.tasks
+				WHERE
+					latitude IS NULL
+					AND is_closed = false
+					AND geocoding_attempts < 5
+					AND address IS NOT NULL AND TRIM(address) <> ''
+					AND region = $2
+					AND (last_attempt_at IS NULL OR last_attempt_at < NOW() - make_interval(secs => $3))
+				ORDER BY created_at ASC
+				LIMIT $1;
+			`,
+			args: []any{limit, "Kyiv", time.Hour.Seconds()},
+		},
+		{
+			name: "region filter and priority ordering with task claim",
+			opts: []repository.Option{
+				repository.WithTaskClaim("atlas-0", time.Minute),
+				repository.WithFetchOptions(repository.FetchOptions{Region: "Kyiv", ByPriority: true}),
+			},
+			query: `
+				UPDATE public.tasks
+				SET
+					locked_by = $2,
+					locked_at = NOW()
+				WHERE task_id IN (
+					SELECT task_id
+					FROM public.tasks
+					WHERE
+						latitude IS NULL
+						AND is_closed = false
+						AND geocoding_attempts < 5
+						AND address IS NOT NULL AND TRIM(address) <> ''
+						AND (locked_at IS NULL OR locked_at < NOW() - make_interval(secs => $3))
+						AND region = $4
+					ORDER BY priority DESC, created_at ASC
+					LIMIT $1
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING task_id, address;
+			`,
+			args: []any{limit, "atlas-0", time.Minute.Seconds(), "Kyiv"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			defer mock.Close()
+
+			repo := repository.NewRepository(mock, logger, tt.opts...)
+
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).
+				WithArgs(tt.args...).
+				WillReturnRows(
+					pgxmock.NewRows([]string{"task_id", "address"}).AddRow(123, "valid address"),
+				)
+
+			tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+
+			require.NoError(t, err)
+			require.Len(t, tasks, 1)
+			assert.Equal(t, 123, tasks[0].ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
 	}
+}
+
+func TestFetchTasksForGeocoding_PreferredProvider(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	limit := 10
 	query := `
-		UPDATE tasks
-		SET
-			latitude = $1,
-			longitude = $2,
-			geocoding_error = NULL
+		SELECT task_id, address, COALESCE(preferred_provider, '')
+		FROM public.tasks
 		WHERE
-			task_id = $3;
+			latitude IS NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND address IS NOT NULL AND TRIM(address) <> ''
+		ORDER BY created_at ASC
+		LIMIT $1;
 	`
 
-	t.Run("error - update task coords", func(t *testing.T) {
+	t.Run("success - preferred provider of each task", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger,
+			repository.WithFetchOptions(repository.FetchOptions{PreferredProvider: true}))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(limit).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address", "preferred_provider"}).
+					AddRow(123, "valid address", "here").
+					AddRow(124, "another address", ""),
+			)
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{
+			{ID: 123, Address: "valid address", PreferredProvider: "here"},
+			{ID: 124, Address: "another address"},
+		}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - claimed tasks return the preferred provider", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger,
+			repository.WithTaskClaim("atlas-0", time.Minute),
+			repository.WithFetchOptions(repository.FetchOptions{PreferredProvider: true}))
+
+		mock.ExpectQuery(regexp.QuoteMeta("RETURNING task_id, address, COALESCE(preferred_provider, '');")).
+			WithArgs(limit, "atlas-0", time.Minute.Seconds()).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address", "preferred_provider"}).
+					AddRow(123, "valid address", "nominatim"),
+			)
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{{ID: 123, Address: "valid address", PreferredProvider: "nominatim"}}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchTasksForGeocoding_Language(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	limit := 10
+
+	t.Run("success - language of each task", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger,
+			repository.WithFetchOptions(repository.FetchOptions{Language: true}))
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT task_id, address, COALESCE(language, '')")).
+			WithArgs(limit).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address", "language"}).
+					AddRow(123, "valid address", "ru").
+					AddRow(124, "another address", ""),
+			)
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{
+			{ID: 123, Address: "valid address", Language: "ru"},
+			{ID: 124, Address: "another address"},
+		}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - language follows the preferred provider", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger,
+			repository.WithFetchOptions(repository.FetchOptions{PreferredProvider: true, Language: true}))
+
+		mock.ExpectQuery(regexp.QuoteMeta(
+			"SELECT task_id, address, COALESCE(preferred_provider, ''), COALESCE(language, '')")).
+			WithArgs(limit).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address", "preferred_provider", "language"}).
+					AddRow(123, "valid address", "here", "ru,uk"),
+			)
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{
+			{ID: 123, Address: "valid address", PreferredProvider: "here", Language: "ru,uk"},
+		}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFetchStructuredTasksForGeocoding(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	limit := 10
+	query := `
+		SELECT task_id, address_json
+		FROM public.tasks
+		WHERE
+			latitude IS NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND address_json IS NOT NULL
+		ORDER BY created_at ASC
+		LIMIT $1;
+	`
+
+	t.Run("error - query tasks", func(t *testing.T) {
 		t.Parallel()
 		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
@@ -148,18 +508,18 @@ func TestUpdateTasCoordinates(t *testing.T) {
 
 		repo := repository.NewRepository(mock, logger)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(coords.Latitude, coords.Longitude, taskID).
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(limit).
 			WillReturnError(assert.AnError)
 
-		err = repo.UpdateTaskCoordinates(ctx, taskID, coords)
+		tasks, err := repo.FetchStructuredTasksForGeocoding(ctx, limit)
 
-		require.Error(t, err)
-		require.ErrorContains(t, err, "failed to update task coordinates")
+		require.Nil(t, tasks)
 		require.ErrorIs(t, err, assert.AnError)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("success - update task coords", func(t *testing.T) {
+	t.Run("success - JSONB payload scanned into the structured address", func(t *testing.T) {
 		t.Parallel()
 		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
@@ -167,30 +527,127 @@ func TestUpdateTasCoordinates(t *testing.T) {
 
 		repo := repository.NewRepository(mock, logger)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(coords.Latitude, coords.Longitude, taskID).
-			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(limit).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address_json"}).
+					AddRow(123, []byte(`{"city": "с. Грабовець", "street": "вул. Польова", "house_number": "3"}`)).
+					AddRow(124, []byte(`{"postal_code": "01001", "city": "м. Київ", "unknown": true}`)),
+			)
 
-		err = repo.UpdateTaskCoordinates(ctx, taskID, coords)
+		tasks, err := repo.FetchStructuredTasksForGeocoding(ctx, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{
+			{
+				ID:      123,
+				Address: "с. Грабовець, вул. Польова, 3",
+				Structured: &models.StructuredAddress{
+					City: "с. Грабовець", Street: "вул. Польова", HouseNumber: "3",
+				},
+			},
+			{
+				ID:         124,
+				Address:    "01001, м. Київ",
+				Structured: &models.StructuredAddress{PostalCode: "01001", City: "м. Київ"},
+			},
+		}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - invalid payload leaves the address empty", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(limit).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address_json"}).
+					AddRow(123, []byte(`"с. Грабовець, вул. Польова, 3"`)),
+			)
+
+		tasks, err := repo.FetchStructuredTasksForGeocoding(ctx, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{{ID: 123}}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - claim tasks with a preferred provider", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		ttl := 30 * time.Minute
+		repo := repository.NewRepository(mock, logger,
+			repository.WithTaskClaim("atlas-0", ttl),
+			repository.WithFetchOptions(repository.FetchOptions{PreferredProvider: true}))
+
+		claimQuery := `
+			UPDATE public.tasks
+			SET
+				locked_by = $2,
+				locked_at = NOW()
+			WHERE task_id IN (
+				SELECT task_id
+				FROM public.tasks
+				WHERE
+					latitude IS NULL
+					AND is_closed = false
+					AND geocoding_attempts < 5
+					AND address_json IS NOT NULL
+					AND (locked_at IS NULL OR locked_at < NOW() - make_interval(secs => $3))
+				ORDER BY created_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING task_id, address_json, COALESCE(preferred_provider, '');
+		`
+		mock.ExpectQuery(regexp.QuoteMeta(claimQuery)).
+			WithArgs(limit, "atlas-0", ttl.Seconds()).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "address_json", "preferred_provider"}).
+					AddRow(123, []byte(`{"city": "м. Київ", "street": "вул. Хрещатик", "house_number": "1"}`), "google"),
+			)
+
+		tasks, err := repo.FetchStructuredTasksForGeocoding(ctx, limit)
 
 		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "м. Київ, вул. Хрещатик, 1", tasks[0].Address)
+		assert.Equal(t, "google", tasks[0].PreferredProvider)
+		require.NotNil(t, tasks[0].Structured)
+		assert.Equal(t, "вул. Хрещатик", tasks[0].Structured.Street)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
-func TestIncrementFailureCount(t *testing.T) {
+func TestFetchStaleTasks(t *testing.T) {
 	t.Parallel()
 	logger := slog.Default()
 	ctx := t.Context()
-	taskID := 123
+	limit := 10
+	olderThan := 30 * 24 * time.Hour
 	query := `
-		UPDATE tasks
-		SET
-			geocoding_attempts = geocoding_attempts + 1,
-			geocoding_error = $1
-		WHERE task_id = $2;
+		SELECT task_id, address
+		FROM public.tasks
+		WHERE
+			latitude IS NOT NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND address IS NOT NULL AND TRIM(address) <> ''
+			AND geocoded_at < NOW() - make_interval(secs => $2)
+		ORDER BY geocoded_at ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED;
 	`
 
-	t.Run("error - increment failure count", func(t *testing.T) {
+	t.Run("error - query stale tasks", func(t *testing.T) {
 		t.Parallel()
 		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
@@ -198,18 +655,19 @@ func TestIncrementFailureCount(t *testing.T) {
 
 		repo := repository.NewRepository(mock, logger)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("error", taskID).
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(limit, olderThan.Seconds()).
 			WillReturnError(assert.AnError)
 
-		err = repo.IncrementFailureCount(ctx, taskID, "error")
+		tasks, err := repo.FetchStaleTasks(ctx, olderThan, limit)
 
-		require.Error(t, err)
-		require.ErrorContains(t, err, "failed to update geocoding error and number of attempts")
+		require.Nil(t, tasks)
+		require.ErrorContains(t, err, "failed to query stale tasks")
 		require.ErrorIs(t, err, assert.AnError)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("success - increment failure count", func(t *testing.T) {
+	t.Run("success - stale tasks", func(t *testing.T) {
 		t.Parallel()
 		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
@@ -217,12 +675,1434 @@ func TestIncrementFailureCount(t *testing.T) {
 
 		repo := repository.NewRepository(mock, logger)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("error", taskID).
-			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(limit, olderThan.Seconds()).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "address"}).
+				AddRow(1, "м. Київ, вул. Хрещатик, 1").
+				AddRow(2, "с. Грабовець, вул. Польова, 3"))
+
+		tasks, err := repo.FetchStaleTasks(ctx, olderThan, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{
+			{ID: 1, Address: "м. Київ, вул. Хрещатик, 1"},
+			{ID: 2, Address: "с. Грабовець, вул. Польова, 3"},
+		}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - fetch options", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		// The priority order of the options doesn't apply, the stalest tasks come first
+		repo := repository.NewRepository(mock, logger, repository.WithFetchOptions(repository.FetchOptions{
+			Region:             "kyiv",
+			ByPriority:         true,
+			MinAttemptInterval: time.Hour,
+			PreferredProvider:  true,
+		}))
+		optionsQuery := `
+			SELECT task_id, address, COALESCE(preferred_provider, '')
+			FROM public.tasks
+			WHERE
+				latitude IS NOT NULL
+				AND is_closed = false
+				AND geocoding_attempts < 5
+				AND address IS NOT NULL AND TRIM(address) <> ''
+				AND geocoded_at < NOW() - make_interval(secs => $2)
+				AND region = $3
+				AND (last_attempt_at IS NULL OR last_attempt_at < NOW() - make_interval(secs => $4))
+			ORDER BY geocoded_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED;
+		`
+
+		mock.ExpectQuery(regexp.QuoteMeta(optionsQuery)).
+			WithArgs(limit, olderThan.Seconds(), "kyiv", time.Hour.Seconds()).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "address", "preferred_provider"}).
+				AddRow(1, "м. Київ, вул. Хрещатик, 1", "google"))
+
+		tasks, err := repo.FetchStaleTasks(ctx, olderThan, limit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Task{{ID: 1, Address: "м. Київ, вул. Хрещатик, 1", PreferredProvider: "google"}}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPostponeStaleRefresh(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 7
+	query := `
+		UPDATE public.tasks
+		SET geocoded_at = NOW()
+		WHERE task_id = $1;
+	`
+
+	t.Run("error - postpone refresh", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(taskID).WillReturnError(assert.AnError)
 
-		err = repo.IncrementFailureCount(ctx, taskID, "error")
+		err = repo.PostponeStaleRefresh(ctx, taskID)
+
+		require.ErrorContains(t, err, "failed to postpone stale coordinates refresh")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 
+	t.Run("success - geocoded_at advanced", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(taskID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.PostponeStaleRefresh(ctx, taskID))
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestUpdateTasCoordinates(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 123
+	coords := models.Coordinates{
+		Longitude: 123.123,
+		Latitude:  456.456,
+		Precision: models.PrecisionRooftop,
+	}
+	query := `
+		UPDATE tasks
+		SET
+			latitude = $1,
+			longitude = $2,
+			geocoding_precision = NULLIF($3, ''),
+			geocoding_error = NULL,
+			geocoding_error_code = NULL
+		WHERE
+			task_id = $4;
+	`
+
+	t.Run("error - update task coords", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(coords.Latitude, coords.Longitude, "rooftop", taskID).
+			WillReturnError(assert.AnError)
+
+		err = repo.UpdateTaskCoordinates(ctx, taskID, coords)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to update task coordinates")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - update task coords", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(coords.Latitude, coords.Longitude, "rooftop", taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskCoordinates(ctx, taskID, coords)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - release task claim", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithTaskClaim("atlas-0", time.Minute))
+		claimQuery := `
+			UPDATE tasks
+			SET
+				latitude = $1,
+				longitude = $2,
+				geocoding_precision = NULLIF($3, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				locked_by = NULL,
+				locked_at = NULL
+			WHERE
+				task_id = $4;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(claimQuery)).WithArgs(coords.Latitude, coords.Longitude, "rooftop", taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskCoordinates(ctx, taskID, coords)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store geohash", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeohash(7),
+			repository.WithTaskClaim("atlas-0", time.Minute))
+		kyiv := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop}
+		geohashQuery := `
+			UPDATE tasks
+			SET
+				latitude = $1,
+				longitude = $2,
+				geocoding_precision = NULLIF($3, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geohash = $5,
+				locked_by = NULL,
+				locked_at = NULL
+			WHERE
+				task_id = $4;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(geohashQuery)).
+			WithArgs(kyiv.Latitude, kyiv.Longitude, "rooftop", taskID, "u8vxn84").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskCoordinates(ctx, taskID, kyiv)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store geometry", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeometryColumn("location"))
+		kyiv := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop}
+		geometryQuery := `
+			UPDATE tasks
+			SET
+				latitude = $1,
+				longitude = $2,
+				geocoding_precision = NULLIF($3, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				location = ST_SetSRID(ST_MakePoint($2, $1), 4326)
+			WHERE
+				task_id = $4;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(geometryQuery)).
+			WithArgs(kyiv.Latitude, kyiv.Longitude, "rooftop", taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskCoordinates(ctx, taskID, kyiv)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - record geocoded at", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeocodedAt(true))
+		geocodedAtQuery := `
+			UPDATE tasks
+			SET
+				latitude = $1,
+				longitude = $2,
+				geocoding_precision = NULLIF($3, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geocoded_at = NOW()
+			WHERE
+				task_id = $4;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(geocodedAtQuery)).WithArgs(coords.Latitude, coords.Longitude, "rooftop", taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskCoordinates(ctx, taskID, coords)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateTaskResult(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 123
+	result := models.GeocodeResult{
+		Coordinates:      models.Coordinates{Longitude: -122.08, Latitude: 37.42, Precision: models.PrecisionRooftop},
+		PlaceID:          "ChIJ2eUgeAK6j4ARbn5u_wAGqWA",
+		FormattedAddress: "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+	}
+	query := `
+		UPDATE tasks
+		SET
+			latitude = $1,
+			longitude = $2,
+			geocoding_precision = NULLIF($3, ''),
+			geocoding_place_id = NULLIF($4, ''),
+			geocoding_address = NULLIF($5, ''),
+			geocoding_error = NULL,
+			geocoding_error_code = NULL
+		WHERE
+			task_id = $6;
+	`
+
+	t.Run("error - update task result", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(result.Latitude, result.Longitude, "rooftop", result.PlaceID, result.FormattedAddress, taskID).
+			WillReturnError(assert.AnError)
+
+		err = repo.UpdateTaskResult(ctx, taskID, result)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to update task geocoding result")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - update task result", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(result.Latitude, result.Longitude, "rooftop", result.PlaceID, result.FormattedAddress, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskResult(ctx, taskID, result)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - matched address without a place identifier", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+		matched := models.GeocodeResult{
+			Coordinates:      models.Coordinates{Longitude: 30.5234, Latitude: 50.4501},
+			FormattedAddress: "вул. Хрещатик, 1, Київ",
+		}
+
+		// The empty place identifier and precision are stored as NULL by the query
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(matched.Latitude, matched.Longitude, "", "", "вул. Хрещатик, 1, Київ", taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskResult(ctx, taskID, matched)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - record geocoded at", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeocodedAt(true),
+			repository.WithTaskClaim("atlas-0", time.Minute))
+		geocodedAtQuery := `
+			UPDATE tasks
+			SET
+				latitude = $1,
+				longitude = $2,
+				geocoding_precision = NULLIF($3, ''),
+				geocoding_place_id = NULLIF($4, ''),
+				geocoding_address = NULLIF($5, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geocoded_at = NOW(),
+				locked_by = NULL,
+				locked_at = NULL
+			WHERE
+				task_id = $6;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(geocodedAtQuery)).
+			WithArgs(result.Latitude, result.Longitude, "rooftop", result.PlaceID, result.FormattedAddress, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskResult(ctx, taskID, result)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store geohash", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeohash(9))
+		geohashQuery := `
+			UPDATE tasks
+			SET
+				latitude = $1,
+				longitude = $2,
+				geocoding_precision = NULLIF($3, ''),
+				geocoding_place_id = NULLIF($4, ''),
+				geocoding_address = NULLIF($5, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geohash = $7
+			WHERE
+				task_id = $6;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(geohashQuery)).
+			WithArgs(result.Latitude, result.Longitude, "rooftop", result.PlaceID, result.FormattedAddress, taskID,
+				"9q9hyh053").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskResult(ctx, taskID, result)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store geohash and geometry", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeohash(9),
+			repository.WithGeometryColumn("geom"))
+		geometryQuery := `
+			UPDATE tasks
+			SET
+				latitude = $1,
+				longitude = $2,
+				geocoding_precision = NULLIF($3, ''),
+				geocoding_place_id = NULLIF($4, ''),
+				geocoding_address = NULLIF($5, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geohash = $7,
+				geom = ST_SetSRID(ST_MakePoint($2, $1), 4326)
+			WHERE
+				task_id = $6;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(geometryQuery)).
+			WithArgs(result.Latitude, result.Longitude, "rooftop", result.PlaceID, result.FormattedAddress, taskID,
+				"9q9hyh053").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskResult(ctx, taskID, result)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateTaskGeocodeResult(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 123
+	cleanedAddress := "с. Грабовець, вул. Польова, 3"
+	coords := models.Coordinates{Latitude: 49.95, Longitude: 24.95, Precision: models.PrecisionStreet}
+	result := models.GeocodeResult{Coordinates: coords}
+	query := `
+		UPDATE tasks
+		SET
+			address = $1,
+			latitude = $2,
+			longitude = $3,
+			geocoding_precision = NULLIF($4, ''),
+			geocoding_error = NULL,
+			geocoding_error_code = NULL
+		WHERE
+			task_id = $5;
+	`
+
+	t.Run("error - update task address and coords", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(cleanedAddress, coords.Latitude, coords.Longitude, "street", taskID).
+			WillReturnError(assert.AnError)
+
+		err = repo.UpdateTaskGeocodeResult(ctx, taskID, cleanedAddress, result)
+
+		require.ErrorContains(t, err, "failed to update task address and coordinates")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - update task address and coords", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(cleanedAddress, coords.Latitude, coords.Longitude, "street", taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskGeocodeResult(ctx, taskID, cleanedAddress, result)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store geohash and release task claim", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeohash(5),
+			repository.WithTaskClaim("atlas-0", time.Minute))
+		claimQuery := `
+			UPDATE tasks
+			SET
+				address = $1,
+				latitude = $2,
+				longitude = $3,
+				geocoding_precision = NULLIF($4, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geohash = $6,
+				locked_by = NULL,
+				locked_at = NULL
+			WHERE
+				task_id = $5;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(claimQuery)).
+			WithArgs(cleanedAddress, coords.Latitude, coords.Longitude, "street", taskID, coords.Geohash(5)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskGeocodeResult(ctx, taskID, cleanedAddress, result)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store geometry", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeometryColumn("location"))
+		geometryQuery := `
+			UPDATE tasks
+			SET
+				address = $1,
+				latitude = $2,
+				longitude = $3,
+				geocoding_precision = NULLIF($4, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				location = ST_SetSRID(ST_MakePoint($3, $2), 4326)
+			WHERE
+				task_id = $5;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(geometryQuery)).
+			WithArgs(cleanedAddress, coords.Latitude, coords.Longitude, "street", taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskGeocodeResult(ctx, taskID, cleanedAddress, result)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - store place metadata", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeohash(5))
+		placeQuery := `
+			UPDATE tasks
+			SET
+				address = $1,
+				latitude = $2,
+				longitude = $3,
+				geocoding_precision = NULLIF($4, ''),
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geocoding_place_id = NULLIF($6, ''),
+				geocoding_address = NULLIF($7, ''),
+				geohash = $8
+			WHERE
+				task_id = $5;
+		`
+		placeResult := models.GeocodeResult{Coordinates: coords, PlaceID: "ChIJ2Uq3Gm3OOkcRuXzvqJ4QG3s"}
+
+		mock.ExpectExec(regexp.QuoteMeta(placeQuery)).
+			WithArgs(cleanedAddress, coords.Latitude, coords.Longitude, "street", taskID,
+				"ChIJ2Uq3Gm3OOkcRuXzvqJ4QG3s", "", coords.Geohash(5)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskGeocodeResult(ctx, taskID, cleanedAddress, placeResult)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateTaskGeometry(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 123
+	coords := models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+	query := `
+		UPDATE tasks
+		SET
+			location = ST_SetSRID(ST_MakePoint($2, $1), 4326)
+		WHERE
+			task_id = $3;
+	`
+
+	t.Run("error - geometry column not configured", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		err = repo.UpdateTaskGeometry(ctx, taskID, coords)
+
+		require.ErrorIs(t, err, repository.ErrGeometryDisabled)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update task geometry", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeometryColumn("location"))
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(coords.Latitude, coords.Longitude, taskID).
+			WillReturnError(assert.AnError)
+
+		err = repo.UpdateTaskGeometry(ctx, taskID, coords)
+
+		require.ErrorContains(t, err, "failed to update task geometry")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - longitude first", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithGeometryColumn("location"))
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(coords.Latitude, coords.Longitude, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTaskGeometry(ctx, taskID, coords)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateTaskWhat3Words(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskIDs := []int{1, 2}
+	words := "filled.count.soap"
+	query := `
+		UPDATE tasks
+		SET w3w = $1
+		WHERE task_id = ANY($2);
+	`
+
+	t.Run("error - update task what3words address", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(words, taskIDs).
+			WillReturnError(assert.AnError)
+
+		err = repo.UpdateTaskWhat3Words(ctx, taskIDs, words)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to update task what3words address")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - update task what3words address", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(words, taskIDs).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+		err = repo.UpdateTaskWhat3Words(ctx, taskIDs, words)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMarkInvalidAddress(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 123
+	reason := models.GeocodeError{Code: "invalid_address", Message: "address is blank"}
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_attempts = GREATEST(geocoding_attempts, 5),
+			geocoding_error = $1,
+			geocoding_error_code = $2
+		WHERE task_id = $3;
+	`
+
+	t.Run("error - mark invalid address", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(reason.Message, reason.Code, taskID).
+			WillReturnError(assert.AnError)
+
+		err = repo.MarkInvalidAddress(ctx, taskID, reason)
+
+		require.ErrorContains(t, err, "failed to mark task address as invalid")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - mark invalid address", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(reason.Message, reason.Code, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.MarkInvalidAddress(ctx, taskID, reason)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - release task claim", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithTaskClaim("atlas-0", time.Minute))
+		claimQuery := `
+			UPDATE tasks
+			SET
+				geocoding_attempts = GREATEST(geocoding_attempts, 5),
+				geocoding_error = $1,
+				geocoding_error_code = $2,
+				locked_by = NULL,
+				locked_at = NULL
+			WHERE task_id = $3;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(claimQuery)).WithArgs(reason.Message, reason.Code, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.MarkInvalidAddress(ctx, taskID, reason)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIncrementFailureCount(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 123
+	failure := models.GeocodeError{Code: "timeout", Message: "request timed out"}
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_attempts = geocoding_attempts + 1,
+			geocoding_error = $1,
+			geocoding_error_code = $2
+		WHERE task_id = $3;
+	`
+
+	t.Run("error - increment failure count", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(failure.Message, failure.Code, taskID).
+			WillReturnError(assert.AnError)
+
+		err = repo.IncrementFailureCount(ctx, taskID, failure)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to update geocoding error and number of attempts")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - increment failure count", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(failure.Message, failure.Code, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.IncrementFailureCount(ctx, taskID, failure)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - release task claim", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithTaskClaim("atlas-0", time.Minute))
+		claimQuery := `
+			UPDATE tasks
+			SET
+				geocoding_attempts = geocoding_attempts + 1,
+				geocoding_error = $1,
+				geocoding_error_code = $2,
+				locked_by = NULL,
+				locked_at = NULL
+			WHERE task_id = $3;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(claimQuery)).WithArgs(failure.Message, failure.Code, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.IncrementFailureCount(ctx, taskID, failure)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - record attempt time", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger,
+			repository.WithFetchOptions(repository.FetchOptions{MinAttemptInterval: time.Hour}))
+		cooldownQuery := `
+			UPDATE tasks
+			SET
+				geocoding_attempts = geocoding_attempts + 1,
+				geocoding_error = $1,
+				geocoding_error_code = $2,
+				last_attempt_at = NOW()
+			WHERE task_id = $3;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(cooldownQuery)).WithArgs(failure.Message, failure.Code, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.IncrementFailureCount(ctx, taskID, failure)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestResetGeocodingAttempts(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskIDs := []int{1, 2, 3}
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_attempts = 0,
+			geocoding_error = NULL,
+			geocoding_error_code = NULL
+		WHERE task_id = ANY($1);
+	`
+
+	t.Run("error - reset geocoding attempts", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(taskIDs).
+			WillReturnError(assert.AnError)
+
+		reset, err := repo.ResetGeocodingAttempts(ctx, taskIDs)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to reset geocoding attempts")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Zero(t, reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - reset geocoding attempts", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(taskIDs).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+		reset, err := repo.ResetGeocodingAttempts(ctx, taskIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - clear attempt cooldown", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger,
+			repository.WithFetchOptions(repository.FetchOptions{MinAttemptInterval: time.Hour}))
+		cooldownQuery := `
+			UPDATE tasks
+			SET
+				geocoding_attempts = 0,
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				last_attempt_at = NULL
+			WHERE task_id = ANY($1);
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(cooldownQuery)).WithArgs(taskIDs).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+		reset, err := repo.ResetGeocodingAttempts(ctx, taskIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - clear not found status", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithNotFoundStatus(true))
+		notFoundQuery := `
+			UPDATE tasks
+			SET
+				geocoding_attempts = 0,
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geocoding_status = NULLIF(geocoding_status, $2)
+			WHERE task_id = ANY($1);
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(notFoundQuery)).WithArgs(taskIDs, repository.GeocodingStatusNotFound).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+		reset, err := repo.ResetGeocodingAttempts(ctx, taskIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestResetFailedGeocodingAttempts(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_attempts = 0,
+			geocoding_error = NULL,
+			geocoding_error_code = NULL
+		WHERE
+			latitude IS NULL
+			AND geocoding_attempts >= 5;
+	`
+
+	t.Run("error - reset failed geocoding attempts", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnError(assert.AnError)
+
+		reset, err := repo.ResetFailedGeocodingAttempts(ctx)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to reset failed geocoding attempts")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Zero(t, reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - reset failed geocoding attempts", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 7))
+
+		reset, err := repo.ResetFailedGeocodingAttempts(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - clear not found status", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithNotFoundStatus(true))
+		notFoundQuery := `
+			UPDATE tasks
+			SET
+				geocoding_attempts = 0,
+				geocoding_error = NULL,
+				geocoding_error_code = NULL,
+				geocoding_status = NULLIF(geocoding_status, $1)
+			WHERE
+				latitude IS NULL
+				AND geocoding_attempts >= 5;
+		`
+
+		mock.ExpectExec(regexp.QuoteMeta(notFoundQuery)).WithArgs(repository.GeocodingStatusNotFound).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 7))
+
+		reset, err := repo.ResetFailedGeocodingAttempts(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMarkTaskNotFound(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskID := 123
+	failure := models.GeocodeError{Code: "empty_response", Message: "nominatim API returned empty response"}
+	query := `
+		UPDATE tasks
+		SET
+			geocoding_status = $1,
+			geocoding_attempts = GREATEST(geocoding_attempts + 1, 5),
+			geocoding_error = $2,
+			geocoding_error_code = $3
+		WHERE task_id = $4;
+	`
+
+	t.Run("error - mark task not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(repository.GeocodingStatusNotFound, failure.Message, failure.Code, taskID).
+			WillReturnError(assert.AnError)
+
+		err = repo.MarkTaskNotFound(ctx, taskID, failure)
+
+		require.ErrorContains(t, err, "failed to mark task as not found")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - mark task not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(repository.GeocodingStatusNotFound, failure.Message, failure.Code, taskID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.MarkTaskNotFound(ctx, taskID, failure)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSkipTaskGeocoding(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskIDs := []int{1, 2, 3}
+	query := `
+		UPDATE tasks
+		SET geocoding_status = $1
+		WHERE task_id = ANY($2);
+	`
+
+	t.Run("error - skip task geocoding", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(repository.GeocodingStatusSkip, taskIDs).
+			WillReturnError(assert.AnError)
+
+		skipped, err := repo.SkipTaskGeocoding(ctx, taskIDs)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to skip task geocoding")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Zero(t, skipped)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - skip task geocoding", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(repository.GeocodingStatusSkip, taskIDs).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+
+		skipped, err := repo.SkipTaskGeocoding(ctx, taskIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), skipped)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReleaseTaskClaims(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	taskIDs := []int{1, 2, 3}
+	query := `
+		UPDATE public.tasks
+		SET
+			locked_by = NULL,
+			locked_at = NULL
+		WHERE task_id = ANY($1) AND locked_by = $2;
+	`
+
+	t.Run("error - release task claims", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithTaskClaim("atlas-0", time.Minute))
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(taskIDs, "atlas-0").WillReturnError(assert.AnError)
+
+		released, err := repo.ReleaseTaskClaims(ctx, taskIDs)
+
+		require.ErrorContains(t, err, "failed to release task claims")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Zero(t, released)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - claims of the instance released", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithTaskClaim("atlas-0", time.Minute))
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(taskIDs, "atlas-0").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+		released, err := repo.ReleaseTaskClaims(ctx, taskIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), released)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - no query without task claiming", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		released, err := repo.ReleaseTaskClaims(ctx, taskIDs)
+
+		require.NoError(t, err)
+		assert.Zero(t, released)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCountPendingTasks(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	query := `
+		SELECT COUNT(*)
+		FROM public.tasks
+		WHERE
+			latitude IS NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND address IS NOT NULL AND TRIM(address) <> '';
+	`
+
+	t.Run("error - count pending tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(assert.AnError)
+
+		count, err := repo.CountPendingTasks(ctx)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to count pending tasks")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Zero(t, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - count pending tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(42))
+
+		count, err := repo.CountPendingTasks(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 42, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - skipped tasks left out", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger,
+			repository.WithFetchOptions(repository.FetchOptions{SkipFlagged: true}))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT COUNT(*)
+			FROM public.tasks
+			WHERE
+				latitude IS NULL
+				AND is_closed = false
+				AND geocoding_attempts < 5
+				AND address IS NOT NULL AND TRIM(address) <> ''
+				AND geocoding_status IS DISTINCT FROM $1;
+		`)).WithArgs(repository.GeocodingStatusSkip).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(40))
+
+		count, err := repo.CountPendingTasks(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 40, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - region and cooldown applied", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock, logger, repository.WithFetchOptions(repository.FetchOptions{
+			Region:             "kyiv",
+			MinAttemptInterval: 10 * time.Minute,
+			SkipFlagged:        true,
+		}))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT COUNT(*)
+			FROM public.tasks
+			WHERE
+				latitude IS NULL
+				AND is_closed = false
+				AND geocoding_attempts < 5
+				AND address IS NOT NULL AND TRIM(address) <> ''
+				AND region = $1
+				AND (last_attempt_at IS NULL OR last_attempt_at < NOW() - make_interval(secs => $2))
+				AND geocoding_status IS DISTINCT FROM $3;
+		`)).WithArgs("kyiv", float64(600), repository.GeocodingStatusSkip).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(7))
+
+		count, err := repo.CountPendingTasks(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 7, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCountPendingStructuredTasks(t *testing.T) {
+	t.Parallel()
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewRepository(mock, slog.Default(),
+		repository.WithFetchOptions(repository.FetchOptions{Region: "lviv", MinAttemptInterval: time.Minute}))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		SELECT COUNT(*)
+		FROM public.tasks
+		WHERE
+			latitude IS NULL
+			AND is_closed = false
+			AND geocoding_attempts < 5
+			AND address_json IS NOT NULL
+			AND region = $1
+			AND (last_attempt_at IS NULL OR last_attempt_at < NOW() - make_interval(secs => $2));
+	`)).WithArgs("lviv", float64(60)).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := repo.CountPendingStructuredTasks(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// DefaultReplicaMaxLag is how long tasks written through the repository are kept from being fetched
+// from the read replica again, unless set with WithReadDatabase.
+const DefaultReplicaMaxLag = time.Minute
+
+// WithReadDatabase makes FetchTasksForGeocoding and CountPendingTasks read from db, typically a read replica,
+// while every write still goes to the database the repository was created with. FetchTasksForGeocoding keeps
+// using the primary database if task claiming is enabled (see WithTaskClaim), since it claims the tasks it reads.
+//
+// A replica lagging behind the primary could return tasks that were just geocoded, so the tasks updated through
+// the repository are left out of the fetched ones for maxLag after their update. Zero or less uses
+// DefaultReplicaMaxLag.
+func WithReadDatabase(db Database, maxLag time.Duration) Option {
+	return func(r *Repository) {
+		if maxLag <= 0 {
+			maxLag = DefaultReplicaMaxLag
+		}
+		r.readDB = db
+		r.written = &recentWrites{maxLag: maxLag, at: make(map[int]time.Time)}
+	}
+}
+
+// ReadDatabase returns the database tasks are fetched from, the read database if one is set
+// with WithReadDatabase, and the write database otherwise.
+func (r *Repository) ReadDatabase() Database {
+	if r.readDB == nil {
+		return r.db
+	}
+
+	return r.readDB
+}
+
+// WriteDatabase returns the primary database, which every write goes to.
+func (r *Repository) WriteDatabase() Database {
+	return r.db
+}
+
+// fetchDatabase returns the database FetchTasksForGeocoding queries: the primary database if it claims
+// the fetched tasks, and the read database otherwise.
+func (r *Repository) fetchDatabase() Database {
+	if r.claimOwner != "" {
+		return r.db
+	}
+
+	return r.ReadDatabase()
+}
+
+// recentWrites remembers when tasks were last updated, so that tasks a lagging replica still reports
+// as pending are not fetched again.
+type recentWrites struct {
+	maxLag time.Duration
+
+	mu sync.Mutex        // mu guards at
+	at map[int]time.Time // at holds the time of the last update of each recently written task
+}
+
+// add records that the task was just updated. It does nothing on a nil recentWrites.
+func (w *recentWrites) add(taskID int) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.at[taskID] = time.Now()
+}
+
+// remove forgets the tasks, so that they can be fetched again right away. It does nothing on a nil recentWrites.
+func (w *recentWrites) remove(taskIDs []int) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range taskIDs {
+		delete(w.at, id)
+	}
+}
+
+// filter returns the tasks that weren't updated within the maximum lag, and forgets the tasks updated
+// before it. It returns tasks as is on a nil recentWrites.
+func (w *recentWrites) filter(tasks []models.Task) []models.Task {
+	if w == nil {
+		return tasks
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cutoff := time.Now().Add(-w.maxLag)
+	for id, at := range w.at {
+		if at.Before(cutoff) {
+			delete(w.at, id)
+		}
+	}
+
+	fresh := tasks[:0]
+	for _, task := range tasks {
+		if _, ok := w.at[task.ID]; !ok {
+			fresh = append(fresh, task)
+		}
+	}
+
+	return fresh
+}
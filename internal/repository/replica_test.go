@@ -0,0 +1,159 @@
+package repository_test
+
+import (
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPools returns a mock primary and a mock replica pool, closed when the test ends.
+func newPools(t *testing.T) (pgxmock.PgxPoolIface, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	primary, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(primary.Close)
+	replica, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(replica.Close)
+
+	return primary, replica
+}
+
+func TestRepository_Databases(t *testing.T) {
+	t.Parallel()
+	primary, replica := newPools(t)
+
+	repo := repository.NewRepository(primary, slog.Default())
+	assert.Equal(t, primary, repo.ReadDatabase(), "reads default to the primary")
+	assert.Equal(t, primary, repo.WriteDatabase())
+
+	repo = repository.NewRepository(primary, slog.Default(), repository.WithReadDatabase(replica, time.Minute))
+	assert.Equal(t, replica, repo.ReadDatabase())
+	assert.Equal(t, primary, repo.WriteDatabase())
+}
+
+func TestFetchTasksForGeocoding_ReadDatabase(t *testing.T) {
+	t.Parallel()
+	logger := slog.Default()
+	ctx := t.Context()
+	limit := 10
+	coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("success - reads from the replica and writes to the primary", func(t *testing.T) {
+		t.Parallel()
+		primary, replica := newPools(t)
+		repo := repository.NewRepository(primary, logger, repository.WithReadDatabase(replica, time.Minute))
+
+		replica.ExpectQuery(regexp.QuoteMeta(fetchTasksQuery)).WithArgs(limit).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "address"}).
+				AddRow(1, "м. Київ").
+				AddRow(2, "м. Львів"))
+		primary.ExpectExec("UPDATE tasks").WithArgs(coords.Latitude, coords.Longitude, "", 1).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		// The replica hasn't caught up with the update of task 1 yet
+		replica.ExpectQuery(regexp.QuoteMeta(fetchTasksQuery)).WithArgs(limit).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "address"}).
+				AddRow(1, "м. Київ").
+				AddRow(2, "м. Львів"))
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+		require.NoError(t, err)
+		require.Len(t, tasks, 2)
+		require.NoError(t, repo.UpdateTaskCoordinates(ctx, 1, coords))
+		tasks, err = repo.FetchTasksForGeocoding(ctx, limit)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Task{{ID: 2, Address: "м. Львів"}}, tasks, "a just written task must not be refetched")
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+
+	t.Run("success - failed and reset tasks", func(t *testing.T) {
+		t.Parallel()
+		primary, replica := newPools(t)
+		repo := repository.NewRepository(primary, logger, repository.WithReadDatabase(replica, time.Minute))
+
+		primary.ExpectExec("UPDATE tasks").WithArgs("failed", "other", 1).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		primary.ExpectExec("UPDATE tasks").WithArgs("blank", "invalid", 2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		primary.ExpectExec("UPDATE tasks").WithArgs([]int{2}).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		replica.ExpectQuery(regexp.QuoteMeta(fetchTasksQuery)).WithArgs(limit).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "address"}).
+				AddRow(1, "м. Київ").
+				AddRow(2, "м. Львів").
+				AddRow(3, "м. Одеса"))
+
+		require.NoError(t, repo.IncrementFailureCount(ctx, 1, models.GeocodeError{Message: "failed", Code: "other"}))
+		require.NoError(t, repo.MarkInvalidAddress(ctx, 2, models.GeocodeError{Message: "blank", Code: "invalid"}))
+		_, err := repo.ResetGeocodingAttempts(ctx, []int{2})
+		require.NoError(t, err)
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Task{{ID: 2, Address: "м. Львів"}, {ID: 3, Address: "м. Одеса"}}, tasks)
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+
+	t.Run("success - written tasks are fetched again after the maximum lag", func(t *testing.T) {
+		t.Parallel()
+		primary, replica := newPools(t)
+		repo := repository.NewRepository(primary, logger,
+			repository.WithReadDatabase(replica, 10*time.Millisecond))
+
+		primary.ExpectExec("UPDATE tasks").WithArgs("failed", "other", 1).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		replica.ExpectQuery(regexp.QuoteMeta(fetchTasksQuery)).WithArgs(limit).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "address"}).AddRow(1, "м. Київ"))
+
+		require.NoError(t, repo.IncrementFailureCount(ctx, 1, models.GeocodeError{Message: "failed", Code: "other"}))
+		time.Sleep(20 * time.Millisecond)
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Task{{ID: 1, Address: "м. Київ"}}, tasks)
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+
+	t.Run("success - claims on the primary", func(t *testing.T) {
+		t.Parallel()
+		primary, replica := newPools(t)
+		repo := repository.NewRepository(primary, logger,
+			repository.WithReadDatabase(replica, time.Minute), repository.WithTaskClaim("atlas-0", time.Minute))
+
+		primary.ExpectQuery(regexp.QuoteMeta(claimTasksQuery)).WithArgs(limit, "atlas-0", 60.0).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "address"}).AddRow(1, "м. Київ"))
+
+		tasks, err := repo.FetchTasksForGeocoding(ctx, limit)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Task{{ID: 1, Address: "м. Київ"}}, tasks)
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+
+	t.Run("success - counts pending tasks on the replica", func(t *testing.T) {
+		t.Parallel()
+		primary, replica := newPools(t)
+		repo := repository.NewRepository(primary, logger, repository.WithReadDatabase(replica, time.Minute))
+
+		replica.ExpectQuery("SELECT COUNT").WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(42))
+
+		count, err := repo.CountPendingTasks(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 42, count)
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+}
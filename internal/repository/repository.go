@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/UnknownOlympus/atlas/internal/models"
 )
@@ -11,26 +12,185 @@ import (
 // and provides logging capabilities. It holds a reference to the database
 // and a logger instance for logging operations.
 type Repository struct {
-	db  Database
-	log *slog.Logger
+	db         Database      // db is the primary database, which every write goes to
+	readDB     Database      // readDB is the database pending tasks are read from, nil reads from db
+	written    *recentWrites // written holds the recently updated tasks while reading from readDB
+	log        *slog.Logger
+	claimOwner string        // claimOwner identifies this instance in claimed tasks, empty disables claiming
+	claimTTL   time.Duration // claimTTL is how long a claim is held before other instances may take the task
+	fetch      FetchOptions  // fetch filters and orders the tasks returned by FetchTasksForGeocoding
+	cacheTTL   time.Duration // cacheTTL is how long cached coordinates stay fresh
+
+	negativeCacheTTL time.Duration // negativeCacheTTL is how long a not found entry stays fresh, zero disables them
+	geohashPrecision int           // geohashPrecision is the length of the stored geohash, zero disables storing it
+	geocodedAt       bool          // geocodedAt records when coordinates were stored, for FetchStaleTasks
+	notFoundStatus   bool          // notFoundStatus clears GeocodingStatusNotFound when attempts are reset
+	geometryColumn   string        // geometryColumn is the PostGIS point column, empty disables storing it
+}
+
+// FetchOptions filters and prioritizes the tasks returned by FetchTasksForGeocoding.
+// The zero value fetches tasks of all regions, oldest first.
+type FetchOptions struct {
+	Region     string // Region restricts tasks to the given value of the region column, empty fetches all
+	ByPriority bool   // ByPriority orders tasks by descending priority column first, so urgent tasks jump the queue
+
+	// MinAttemptInterval is the cooldown after a failed attempt before a task is fetched again,
+	// tracked in the last_attempt_at column. Zero disables the cooldown.
+	MinAttemptInterval time.Duration
+
+	// PreferredProvider reads the preferred_provider column of each task into Task.PreferredProvider,
+	// so that the task can be routed to that provider.
+	PreferredProvider bool
+
+	// Language reads the language column of each task into Task.Language, so that the task can be geocoded
+	// with results in its own language rather than the language configured for the provider.
+	Language bool
+
+	// SkipFlagged excludes the tasks whose geocoding_status column is GeocodingStatusSkip, as set by
+	// SkipTaskGeocoding, so that operators can cancel the geocoding of tasks without deleting them.
+	SkipFlagged bool
+}
+
+// Values of the geocoding_status column.
+const (
+	// GeocodingStatusSkip is the status of the tasks excluded from geocoding with FetchOptions.SkipFlagged.
+	GeocodingStatusSkip = "skip"
+
+	// GeocodingStatusNotFound is the status of the tasks whose address has no match, set by MarkTaskNotFound.
+	GeocodingStatusNotFound = "not_found"
+)
+
+// Option configures optional behavior of the Repository.
+type Option func(*Repository)
+
+// WithTaskClaim makes FetchTasksForGeocoding claim the fetched tasks for owner, so that several
+// instances of the service can run against the same database without geocoding the same tasks.
+// Tasks are selected with FOR UPDATE SKIP LOCKED and marked with locked_by and locked_at;
+// the claim is released when the task is updated or with ReleaseTaskClaims, and expires after ttl if the instance
+// crashes.
+func WithTaskClaim(owner string, ttl time.Duration) Option {
+	return func(r *Repository) {
+		r.claimOwner = owner
+		r.claimTTL = ttl
+	}
+}
+
+// WithGeohash makes UpdateTaskCoordinates and UpdateTaskResult store the geohash of the coordinates
+// with precision characters in the geohash column, so that tasks can be indexed for proximity queries.
+// Zero, the default, disables it, and the column may not exist.
+func WithGeohash(precision int) Option {
+	return func(r *Repository) {
+		r.geohashPrecision = precision
+	}
+}
+
+// WithGeometryColumn makes UpdateTaskCoordinates, UpdateTaskResult and UpdateTaskGeocodeResult also store
+// the coordinates as a PostGIS point with SRID 4326 in column, a geometry or geography column, and enables
+// UpdateTaskGeometry. The latitude and longitude columns are still written, since they mark geocoded tasks.
+// Empty, the default, disables it, and the column may not exist. The column name is not quoted,
+// so it must be a valid SQL identifier.
+func WithGeometryColumn(column string) Option {
+	return func(r *Repository) {
+		r.geometryColumn = column
+	}
+}
+
+// WithGeocodedAt makes UpdateTaskCoordinates, UpdateTaskResult and UpdateTaskGeocodeResult record the time
+// the coordinates were stored in the geocoded_at column, so that FetchStaleTasks can find them once they age.
+// Disabled by default, and the column may not exist.
+func WithGeocodedAt(enabled bool) Option {
+	return func(r *Repository) {
+		r.geocodedAt = enabled
+	}
+}
+
+// WithNotFoundStatus makes ResetGeocodingAttempts and ResetFailedGeocodingAttempts clear the
+// GeocodingStatusNotFound status set by MarkTaskNotFound, so that reprocessed tasks no longer report it.
+// Disabled by default, and the geocoding_status column may not exist.
+func WithNotFoundStatus(enabled bool) Option {
+	return func(r *Repository) {
+		r.notFoundStatus = enabled
+	}
 }
 
 // Interface defines the methods for interacting with geocoding tasks in the repository.
-// It provides functionality to fetch tasks, update task coordinates, and increment failure counts.
+// It provides functionality to fetch tasks, update task coordinates, increment failure counts,
+// and reset the attempts of failed tasks for reprocessing.
 type Interface interface {
 	// FetchTasksForGeocoding retrieves a list of tasks for geocoding with a specified limit.
 	FetchTasksForGeocoding(ctx context.Context, limit int) ([]models.Task, error)
 
+	// FetchStructuredTasksForGeocoding retrieves a list of tasks for geocoding with a specified limit,
+	// with their structured address.
+	FetchStructuredTasksForGeocoding(ctx context.Context, limit int) ([]models.Task, error)
+
+	// FetchStaleTasks retrieves a list of tasks with a specified limit whose coordinates were stored
+	// more than olderThan ago, so that they can be geocoded again.
+	FetchStaleTasks(ctx context.Context, olderThan time.Duration, limit int) ([]models.Task, error)
+
+	// PostponeStaleRefresh records a failed refresh of the stale coordinates of a specific task identified
+	// by taskID, so that it isn't refreshed again until its coordinates are stale again.
+	PostponeStaleRefresh(ctx context.Context, taskID int) error
+
 	// UpdateTaskCoordinates updates the coordinates of a specific task identified by taskID.
 	UpdateTaskCoordinates(ctx context.Context, taskID int, coords models.Coordinates) error
 
+	// UpdateTaskResult updates the coordinates and the place metadata of a specific task identified by taskID.
+	UpdateTaskResult(ctx context.Context, taskID int, result models.GeocodeResult) error
+
+	// UpdateTaskGeocodeResult updates the address of a specific task identified by taskID to its cleaned form
+	// together with its coordinates and, if the result has any, its place metadata.
+	UpdateTaskGeocodeResult(ctx context.Context, taskID int, cleanedAddress string, result models.GeocodeResult) error
+
+	// UpdateTaskWhat3Words stores the what3words address of the coordinates of the tasks identified by taskIDs.
+	UpdateTaskWhat3Words(ctx context.Context, taskIDs []int, words string) error
+
 	// IncrementFailureCount increments the failure count for a specific task identified by taskID
-	// and logs the provided error message.
-	IncrementFailureCount(ctx context.Context, taskID int, errMsg string) error
+	// and stores the message and code of the provided error.
+	IncrementFailureCount(ctx context.Context, taskID int, failure models.GeocodeError) error
+
+	// MarkTaskNotFound records that the address of a specific task identified by taskID has no match,
+	// with the provided error, so that the task is no longer fetched instead of being retried.
+	MarkTaskNotFound(ctx context.Context, taskID int, failure models.GeocodeError) error
+
+	// MarkInvalidAddress records that the address of a specific task identified by taskID can't be geocoded,
+	// with the reason as its error, so that the task is no longer fetched without spending provider attempts.
+	MarkInvalidAddress(ctx context.Context, taskID int, reason models.GeocodeError) error
+
+	// ResetGeocodingAttempts clears the attempt count and error of the tasks identified by taskIDs,
+	// so that they are geocoded again. It returns the number of reset tasks.
+	ResetGeocodingAttempts(ctx context.Context, taskIDs []int) (int64, error)
+
+	// ResetFailedGeocodingAttempts clears the attempt count and error of all tasks that have
+	// exhausted their geocoding attempts. It returns the number of reset tasks.
+	ResetFailedGeocodingAttempts(ctx context.Context) (int64, error)
+
+	// SkipTaskGeocoding flags the tasks identified by taskIDs so that they are no longer geocoded.
+	// It returns the number of flagged tasks.
+	SkipTaskGeocoding(ctx context.Context, taskIDs []int) (int64, error)
+
+	// CountPendingTasks returns the number of tasks that still require geocoding.
+	CountPendingTasks(ctx context.Context) (int, error)
+
+	// CountPendingStructuredTasks returns the number of tasks with a structured address that still require geocoding.
+	CountPendingStructuredTasks(ctx context.Context) (int, error)
+}
+
+// WithFetchOptions sets the filters and ordering applied by FetchTasksForGeocoding.
+func WithFetchOptions(opts FetchOptions) Option {
+	return func(r *Repository) {
+		r.fetch = opts
+	}
 }
 
 // NewRepository creates a new instance of Repository with the provided Database.
 // It returns a pointer to the newly created Repository.
-func NewRepository(db Database, log *slog.Logger) *Repository {
-	return &Repository{db: db, log: log}
+func NewRepository(db Database, log *slog.Logger, opts ...Option) *Repository {
+	r := &Repository{db: db, log: log, cacheTTL: DefaultCacheTTL}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
@@ -0,0 +1,337 @@
+// Package repositorytest provides an in-memory implementation of repository.Interface, so that the geocoding
+// service can be exercised end to end in tests without a database or pgxmock expectations.
+package repositorytest
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
+)
+
+// maxAttempts is the number of failed attempts after which a task is no longer fetched,
+// like the geocoding_attempts limit of the PostgreSQL repository.
+const maxAttempts = 5
+
+// TaskState is the state of a task stored by InMemoryRepository, recording the updates made to it.
+type TaskState struct {
+	Task       models.Task           // Task is the task as fetched, with its address rewritten if it was cleaned.
+	Result     *models.GeocodeResult // Result is the stored geocoding result, nil until the task is geocoded.
+	GeocodedAt time.Time             // GeocodedAt is when the result was stored, zero until the task is geocoded.
+	What3Words string                // What3Words is the stored what3words address, empty if none.
+	Attempts   int                   // Attempts is the number of failed geocoding attempts.
+	Error      *models.GeocodeError  // Error is the error of the last failed attempt, nil if none or geocoded.
+	Status     string                // Status is the geocoding status, e.g. repository.GeocodingStatusSkip.
+}
+
+// InMemoryRepository is a repository.Interface keeping its tasks in memory. It behaves like the PostgreSQL
+// repository without fetch options: tasks without a result, with a non-blank address and fewer than 5 failed
+// attempts are fetched in the order they were set, except tasks flagged to be skipped. Updates of unknown
+// tasks are ignored, like updates matching no row. It is safe for concurrent use.
+type InMemoryRepository struct {
+	mu    sync.Mutex
+	order []int              // IDs of the tasks in the order they were set
+	tasks map[int]*TaskState // tasks by ID
+}
+
+// Ensure InMemoryRepository implements repository.Interface.
+var _ repository.Interface = (*InMemoryRepository)(nil)
+
+// NewInMemoryRepository creates a new InMemoryRepository holding the tasks, none of them geocoded yet.
+func NewInMemoryRepository(tasks ...models.Task) *InMemoryRepository {
+	r := &InMemoryRepository{}
+	r.SetTasks(tasks...)
+
+	return r
+}
+
+// SetTasks replaces the tasks of the repository with the tasks, discarding the recorded updates.
+func (r *InMemoryRepository) SetTasks(tasks ...models.Task) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.order = make([]int, 0, len(tasks))
+	r.tasks = make(map[int]*TaskState, len(tasks))
+	for _, task := range tasks {
+		if _, ok := r.tasks[task.ID]; !ok {
+			r.order = append(r.order, task.ID)
+		}
+		r.tasks[task.ID] = &TaskState{Task: task}
+	}
+}
+
+// Task returns a copy of the state of the task identified by taskID, and whether the task exists.
+func (r *InMemoryRepository) Task(taskID int) (TaskState, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.tasks[taskID]
+	if !ok {
+		return TaskState{}, false
+	}
+
+	return *state, true
+}
+
+// Tasks returns a copy of the state of every task, in the order they were set.
+func (r *InMemoryRepository) Tasks() []TaskState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make([]TaskState, len(r.order))
+	for i, id := range r.order {
+		states[i] = *r.tasks[id]
+	}
+
+	return states
+}
+
+// FetchTasksForGeocoding returns up to limit pending tasks.
+func (r *InMemoryRepository) FetchTasksForGeocoding(_ context.Context, limit int) ([]models.Task, error) {
+	return r.fetch(limit, func(state *TaskState) bool {
+		return strings.TrimSpace(state.Task.Address) != ""
+	}), nil
+}
+
+// FetchStructuredTasksForGeocoding returns up to limit pending tasks with a structured address.
+func (r *InMemoryRepository) FetchStructuredTasksForGeocoding(_ context.Context, limit int) ([]models.Task, error) {
+	return r.fetch(limit, func(state *TaskState) bool {
+		return state.Task.Structured != nil
+	}), nil
+}
+
+// FetchStaleTasks returns up to limit geocoded tasks whose result was stored more than olderThan ago,
+// the oldest first.
+func (r *InMemoryRepository) FetchStaleTasks(
+	_ context.Context,
+	olderThan time.Duration,
+	limit int,
+) ([]models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stale []*TaskState
+	for _, id := range r.order {
+		state := r.tasks[id]
+		if state.Result != nil && time.Since(state.GeocodedAt) > olderThan {
+			stale = append(stale, state)
+		}
+	}
+	slices.SortStableFunc(stale, func(a, b *TaskState) int {
+		return a.GeocodedAt.Compare(b.GeocodedAt)
+	})
+
+	tasks := make([]models.Task, 0, min(limit, len(stale)))
+	for _, state := range stale[:min(limit, len(stale))] {
+		tasks = append(tasks, state.Task)
+	}
+
+	return tasks, nil
+}
+
+// PostponeStaleRefresh advances the GeocodedAt of the task to now, keeping its result.
+func (r *InMemoryRepository) PostponeStaleRefresh(_ context.Context, taskID int) error {
+	r.update(taskID, func(state *TaskState) {
+		state.GeocodedAt = time.Now()
+	})
+
+	return nil
+}
+
+// UpdateTaskCoordinates stores the coordinates of the task identified by taskID and clears its error.
+func (r *InMemoryRepository) UpdateTaskCoordinates(_ context.Context, taskID int, coords models.Coordinates) error {
+	r.update(taskID, func(state *TaskState) {
+		state.setResult(models.GeocodeResult{Coordinates: coords})
+	})
+
+	return nil
+}
+
+// UpdateTaskResult stores the coordinates and place metadata of the task identified by taskID
+// and clears its error.
+func (r *InMemoryRepository) UpdateTaskResult(_ context.Context, taskID int, result models.GeocodeResult) error {
+	r.update(taskID, func(state *TaskState) {
+		state.setResult(result)
+	})
+
+	return nil
+}
+
+// UpdateTaskGeocodeResult replaces the address of the task identified by taskID with its cleaned form
+// and stores its result.
+func (r *InMemoryRepository) UpdateTaskGeocodeResult(
+	_ context.Context,
+	taskID int,
+	cleanedAddress string,
+	result models.GeocodeResult,
+) error {
+	r.update(taskID, func(state *TaskState) {
+		state.Task.Address = cleanedAddress
+		state.setResult(result)
+	})
+
+	return nil
+}
+
+// UpdateTaskWhat3Words stores the what3words address of the tasks identified by taskIDs.
+func (r *InMemoryRepository) UpdateTaskWhat3Words(_ context.Context, taskIDs []int, words string) error {
+	for _, taskID := range taskIDs {
+		r.update(taskID, func(state *TaskState) {
+			state.What3Words = words
+		})
+	}
+
+	return nil
+}
+
+// IncrementFailureCount counts a failed attempt of the task identified by taskID and stores its error.
+func (r *InMemoryRepository) IncrementFailureCount(
+	_ context.Context,
+	taskID int,
+	failure models.GeocodeError,
+) error {
+	r.update(taskID, func(state *TaskState) {
+		state.Attempts++
+		state.Error = &failure
+	})
+
+	return nil
+}
+
+// MarkTaskNotFound sets the status of the task identified by taskID to repository.GeocodingStatusNotFound
+// and raises its attempts to the limit, so that it is no longer fetched.
+func (r *InMemoryRepository) MarkTaskNotFound(_ context.Context, taskID int, failure models.GeocodeError) error {
+	r.update(taskID, func(state *TaskState) {
+		state.Status = repository.GeocodingStatusNotFound
+		state.Attempts = max(state.Attempts+1, maxAttempts)
+		state.Error = &failure
+	})
+
+	return nil
+}
+
+// MarkInvalidAddress raises the attempts of the task identified by taskID to the limit, so that it is
+// no longer fetched, and stores the reason as its error.
+func (r *InMemoryRepository) MarkInvalidAddress(_ context.Context, taskID int, reason models.GeocodeError) error {
+	r.update(taskID, func(state *TaskState) {
+		state.Attempts = max(state.Attempts, maxAttempts)
+		state.Error = &reason
+	})
+
+	return nil
+}
+
+// ResetGeocodingAttempts clears the attempts, error and not found status of the tasks identified by taskIDs.
+// It returns the number of reset tasks.
+func (r *InMemoryRepository) ResetGeocodingAttempts(_ context.Context, taskIDs []int) (int64, error) {
+	var reset int64
+	for _, taskID := range taskIDs {
+		if r.update(taskID, (*TaskState).reset) {
+			reset++
+		}
+	}
+
+	return reset, nil
+}
+
+// ResetFailedGeocodingAttempts clears the attempts, error and not found status of the tasks that exhausted
+// their attempts without a result. It returns the number of reset tasks.
+func (r *InMemoryRepository) ResetFailedGeocodingAttempts(_ context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reset int64
+	for _, state := range r.tasks {
+		if state.Result == nil && state.Attempts >= maxAttempts {
+			state.reset()
+			reset++
+		}
+	}
+
+	return reset, nil
+}
+
+// SkipTaskGeocoding sets the status of the tasks identified by taskIDs to repository.GeocodingStatusSkip.
+// It returns the number of flagged tasks.
+func (r *InMemoryRepository) SkipTaskGeocoding(_ context.Context, taskIDs []int) (int64, error) {
+	var skipped int64
+	for _, taskID := range taskIDs {
+		if r.update(taskID, func(state *TaskState) { state.Status = repository.GeocodingStatusSkip }) {
+			skipped++
+		}
+	}
+
+	return skipped, nil
+}
+
+// CountPendingTasks returns the number of tasks FetchTasksForGeocoding would return without a limit.
+func (r *InMemoryRepository) CountPendingTasks(ctx context.Context) (int, error) {
+	tasks, err := r.FetchTasksForGeocoding(ctx, len(r.Tasks()))
+
+	return len(tasks), err
+}
+
+// CountPendingStructuredTasks returns the number of tasks FetchStructuredTasksForGeocoding would return
+// without a limit.
+func (r *InMemoryRepository) CountPendingStructuredTasks(ctx context.Context) (int, error) {
+	tasks, err := r.FetchStructuredTasksForGeocoding(ctx, len(r.Tasks()))
+
+	return len(tasks), err
+}
+
+// fetch returns up to limit pending tasks for which include returns true.
+func (r *InMemoryRepository) fetch(limit int, include func(state *TaskState) bool) []models.Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tasks []models.Task
+	for _, id := range r.order {
+		if len(tasks) >= limit {
+			break
+		}
+		state := r.tasks[id]
+		if state.pending() && include(state) {
+			tasks = append(tasks, state.Task)
+		}
+	}
+
+	return tasks
+}
+
+// update applies apply to the state of the task identified by taskID, and reports whether the task exists.
+func (r *InMemoryRepository) update(taskID int, apply func(state *TaskState)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.tasks[taskID]
+	if ok {
+		apply(state)
+	}
+
+	return ok
+}
+
+// pending reports whether the task still requires geocoding.
+func (s *TaskState) pending() bool {
+	return s.Result == nil && s.Attempts < maxAttempts && s.Status != repository.GeocodingStatusSkip
+}
+
+// setResult stores the geocoding result and clears the error of the last failed attempt.
+func (s *TaskState) setResult(result models.GeocodeResult) {
+	s.Result = &result
+	s.GeocodedAt = time.Now()
+	s.Error = nil
+}
+
+// reset clears the attempts, the error and the not found status, so that the task is geocoded again.
+func (s *TaskState) reset() {
+	s.Attempts = 0
+	s.Error = nil
+	if s.Status == repository.GeocodingStatusNotFound {
+		s.Status = ""
+	}
+}
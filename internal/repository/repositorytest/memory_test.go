@@ -0,0 +1,171 @@
+package repositorytest_test
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
+	"github.com/UnknownOlympus/atlas/internal/repository/repositorytest"
+	"github.com/UnknownOlympus/atlas/internal/service"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryRepository_Fetch(t *testing.T) {
+	ctx := t.Context()
+	failure := models.GeocodeError{Code: "other", Message: "boom"}
+	repo := repositorytest.NewInMemoryRepository(
+		models.Task{ID: 1, Address: "Kyiv"},
+		models.Task{ID: 2, Address: "  "},
+		models.Task{ID: 3, Address: "Lviv"},
+		models.Task{ID: 4, Address: "Odesa"},
+		models.Task{ID: 5, Address: "Dnipro", Structured: &models.StructuredAddress{City: "Dnipro"}},
+	)
+
+	require.NoError(t, repo.UpdateTaskCoordinates(ctx, 3, models.Coordinates{Latitude: 49.84, Longitude: 24.03}))
+	skipped, err := repo.SkipTaskGeocoding(ctx, []int{4, 42})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), skipped)
+
+	tasks, err := repo.FetchTasksForGeocoding(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 5}, taskIDs(tasks))
+
+	tasks, err = repo.FetchTasksForGeocoding(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, taskIDs(tasks))
+
+	tasks, err = repo.FetchStructuredTasksForGeocoding(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5}, taskIDs(tasks))
+
+	for range 5 {
+		require.NoError(t, repo.IncrementFailureCount(ctx, 1, failure))
+	}
+	pending, err := repo.CountPendingTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pending, "a task that exhausted its attempts is no longer pending")
+
+	stale, err := repo.FetchStaleTasks(ctx, 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, taskIDs(stale))
+	stale, err = repo.FetchStaleTasks(ctx, time.Hour, 100)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+
+	stale, err = repo.FetchStaleTasks(ctx, 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, taskIDs(stale), "fetching stale tasks doesn't postpone their refresh")
+	require.NoError(t, repo.PostponeStaleRefresh(ctx, 3))
+	state, _ := repo.Task(3)
+	assert.WithinDuration(t, time.Now(), state.GeocodedAt, time.Minute)
+}
+
+func TestInMemoryRepository_Updates(t *testing.T) {
+	ctx := t.Context()
+	failure := models.GeocodeError{Code: "empty_response", Message: "no match"}
+	repo := repositorytest.NewInMemoryRepository(
+		models.Task{ID: 1, Address: "Kyiv"},
+		models.Task{ID: 2, Address: "Nowhere"},
+		models.Task{ID: 3, Address: "Khreshchatyk 1 "},
+	)
+
+	result := models.GeocodeResult{
+		Coordinates: models.Coordinates{Latitude: 50.45, Longitude: 30.52}, PlaceID: "place-1",
+	}
+	require.NoError(t, repo.IncrementFailureCount(ctx, 1, failure))
+	require.NoError(t, repo.UpdateTaskResult(ctx, 1, result))
+	require.NoError(t, repo.UpdateTaskWhat3Words(ctx, []int{1}, "filled.count.soap"))
+	require.NoError(t, repo.MarkTaskNotFound(ctx, 2, failure))
+	require.NoError(t, repo.UpdateTaskGeocodeResult(ctx, 3, "Khreshchatyk 1", result))
+	require.NoError(t, repo.UpdateTaskCoordinates(ctx, 42, result.Coordinates), "unknown tasks are ignored")
+
+	geocoded, ok := repo.Task(1)
+	require.True(t, ok)
+	assert.Equal(t, &result, geocoded.Result)
+	assert.Equal(t, "filled.count.soap", geocoded.What3Words)
+	assert.Equal(t, 1, geocoded.Attempts)
+	assert.Nil(t, geocoded.Error)
+
+	notFound, _ := repo.Task(2)
+	assert.Equal(t, repository.GeocodingStatusNotFound, notFound.Status)
+	assert.Equal(t, 5, notFound.Attempts)
+	assert.Equal(t, &failure, notFound.Error)
+
+	rewritten, _ := repo.Task(3)
+	assert.Equal(t, "Khreshchatyk 1", rewritten.Task.Address)
+	assert.Equal(t, &result, rewritten.Result)
+
+	_, ok = repo.Task(42)
+	assert.False(t, ok)
+
+	reset, err := repo.ResetFailedGeocodingAttempts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reset)
+	notFound, _ = repo.Task(2)
+	assert.Zero(t, notFound.Attempts)
+	assert.Empty(t, notFound.Status)
+
+	repo.SetTasks(models.Task{ID: 7, Address: "Poltava"})
+	assert.Equal(t, []repositorytest.TaskState{{Task: models.Task{ID: 7, Address: "Poltava"}}}, repo.Tasks())
+}
+
+// TestInMemoryRepository_GeocodingService shows how to run the geocoding service end to end against
+// the in-memory repository.
+func TestInMemoryRepository_GeocodingService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	repo := repositorytest.NewInMemoryRepository(
+		models.Task{ID: 1, Address: "Kyiv"},
+		models.Task{ID: 2, Address: "kyiv"},
+		models.Task{ID: 3, Address: "Nowhere"},
+	)
+
+	provider := mocks.NewProvider(t)
+	provider.On("Geocode", mock.Anything, "Kyiv").Return(coords, nil).Once()
+	provider.On("Geocode", mock.Anything, "Nowhere").Return(nil, geocoding.ErrVisicomEmptyResponse).Once()
+
+	gs := service.NewGeocodingServie(logger, repo, provider, "visicom", metrics.NewMetrics(prometheus.NewRegistry()),
+		2, time.Hour, "")
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gs.RunUntil(t.Context(), stop)
+	}()
+
+	assert.Eventually(t, func() bool {
+		pending, _ := repo.CountPendingTasks(t.Context())
+		failed, _ := repo.Task(3)
+		return pending == 1 && failed.Attempts == 1
+	}, time.Second, time.Millisecond)
+	close(stop)
+	<-done
+
+	for _, id := range []int{1, 2} {
+		state, _ := repo.Task(id)
+		require.NotNil(t, state.Result, "task %d", id)
+		assert.Equal(t, *coords, state.Result.Coordinates)
+	}
+	failed, _ := repo.Task(3)
+	assert.Nil(t, failed.Result)
+	assert.Equal(t, "empty_response", failed.Error.Code)
+}
+
+func taskIDs(tasks []models.Task) []int {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	return ids
+}
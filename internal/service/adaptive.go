@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// adaptiveLimit caps the provider calls in flight at once with a limit that follows the provider's responses,
+// AIMD-style: the limit is halved when a call is rate limited or gets a 5xx response, and grows by one once
+// as many calls as the limit succeeded since it last changed, up to the maximum. Shared or free endpoints are thus
+// protected from overload without tuning the cap by hand.
+//
+// Calls overlapping a decrease were started under the old limit, so their overload responses
+// don't halve the limit again: a burst of 429s halves it once.
+type adaptiveLimit struct {
+	mu         sync.Mutex
+	limit      int              // limit is the number of calls currently allowed in flight
+	maxLimit   int              // maxLimit is the limit the controller starts at and grows back to
+	inFlight   int              // inFlight is the number of calls holding a slot
+	successes  int              // successes counts the successful calls since the limit last changed
+	generation uint64           // generation is incremented on every decrease of the limit
+	changed    chan struct{}    // changed is closed and replaced when a slot is freed or the limit grows
+	gauge      prometheus.Gauge // gauge reports the current limit
+}
+
+// newAdaptiveLimit creates an adaptive limit starting at, and never growing above, maxLimit.
+func newAdaptiveLimit(maxLimit int, gauge prometheus.Gauge) *adaptiveLimit {
+	maxLimit = max(maxLimit, 1)
+	gauge.Set(float64(maxLimit))
+
+	return &adaptiveLimit{limit: maxLimit, maxLimit: maxLimit, changed: make(chan struct{}), gauge: gauge}
+}
+
+// acquire waits until a call may start within the current limit, and returns the generation the call
+// starts in, to be passed to release. It returns false if the context is done first.
+func (al *adaptiveLimit) acquire(ctx context.Context) (uint64, bool) {
+	for {
+		al.mu.Lock()
+		if al.inFlight < al.limit {
+			al.inFlight++
+			generation := al.generation
+			al.mu.Unlock()
+			return generation, true
+		}
+		changed := al.changed
+		al.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, false
+		}
+	}
+}
+
+// release frees the slot of a call started in generation, and adjusts the limit to the outcome of the call:
+// an overload halves it, a success counts towards growing it, and other errors leave it as is.
+func (al *adaptiveLimit) release(generation uint64, err error) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	al.inFlight--
+	switch {
+	case isOverload(err):
+		if generation == al.generation {
+			al.generation++
+			al.successes = 0
+			al.setLimit(max(al.limit/2, 1))
+		}
+	case err == nil && al.limit < al.maxLimit:
+		if al.successes++; al.successes >= al.limit {
+			al.successes = 0
+			al.setLimit(al.limit + 1)
+		}
+	}
+
+	// Wake the waiting calls, as a slot is free now
+	close(al.changed)
+	al.changed = make(chan struct{})
+}
+
+// current returns the current limit.
+func (al *adaptiveLimit) current() int {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	return al.limit
+}
+
+// setLimit sets the limit and reports it. The caller must hold the mutex.
+func (al *adaptiveLimit) setLimit(limit int) {
+	al.limit = limit
+	al.gauge.Set(float64(limit))
+}
+
+// isOverload reports whether err tells that the provider is overloaded: a 429 or a 5xx response.
+func isOverload(err error) bool {
+	return errors.Is(err, geocoding.ErrRateLimited) || errors.Is(err, geocoding.ErrServerError)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// acquireSlots takes n slots of the adaptive limit and returns the generations they were taken in.
+func acquireSlots(t *testing.T, limit *adaptiveLimit, n int) []uint64 {
+	t.Helper()
+
+	generations := make([]uint64, n)
+	for i := range generations {
+		generation, ok := limit.acquire(t.Context())
+		require.True(t, ok)
+		generations[i] = generation
+	}
+
+	return generations
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	rateLimited := &geocoding.RateLimitError{Provider: "nominatim"}
+	serverError := &geocoding.StatusError{Provider: "nominatim", StatusCode: http.StatusBadGateway}
+
+	t.Run("a burst of 429s halves the limit once", func(t *testing.T) {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"})
+		limit := newAdaptiveLimit(8, gauge)
+		assert.InDelta(t, 8, gaugeValue(t, gauge), 0)
+
+		for _, generation := range acquireSlots(t, limit, 8) {
+			limit.release(generation, rateLimited)
+		}
+
+		assert.Equal(t, 4, limit.current())
+		assert.InDelta(t, 4, gaugeValue(t, gauge), 0)
+	})
+
+	t.Run("successive bursts keep halving down to one", func(t *testing.T) {
+		limit := newAdaptiveLimit(8, prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"}))
+
+		for _, expected := range []int{4, 2, 1, 1} {
+			for _, generation := range acquireSlots(t, limit, limit.current()) {
+				limit.release(generation, serverError)
+			}
+			assert.Equal(t, expected, limit.current())
+		}
+	})
+
+	t.Run("sustained success grows the limit back to the maximum", func(t *testing.T) {
+		limit := newAdaptiveLimit(3, prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"}))
+		generation, _ := limit.acquire(t.Context())
+		limit.release(generation, rateLimited)
+		require.Equal(t, 1, limit.current())
+
+		// The limit grows by one once as many calls as the limit succeeded
+		for _, expected := range []int{2, 2, 3, 3, 3, 3} {
+			generation, _ = limit.acquire(t.Context())
+			limit.release(generation, nil)
+			assert.Equal(t, expected, limit.current())
+		}
+	})
+
+	t.Run("other errors leave the limit as is", func(t *testing.T) {
+		limit := newAdaptiveLimit(4, prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"}))
+		generation, _ := limit.acquire(t.Context())
+		limit.release(generation, geocoding.ErrEmptyResponse)
+		generation, _ = limit.acquire(t.Context())
+		limit.release(generation, &geocoding.StatusError{Provider: "here", StatusCode: http.StatusBadRequest})
+
+		assert.Equal(t, 4, limit.current())
+	})
+
+	t.Run("a call waits for a free slot", func(t *testing.T) {
+		limit := newAdaptiveLimit(1, prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"}))
+		generation, _ := limit.acquire(t.Context())
+
+		acquired := make(chan bool)
+		go func() {
+			_, ok := limit.acquire(t.Context())
+			acquired <- ok
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("the slot must not be taken twice")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		limit.release(generation, nil)
+		assert.True(t, <-acquired)
+	})
+
+	t.Run("waiting stops when the context is canceled", func(t *testing.T) {
+		limit := newAdaptiveLimit(1, prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"}))
+		acquireSlots(t, limit, 1)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		_, ok := limit.acquire(ctx)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestProcessTask_AdaptiveConcurrency(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithMaxConcurrentRequests(4),
+		WithAdaptiveConcurrency(true),
+	)
+	require.InDelta(t, 4, gaugeValue(t, metrics.ConcurrencyLimit), 0)
+
+	// Every call of the single worker is rate limited, so each halves the limit
+	tasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}, {ID: 3, Address: "Odesa"}}
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(tasks, nil).Once()
+	mockProvider.On("Geocode", ctx, mock.Anything).
+		Return(nil, &geocoding.RateLimitError{Provider: "test-provider"}).Times(len(tasks))
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	assert.InDelta(t, 1, gaugeValue(t, metrics.ConcurrencyLimit), 0)
+}
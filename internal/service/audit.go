@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// Audit record statuses.
+const (
+	AuditStatusSuccess     = "success"
+	AuditStatusFailure     = "failure"
+	AuditStatusRateLimited = "rate_limited"
+	AuditStatusInvalid     = "invalid_address"
+	AuditStatusTimeout     = "timeout"
+)
+
+// AuditRecord describes the outcome of a single geocoding attempt for a task.
+type AuditRecord struct {
+	TaskID        int                 // TaskID is the identifier of the processed task.
+	Address       string              // Address is the input address sent to the provider.
+	Provider      string              // Provider is the name of the geocoding provider.
+	Status        string              // Status is one of the AuditStatus* constants.
+	Coordinates   *models.Coordinates // Coordinates is the geocoding result (nil on failure).
+	FallbackLevel int                 // FallbackLevel is the address fallback level that matched.
+	Duration      time.Duration       // Duration is the time spent in the provider call.
+	Error         string              // Error is the failure reason (empty on success).
+}
+
+// AuditLogger is a sink for geocoding audit records. It is kept separate from
+// the application logger so audit events can be shipped to a different destination.
+type AuditLogger interface {
+	Log(ctx context.Context, record AuditRecord)
+}
+
+// SlogAuditLogger writes audit records as structured log entries using slog.
+type SlogAuditLogger struct {
+	log *slog.Logger // log is the logger dedicated to audit records
+}
+
+// NewSlogAuditLogger creates an AuditLogger that writes records to the given logger.
+func NewSlogAuditLogger(log *slog.Logger) *SlogAuditLogger {
+	return &SlogAuditLogger{log: log}
+}
+
+// Log writes the audit record as a single structured log entry.
+func (sa *SlogAuditLogger) Log(ctx context.Context, record AuditRecord) {
+	attrs := []slog.Attr{
+		slog.Int("task_id", record.TaskID),
+		slog.String("address", record.Address),
+		slog.String("provider", record.Provider),
+		slog.String("status", record.Status),
+		slog.Int("fallback_level", record.FallbackLevel),
+		slog.Duration("duration", record.Duration),
+	}
+	if record.Coordinates != nil {
+		attrs = append(attrs,
+			slog.Float64("latitude", record.Coordinates.Latitude),
+			slog.Float64("longitude", record.Coordinates.Longitude),
+		)
+	}
+	if record.Error != "" {
+		attrs = append(attrs, slog.String("error", record.Error))
+	}
+
+	sa.log.LogAttrs(ctx, slog.LevelInfo, "geocode audit", attrs...)
+}
+
+// NopAuditLogger is an AuditLogger that discards all records.
+type NopAuditLogger struct{}
+
+// Log discards the audit record.
+func (NopAuditLogger) Log(context.Context, AuditRecord) {}
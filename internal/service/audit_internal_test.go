@@ -0,0 +1,62 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogAuditLogger_Log(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("success record", func(t *testing.T) {
+		var buf bytes.Buffer
+		audit := NewSlogAuditLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+		audit.Log(ctx, AuditRecord{
+			TaskID:        1,
+			Address:       "Kyiv",
+			Provider:      "test-provider",
+			Status:        AuditStatusSuccess,
+			Coordinates:   &models.Coordinates{Latitude: 50.45, Longitude: 30.52},
+			FallbackLevel: 2,
+			Duration:      time.Second,
+		})
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.InDelta(t, 1, entry["task_id"], 0)
+		assert.Equal(t, "Kyiv", entry["address"])
+		assert.Equal(t, "test-provider", entry["provider"])
+		assert.Equal(t, AuditStatusSuccess, entry["status"])
+		assert.InDelta(t, 2, entry["fallback_level"], 0)
+		assert.InDelta(t, 50.45, entry["latitude"], 0.0001)
+		assert.InDelta(t, 30.52, entry["longitude"], 0.0001)
+		assert.NotContains(t, entry, "error")
+	})
+
+	t.Run("failure record", func(t *testing.T) {
+		var buf bytes.Buffer
+		audit := NewSlogAuditLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+		audit.Log(ctx, AuditRecord{
+			TaskID:   2,
+			Address:  "Invalid Address",
+			Provider: "test-provider",
+			Status:   AuditStatusFailure,
+			Error:    "geocoding failed",
+		})
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, AuditStatusFailure, entry["status"])
+		assert.Equal(t, "geocoding failed", entry["error"])
+		assert.NotContains(t, entry, "latitude")
+	})
+}
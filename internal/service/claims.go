@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// ClaimReleaser releases the task claims of the instance, e.g. repository.Repository with task claiming enabled.
+type ClaimReleaser interface {
+	// ReleaseTaskClaims releases the claims of the instance on the tasks identified by taskIDs.
+	// It returns the number of released tasks.
+	ReleaseTaskClaims(ctx context.Context, taskIDs []int) (int64, error)
+}
+
+// claimReleaseTimeout bounds the release of the task claims of a poll, which runs even if the poll timed out.
+const claimReleaseTimeout = 10 * time.Second
+
+// releaseClaims releases the claims of the instance on the tasks of a poll once they are processed, if enabled
+// with WithClaimReleaser. Tasks left for the next poll without being updated, after a rate limit, a task timeout,
+// a spent request budget, an aborted poll or in dry run, would otherwise keep their claim and be skipped by
+// every poll until it expires.
+func (gs *GeocodingService) releaseClaims(ctx context.Context, tasks []models.Task) {
+	if gs.claims == nil || len(tasks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), claimReleaseTimeout)
+	defer cancel()
+
+	taskIDs := make([]int, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+	}
+
+	released, err := gs.claims.ReleaseTaskClaims(ctx, taskIDs)
+	if err != nil {
+		gs.log.ErrorContext(ctx, "Failed to release task claims, tasks are left until their claim expires",
+			"tasks", len(taskIDs), "error", err)
+		return
+	}
+	if released > 0 {
+		gs.log.DebugContext(ctx, "Released the claims of tasks left for the next poll", "tasks", released)
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// claimingRepo is a repository that claims the tasks it fetches like repository.WithTaskClaim: a fetch returns
+// the pending tasks that aren't claimed, storing coordinates releases the claim, and so does ReleaseTaskClaims.
+// The other methods are those of the embedded mock.
+type claimingRepo struct {
+	*mocks.Interface
+
+	mu       sync.Mutex
+	tasks    []models.Task
+	claimed  map[int]bool
+	geocoded map[int]bool
+	released [][]int
+}
+
+func newClaimingRepo(t *testing.T, tasks ...models.Task) *claimingRepo {
+	t.Helper()
+
+	return &claimingRepo{
+		Interface: mocks.NewInterface(t),
+		tasks:     tasks,
+		claimed:   make(map[int]bool),
+		geocoded:  make(map[int]bool),
+	}
+}
+
+func (cr *claimingRepo) FetchTasksForGeocoding(_ context.Context, limit int) ([]models.Task, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	var tasks []models.Task
+	for _, task := range cr.tasks {
+		if len(tasks) < limit && !cr.claimed[task.ID] && !cr.geocoded[task.ID] {
+			cr.claimed[task.ID] = true
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+func (cr *claimingRepo) UpdateTaskCoordinates(_ context.Context, taskID int, _ models.Coordinates) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.geocoded[taskID] = true
+	delete(cr.claimed, taskID)
+
+	return nil
+}
+
+func (cr *claimingRepo) ReleaseTaskClaims(_ context.Context, taskIDs []int) (int64, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.released = append(cr.released, slices.Clone(taskIDs))
+	var released int64
+	for _, taskID := range taskIDs {
+		if cr.claimed[taskID] {
+			delete(cr.claimed, taskID)
+			released++
+		}
+	}
+
+	return released, nil
+}
+
+func TestProcessTask_ReleaseClaims(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	rateLimitErr := &geocoding.RateLimitError{Provider: "nominatim"}
+	tasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}}
+
+	newService := func(repo *claimingRepo, provider *mocks.Provider, opts ...Option) *GeocodingService {
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+
+		return NewGeocodingServie(logger, repo, provider, "test-provider", metrics, 1, time.Second, "", opts...)
+	}
+
+	t.Run("a rate limited task is fetched again on the next poll", func(t *testing.T) {
+		repo := newClaimingRepo(t, tasks...)
+		provider := mocks.NewProvider(t)
+		service := newService(repo, provider, WithClaimReleaser(repo))
+
+		provider.On("Geocode", mock.Anything, "Kyiv").Return(nil, rateLimitErr).Once()
+		provider.On("Geocode", mock.Anything, "Lviv").Return(coords, nil).Once()
+		assert.Equal(t, 2, service.processTask(t.Context()))
+
+		provider.On("Geocode", mock.Anything, "Kyiv").Return(coords, nil).Once()
+		assert.Equal(t, 1, service.processTask(t.Context()))
+
+		assert.Equal(t, map[int]bool{1: true, 2: true}, repo.geocoded)
+		assert.Equal(t, [][]int{{1, 2}, {1}}, repo.released)
+		assert.Empty(t, repo.claimed)
+	})
+
+	t.Run("dry run releases the claims", func(t *testing.T) {
+		repo := newClaimingRepo(t, tasks...)
+		provider := mocks.NewProvider(t)
+		service := newService(repo, provider, WithClaimReleaser(repo), WithDryRun(true))
+
+		provider.On("Geocode", mock.Anything, mock.Anything).Return(coords, nil).Times(4)
+		assert.Equal(t, 2, service.processTask(t.Context()))
+		assert.Equal(t, 2, service.processTask(t.Context()))
+
+		assert.Empty(t, repo.claimed)
+	})
+
+	t.Run("without a releaser a deferred task keeps its claim", func(t *testing.T) {
+		repo := newClaimingRepo(t, tasks...)
+		provider := mocks.NewProvider(t)
+		service := newService(repo, provider)
+
+		provider.On("Geocode", mock.Anything, "Kyiv").Return(nil, rateLimitErr).Once()
+		provider.On("Geocode", mock.Anything, "Lviv").Return(coords, nil).Once()
+		assert.Equal(t, 2, service.processTask(t.Context()))
+		assert.Zero(t, service.processTask(t.Context()))
+
+		assert.Equal(t, map[int]bool{1: true}, repo.claimed)
+	})
+}
+
+func TestProcessPipeline_ReleaseClaims(t *testing.T) {
+	repo := newClaimingRepo(t, pipelineTasks(1, 3)...)
+	provider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	service := NewGeocodingServie(logger, repo, provider, "test-provider", metrics, 2, time.Second, "",
+		WithPipeline(1), WithClaimReleaser(repo))
+	coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	provider.On("Geocode", mock.Anything, "Khreshchatyk 1").Return(coords, nil).Once()
+	provider.On("Geocode", mock.Anything, "Khreshchatyk 2").Return(nil, &geocoding.RateLimitError{}).Once()
+	provider.On("Geocode", mock.Anything, "Khreshchatyk 3").Return(coords, nil).Once()
+
+	assert.Equal(t, 3, service.processPipeline(t.Context(), nil))
+
+	assert.Equal(t, [][]int{{1, 2, 3}}, repo.released)
+	assert.Empty(t, repo.claimed)
+}
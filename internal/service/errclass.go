@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
+)
+
+// Error classes used as the "class" label of the provider API errors metric.
+const (
+	errorClassTimeout       = "timeout"
+	errorClassRateLimited   = "rate_limited"
+	errorClassUnauthorized  = "unauthorized"
+	errorClassEmptyResponse = "empty_response"
+	errorClassInvalidCoords = "invalid_coords"
+	errorClassNetwork       = "network"
+	errorClassOutsideArea   = "outside_service_area"
+	errorClassOther         = "other"
+)
+
+// errorCodeInvalidAddress is the error code stored for tasks whose address can't be geocoded at all.
+// Other failures are stored with their error class as the code.
+const errorCodeInvalidAddress = "invalid_address"
+
+// errNoCoordinates is reported when a provider returns neither coordinates nor an error.
+var errNoCoordinates = errors.New("geocoding provider returned no coordinates")
+
+// errTaskTimeout is reported when the provider call of a task group runs out of the task timeout.
+var errTaskTimeout = errors.New("task timeout exceeded")
+
+// errPollTimeout is reported when a task group is cut short by the poll running out of the poll timeout.
+var errPollTimeout = errors.New("poll timeout exceeded")
+
+// errOutsideServiceArea is reported when a provider returns coordinates outside the configured service area.
+var errOutsideServiceArea = errors.New("geocoding result is outside the service area")
+
+// errWorkerPanic is recorded for the tasks of a group whose processing panicked.
+var errWorkerPanic = errors.New("worker panicked while processing the task")
+
+// errBlankAddress is recorded for tasks whose address is empty after trimming and normalization.
+// Such tasks are never sent to the provider.
+var errBlankAddress = errors.New("address is blank")
+
+// newGeocodeError returns the failure stored with a task for err, with the error class of err as its code,
+// so failures can be grouped by code in SQL.
+func newGeocodeError(err error) models.GeocodeError {
+	code := classifyError(err)
+	if errors.Is(err, errBlankAddress) {
+		code = errorCodeInvalidAddress
+	}
+
+	return models.GeocodeError{Code: code, Message: err.Error()}
+}
+
+// classifyError maps a geocoding error to an error class, so that alerts can distinguish
+// authentication failures from transient timeouts. Provider sentinel errors are matched first,
+// then timeouts and network errors; anything else is classified as "other".
+func classifyError(err error) string {
+	switch {
+	case errors.Is(err, geocoding.ErrRateLimited):
+		return errorClassRateLimited
+	case errors.Is(err, geocoding.ErrVisicomUnathorized),
+		errors.Is(err, geocoding.ErrHereUnauthorized),
+		errors.Is(err, geocoding.ErrLocationIQUnauthorized),
+		errors.Is(err, geocoding.ErrBingUnauthorized):
+		return errorClassUnauthorized
+	case errors.Is(err, errNoCoordinates),
+		errors.Is(err, geocoding.ErrEmptyResponse),
+		errors.Is(err, geocoding.ErrNominatimEmptyResponse),
+		errors.Is(err, geocoding.ErrVisicomEmptyResponse),
+		errors.Is(err, geocoding.ErrHereEmptyResponse),
+		errors.Is(err, geocoding.ErrBingEmptyResponse),
+		errors.Is(err, geocoding.ErrRegionCentroidNotFound),
+		errors.Is(err, repository.ErrCachedNotFound):
+		return errorClassEmptyResponse
+	case errors.Is(err, errOutsideServiceArea):
+		return errorClassOutsideArea
+	case errors.Is(err, geocoding.ErrNominatimInvalidCoords),
+		errors.Is(err, geocoding.ErrVisicomInvalidCoords),
+		errors.Is(err, geocoding.ErrBingInvalidCoords):
+		return errorClassInvalidCoords
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorClassTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return errorClassTimeout
+		}
+		return errorClassNetwork
+	}
+
+	return errorClassOther
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// timeoutError is a net.Error reporting a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "rate limited", err: &geocoding.RateLimitError{Provider: "here"}, expected: errorClassRateLimited},
+		{name: "visicom unauthorized", err: geocoding.ErrVisicomUnathorized, expected: errorClassUnauthorized},
+		{name: "here unauthorized", err: geocoding.ErrHereUnauthorized, expected: errorClassUnauthorized},
+		{
+			name:     "wrapped nominatim empty response",
+			err:      fmt.Errorf("geocode: %w", geocoding.ErrNominatimEmptyResponse),
+			expected: errorClassEmptyResponse,
+		},
+		{name: "google empty response", err: geocoding.ErrEmptyResponse, expected: errorClassEmptyResponse},
+		{name: "no coordinates", err: errNoCoordinates, expected: errorClassEmptyResponse},
+		{name: "cached not found", err: repository.ErrCachedNotFound, expected: errorClassEmptyResponse},
+		{name: "visicom invalid coords", err: geocoding.ErrVisicomInvalidCoords, expected: errorClassInvalidCoords},
+		{
+			name:     "outside service area",
+			err:      fmt.Errorf("%w: POINT(-74.006 40.7128)", errOutsideServiceArea),
+			expected: errorClassOutsideArea,
+		},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expected: errorClassTimeout},
+		{name: "network timeout", err: &net.OpError{Op: "dial", Err: timeoutError{}}, expected: errorClassTimeout},
+		{
+			name:     "connection reset",
+			err:      fmt.Errorf("request: %w", &net.OpError{Op: "read", Err: syscall.ECONNRESET}),
+			expected: errorClassNetwork,
+		},
+		{name: "unknown error", err: errors.New("boom"), expected: errorClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifyError(tt.err))
+		})
+	}
+}
+
+func TestNewGeocodeError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected models.GeocodeError
+	}{
+		{
+			name: "provider sentinel",
+			err:  fmt.Errorf("geocode: %w", geocoding.ErrHereUnauthorized),
+			expected: models.GeocodeError{
+				Code:    errorClassUnauthorized,
+				Message: "geocode: " + geocoding.ErrHereUnauthorized.Error(),
+			},
+		},
+		{
+			name:     "blank address",
+			err:      errBlankAddress,
+			expected: models.GeocodeError{Code: errorCodeInvalidAddress, Message: "address is blank"},
+		},
+		{
+			name:     "unknown error",
+			err:      errors.New("boom"),
+			expected: models.GeocodeError{Code: errorClassOther, Message: "boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newGeocodeError(tt.err))
+		})
+	}
+}
+
+func TestProcessTask_APIErrorClass(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "visicom", metrics, 1, 1*time.Second, "")
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Nowhere"}}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrVisicomUnathorized).Once()
+	mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, geocoding.ErrVisicomEmptyResponse).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 1, geocodeError(errorClassUnauthorized, geocoding.ErrVisicomUnathorized)).
+		Return(nil).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 2, geocodeError(errorClassEmptyResponse, geocoding.ErrVisicomEmptyResponse)).
+		Return(nil).Once()
+
+	service.processTask(ctx)
+
+	assert.InDelta(t, 1, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassUnauthorized)), 0)
+	assert.InDelta(t, 1, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassEmptyResponse)), 0)
+	assert.InDelta(t, 0, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassOther)), 0)
+}
+
+func TestProcessTask_NotFoundStatus(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	newService := func(t *testing.T, opts ...Option) (*GeocodingService, *mocks.Interface, *mocks.Provider) {
+		t.Helper()
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		opts = append(opts, WithNotFoundStatus(true))
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "visicom", metrics, 1, 1*time.Second, "",
+			opts...)
+
+		return service, mockRepo, mockProvider
+	}
+
+	t.Run("no match marks the task as not found and a transient error is retried", func(t *testing.T) {
+		service, mockRepo, mockProvider := newService(t)
+		ctx := t.Context()
+
+		sampleTasks := []models.Task{{ID: 1, Address: "Nowhere"}, {ID: 2, Address: "Kyiv"}}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, geocoding.ErrVisicomEmptyResponse).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrServerError).Once()
+		mockRepo.On("MarkTaskNotFound", ctx, 1, geocodeError(errorClassEmptyResponse, geocoding.ErrVisicomEmptyResponse)).
+			Return(nil).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 2, newGeocodeError(geocoding.ErrServerError)).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", ctx, 1, mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkTaskNotFound", ctx, 2, mock.Anything)
+	})
+
+	t.Run("dry run doesn't mark the task", func(t *testing.T) {
+		service, mockRepo, mockProvider := newService(t, WithDryRun(true))
+		ctx := t.Context()
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Nowhere"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, geocoding.ErrVisicomEmptyResponse).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "MarkTaskNotFound", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
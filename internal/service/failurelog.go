@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// maxFailureRecordSize is the longest line ReadFailureLog accepts, well above any real record.
+const maxFailureRecordSize = 1 << 20
+
+// FailureRecord describes a failed geocoding attempt of a task, with enough of its input
+// to replay it later against another provider or configuration.
+type FailureRecord struct {
+	TaskID     int                       `json:"task_id"`              // TaskID is the identifier of the task.
+	Address    string                    `json:"address"`              // Address is the task address as stored.
+	Structured *models.StructuredAddress `json:"structured,omitempty"` // Structured is the structured address, if any.
+	Language   string                    `json:"language,omitempty"`   // Language is the task language, if any.
+	Provider   string                    `json:"provider"`             // Provider is the name of the provider.
+	Class      string                    `json:"class"`                // Class is the error class used in metrics.
+	Error      string                    `json:"error"`                // Error is the failure reason.
+	FailedAt   time.Time                 `json:"failed_at"`            // FailedAt is when the attempt failed.
+}
+
+// FailureLog is a sink for failed geocoding attempts, kept apart from the audit log
+// so the failures can be replayed without scanning the database.
+type FailureLog interface {
+	Record(ctx context.Context, record FailureRecord) error
+}
+
+// JSONFailureLog appends failure records to a writer as JSON lines.
+type JSONFailureLog struct {
+	mu  sync.Mutex    // mu serializes the records of concurrent workers
+	enc *json.Encoder // enc writes one record per line
+}
+
+// NewJSONFailureLog creates a FailureLog that appends records to out, one JSON object per line.
+func NewJSONFailureLog(out io.Writer) *JSONFailureLog {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	return &JSONFailureLog{enc: enc}
+}
+
+// Record appends the failure record as a single JSON line.
+func (fl *JSONFailureLog) Record(_ context.Context, record FailureRecord) error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	if err := fl.enc.Encode(record); err != nil {
+		return fmt.Errorf("failed to write failure record: %w", err)
+	}
+
+	return nil
+}
+
+// ReadFailureLog reads the failure records written by a JSONFailureLog, in the order they were written.
+// Blank lines are skipped, and a line that isn't a valid record fails the whole read.
+func ReadFailureLog(in io.Reader) ([]FailureRecord, error) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, maxFailureRecordSize)
+
+	var records []FailureRecord
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record FailureRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode failure record on line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read failure log: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFailureLog(t *testing.T) {
+	ctx := t.Context()
+	failedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []FailureRecord{
+		{
+			TaskID:   1,
+			Address:  "с. Грабовець, вул. Польова, 3",
+			Provider: "nominatim",
+			Class:    errorClassEmptyResponse,
+			Error:    "empty response from geocoding provider",
+			FailedAt: failedAt,
+		},
+		{
+			TaskID:     2,
+			Structured: &models.StructuredAddress{City: "Київ", Street: "Хрещатик", HouseNumber: "1"},
+			Provider:   "google",
+			Class:      errorClassOutsideArea,
+			Error:      "geocoding result is outside the service area: POINT(-74.006 40.7128)",
+			FailedAt:   failedAt.Add(time.Minute),
+		},
+	}
+
+	var buf bytes.Buffer
+	failures := NewJSONFailureLog(&buf)
+	for _, record := range records {
+		require.NoError(t, failures.Record(ctx, record))
+	}
+
+	// One record per line, so the log can be appended to and grepped
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"address":"с. Грабовець, вул. Польова, 3"`)
+
+	read, err := ReadFailureLog(&buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, records, read)
+}
+
+func TestReadFailureLog(t *testing.T) {
+	t.Run("blank lines are skipped", func(t *testing.T) {
+		log := "\n" + `{"task_id":7,"address":"Київ","provider":"here","error":"boom"}` + "\n\n"
+
+		records, err := ReadFailureLog(strings.NewReader(log))
+
+		require.NoError(t, err)
+		assert.Equal(t, []FailureRecord{{TaskID: 7, Address: "Київ", Provider: "here", Error: "boom"}}, records)
+	})
+
+	t.Run("empty log", func(t *testing.T) {
+		records, err := ReadFailureLog(strings.NewReader(""))
+
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("invalid record", func(t *testing.T) {
+		log := `{"task_id":7,"address":"Київ"}` + "\n" + `{"task_id":` + "\n"
+
+		records, err := ReadFailureLog(strings.NewReader(log))
+
+		require.ErrorContains(t, err, "failed to decode failure record on line 2")
+		assert.Nil(t, records)
+	})
+}
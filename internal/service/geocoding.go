@@ -2,14 +2,24 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/UnknownOlympus/atlas/internal/geocoding"
 	"github.com/UnknownOlympus/atlas/internal/metrics"
 	"github.com/UnknownOlympus/atlas/internal/models"
 	"github.com/UnknownOlympus/atlas/internal/repository"
+	"github.com/UnknownOlympus/atlas/internal/webhook"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // GeocodingService provides methods for geocoding operations,
@@ -18,19 +28,351 @@ import (
 type GeocodingService struct {
 	log          *slog.Logger         // Logger for logging service activities
 	repo         repository.Interface // Interface for data repository access
-	provider     geocoding.Provider   // Geocoding provider for external geocoding services
-	providerName string               // Name of the provider for metrics labeling
 	metrics      *metrics.Metrics     // Metrics for tracking service performance
 	numWorkers   int                  // Number of concurrent workers for processing
 	pollInterval time.Duration        // Interval for polling geocoding updates
+	pollJitter   time.Duration        // Upper bound of the random delay added to each poll interval
+	firstPoll    bool                 // Poll immediately on start instead of after the first interval
+	pipeline     int                  // Batches fetched ahead of the workers within a poll, zero fetches one batch
+	minInterval  time.Duration        // Floor of the adaptive poll interval, used while polls find work
+	maxInterval  time.Duration        // Ceiling of the adaptive poll interval, used while polls find nothing
+	interval     time.Duration        // Current adaptive poll interval, zero until it is first adapted
+	stats        pollStats            // Outcomes of the task groups of the current poll
 	addresPrefix string               // Address prefix for more accurate geocoding (indicating country, city, etc.)
+	audit        AuditLogger          // Sink for geocoding audit records
+	failures     FailureLog           // Sink for failed attempts to replay later, nil disables it
+	stagger      time.Duration        // Upper bound of the random delay before a worker's first request
+	normalizer   AddressNormalizer    // Address preprocessing applied before geocoding
+	tracer       trace.Tracer         // Tracer for task and provider spans, nil disables tracing
+	dryRun       bool                 // Geocode without writing results to the database
+	fetchRetries int                  // Number of retries of a task fetch failed with a transient database error
+	fetchBackoff time.Duration        // Delay before the first fetch retry, doubled after each retry
+	cache        repository.Cache     // Persistent geocoding result cache, nil disables caching
+	claims       ClaimReleaser        // Releaser of the task claims left at the end of a poll, nil disables it
+	requestSlots chan struct{}        // Semaphore capping concurrent provider calls, nil leaves them bounded by workers
+	adaptive     bool                 // Adjust the cap on concurrent provider calls to rate limits and server errors
+	adaptiveCap  *adaptiveLimit       // Adaptive cap replacing requestSlots if adaptive is set
+	inFlight     sync.Map             // IDs of the tasks being processed, so that no task is processed twice at once
+	addrTemplate string               // Template the address is placed in before geocoding, empty sends it as is
+	budget       int                  // Upstream provider requests allowed per poll, zero for no limit
+	taskTimeout  time.Duration        // Deadline of the provider call of a task group, zero for none
+	pollTimeout  int                  // Multiple of the poll interval a poll may run for, zero for no bound
+	rewriteAddr  bool                 // Write the normalized address of geocoded tasks back to the database
+	structured   bool                 // Fetch structured addresses and geocode them field by field
+	serviceArea  *models.BoundingBox  // Area results must lie in, nil accepts results anywhere
+	staleAfter   time.Duration        // Age of coordinates refreshed while the queue is empty, zero disables it
+	what3words   What3WordsConverter  // Converter of geocoded coordinates to what3words addresses, nil disables it
+	notifier     CompletionNotifier   // Notifier of completed tasks, nil disables it
+	notFoundStop bool                 // Mark tasks without a match as not found instead of retrying them
+	failFast     bool                 // Abort the poll when the provider rejects the API key
+	unauthorized sync.Map             // Names of the providers that rejected the API key during the current poll
 
+	providers atomic.Pointer[providerSet] // Geocoding providers, replaced as a whole by SetProviders
+}
+
+// providerSet is the default geocoding provider together with the additional providers
+// that tasks can be routed to. Each batch uses the set that was current when it was fetched.
+type providerSet struct {
+	name     string                        // name is the name of the default provider for metrics labeling
+	provider geocoding.Provider            // provider is the default provider for external geocoding services
+	routed   map[string]geocoding.Provider // routed are the additional providers that tasks can be routed to by name
+}
+
+// of returns the name and the provider that geocode tasks routed to name, the default provider for empty.
+func (ps *providerSet) of(name string) (string, geocoding.Provider) {
+	if name == "" {
+		return ps.name, ps.provider
+	}
+
+	return name, ps.routed[name]
+}
+
+// Results of a geocoding cache lookup, used as metric labels.
+const (
+	cacheResultHit   = "hit"
+	cacheResultMiss  = "miss"
+	cacheResultError = "error"
+)
+
+// taskBatchSize is the number of tasks fetched at once.
+const taskBatchSize = 100
+
+// Defaults for retrying a task fetch that failed with a transient database error.
+const (
+	defaultFetchRetries = 3
+	defaultFetchBackoff = 500 * time.Millisecond
+)
+
+// Option configures optional behavior of the GeocodingService.
+type Option func(*GeocodingService)
+
+// WithAuditLogger sets the sink that receives an audit record for every geocoding attempt.
+func WithAuditLogger(audit AuditLogger) Option {
+	return func(gs *GeocodingService) {
+		gs.audit = audit
+	}
+}
+
+// WithFailureLog sets the sink that receives a record of every failed geocoding attempt, so the failures
+// can be replayed later against another provider or configuration. It is disabled by default.
+func WithFailureLog(failures FailureLog) Option {
+	return func(gs *GeocodingService) {
+		gs.failures = failures
+	}
+}
+
+// WithStaleRefresh makes the service geocode again the tasks whose coordinates were stored more than olderThan
+// ago, in polls that find no task without coordinates, so that addresses whose meaning changed, e.g. after new
+// buildings or renamed streets, are eventually corrected. New tasks always come first. Zero, the default,
+// disables the refresh. The repository must record when coordinates are stored (see repository.WithGeocodedAt).
+func WithStaleRefresh(olderThan time.Duration) Option {
+	return func(gs *GeocodingService) {
+		gs.staleAfter = olderThan
+	}
+}
+
+// WithWorkerStagger sets the upper bound of a random delay each worker waits before its first
+// request in a batch, so workers don't all hit the provider simultaneously. Zero disables the stagger.
+func WithWorkerStagger(stagger time.Duration) Option {
+	return func(gs *GeocodingService) {
+		gs.stagger = stagger
+	}
+}
+
+// WithAddressNormalizer sets the preprocessing applied to addresses before they are sent to the provider.
+func WithAddressNormalizer(normalizer AddressNormalizer) Option {
+	return func(gs *GeocodingService) {
+		gs.normalizer = normalizer
+	}
+}
+
+// WithAddressTemplate places the address of each task in template before it is sent to the provider,
+// replacing the AddressPlaceholder, e.g. "{address}, Україна" to add the country the database leaves out.
+// The template is applied after normalization and the address prefix, and the original address is kept
+// in the database. An empty template, the default, sends the address as is.
+func WithAddressTemplate(template string) Option {
+	return func(gs *GeocodingService) {
+		gs.addrTemplate = template
+	}
+}
+
+// WithTracerProvider enables OpenTelemetry spans around each batch, each worker task group
+// and each provider call, created with a tracer from tp. Tracing is disabled by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(gs *GeocodingService) {
+		gs.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// WithPollJitter sets the upper bound of a random delay added to each poll interval,
+// so that several replicas started together don't poll in lockstep. Zero disables the jitter.
+func WithPollJitter(jitter time.Duration) Option {
+	return func(gs *GeocodingService) {
+		gs.pollJitter = jitter
+	}
+}
+
+// WithAdaptivePolling adapts the poll interval to the polls: it is halved after each poll whose tasks the
+// provider geocoded, down to floor, so a backlog is worked off in tight loops, and doubled after each poll
+// that found no task or whose tasks all failed because the provider is failing, up to ceiling, so an idle
+// queue or a provider outage is polled less often. The interval starts at the configured poll interval,
+// which should lie between floor and ceiling. Zero floor and ceiling, the default, disable it.
+func WithAdaptivePolling(floor, ceiling time.Duration) Option {
+	return func(gs *GeocodingService) {
+		gs.minInterval = floor
+		gs.maxInterval = ceiling
+	}
+}
+
+// WithImmediatePoll sets whether the service polls for tasks as soon as it starts (the default),
+// or only after the first poll interval has elapsed.
+func WithImmediatePoll(enabled bool) Option {
+	return func(gs *GeocodingService) {
+		gs.firstPoll = enabled
+	}
+}
+
+// WithPipeline makes each poll fetch the next batches of tasks while the workers geocode the previous ones,
+// holding up to depth fetched batches ahead of them, until the backlog is drained. Zero, the default,
+// fetches a single batch per poll. Batches are only fetched past the tasks in flight if tasks are claimed
+// with repository.WithTaskClaim.
+func WithPipeline(depth int) Option {
+	return func(gs *GeocodingService) {
+		gs.pipeline = depth
+	}
+}
+
+// WithFetchRetry sets how many times a task fetch that failed with a transient database error
+// (see repository.IsTransient) is retried within a poll, and the delay before the first retry,
+// which doubles after each retry. Zero retries disables retrying.
+func WithFetchRetry(retries int, backoff time.Duration) Option {
+	return func(gs *GeocodingService) {
+		gs.fetchRetries = retries
+		gs.fetchBackoff = backoff
+	}
+}
+
+// WithDryRun makes the service geocode tasks without writing the results to the database.
+// The writes that would happen are logged instead, and metrics and audit records are still recorded,
+// so a provider or address normalization change can be evaluated against production data.
+func WithDryRun(dryRun bool) Option {
+	return func(gs *GeocodingService) {
+		gs.dryRun = dryRun
+	}
+}
+
+// WithGeocodeCache makes workers look up the address of each task group in cache before calling
+// the provider, and store successful provider results in it. Caching is disabled by default.
+func WithGeocodeCache(cache repository.Cache) Option {
+	return func(gs *GeocodingService) {
+		gs.cache = cache
+	}
+}
+
+// WithClaimReleaser makes the service release the claims of the instance on the fetched tasks at the end of
+// every poll, as taken with repository.WithTaskClaim, so that the tasks it left for the next poll aren't skipped
+// until their claim expires. It is disabled by default.
+func WithClaimReleaser(releaser ClaimReleaser) Option {
+	return func(gs *GeocodingService) {
+		gs.claims = releaser
+	}
+}
+
+// WithMaxConcurrentRequests caps the number of provider calls in flight at once, independently of the
+// number of workers, so that many workers can write results to the database while a provider with a low
+// concurrency allowance is called by only a few of them. Workers wait for a free slot before calling
+// the provider. Zero or less disables the cap.
+func WithMaxConcurrentRequests(limit int) Option {
+	return func(gs *GeocodingService) {
+		gs.requestSlots = nil
+		if limit > 0 {
+			gs.requestSlots = make(chan struct{}, limit)
+		}
+	}
+}
+
+// WithAdaptiveConcurrency makes the cap on provider calls in flight follow the provider's responses:
+// it is halved when a call is rate limited or gets a 5xx response, and grows back by one at a time on
+// sustained success, up to the cap set with WithMaxConcurrentRequests, or the number of workers without one.
+// The current cap is reported by the concurrency limit gauge. Disabled by default.
+func WithAdaptiveConcurrency(enabled bool) Option {
+	return func(gs *GeocodingService) {
+		gs.adaptive = enabled
+	}
+}
+
+// WithRequestBudget caps the number of upstream requests the providers make per poll, counting every
+// fallback request, so that a batch of hard addresses can't use up the provider quota unnoticed.
+// Once the budget is spent, the remaining task groups are left for the next poll without counting
+// a failed attempt, while cached addresses are still resolved. Zero or less disables the budget.
+func WithRequestBudget(limit int) Option {
+	return func(gs *GeocodingService) {
+		gs.budget = max(limit, 0)
+	}
+}
+
+// WithTaskTimeout bounds the provider call of each task group, fallbacks included, so that a single
+// pathological address can't hold a worker for long even if every request stays within its own timeout.
+// Tasks whose call runs out of time are left for the next poll without counting a failed attempt.
+// Zero or less disables the timeout.
+func WithTaskTimeout(timeout time.Duration) Option {
+	return func(gs *GeocodingService) {
+		gs.taskTimeout = max(timeout, 0)
+	}
+}
+
+// WithPollTimeout bounds each poll to the multiple of the poll interval, or of the current adaptive interval
+// if it is longer, so that a stuck provider or database call can't hold up polling indefinitely. Once the poll
+// runs out of time, its context is canceled and the tasks not geocoded yet are left for the next poll without
+// counting a failed attempt. Zero or less disables the timeout.
+func WithPollTimeout(multiple int) Option {
+	return func(gs *GeocodingService) {
+		gs.pollTimeout = max(multiple, 0)
+	}
+}
+
+// WithAddressRewrite makes the service replace the address of each geocoded task with its normalized form,
+// in the same update as the coordinates, so that messy addresses are cleaned up in the database.
+// The address prefix and template are not written back. Tasks whose address is already normalized
+// are updated as usual. Disabled by default, which keeps the original address.
+func WithAddressRewrite(enabled bool) Option {
+	return func(gs *GeocodingService) {
+		gs.rewriteAddr = enabled
+	}
+}
+
+// WithStructuredAddresses makes the service fetch tasks with their structured address, stored as a JSON object
+// in the address_json column, instead of a free-form address, and geocode it with providers implementing
+// geocoding.StructuredGeocoder field by field. Other providers geocode its free-form text, which is also
+// what is normalized, cached and audited. Disabled by default, which fetches free-form addresses.
+func WithStructuredAddresses(enabled bool) Option {
+	return func(gs *GeocodingService) {
+		gs.structured = enabled
+	}
+}
+
+// WithServiceArea makes the service reject geocoding results outside the area, e.g. a village matched
+// to its namesake in another country, as failures of the task with their own error code, so that the task
+// is retried on the next poll instead of storing a wrong point. Rejected results are not cached.
+// Nil, the default, accepts results anywhere.
+func WithServiceArea(area *models.BoundingBox) Option {
+	return func(gs *GeocodingService) {
+		gs.serviceArea = area
+	}
+}
+
+// WithWhat3Words makes the service convert the coordinates of geocoded tasks into their what3words address
+// with converter and store it with repository.Interface.UpdateTaskWhat3Words. A failed conversion doesn't
+// fail the task.
+func WithWhat3Words(converter What3WordsConverter) Option {
+	return func(gs *GeocodingService) {
+		gs.what3words = converter
+	}
+}
+
+// WithCompletionNotifier makes the service notify notifier of every task whose coordinates were stored,
+// and of every task marked as not found or as having an invalid address, so that consumers can be pushed
+// the outcome instead of polling the database. Nothing is notified in dry run. It is disabled by default.
+func WithCompletionNotifier(notifier CompletionNotifier) Option {
+	return func(gs *GeocodingService) {
+		gs.notifier = notifier
+	}
+}
+
+// WithNotFoundStatus makes the service mark a task whose address has no match, i.e. whose failure is classified
+// as an empty response, with repository.Interface.MarkTaskNotFound, so that it isn't retried on later polls.
+// Other failures, such as timeouts or server errors, still count as attempts and are retried.
+func WithNotFoundStatus(enabled bool) Option {
+	return func(gs *GeocodingService) {
+		gs.notFoundStop = enabled
+	}
+}
+
+// WithFailFastUnauthorized makes the service abort the tasks of the current poll routed to a provider as soon as
+// it rejects the API key, instead of failing each of them identically and counting an attempt for each: the tasks
+// are left for the next poll without counting attempts, tasks routed to other providers are still geocoded, and
+// the provider is reported on the unhealthy provider gauge until it geocodes an address again. Disabled by default.
+func WithFailFastUnauthorized(enabled bool) Option {
+	return func(gs *GeocodingService) {
+		gs.failFast = enabled
+	}
+}
+
+// WithProviders sets additional providers by name that tasks with a matching preferred provider
+// are routed to. Tasks without a preferred provider, or with an unknown one, use the default provider.
+func WithProviders(providers map[string]geocoding.Provider) Option {
+	return func(gs *GeocodingService) {
+		set := *gs.providers.Load()
+		set.routed = providers
+		gs.providers.Store(&set)
+	}
 }
 
 // NewGeocodingServie creates a new instance of GeocodingService.
 // It takes a logger, a repository interface, a geocoding provider,
 // provider name for metrics, metrics for monitoring, the number of workers
-// to use, and a polling interval for geocoding requests. It returns a pointer
+// to use, and a polling interval for geocoding requests. Optional behavior
+// can be configured with Option values. It returns a pointer
 // to the newly created GeocodingService.
 func NewGeocodingServie(
 	log *slog.Logger,
@@ -41,131 +383,1313 @@ func NewGeocodingServie(
 	numWorkers int,
 	pollInterval time.Duration,
 	addressPrefix string,
+	opts ...Option,
 ) *GeocodingService {
-	return &GeocodingService{
+	gs := &GeocodingService{
 		log:          log,
 		repo:         repo,
-		provider:     provider,
-		providerName: providerName,
 		metrics:      metrics,
 		numWorkers:   numWorkers,
 		pollInterval: pollInterval,
+		firstPoll:    true,
 		addresPrefix: addressPrefix,
+		audit:        NopAuditLogger{},
+		normalizer:   UkrainianAddressNormalizer{},
+		fetchRetries: defaultFetchRetries,
+		fetchBackoff: defaultFetchBackoff,
+	}
+	gs.providers.Store(&providerSet{name: providerName, provider: provider})
+
+	for _, opt := range opts {
+		opt(gs)
 	}
+
+	// The adaptive cap starts at the fixed cap, or at the number of workers without one
+	if gs.adaptive {
+		maxLimit := numWorkers
+		if gs.requestSlots != nil {
+			maxLimit = cap(gs.requestSlots)
+		}
+		gs.adaptiveCap = newAdaptiveLimit(maxLimit, metrics.ConcurrencyLimit)
+		gs.requestSlots = nil
+	}
+
+	return gs
 }
 
-// Run starts the geocoding service, which periodically polls for new tasks to geocode.
+// SetProviders replaces the default provider, its name and the additional providers that tasks can be
+// routed to, e.g. after an API key rotation. It is safe to call while the service is running:
+// task groups already being processed finish with the previous providers, and the next batch
+// is geocoded with the new ones.
+func (gs *GeocodingService) SetProviders(
+	provider geocoding.Provider,
+	providerName string,
+	routed map[string]geocoding.Provider,
+) {
+	gs.providers.Store(&providerSet{name: providerName, provider: provider, routed: routed})
+}
+
+// Run starts the geocoding service, which polls for new tasks to geocode on start
+// and then periodically, every poll interval plus a random jitter. The interval is recomputed after each poll
+// if adaptive polling is enabled with WithAdaptivePolling.
 // It listens for a cancellation signal from the context to gracefully stop the service.
 func (gs *GeocodingService) Run(ctx context.Context) {
-	ticker := time.NewTicker(gs.pollInterval)
-	defer ticker.Stop()
+	gs.RunUntil(ctx, ctx.Done())
+}
 
+// RunUntil runs the geocoding service like Run, but stops polling once stop is closed, and returns
+// after the batch in progress, if any, has finished. Canceling ctx aborts the batch in progress as well,
+// so a shutdown can first drain the current batch and still be forced.
+func (gs *GeocodingService) RunUntil(ctx context.Context, stop <-chan struct{}) {
 	gs.log.InfoContext(ctx, "Geocoding service started...")
 
+	lastPoll := time.Now()
+	if gs.firstPoll && !stopped(ctx, stop) {
+		gs.adaptInterval(gs.poll(ctx, stop))
+	}
+
+	timer := time.NewTimer(time.Until(gs.followingPollAt(ctx, lastPoll)))
+	defer timer.Stop()
+
 	for {
-		select {
-		case <-ctx.Done():
+		if stopped(ctx, stop) {
 			gs.log.InfoContext(ctx, "Goecoding service stopped.")
 			return
-		case <-ticker.C:
-			gs.log.InfoContext(ctx, "Polling for new tasks to geocode...")
-			gs.processTask(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		case <-timer.C:
+			// The poll may have become due just as the service was stopped
+			if stopped(ctx, stop) {
+				continue
+			}
+			lastPoll = time.Now()
+			gs.adaptInterval(gs.poll(ctx, stop))
+			timer.Reset(time.Until(gs.followingPollAt(ctx, lastPoll)))
+		}
+	}
+}
+
+// stopped reports whether the service must not start another poll, because ctx is done or stop is closed.
+func stopped(ctx context.Context, stop <-chan struct{}) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// nextPollAt returns when the poll following the one started at lastPoll is due.
+// Like a ticker, the interval is measured between poll starts.
+func (gs *GeocodingService) nextPollAt(lastPoll time.Time) time.Time {
+	return lastPoll.Add(gs.currentInterval() + staggerDelay(gs.pollJitter))
+}
+
+// followingPollAt returns when the poll following the one started at lastPoll, which has just finished,
+// is due. Polls run one at a time: if the batch took longer than the interval, the polls that became due
+// while it was running are skipped and counted, and the next poll is due an interval after the batch finished,
+// so a slow backlog is polled at the configured pace instead of back to back.
+func (gs *GeocodingService) followingPollAt(ctx context.Context, lastPoll time.Time) time.Time {
+	next := gs.nextPollAt(lastPoll)
+	now := time.Now()
+	interval := gs.currentInterval()
+	if next.After(now) || interval <= 0 {
+		return next
+	}
+
+	elapsed := now.Sub(lastPoll)
+	skipped := max(int(elapsed/interval), 1)
+	gs.metrics.PollsSkipped.Add(float64(skipped))
+	gs.log.WarnContext(ctx, "Batch took longer than the poll interval, skipping overlapping polls",
+		"duration", elapsed, "interval", interval, "skipped", skipped)
+
+	return gs.nextPollAt(now)
+}
+
+// poll refreshes the pending tasks gauge and processes a batch of tasks, or the whole backlog if pipelining
+// is enabled with WithPipeline, and returns the number of tasks found. A pipelined poll stops fetching
+// once stop is closed. The poll is bounded by the poll timeout, if one is set with WithPollTimeout.
+func (gs *GeocodingService) poll(ctx context.Context, stop <-chan struct{}) int {
+	if timeout := gs.currentPollTimeout(); timeout > 0 {
+		pollCtx, cancel := context.WithTimeoutCause(ctx, timeout, errPollTimeout)
+		defer cancel()
+		defer gs.logPollTimeout(pollCtx, timeout)
+		ctx = pollCtx
+	}
+
+	gs.log.InfoContext(ctx, "Polling for new tasks to geocode...")
+	gs.updatePendingTasks(ctx)
+	gs.stats.reset()
+	gs.unauthorized.Clear()
+
+	if gs.pipeline > 0 {
+		return gs.processPipeline(ctx, stop)
+	}
+
+	return gs.processTask(ctx)
+}
+
+// currentPollTimeout returns the deadline of the next poll: the poll timeout multiple of the poll interval,
+// or of the current adaptive interval if it is longer. It returns zero if the poll timeout is disabled.
+func (gs *GeocodingService) currentPollTimeout() time.Duration {
+	return time.Duration(gs.pollTimeout) * max(gs.pollInterval, gs.currentInterval())
+}
+
+// logPollTimeout counts and logs the poll if it ran out of the poll timeout.
+func (gs *GeocodingService) logPollTimeout(ctx context.Context, timeout time.Duration) {
+	if !errors.Is(context.Cause(ctx), errPollTimeout) {
+		return
+	}
+
+	gs.metrics.PollTimeouts.Inc()
+	gs.log.WarnContext(ctx, "Poll timeout exceeded, remaining tasks are left for the next poll", "timeout", timeout)
+}
+
+// updatePendingTasks refreshes the pending tasks gauge with the current backlog size, counting the tasks
+// with a structured address if enabled with WithStructuredAddresses. On error the gauge keeps its previous value.
+func (gs *GeocodingService) updatePendingTasks(ctx context.Context) {
+	count := gs.repo.CountPendingTasks
+	if gs.structured {
+		count = gs.repo.CountPendingStructuredTasks
+	}
+
+	pending, err := count(ctx)
+	if err != nil {
+		gs.log.ErrorContext(ctx, "Failed to count pending tasks", "error", err)
+		return
+	}
+
+	gs.metrics.PendingTasks.Set(float64(pending))
+}
+
+// taskGroup is a set of tasks from a single batch that share the same normalized address, provider and language.
+// The address is geocoded once and the result is applied to every task in the group.
+type taskGroup struct {
+	address  string        // address is the original address of the first task in the group
+	tasks    []models.Task // tasks share the same normalized address
+	provider string        // provider is the name of the provider the tasks are routed to, empty for the default
+	language string        // language is the preferred result language of the tasks, empty for the default
+
+	// structured is the structured address of the first task in the group, nil for a free-form address
+	structured *models.StructuredAddress
+
+	providers *providerSet // providers is the provider set of the batch the group belongs to
+}
+
+// normalizeAddress returns a canonical form of the address used to detect duplicates:
+// lower-cased, with surrounding whitespace trimmed and inner whitespace collapsed.
+func normalizeAddress(address string) string {
+	return strings.Join(strings.Fields(strings.ToLower(address)), " ")
+}
+
+// groupTasksByAddress groups tasks by preferred provider, language and normalized address, preserving the order
+// in which each distinct address first appears in the batch.
+func groupTasksByAddress(tasks []models.Task) []taskGroup {
+	type groupKey struct{ provider, language, address string }
+
+	index := make(map[groupKey]int, len(tasks))
+	groups := make([]taskGroup, 0, len(tasks))
+
+	for _, task := range tasks {
+		key := groupKey{
+			provider: task.PreferredProvider,
+			language: task.Language,
+			address:  normalizeAddress(task.Address),
+		}
+		if i, ok := index[key]; ok {
+			groups[i].tasks = append(groups[i].tasks, task)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, taskGroup{
+			address:    task.Address,
+			tasks:      []models.Task{task},
+			provider:   task.PreferredProvider,
+			language:   task.Language,
+			structured: task.Structured,
+		})
+	}
+
+	return groups
+}
+
+// routeTasks resolves the preferred provider of each task to the name of an additional provider of the set,
+// or to empty for the default provider. Tasks routed to the same provider can then share a group.
+func (gs *GeocodingService) routeTasks(ctx context.Context, providers *providerSet, tasks []models.Task) {
+	for i, task := range tasks {
+		name := task.PreferredProvider
+		if name == "" || name == providers.name {
+			tasks[i].PreferredProvider = ""
+			continue
+		}
+
+		if _, ok := providers.routed[name]; !ok {
+			gs.log.WarnContext(ctx, "Unknown preferred provider, using the default provider",
+				"task", task.ID, "preferred_provider", name, "provider", providers.name)
+			tasks[i].PreferredProvider = ""
 		}
 	}
 }
 
+// providerOf returns the name and the provider that geocode the group's address.
+func providerOf(group taskGroup) (string, geocoding.Provider) {
+	return group.providers.of(group.provider)
+}
+
 // processTask fetches tasks for geocoding from the repository, starts a worker pool to process the tasks,
-// and waits for all workers to finish. It logs errors if task fetching fails and logs the status of task processing.
-func (gs *GeocodingService) processTask(ctx context.Context) {
-	taskLimit := 100
-	tasks, err := gs.repo.FetchTasksForGeocoding(ctx, taskLimit)
+// and waits for all workers to finish. Tasks sharing the same address are geocoded only once.
+// It logs errors if task fetching fails and logs the status of task processing.
+// It returns the number of tasks fetched.
+func (gs *GeocodingService) processTask(ctx context.Context) int {
+	ctx, span := gs.startSpan(ctx, "GeocodingService.processTask")
+	defer span.End()
+
+	tasks, err := gs.fetchTasks(ctx, taskBatchSize)
 	if err != nil {
 		gs.log.ErrorContext(ctx, "Failed to fetch tasks", "error", err)
-		return
+		recordSpanError(span, err)
+		return 0
+	}
+	if len(tasks) == 0 {
+		tasks, err = gs.fetchStaleTasks(ctx, taskBatchSize)
+		if err != nil {
+			gs.log.ErrorContext(ctx, "Failed to fetch stale tasks", "error", err)
+			recordSpanError(span, err)
+			return 0
+		}
 	}
 	if len(tasks) == 0 {
 		gs.log.InfoContext(ctx, "No tasks to process.")
-		return
+		return 0
+	}
+	found := len(tasks)
+
+	tasks = gs.claimInFlight(ctx, tasks)
+	defer gs.releaseInFlight(tasks)
+	defer gs.releaseClaims(ctx, tasks)
+
+	tasks = gs.skipInvalidAddresses(ctx, tasks)
+	if len(tasks) == 0 {
+		return found
+	}
+
+	if gs.budget > 0 {
+		budget := geocoding.NewRequestBudget(gs.budget)
+		ctx = geocoding.WithRequestBudget(ctx, budget)
+		defer gs.logBudgetExhausted(ctx, budget)
+	}
+
+	groups := gs.groupBatch(ctx, tasks)
+	span.SetAttributes(attribute.Int(attrTasks, len(tasks)), attribute.Int(attrJobs, len(groups)))
+
+	if groups = gs.processNativeBatch(ctx, groups); len(groups) > 0 {
+		gs.runWorkerPool(ctx, len(tasks), groups)
 	}
+	gs.log.InfoContext(ctx, "Processing batch finished")
+
+	return found
+}
 
+// runWorkerPool geocodes the task groups of a batch of tasks with a pool of workers, and waits for them to finish.
+func (gs *GeocodingService) runWorkerPool(ctx context.Context, tasks int, groups []taskGroup) {
+	numWorkers := gs.poolSize(len(groups))
 	gs.log.InfoContext(
 		ctx,
 		"Found tasks to process. Starting worker pool.",
+		"tasks",
+		tasks,
 		"jobs",
-		len(tasks),
+		len(groups),
 		"num_workers",
-		gs.numWorkers,
+		numWorkers,
 	)
 
-	jobs := make(chan models.Task, len(tasks))
+	jobs := make(chan taskGroup, len(groups))
 	var wgr sync.WaitGroup
 
-	for i := 1; i <= gs.numWorkers; i++ {
+	for i := 1; i <= numWorkers; i++ {
 		wgr.Add(1)
 		go gs.worker(ctx, i, &wgr, jobs)
 	}
 
-	for _, task := range tasks {
-		jobs <- task
+	for _, group := range groups {
+		jobs <- group
 	}
 	close(jobs)
 
 	wgr.Wait()
-	gs.log.InfoContext(ctx, "Processing batch finished")
 }
 
-// worker processes tasks from the jobs channel. It increments the active worker count,
-// logs the processing of each task, and measures the time taken for geocoding.
-// In case of an error, it updates the failure count and logs the error.
-// On successful geocoding, it updates the task with the obtained coordinates.
+// groupBatch groups the tasks of a batch by address. The whole batch is geocoded with the providers current
+// at this point, so a concurrent SetProviders only affects the next batch.
+func (gs *GeocodingService) groupBatch(ctx context.Context, tasks []models.Task) []taskGroup {
+	providers := gs.providers.Load()
+	gs.routeTasks(ctx, providers, tasks)
+	groups := groupTasksByAddress(tasks)
+	for i := range groups {
+		groups[i].providers = providers
+	}
+
+	return groups
+}
+
+// processNativeBatch geocodes the groups of the default provider with a single batch call if it has a native
+// batch API (see geocoding.SupportsBatch), and returns the other groups, which are left to the worker pool.
+// Without a native batch API, all groups are returned.
+func (gs *GeocodingService) processNativeBatch(ctx context.Context, groups []taskGroup) []taskGroup {
+	if len(groups) == 0 || !geocoding.SupportsBatch(groups[0].providers.provider) {
+		return groups
+	}
+
+	batched, rest := splitBatchGroups(groups)
+	if len(batched) > 0 {
+		gs.processBatchRecovering(ctx, batched)
+	}
+
+	return rest
+}
+
+// claimInFlight marks the tasks as being processed and returns those that weren't already, so a task listed
+// twice in a batch, or fetched again while it is still being processed, is processed only once.
+// This only guards a single instance: replicas are kept apart by the task claims of repository.WithTaskClaim.
+func (gs *GeocodingService) claimInFlight(ctx context.Context, tasks []models.Task) []models.Task {
+	claimed := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if _, loaded := gs.inFlight.LoadOrStore(task.ID, struct{}{}); loaded {
+			gs.log.WarnContext(ctx, "Skipping task that is already being processed", "task", task.ID)
+			gs.metrics.DuplicateTasks.Inc()
+			continue
+		}
+		claimed = append(claimed, task)
+	}
+
+	return claimed
+}
+
+// releaseInFlight unmarks the tasks claimed by claimInFlight once they have been processed.
+func (gs *GeocodingService) releaseInFlight(tasks []models.Task) {
+	for _, task := range tasks {
+		gs.inFlight.Delete(task.ID)
+	}
+}
+
+// skipInvalidAddresses returns the tasks whose address is left non-empty by trimming and normalization.
+// The others, e.g. whitespace-only addresses, are marked as invalid instead of being sent to the provider,
+// which would only fail them and spend an attempt on each of them.
+func (gs *GeocodingService) skipInvalidAddresses(ctx context.Context, tasks []models.Task) []models.Task {
+	valid := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if strings.TrimSpace(gs.normalizer.Normalize(task.Address)) == "" {
+			gs.handleInvalidAddress(ctx, task)
+			continue
+		}
+		valid = append(valid, task)
+	}
+
+	return valid
+}
+
+// logBudgetExhausted warns if the request budget of the poll ran out, so some tasks were left for the next poll.
+func (gs *GeocodingService) logBudgetExhausted(ctx context.Context, budget *geocoding.RequestBudget) {
+	if budget.Exhausted() {
+		gs.log.WarnContext(ctx, "Request budget of the poll exhausted, remaining tasks are left for the next poll",
+			"budget", gs.budget)
+	}
+}
+
+// splitBatchGroups splits the groups into those geocoded by the default provider from free-form text in its
+// default language, and the others: routed to an additional provider, asking for their own language
+// or with a structured address, which a batch call can't carry per address.
+func splitBatchGroups(groups []taskGroup) ([]taskGroup, []taskGroup) {
+	var batched, rest []taskGroup
+	for _, group := range groups {
+		if group.provider == "" && group.language == "" && group.structured == nil {
+			batched = append(batched, group)
+		} else {
+			rest = append(rest, group)
+		}
+	}
+
+	return batched, rest
+}
+
+// poolSize returns the number of workers to start for the given number of jobs:
+// the configured number of workers, but no more than there are jobs to process.
+func (gs *GeocodingService) poolSize(jobs int) int {
+	return min(gs.numWorkers, jobs)
+}
+
+// fetchTasks fetches tasks for geocoding, with their structured address if enabled with WithStructuredAddresses,
+// retrying with exponential backoff if the fetch fails with a transient database error, so that a brief
+// database hiccup doesn't cost a whole poll interval. Other errors are returned immediately, and retrying
+// stops when the context is cancelled.
+func (gs *GeocodingService) fetchTasks(ctx context.Context, limit int) ([]models.Task, error) {
+	fetch := gs.repo.FetchTasksForGeocoding
+	if gs.structured {
+		fetch = gs.repo.FetchStructuredTasksForGeocoding
+	}
+
+	backoff := gs.fetchBackoff
+
+	for retry := 1; ; retry++ {
+		tasks, err := fetch(ctx, limit)
+		if err == nil || retry > gs.fetchRetries || !repository.IsTransient(err) {
+			return tasks, err
+		}
+
+		gs.log.WarnContext(ctx, "Transient database error while fetching tasks, retrying",
+			"retry", retry, "delay", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// fetchStaleTasks fetches the tasks whose coordinates are due for a refresh if it is enabled with
+// WithStaleRefresh, marked with models.Task.Refresh, and returns none otherwise.
+func (gs *GeocodingService) fetchStaleTasks(ctx context.Context, limit int) ([]models.Task, error) {
+	if gs.staleAfter <= 0 {
+		return nil, nil
+	}
+
+	tasks, err := gs.repo.FetchStaleTasks(ctx, gs.staleAfter, limit)
+	if err == nil && len(tasks) > 0 {
+		gs.log.InfoContext(ctx, "Refreshing stale coordinates", "tasks", len(tasks), "older_than", gs.staleAfter)
+	}
+	for i := range tasks {
+		tasks[i].Refresh = true
+	}
+
+	return tasks, err
+}
+
+// worker processes task groups from the jobs channel. It increments the active worker count,
+// logs the processing of each group, and measures the time taken for geocoding.
+// Each distinct address is geocoded once and the outcome is applied to all tasks in the group:
+// in case of an error, it updates the failure count of every task and logs the error,
+// unless the provider rate-limited the request, in which case the tasks are left for the next poll;
+// on successful geocoding, it updates every task with the obtained coordinates.
 // The function takes a context, an index for the worker, a wait group to signal completion,
-// and a channel of tasks to process.
-func (gs *GeocodingService) worker(ctx context.Context, idx int, wg *sync.WaitGroup, jobs <-chan models.Task) {
+// and a channel of task groups to process.
+func (gs *GeocodingService) worker(ctx context.Context, idx int, wg *sync.WaitGroup, jobs <-chan taskGroup) {
 	defer wg.Done()
-	for task := range jobs {
-		var err error
 
-		gs.metrics.ActiveWorkers.Inc()
-		gs.log.DebugContext(ctx, "Processing task", "worker", idx, "task", task.ID)
+	if delay := staggerDelay(gs.stagger); delay > 0 {
+		gs.log.DebugContext(ctx, "Worker start staggered", "worker", idx, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
+	for group := range jobs {
+		gs.processGroupRecovering(ctx, idx, group)
+	}
+}
 
-		task.Address = gs.addresPrefix + task.Address
-		startTime := time.Now()
-		coords, err := gs.provider.Geocode(ctx, task.Address)
-		duration := time.Since(startTime).Seconds()
-		gs.metrics.RequestSeconds.WithLabelValues(gs.providerName).Observe(duration)
+// processGroupRecovering processes the group like processGroup, recovering from a panic of the provider
+// or the service, so that a single bad task or response takes down neither the worker nor the batch.
+// The tasks of a group that panicked are marked as failed, so a task panicking every time runs out of attempts.
+func (gs *GeocodingService) processGroupRecovering(ctx context.Context, idx int, group taskGroup) {
+	dequeuedAt := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			gs.handlePanic(ctx, idx, recovered, dequeuedAt, group)
+		}
+	}()
 
-		if err != nil {
-			gs.log.ErrorContext(ctx, "Failed to geocode", "worker", idx, "task", task.ID, "error", err)
-			gs.metrics.TaskProcessed.WithLabelValues("failure").Inc()
-			gs.metrics.APIErrors.Inc()
-
-			if err = gs.repo.IncrementFailureCount(ctx, task.ID, err.Error()); err != nil {
-				gs.log.ErrorContext(
-					ctx,
-					"Could not update failure count for task",
-					"worker", idx,
-					"task", task.ID,
-					"error", err,
-				)
+	gs.processGroup(ctx, idx, group, dequeuedAt)
+}
+
+// handlePanic records a panic recovered while processing the groups, and marks each of their tasks as failed.
+func (gs *GeocodingService) handlePanic(
+	ctx context.Context,
+	idx int,
+	recovered any,
+	dequeuedAt time.Time,
+	groups ...taskGroup,
+) {
+	var tasks []models.Task
+	for _, group := range groups {
+		tasks = append(tasks, group.tasks...)
+	}
+	gs.metrics.WorkerPanics.Inc()
+	gs.log.ErrorContext(ctx, "Recovered from a panic while processing task group", "worker", idx,
+		"tasks", taskIDs(tasks), "panic", recovered, "stack", string(debug.Stack()))
+
+	err := fmt.Errorf("%w: %v", errWorkerPanic, recovered)
+	for _, group := range groups {
+		providerName, _ := providerOf(group)
+		for _, task := range group.tasks {
+			gs.handleFailure(ctx, idx, task, providerName, err, dequeuedAt)
+		}
+	}
+}
+
+// processGroup geocodes the address of a task group with its provider and applies the result to its tasks,
+// within a span covering the provider call and the database updates. If caching is enabled,
+// cached coordinates are used without calling the provider, and provider results are cached.
+// Groups routed to an additional provider bypass the cache, since they ask for that provider's result.
+func (gs *GeocodingService) processGroup(ctx context.Context, idx int, group taskGroup, dequeuedAt time.Time) {
+	gs.metrics.ActiveWorkers.Inc()
+	defer gs.metrics.ActiveWorkers.Dec()
+
+	ctx, span := gs.startSpan(ctx, "GeocodingService.worker",
+		attribute.Int(attrWorker, idx),
+		attribute.IntSlice(attrTaskIDs, taskIDs(group.tasks)),
+	)
+	defer span.End()
+
+	gs.log.DebugContext(ctx, "Processing task group", "worker", idx, "tasks", len(group.tasks))
+
+	address := gs.providerAddress(group)
+	routed := group.provider != ""
+	if !routed && gs.applyCachedResult(ctx, idx, group, address, dequeuedAt) {
+		return
+	}
+
+	if budget := geocoding.RequestBudgetFrom(ctx); budget != nil && budget.Exhausted() {
+		gs.deferGroup(ctx, idx, group)
+		return
+	}
+	name, provider := providerOf(group)
+	if gs.providerAborted(name) {
+		return
+	}
+
+	// The slot is held for the provider call only, not for the database updates
+	generation, ok := gs.acquireRequestSlot(ctx)
+	if !ok {
+		gs.log.DebugContext(ctx, "Stopped waiting for a provider request slot, tasks are left for the next poll",
+			"worker", idx, "error", ctx.Err())
+		return
+	}
+	startTime := time.Now()
+	result, err := gs.geocodeInRequestSlot(geocoding.WithLanguage(ctx, group.language), generation, name, provider,
+		address, group.structured)
+	elapsed := time.Since(startTime)
+	gs.metrics.RequestSeconds.WithLabelValues(name).Observe(elapsed.Seconds())
+
+	if !routed {
+		gs.storeCachedResult(ctx, address, name, result, err)
+	}
+
+	gs.applyGroupResult(ctx, idx, group, address, result, err, elapsed, dequeuedAt)
+}
+
+// applyCachedResult applies the cached result of the address to the group's tasks if caching is enabled and
+// the address is cached, as coordinates or as not found, and reports whether it did.
+func (gs *GeocodingService) applyCachedResult(
+	ctx context.Context,
+	idx int,
+	group taskGroup,
+	address string,
+	dequeuedAt time.Time,
+) bool {
+	coords, notFound := gs.cachedCoordinates(ctx, address)
+	if notFound {
+		gs.applyGroupResult(ctx, idx, group, address, nil, repository.ErrCachedNotFound, 0, dequeuedAt)
+		return true
+	}
+	if coords != nil {
+		gs.log.DebugContext(ctx, "Using cached coordinates", "worker", idx, "address", address)
+		gs.applyGroupResult(ctx, idx, group, address, resultOf(coords), nil, 0, dequeuedAt)
+		return true
+	}
+
+	return false
+}
+
+// storeCachedResult caches the outcome of the named provider for the address: its coordinates if they lie
+// in the service area, or that it found nothing.
+func (gs *GeocodingService) storeCachedResult(
+	ctx context.Context,
+	address string,
+	name string,
+	result *models.GeocodeResult,
+	err error,
+) {
+	switch {
+	case err == nil && result != nil && gs.inServiceArea(result.Coordinates):
+		gs.storeCachedCoordinates(ctx, address, &result.Coordinates, name)
+	case classifyError(err) == errorClassEmptyResponse:
+		gs.storeCachedNotFound(ctx, address, name)
+	}
+}
+
+// acquireRequestSlot waits until a provider call may start without exceeding the configured cap,
+// and returns the generation of the adaptive cap the call starts in, zero without one.
+// It returns false if the context is done first. Without a cap it returns true immediately.
+func (gs *GeocodingService) acquireRequestSlot(ctx context.Context) (uint64, bool) {
+	if gs.adaptiveCap != nil {
+		return gs.adaptiveCap.acquire(ctx)
+	}
+	if gs.requestSlots == nil {
+		return 0, true
+	}
+
+	select {
+	case gs.requestSlots <- struct{}{}:
+		return 0, true
+	case <-ctx.Done():
+		return 0, false
+	}
+}
+
+// releaseRequestSlot frees the slot taken by acquireRequestSlot, and adjusts the adaptive cap, if any,
+// to the outcome err of the provider call.
+func (gs *GeocodingService) releaseRequestSlot(generation uint64, err error) {
+	if gs.adaptiveCap != nil {
+		gs.adaptiveCap.release(generation, err)
+		return
+	}
+	if gs.requestSlots != nil {
+		<-gs.requestSlots
+	}
+}
+
+// geocodeInRequestSlot geocodes the address like geocodeWithinTaskTimeout, and then releases the request slot
+// taken by acquireRequestSlot in generation, even if the provider panics.
+func (gs *GeocodingService) geocodeInRequestSlot(
+	ctx context.Context,
+	generation uint64,
+	name string,
+	provider geocoding.Provider,
+	address string,
+	structured *models.StructuredAddress,
+) (result *models.GeocodeResult, err error) {
+	defer func() { gs.releaseRequestSlot(generation, err) }()
+
+	return gs.geocodeWithinTaskTimeout(ctx, name, provider, address, structured)
+}
+
+// cachedCoordinates returns the cached coordinates of the address, or nil if caching is disabled,
+// the address is not cached or the lookup failed. A failed lookup falls back to the provider.
+// notFound reports that the address is cached as not found by the provider.
+func (gs *GeocodingService) cachedCoordinates(
+	ctx context.Context,
+	address string,
+) (coords *models.Coordinates, notFound bool) {
+	if gs.cache == nil {
+		return nil, false
+	}
+
+	coords, err := gs.cache.LookupCachedCoordinates(ctx, address)
+	switch {
+	case errors.Is(err, repository.ErrCacheMiss):
+		gs.metrics.CacheLookups.WithLabelValues(cacheResultMiss).Inc()
+		return nil, false
+	case errors.Is(err, repository.ErrCachedNotFound):
+		gs.metrics.NegativeCacheHits.Inc()
+		return nil, true
+	case err != nil:
+		gs.log.WarnContext(ctx, "Failed to look up cached coordinates", "address", address, "error", err)
+		gs.metrics.CacheLookups.WithLabelValues(cacheResultError).Inc()
+		return nil, false
+	}
+
+	gs.metrics.CacheLookups.WithLabelValues(cacheResultHit).Inc()
+	return coords, false
+}
+
+// storeCachedCoordinates caches the result of the named provider for the address if caching is enabled.
+// Failures are only logged, and nothing is written in dry-run mode.
+func (gs *GeocodingService) storeCachedCoordinates(
+	ctx context.Context,
+	address string,
+	coords *models.Coordinates,
+	providerName string,
+) {
+	if gs.cache == nil {
+		return
+	}
+
+	if gs.dryRun {
+		gs.log.InfoContext(ctx, "Dry run: would cache coordinates", "address", address)
+		return
+	}
+
+	if err := gs.cache.StoreCachedCoordinates(ctx, address, *coords, providerName); err != nil {
+		gs.log.WarnContext(ctx, "Failed to cache coordinates", "address", address, "error", err)
+	}
+}
+
+// storeCachedNotFound caches that the named provider found nothing for the address if caching is enabled.
+// Failures are only logged, and nothing is written in dry-run mode.
+func (gs *GeocodingService) storeCachedNotFound(ctx context.Context, address string, providerName string) {
+	if gs.cache == nil {
+		return
+	}
+
+	if gs.dryRun {
+		gs.log.InfoContext(ctx, "Dry run: would cache address as not found", "address", address)
+		return
+	}
+
+	if err := gs.cache.StoreCachedNotFound(ctx, address, providerName); err != nil {
+		gs.log.WarnContext(ctx, "Failed to cache address as not found", "address", address, "error", err)
+	}
+}
+
+// geocode calls the named provider within a span carrying the provider name, the address length
+// and, on success, the fallback level that matched. Providers implementing geocoding.DetailedGeocoder
+// are asked for the place metadata of the match as well. A structured address, if not nil, is geocoded
+// with geocoding.GeocodeStructured instead of the address.
+func (gs *GeocodingService) geocode(
+	ctx context.Context,
+	name string,
+	provider geocoding.Provider,
+	address string,
+	structured *models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	ctx, span := gs.startSpan(ctx, "Provider.Geocode",
+		attribute.String(attrProvider, name),
+		attribute.Int(attrAddressLength, utf8.RuneCountInString(address)),
+	)
+	defer span.End()
+
+	var (
+		result *models.GeocodeResult
+		err    error
+	)
+	if structured != nil {
+		result, err = geocoding.GeocodeStructured(ctx, provider, *structured)
+	} else {
+		result, err = geocoding.GeocodeDetailed(ctx, provider, address)
+	}
+	if err != nil {
+		recordSpanError(span, err)
+		return nil, err
+	}
+	if result != nil {
+		span.SetAttributes(attribute.Int(attrFallbackLevel, result.FallbackLevel))
+	}
+
+	return result, nil
+}
+
+// geocodeWithinTaskTimeout calls geocode with the task timeout applied, if one is set. An error caused by
+// the task timeout running out, rather than by the provider or the caller's context, wraps errTaskTimeout.
+func (gs *GeocodingService) geocodeWithinTaskTimeout(
+	ctx context.Context,
+	name string,
+	provider geocoding.Provider,
+	address string,
+	structured *models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	if gs.taskTimeout <= 0 {
+		return gs.geocode(ctx, name, provider, address, structured)
+	}
+
+	taskCtx, cancel := context.WithTimeoutCause(ctx, gs.taskTimeout, errTaskTimeout)
+	defer cancel()
+
+	result, err := gs.geocode(taskCtx, name, provider, address, structured)
+	if err != nil && errors.Is(context.Cause(taskCtx), errTaskTimeout) {
+		return nil, fmt.Errorf("%w after %s: %w", errTaskTimeout, gs.taskTimeout, err)
+	}
+
+	return result, err
+}
+
+// resultOf wraps coordinates without place metadata into a result, keeping nil as nil.
+func resultOf(coords *models.Coordinates) *models.GeocodeResult {
+	if coords == nil {
+		return nil
+	}
+
+	return &models.GeocodeResult{Coordinates: *coords}
+}
+
+// batchWorkerIdx is the worker index logged for the task groups geocoded with a native batch call.
+const batchWorkerIdx = 0
+
+// processBatchRecovering processes the groups like processBatch, recovering from a panic of the provider
+// or the service like processGroupRecovering. The tasks of a batch that panicked are marked as failed.
+func (gs *GeocodingService) processBatchRecovering(ctx context.Context, groups []taskGroup) {
+	dequeuedAt := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			gs.handlePanic(ctx, batchWorkerIdx, recovered, dequeuedAt, groups...)
+		}
+	}()
+
+	gs.processBatch(ctx, groups, dequeuedAt)
+}
+
+// processBatch geocodes the addresses of the task groups of the default provider with a single call to its
+// native batch API, and applies each result to its group, so one bad address doesn't fail the whole batch.
+// The batch goes through the same steps as the groups of the worker pool: cached addresses are applied without
+// calling the provider, the call waits for a request slot and is bounded by the task timeout, and nothing is
+// sent once the request budget is spent or the provider rejected the API key.
+func (gs *GeocodingService) processBatch(ctx context.Context, groups []taskGroup, dequeuedAt time.Time) {
+	gs.metrics.ActiveWorkers.Inc()
+	defer gs.metrics.ActiveWorkers.Dec()
+
+	ctx, span := gs.startSpan(ctx, "GeocodingService.processBatch", attribute.Int(attrJobs, len(groups)))
+	defer span.End()
+
+	uncached := make([]taskGroup, 0, len(groups))
+	addresses := make([]string, 0, len(groups))
+	for _, group := range groups {
+		address := gs.providerAddress(group)
+		if !gs.applyCachedResult(ctx, batchWorkerIdx, group, address, dequeuedAt) {
+			uncached = append(uncached, group)
+			addresses = append(addresses, address)
+		}
+	}
+	if len(uncached) == 0 {
+		return
+	}
+
+	if budget := geocoding.RequestBudgetFrom(ctx); budget != nil && budget.Exhausted() {
+		for _, group := range uncached {
+			gs.deferGroup(ctx, batchWorkerIdx, group)
+		}
+		return
+	}
+	name, provider := providerOf(uncached[0])
+	if gs.providerAborted(name) {
+		return
+	}
+
+	generation, ok := gs.acquireRequestSlot(ctx)
+	if !ok {
+		gs.log.DebugContext(ctx, "Stopped waiting for a provider request slot, tasks are left for the next poll",
+			"worker", batchWorkerIdx, "error", ctx.Err())
+		return
+	}
+	gs.log.InfoContext(ctx, "Geocoding batch with the provider's batch API", "provider", name,
+		"addresses", len(addresses))
+	startTime := time.Now()
+	coords, errs := gs.geocodeBatchInRequestSlot(ctx, generation, name, provider, addresses)
+	elapsed := time.Since(startTime)
+	gs.metrics.RequestSeconds.WithLabelValues(name).Observe(elapsed.Seconds())
+
+	for i, group := range uncached {
+		result := resultOf(coords[i])
+		gs.storeCachedResult(ctx, addresses[i], name, result, errs[i])
+		gs.applyGroupResult(ctx, batchWorkerIdx, group, addresses[i], result, errs[i], elapsed, dequeuedAt)
+	}
+}
+
+// geocodeBatchInRequestSlot geocodes the addresses like geocodeBatchWithinTaskTimeout, and then releases
+// the request slot taken by acquireRequestSlot in generation, even if the provider panics.
+func (gs *GeocodingService) geocodeBatchInRequestSlot(
+	ctx context.Context,
+	generation uint64,
+	name string,
+	provider geocoding.Provider,
+	addresses []string,
+) (coords []*models.Coordinates, errs []error) {
+	defer func() { gs.releaseRequestSlot(generation, errors.Join(errs...)) }()
+
+	return gs.geocodeBatchWithinTaskTimeout(ctx, name, provider, addresses)
+}
+
+// geocodeBatchWithinTaskTimeout geocodes the addresses with geocoding.GeocodeBatch within a span, with
+// the task timeout, if one is set, applied to the batch call as a whole. The error of an address caused
+// by the task timeout running out wraps errTaskTimeout, like in geocodeWithinTaskTimeout.
+func (gs *GeocodingService) geocodeBatchWithinTaskTimeout(
+	ctx context.Context,
+	name string,
+	provider geocoding.Provider,
+	addresses []string,
+) ([]*models.Coordinates, []error) {
+	ctx, span := gs.startSpan(ctx, "Provider.GeocodeBatch",
+		attribute.String(attrProvider, name),
+		attribute.Int(attrJobs, len(addresses)),
+	)
+	defer span.End()
+
+	if gs.taskTimeout <= 0 {
+		return geocoding.GeocodeBatch(ctx, provider, addresses)
+	}
+
+	taskCtx, cancel := context.WithTimeoutCause(ctx, gs.taskTimeout, errTaskTimeout)
+	defer cancel()
+
+	coords, errs := geocoding.GeocodeBatch(taskCtx, provider, addresses)
+	if errors.Is(context.Cause(taskCtx), errTaskTimeout) {
+		for i, err := range errs {
+			if err != nil {
+				errs[i] = fmt.Errorf("%w after %s: %w", errTaskTimeout, gs.taskTimeout, err)
 			}
-			gs.metrics.ActiveWorkers.Dec()
+		}
+	}
+
+	return coords, errs
+}
+
+// providerAddress returns the address sent to the provider for the group.
+// Only the normalized and templated form is sent to the provider, the original address stays in the DB.
+func (gs *GeocodingService) providerAddress(group taskGroup) string {
+	return PrepareAddress(gs.normalizer, gs.addresPrefix, gs.addrTemplate, group.address)
+}
+
+// applyGroupResult applies the geocoding outcome of a group's address to every task in the group,
+// writing an audit record and updating the database for each task.
+func (gs *GeocodingService) applyGroupResult(
+	ctx context.Context,
+	idx int,
+	group taskGroup,
+	address string,
+	result *models.GeocodeResult,
+	err error,
+	elapsed time.Duration,
+	dequeuedAt time.Time,
+) {
+	providerName, _ := providerOf(group)
+	if err == nil && result == nil {
+		err = errNoCoordinates
+	}
+	// A call cut short by the poll timeout says nothing about the address, so it is retried like a task timeout
+	if err != nil && errors.Is(context.Cause(ctx), errPollTimeout) {
+		err = fmt.Errorf("%w: %w", errPollTimeout, err)
+	}
+	if err == nil && !gs.inServiceArea(result.Coordinates) {
+		gs.metrics.OutsideServiceArea.WithLabelValues(providerName).Inc()
+		err = fmt.Errorf("%w: %s", errOutsideServiceArea, result.WKT())
+	}
+
+	if errors.Is(err, geocoding.ErrRequestBudgetExhausted) {
+		gs.deferGroup(ctx, idx, group)
+		return
+	}
+	gs.stats.record(err)
+	if gs.abortOnUnauthorized(ctx, idx, group, providerName, err) {
+		return
+	}
+	if err == nil {
+		gs.markProviderHealthy(providerName)
+	}
+
+	rateLimited := errors.Is(err, geocoding.ErrRateLimited)
+	timedOut := errors.Is(err, errTaskTimeout) || errors.Is(err, errPollTimeout)
+	switch {
+	case timedOut:
+		// The provider may be fine and only this address slow to resolve, so no API error is counted
+		gs.log.WarnContext(ctx, "Timeout exceeded, tasks will be retried on the next poll",
+			"worker", idx, "address", address, "provider", providerName, "error", err)
+	case rateLimited:
+		gs.log.WarnContext(ctx, "Geocoding provider rate limit exceeded, tasks will be retried on the next poll",
+			"worker", idx, "address", address, "provider", providerName, "error", err)
+		gs.metrics.RateLimited.WithLabelValues(providerName).Inc()
+		gs.metrics.APIErrors.WithLabelValues(errorClassRateLimited).Inc()
+	case errors.Is(err, repository.ErrCachedNotFound):
+		// The provider wasn't called, so there is no API error to count
+		gs.log.DebugContext(ctx, "Address cached as not found", "worker", idx, "address", address)
+	case err != nil:
+		class := classifyError(err)
+		gs.log.ErrorContext(ctx, "Failed to geocode", "worker", idx, "address", address, "class", class, "error", err)
+		gs.metrics.APIErrors.WithLabelValues(class).Inc()
+	}
+
+	var stored []int // stored are the tasks whose coordinates were written
+	for _, task := range group.tasks {
+		record := AuditRecord{
+			TaskID:   task.ID,
+			Address:  address,
+			Provider: providerName,
+			Duration: elapsed,
+		}
+
+		if timedOut {
+			record.Status, record.Error = AuditStatusTimeout, err.Error()
+			gs.audit.Log(ctx, record)
+			gs.handleTimedOut(ctx, idx, task, providerName, dequeuedAt)
+			continue
+		}
+
+		if rateLimited {
+			record.Status, record.Error = AuditStatusRateLimited, err.Error()
+			gs.audit.Log(ctx, record)
+			gs.handleRateLimited(ctx, idx, task, providerName, dequeuedAt)
 			continue
 		}
 
-		gs.metrics.TaskProcessed.WithLabelValues("success").Inc()
+		if err != nil {
+			record.Status, record.Error = AuditStatusFailure, err.Error()
+			gs.audit.Log(ctx, record)
+			gs.handleFailure(ctx, idx, task, providerName, err, dequeuedAt)
+			continue
+		}
 
-		if err = gs.repo.UpdateTaskCoordinates(ctx, task.ID, *coords); err != nil {
-			gs.log.ErrorContext(
-				ctx,
-				"Failed to update coordinates for task",
-				"worker", idx,
-				"task", task.ID,
-				"error", err,
-			)
-		} else {
-			gs.log.DebugContext(ctx, "Worker successfully processed the task", "worker", idx, "task", task.ID)
+		record.Status, record.Coordinates = AuditStatusSuccess, &result.Coordinates
+		record.FallbackLevel = result.FallbackLevel
+		gs.audit.Log(ctx, record)
+		if gs.handleSuccess(ctx, idx, task, providerName, result, dequeuedAt) {
+			stored = append(stored, task.ID)
+			gs.notifyGeocoded(task.ID, providerName, result.Coordinates)
+		}
+	}
+
+	if len(stored) > 0 {
+		gs.storeWhat3Words(ctx, idx, stored, result.Coordinates)
+	}
+}
+
+// inServiceArea reports whether the coordinates lie within the service area, if one is set.
+func (gs *GeocodingService) inServiceArea(coords models.Coordinates) bool {
+	return gs.serviceArea == nil || gs.serviceArea.Contains(coords)
+}
+
+// deferGroup leaves the tasks of a group for the next poll because the request budget of the poll is spent.
+// Like a rate limit, this isn't the address's fault, so the failure counts are left untouched.
+func (gs *GeocodingService) deferGroup(ctx context.Context, idx int, group taskGroup) {
+	gs.log.DebugContext(ctx, "Request budget exhausted, tasks are left for the next poll",
+		"worker", idx, "tasks", len(group.tasks))
+	gs.metrics.BudgetExhausted.Add(float64(len(group.tasks)))
+}
+
+// staggerDelay returns a random delay in the range [0, maxDelay). It returns zero if maxDelay is not positive.
+func staggerDelay(maxDelay time.Duration) time.Duration {
+	if maxDelay <= 0 {
+		return 0
+	}
+
+	return rand.N(maxDelay) //nolint:gosec // jitter does not need a cryptographically secure source
+}
+
+// handleFailure records a failed geocoding attempt of the named provider for the task and increments
+// its failure count, or marks it as not found if enabled with WithNotFoundStatus. A failed refresh of stale
+// coordinates keeps the stored coordinates and is only postponed until they are stale again.
+// The end-to-end task duration is measured from dequeuedAt to the final database update.
+func (gs *GeocodingService) handleFailure(
+	ctx context.Context,
+	idx int,
+	task models.Task,
+	providerName string,
+	geocodeErr error,
+	dequeuedAt time.Time,
+) {
+	gs.metrics.TaskProcessed.WithLabelValues("failure", providerName).Inc()
+	defer gs.observeTaskDuration("failure", dequeuedAt)
+	gs.recordFailure(ctx, task, providerName, geocodeErr)
+
+	if task.Refresh {
+		gs.postponeStaleRefresh(ctx, idx, task, geocodeErr)
+		return
+	}
+
+	notFound := gs.notFoundStop && classifyError(geocodeErr) == errorClassEmptyResponse
+	if gs.dryRun {
+		if notFound {
+			gs.log.InfoContext(ctx, "Dry run: would mark task as not found",
+				"worker", idx, "task", task.ID, "error", geocodeErr)
+			return
+		}
+		gs.log.InfoContext(ctx, "Dry run: would increment failure count for task",
+			"worker", idx, "task", task.ID, "error", geocodeErr)
+		return
+	}
+
+	if notFound {
+		if err := gs.repo.MarkTaskNotFound(ctx, task.ID, newGeocodeError(geocodeErr)); err != nil {
+			gs.log.ErrorContext(ctx, "Could not mark task as not found", "worker", idx, "task", task.ID, "error", err)
+			return
 		}
+		gs.notifyFailed(task.ID, webhook.StatusNotFound, providerName, geocodeErr)
+		return
+	}
+
+	if err := gs.repo.IncrementFailureCount(ctx, task.ID, newGeocodeError(geocodeErr)); err != nil {
+		gs.log.ErrorContext(
+			ctx,
+			"Could not update failure count for task",
+			"worker", idx,
+			"task", task.ID,
+			"error", err,
+		)
+	}
+}
+
+// postponeStaleRefresh keeps the stored coordinates of a task whose refresh failed, and postpones the next
+// refresh until they are stale again, so that the task isn't fetched again on every poll.
+func (gs *GeocodingService) postponeStaleRefresh(ctx context.Context, idx int, task models.Task, geocodeErr error) {
+	gs.log.WarnContext(ctx, "Could not refresh stale coordinates, keeping the stored ones",
+		"worker", idx, "task", task.ID, "error", geocodeErr)
+
+	if gs.dryRun {
+		gs.log.InfoContext(ctx, "Dry run: would postpone the refresh of stale coordinates", "worker", idx, "task", task.ID)
+		return
+	}
+
+	if err := gs.repo.PostponeStaleRefresh(ctx, task.ID); err != nil {
+		gs.log.ErrorContext(ctx, "Could not postpone the refresh of stale coordinates",
+			"worker", idx, "task", task.ID, "error", err)
+	}
+}
+
+// recordFailure writes the failed attempt of the task to the failure log, if one is set.
+// The failure log is only a debugging aid, so a write error is logged and otherwise ignored.
+func (gs *GeocodingService) recordFailure(ctx context.Context, task models.Task, providerName string, err error) {
+	if gs.failures == nil {
+		return
+	}
+
+	record := FailureRecord{
+		TaskID:     task.ID,
+		Address:    task.Address,
+		Structured: task.Structured,
+		Language:   task.Language,
+		Provider:   providerName,
+		Class:      classifyError(err),
+		Error:      err.Error(),
+		FailedAt:   time.Now(),
+	}
+	if writeErr := gs.failures.Record(ctx, record); writeErr != nil {
+		gs.log.ErrorContext(ctx, "Could not write failure record", "task", task.ID, "error", writeErr)
+	}
+}
+
+// handleInvalidAddress records a task skipped because its address is blank, and marks its address as invalid,
+// so that it is no longer fetched, unless it is a refresh of stale coordinates, which keeps them.
+// No provider attempt is counted for it.
+func (gs *GeocodingService) handleInvalidAddress(ctx context.Context, task models.Task) {
+	gs.log.WarnContext(ctx, "Skipping task with a blank address", "task", task.ID, "address", task.Address)
+	gs.metrics.InvalidAddresses.Inc()
+	gs.audit.Log(ctx, AuditRecord{
+		TaskID:  task.ID,
+		Address: task.Address,
+		Status:  AuditStatusInvalid,
+		Error:   errBlankAddress.Error(),
+	})
 
-		gs.metrics.ActiveWorkers.Dec()
+	if task.Refresh {
+		return
+	}
+	if gs.dryRun {
+		gs.log.InfoContext(ctx, "Dry run: would mark task address as invalid", "task", task.ID)
+		return
 	}
+
+	if err := gs.repo.MarkInvalidAddress(ctx, task.ID, newGeocodeError(errBlankAddress)); err != nil {
+		gs.log.ErrorContext(ctx, "Could not mark task address as invalid", "task", task.ID, "error", err)
+		return
+	}
+	gs.notifyFailed(task.ID, webhook.StatusInvalid, "", errBlankAddress)
+}
+
+// handleRateLimited records a rate-limited geocoding attempt. The failure isn't the address's fault,
+// so the failure count is left untouched and the task is picked up again on the next poll.
+func (gs *GeocodingService) handleRateLimited(
+	ctx context.Context,
+	idx int,
+	task models.Task,
+	providerName string,
+	dequeuedAt time.Time,
+) {
+	gs.metrics.TaskProcessed.WithLabelValues("rate_limited", providerName).Inc()
+	gs.observeTaskDuration("rate_limited", dequeuedAt)
+	gs.log.DebugContext(ctx, "Task left for the next poll after rate limit", "worker", idx, "task", task.ID)
+}
+
+// handleTimedOut records a geocoding attempt cut short by the task or poll timeout. Like a rate limit, the failure
+// count is left untouched and the task is picked up again on the next poll.
+func (gs *GeocodingService) handleTimedOut(
+	ctx context.Context,
+	idx int,
+	task models.Task,
+	providerName string,
+	dequeuedAt time.Time,
+) {
+	gs.metrics.TaskProcessed.WithLabelValues("timeout", providerName).Inc()
+	gs.observeTaskDuration("timeout", dequeuedAt)
+	gs.log.DebugContext(ctx, "Task left for the next poll after task timeout", "worker", idx, "task", task.ID)
+}
+
+// handleSuccess records a successful geocoding attempt of the named provider and stores the coordinates
+// for the task, along with the place metadata of the match if the provider reported any. If address rewriting
+// is enabled and the address was normalized, the normalized address is stored with the coordinates instead,
+// without the place metadata.
+// The end-to-end task duration is measured from dequeuedAt to the final database update;
+// a failed database update is observed as a failure outcome. Written results are counted by precision.
+// It reports whether the coordinates were written to the database.
+func (gs *GeocodingService) handleSuccess(
+	ctx context.Context,
+	idx int,
+	task models.Task,
+	providerName string,
+	result *models.GeocodeResult,
+	dequeuedAt time.Time,
+) bool {
+	gs.metrics.TaskProcessed.WithLabelValues("success", providerName).Inc()
+
+	cleanedAddress, rewrite := gs.cleanedAddress(task)
+	if gs.dryRun {
+		gs.observeTaskDuration("success", dequeuedAt)
+		gs.log.InfoContext(ctx, "Dry run: would update coordinates for task", "worker", idx, "task", task.ID,
+			"lat", result.Latitude, "lon", result.Longitude, "precision", result.Precision, "place_id", result.PlaceID)
+		if rewrite {
+			gs.log.InfoContext(ctx, "Dry run: would rewrite address for task", "worker", idx, "task", task.ID,
+				"address", task.Address, "cleaned_address", cleanedAddress)
+		}
+		return false
+	}
+
+	var err error
+	switch {
+	case rewrite:
+		err = gs.repo.UpdateTaskGeocodeResult(ctx, task.ID, cleanedAddress, *result)
+	case result.HasPlace():
+		err = gs.repo.UpdateTaskResult(ctx, task.ID, *result)
+	default:
+		err = gs.repo.UpdateTaskCoordinates(ctx, task.ID, result.Coordinates)
+	}
+	if err != nil {
+		gs.observeTaskDuration("failure", dequeuedAt)
+		gs.log.ErrorContext(
+			ctx,
+			"Failed to update coordinates for task",
+			"worker", idx,
+			"task", task.ID,
+			"error", err,
+		)
+		return false
+	}
+
+	gs.observeTaskDuration("success", dequeuedAt)
+	gs.metrics.ResultPrecision.WithLabelValues(precisionLabel(result.Precision), providerName).Inc()
+	gs.log.DebugContext(ctx, "Worker successfully processed the task", "worker", idx, "task", task.ID)
+
+	return true
+}
+
+// precisionLabel returns the precision metric label of a result, PrecisionApproximate if the provider
+// didn't report one.
+func precisionLabel(precision models.Precision) string {
+	if precision == "" {
+		return string(models.PrecisionApproximate)
+	}
+
+	return string(precision)
+}
+
+// cleanedAddress returns the normalized address of the task, and whether it should replace the stored address
+// because address rewriting is enabled and normalization changed it. Structured addresses are never rewritten,
+// as their free-form text isn't stored.
+func (gs *GeocodingService) cleanedAddress(task models.Task) (string, bool) {
+	if !gs.rewriteAddr || task.Structured != nil {
+		return "", false
+	}
+
+	cleaned := gs.normalizer.Normalize(task.Address)
+
+	return cleaned, cleaned != task.Address
+}
+
+// observeTaskDuration records the end-to-end duration of a task with the given outcome.
+func (gs *GeocodingService) observeTaskDuration(outcome string, dequeuedAt time.Time) {
+	gs.metrics.TaskDurationSeconds.WithLabelValues(outcome).Observe(time.Since(dequeuedAt).Seconds())
 }
@@ -1,18 +1,28 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
 	"github.com/UnknownOlympus/atlas/internal/metrics"
 	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/repository"
 	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProcessTask(t *testing.T) {
@@ -62,7 +72,7 @@ func TestProcessTask(t *testing.T) {
 
 		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
 		mockProvider.On("Geocode", ctx, "Invalid Address").Return(nil, geocodeErr).Once()
-		mockRepo.On("IncrementFailureCount", ctx, 2, geocodeErr.Error()).Return(nil).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 2, geocodeError(errorClassOther, geocodeErr)).Return(nil).Once()
 
 		service.processTask(ctx)
 
@@ -76,7 +86,7 @@ func TestProcessTask(t *testing.T) {
 
 		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
 		mockProvider.On("Geocode", ctx, "Invalid Address").Return(nil, geocodeErr).Once()
-		mockRepo.On("IncrementFailureCount", ctx, 2, geocodeErr.Error()).Return(assert.AnError).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 2, geocodeError(errorClassOther, geocodeErr)).Return(assert.AnError).Once()
 
 		service.processTask(ctx)
 
@@ -98,10 +108,2105 @@ func TestProcessTask(t *testing.T) {
 		mockProvider.AssertExpectations(t)
 	})
 
+	t.Run("duplicate addresses are geocoded once", func(t *testing.T) {
+		sampleTasks := []models.Task{
+			{ID: 1, Address: "Kyiv, Khreshchatyk 1"},
+			{ID: 2, Address: "Lviv"},
+			{ID: 3, Address: "  kyiv,  Khreshchatyk 1 "},
+		}
+		kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+		lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv, Khreshchatyk 1").Return(kyivCoords, nil).Once()
+		mockProvider.On("Geocode", ctx, "Lviv").Return(lvivCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *kyivCoords).Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 3, *kyivCoords).Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 2, *lvivCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertExpectations(t)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("failure is propagated to all duplicate tasks", func(t *testing.T) {
+		sampleTasks := []models.Task{{ID: 4, Address: "Nowhere"}, {ID: 5, Address: "nowhere"}}
+		geocodeErr := errors.New("geocoding failed")
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, geocodeErr).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 4, geocodeError(errorClassOther, geocodeErr)).Return(nil).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 5, geocodeError(errorClassOther, geocodeErr)).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertExpectations(t)
+		mockProvider.AssertExpectations(t)
+	})
+
 	t.Run("start context cancelled", func(t *testing.T) {
-		tctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
-		defer cancel()
+		tctx, cancel := context.WithCancel(t.Context())
+		cancel()
 
+		// No poll is expected once the context is cancelled
 		service.Run(tctx)
 	})
 }
+
+// recordingAuditLogger collects audit records for assertions.
+type recordingAuditLogger struct {
+	mu      sync.Mutex
+	records []AuditRecord
+}
+
+func (ra *recordingAuditLogger) Log(_ context.Context, record AuditRecord) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.records = append(ra.records, record)
+}
+
+func TestProcessTask_Audit(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	audit := &recordingAuditLogger{}
+	service := NewGeocodingServie(
+		logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "", WithAuditLogger(audit),
+	)
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Invalid Address"}}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52, FallbackLevel: 1}
+	geocodeErr := errors.New("geocoding failed")
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+	mockProvider.On("Geocode", ctx, "Invalid Address").Return(nil, geocodeErr).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 2, geocodeError(errorClassOther, geocodeErr)).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	require.Len(t, audit.records, 2)
+	assert.Equal(t, 1, audit.records[0].TaskID)
+	assert.Equal(t, AuditStatusSuccess, audit.records[0].Status)
+	assert.Equal(t, "test-provider", audit.records[0].Provider)
+	assert.Equal(t, 1, audit.records[0].FallbackLevel)
+	assert.Equal(t, sampleCoords, audit.records[0].Coordinates)
+	assert.Equal(t, 2, audit.records[1].TaskID)
+	assert.Equal(t, AuditStatusFailure, audit.records[1].Status)
+	assert.Equal(t, geocodeErr.Error(), audit.records[1].Error)
+}
+
+func TestProcessTask_FailureLog(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	var buf bytes.Buffer
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithFailureLog(NewJSONFailureLog(&buf)),
+	)
+
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+		Return([]models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "  Invalid Address"}}, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+	mockProvider.On("Geocode", ctx, "Invalid Address").Return(nil, geocoding.ErrEmptyResponse).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 2, mock.Anything).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	// Only the failure is logged, with the address as stored so it can be prepared again on replay
+	records, err := ReadFailureLog(&buf)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, 2, records[0].TaskID)
+	assert.Equal(t, "  Invalid Address", records[0].Address)
+	assert.Equal(t, "test-provider", records[0].Provider)
+	assert.Equal(t, errorClassEmptyResponse, records[0].Class)
+	assert.Equal(t, geocoding.ErrEmptyResponse.Error(), records[0].Error)
+	assert.False(t, records[0].FailedAt.IsZero())
+}
+
+func TestGroupTasksByAddress(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "Lviv"},
+		{ID: 3, Address: " KYIV "},
+		{ID: 4, Address: "Odesa"},
+	}
+
+	groups := groupTasksByAddress(tasks)
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "Kyiv", groups[0].address)
+	assert.Equal(t, []models.Task{tasks[0], tasks[2]}, groups[0].tasks)
+	assert.Equal(t, "Lviv", groups[1].address)
+	assert.Equal(t, "Odesa", groups[2].address)
+}
+
+func TestGroupTasksByAddress_PreferredProvider(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "kyiv", PreferredProvider: "here"},
+		{ID: 3, Address: "KYIV"},
+		{ID: 4, Address: "Kyiv", PreferredProvider: "here"},
+	}
+
+	groups := groupTasksByAddress(tasks)
+
+	require.Len(t, groups, 2)
+	assert.Empty(t, groups[0].provider)
+	assert.Equal(t, []models.Task{tasks[0], tasks[2]}, groups[0].tasks)
+	assert.Equal(t, "here", groups[1].provider)
+	assert.Equal(t, "kyiv", groups[1].address)
+	assert.Equal(t, []models.Task{tasks[1], tasks[3]}, groups[1].tasks)
+}
+
+func TestGroupTasksByAddress_Language(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "kyiv", Language: "ru"},
+		{ID: 3, Address: "KYIV"},
+		{ID: 4, Address: "Kyiv", Language: "ru"},
+	}
+
+	groups := groupTasksByAddress(tasks)
+
+	require.Len(t, groups, 2)
+	assert.Empty(t, groups[0].language)
+	assert.Equal(t, []models.Task{tasks[0], tasks[2]}, groups[0].tasks)
+	assert.Equal(t, "ru", groups[1].language)
+	assert.Equal(t, []models.Task{tasks[1], tasks[3]}, groups[1].tasks)
+}
+
+func TestProcessTask_TaskLanguage(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "")
+
+	coords := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+	withLanguage := func(language string) any {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			return geocoding.LanguageFrom(ctx) == language
+		})
+	}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "Kyiv", Language: "ru,uk"},
+	}, nil).Once()
+	// The task language overrides the provider's, and the other task keeps the provider's default
+	mockProvider.On("Geocode", withLanguage(""), "Kyiv").Return(coords, nil).Once()
+	mockProvider.On("Geocode", withLanguage("ru,uk"), "Kyiv").Return(coords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *coords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *coords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestProcessTask_ProviderRouting(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	defaultProvider := mocks.NewProvider(t)
+	hereProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	audit := &recordingAuditLogger{}
+	service := NewGeocodingServie(logger, mockRepo, defaultProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithAuditLogger(audit),
+		WithProviders(map[string]geocoding.Provider{"here": hereProvider}),
+	)
+
+	sampleTasks := []models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "Kyiv", PreferredProvider: "here"},
+		{ID: 3, Address: "Lviv", PreferredProvider: "unknown"},
+		{ID: 4, Address: "Odesa", PreferredProvider: "test-provider"},
+	}
+	defaultKyiv := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	hereKyiv := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234, Precision: models.PrecisionRooftop}
+	lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+	odesaCoords := &models.Coordinates{Latitude: 46.48, Longitude: 30.72}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	defaultProvider.On("Geocode", ctx, "Kyiv").Return(defaultKyiv, nil).Once()
+	hereProvider.On("Geocode", ctx, "Kyiv").Return(hereKyiv, nil).Once()
+	// Unknown and default preferred providers fall back to the default provider
+	defaultProvider.On("Geocode", ctx, "Lviv").Return(lvivCoords, nil).Once()
+	defaultProvider.On("Geocode", ctx, "Odesa").Return(odesaCoords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *defaultKyiv).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *hereKyiv).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 3, *lvivCoords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 4, *odesaCoords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	require.Len(t, audit.records, 4)
+	providers := make(map[int]string, len(audit.records))
+	for _, record := range audit.records {
+		providers[record.TaskID] = record.Provider
+	}
+	assert.Equal(t, map[int]string{1: "test-provider", 2: "here", 3: "test-provider", 4: "test-provider"}, providers)
+}
+
+func TestProcessTask_TaskProcessedByProvider(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	defaultProvider := mocks.NewProvider(t)
+	hereProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, defaultProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithProviders(map[string]geocoding.Provider{"here": hereProvider}),
+	)
+
+	sampleTasks := []models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "Lviv", PreferredProvider: "here"},
+		{ID: 3, Address: "Odesa", PreferredProvider: "here"},
+	}
+	kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	defaultProvider.On("Geocode", ctx, "Kyiv").Return(kyivCoords, nil).Once()
+	hereProvider.On("Geocode", ctx, "Lviv").Return(lvivCoords, nil).Once()
+	hereProvider.On("Geocode", ctx, "Odesa").Return(nil, errors.New("geocoding failed")).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *kyivCoords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *lvivCoords).Return(nil).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 3, geocodeError(errorClassOther, errors.New("geocoding failed"))).
+		Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("success", "test-provider")), 0)
+	assert.InDelta(t, 0, counterValue(t, metrics.TaskProcessed.WithLabelValues("failure", "test-provider")), 0)
+	assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("success", "here")), 0)
+	assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("failure", "here")), 0)
+}
+
+func TestProcessTask_RoutedGroupBypassesCache(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockCache := mocks.NewCache(t)
+	defaultProvider := mocks.NewProvider(t)
+	hereProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, defaultProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithGeocodeCache(mockCache),
+		WithProviders(map[string]geocoding.Provider{"here": hereProvider}),
+	)
+
+	hereKyiv := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+		Return([]models.Task{{ID: 1, Address: "Kyiv", PreferredProvider: "here"}}, nil).Once()
+	hereProvider.On("Geocode", ctx, "Kyiv").Return(hereKyiv, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *hereKyiv).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockCache.AssertNotCalled(t, "LookupCachedCoordinates", mock.Anything, mock.Anything)
+	mockCache.AssertNotCalled(t, "StoreCachedCoordinates", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	defaultProvider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// gatedProvider returns fixed coordinates, signalling each call on started and blocking it until release is closed.
+type gatedProvider struct {
+	*mocks.Provider
+
+	coords  *models.Coordinates
+	started chan struct{}
+	release chan struct{}
+}
+
+func (gp *gatedProvider) Geocode(context.Context, string) (*models.Coordinates, error) {
+	gp.started <- struct{}{}
+	<-gp.release
+
+	return gp.coords, nil
+}
+
+func TestSetProviders_InFlightBatch(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	newProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	audit := &recordingAuditLogger{}
+	oldProvider := &gatedProvider{
+		Provider: mocks.NewProvider(t),
+		coords:   &models.Coordinates{Latitude: 50.45, Longitude: 30.52},
+		started:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+	service := NewGeocodingServie(logger, mockRepo, oldProvider, "old-provider", metrics, 1, 1*time.Second, "",
+		WithAuditLogger(audit),
+	)
+
+	newCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 2, Address: "Lviv"}}, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *oldProvider.coords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *newCoords).Return(nil).Once()
+	newProvider.On("Geocode", ctx, "Lviv").Return(newCoords, nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.processTask(ctx)
+	}()
+
+	// The swap happens while the first batch is geocoded, so only the next batch uses the new provider
+	<-oldProvider.started
+	service.SetProviders(newProvider, "new-provider", nil)
+	close(oldProvider.release)
+	<-done
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	require.Len(t, audit.records, 2)
+	assert.Equal(t, "old-provider", audit.records[0].Provider)
+	assert.Equal(t, "new-provider", audit.records[1].Provider)
+}
+
+func TestSetProviders_ConcurrentGeocoding(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	audit := &recordingAuditLogger{}
+
+	coords := map[string]*models.Coordinates{
+		"first":  {Latitude: 50.45, Longitude: 30.52},
+		"second": {Latitude: 49.84, Longitude: 24.03},
+	}
+	providers := make(map[string]geocoding.Provider, len(coords))
+	for name, result := range coords {
+		provider := mocks.NewProvider(t)
+		provider.On("Geocode", mock.Anything, mock.Anything).Return(result, nil).Maybe()
+		providers[name] = provider
+	}
+	service := NewGeocodingServie(logger, mockRepo, providers["first"], "first", metrics, 4, 1*time.Second, "",
+		WithAuditLogger(audit),
+	)
+
+	tasks := []models.Task{
+		{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}, {ID: 3, Address: "Odesa"}, {ID: 4, Address: "Kharkiv"},
+	}
+	mockRepo.On("FetchTasksForGeocoding", mock.Anything, 100).Return(tasks, nil)
+	mockRepo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	const batches = 20
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range batches {
+			service.processTask(ctx)
+		}
+	}()
+
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			require.Len(t, audit.records, batches*len(tasks))
+			// Every result is reported with the provider that produced it
+			for _, record := range audit.records {
+				assert.Equal(t, coords[record.Provider], record.Coordinates, "task %d", record.TaskID)
+			}
+			return
+		default:
+			name := []string{"first", "second"}[i%2]
+			service.SetProviders(providers[name], name, nil)
+		}
+	}
+}
+
+func TestProcessTask_TaskDuration(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "")
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}, {ID: 3, Address: "Invalid"}}
+	kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+	geocodeErr := errors.New("geocoding failed")
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(kyivCoords, nil).Once()
+	mockProvider.On("Geocode", ctx, "Lviv").Return(lvivCoords, nil).Once()
+	mockProvider.On("Geocode", ctx, "Invalid").Return(nil, geocodeErr).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *kyivCoords).Return(nil).Once()
+	// A failed database write is observed as a failure outcome.
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *lvivCoords).Return(assert.AnError).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 3, geocodeError(errorClassOther, geocodeErr)).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	assert.Equal(t, uint64(1), histogramCount(t, metrics.TaskDurationSeconds.WithLabelValues("success")))
+	assert.Equal(t, uint64(2), histogramCount(t, metrics.TaskDurationSeconds.WithLabelValues("failure")))
+}
+
+// histogramCount returns the number of observations recorded by the histogram.
+func histogramCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+
+	metric, ok := observer.(prometheus.Metric)
+	require.True(t, ok)
+
+	var written dto.Metric
+	require.NoError(t, metric.Write(&written))
+
+	return written.GetHistogram().GetSampleCount()
+}
+
+func TestStaggerDelay(t *testing.T) {
+	t.Run("disabled stagger", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), staggerDelay(0))
+		assert.Equal(t, time.Duration(0), staggerDelay(-time.Second))
+	})
+
+	t.Run("delays are within bounds", func(t *testing.T) {
+		maxDelay := 100 * time.Millisecond
+		for range 1000 {
+			delay := staggerDelay(maxDelay)
+			assert.GreaterOrEqual(t, delay, time.Duration(0))
+			assert.Less(t, delay, maxDelay)
+		}
+	})
+}
+
+func TestProcessTask_WorkerStagger(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(
+		logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+		WithWorkerStagger(10*time.Millisecond),
+	)
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestPoolSize(t *testing.T) {
+	service := &GeocodingService{numWorkers: 50}
+
+	tests := []struct {
+		name     string
+		jobs     int
+		expected int
+	}{
+		{name: "small batch starts a worker per job", jobs: 3, expected: 3},
+		{name: "batch matching worker count", jobs: 50, expected: 50},
+		{name: "large batch is capped at worker count", jobs: 100, expected: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.poolSize(tt.jobs))
+		})
+	}
+}
+
+func TestProcessTask_SmallBatchWorkers(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 50, 1*time.Second, "")
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}, {ID: 3, Address: "Odesa"}}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, mock.Anything).Return(sampleCoords, nil).Times(len(sampleTasks))
+	mockRepo.On("UpdateTaskCoordinates", ctx, mock.Anything, *sampleCoords).Return(nil).Times(len(sampleTasks))
+
+	service.processTask(ctx)
+
+	assert.Contains(t, logs.String(), "num_workers=3")
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestProcessTask_DuplicateTaskIDs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("duplicate tasks in a batch are processed once", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 4, 1*time.Second, "")
+
+		sampleTasks := []models.Task{
+			{ID: 1, Address: "Kyiv"},
+			{ID: 2, Address: "Lviv"},
+			{ID: 1, Address: "Kyiv"},
+			{ID: 2, Address: "  lviv "},
+		}
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockProvider.On("Geocode", ctx, mock.Anything).Return(sampleCoords, nil).Twice()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 2, *sampleCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNumberOfCalls(t, "UpdateTaskCoordinates", 2)
+		assert.InDelta(t, 2, counterValue(t, metrics.DuplicateTasks), 0)
+	})
+
+	t.Run("task still in flight is skipped", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 4, 1*time.Second, "")
+		service.inFlight.Store(1, struct{}{})
+
+		sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}}
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Lviv").Return(sampleCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 2, *sampleCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.InDelta(t, 1, counterValue(t, metrics.DuplicateTasks), 0)
+	})
+
+	t.Run("tasks are released after the batch", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 4, 1*time.Second, "")
+
+		sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}}
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Twice()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Twice()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Twice()
+
+		service.processTask(ctx)
+		service.processTask(ctx)
+
+		assert.InDelta(t, 0, counterValue(t, metrics.DuplicateTasks), 0)
+	})
+}
+
+func TestProcessTask_MaxConcurrentRequests(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	const maxRequests = 2
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 4, 1*time.Second, "",
+		WithMaxConcurrentRequests(maxRequests),
+	)
+
+	sampleTasks := []models.Task{
+		{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}, {ID: 3, Address: "Odesa"}, {ID: 4, Address: "Kharkiv"},
+		{ID: 5, Address: "Dnipro"}, {ID: 6, Address: "Poltava"}, {ID: 7, Address: "Sumy"}, {ID: 8, Address: "Rivne"},
+	}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	var mu sync.Mutex
+	var inFlight, peak int
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, mock.Anything).Return(sampleCoords, nil).Times(len(sampleTasks)).
+		Run(func(mock.Arguments) {
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		})
+	mockRepo.On("UpdateTaskCoordinates", ctx, mock.Anything, *sampleCoords).Return(nil).Times(len(sampleTasks))
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, maxRequests, peak, "provider calls must not exceed the cap")
+}
+
+func TestProcessTask_MaxConcurrentRequestsCanceled(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx, cancel := context.WithCancel(t.Context())
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithMaxConcurrentRequests(1),
+	)
+
+	// Another caller holds the only slot until the context is canceled
+	_, ok := service.acquireRequestSlot(ctx)
+	require.True(t, ok)
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once().
+		Run(func(mock.Arguments) { cancel() })
+
+	service.processTask(ctx)
+
+	// The task is neither geocoded nor counted as a failure, it is picked up again on the next poll
+	mockProvider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// fallbackProvider is a provider that spends requests from the request budget of the context
+// like a provider falling back to coarser searches: every address takes the given number of requests.
+type fallbackProvider struct {
+	*mocks.Provider
+
+	requests  int
+	addresses []string
+}
+
+func (fp *fallbackProvider) Geocode(ctx context.Context, address string) (*models.Coordinates, error) {
+	fp.addresses = append(fp.addresses, address)
+	for range fp.requests {
+		if budget := geocoding.RequestBudgetFrom(ctx); budget != nil && !budget.Spend() {
+			return nil, geocoding.ErrRequestBudgetExhausted
+		}
+	}
+
+	return &models.Coordinates{Latitude: 50.45, Longitude: 30.52}, nil
+}
+
+func TestProcessTask_RequestBudget(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}, {ID: 3, Address: "Odesa"}}
+
+	t.Run("remaining tasks are left for the next poll", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := &fallbackProvider{Provider: mocks.NewProvider(t), requests: 2}
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithRequestBudget(3),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, 1, mock.Anything).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		// Lviv runs out of budget halfway through its requests, Odesa isn't sent at all
+		assert.Equal(t, []string{"Kyiv", "Lviv"}, provider.addresses)
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 2, counterValue(t, metrics.BudgetExhausted), 0)
+	})
+
+	t.Run("budget is renewed on each poll", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := &fallbackProvider{Provider: mocks.NewProvider(t), requests: 1}
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithRequestBudget(2),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks[2:], nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+		service.processTask(ctx)
+		service.processTask(ctx)
+
+		assert.Equal(t, []string{"Kyiv", "Lviv", "Odesa"}, provider.addresses)
+		assert.InDelta(t, 1, counterValue(t, metrics.BudgetExhausted), 0)
+	})
+
+	t.Run("no budget", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := &fallbackProvider{Provider: mocks.NewProvider(t), requests: 4}
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 1, 1*time.Second, "")
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+		service.processTask(ctx)
+
+		assert.Len(t, provider.addresses, 3)
+		assert.InDelta(t, 0, counterValue(t, metrics.BudgetExhausted), 0)
+	})
+}
+
+func TestProcessTask_TaskTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}}
+	sampleCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+
+	t.Run("slow task is left for the next poll", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		audit := &recordingAuditLogger{}
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithTaskTimeout(50*time.Millisecond),
+			WithAuditLogger(audit),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		// The provider takes far longer than the task timeout unless its context is done
+		mockProvider.On("Geocode", mock.Anything, "Kyiv").Run(func(args mock.Arguments) {
+			select {
+			case <-args.Get(0).(context.Context).Done():
+			case <-time.After(5 * time.Second):
+			}
+		}).Return(nil, context.DeadlineExceeded).Once()
+		mockProvider.On("Geocode", mock.Anything, "Lviv").Return(sampleCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 2, *sampleCoords).Return(nil).Once()
+
+		start := time.Now()
+		service.processTask(ctx)
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("timeout", "test-provider")), 0)
+		assert.InDelta(t, 0, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassTimeout)), 0)
+		require.Len(t, audit.records, 2)
+		assert.Equal(t, AuditStatusTimeout, audit.records[0].Status)
+		assert.Contains(t, audit.records[0].Error, errTaskTimeout.Error())
+		assert.Equal(t, AuditStatusSuccess, audit.records[1].Status)
+	})
+
+	t.Run("provider timeout still counts as a failure", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithTaskTimeout(time.Minute),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks[:1], nil).Once()
+		mockProvider.On("Geocode", mock.Anything, "Kyiv").Return(nil, context.DeadlineExceeded).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 1, geocodeError(errorClassTimeout, context.DeadlineExceeded)).
+			Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.InDelta(t, 0, counterValue(t, metrics.TaskProcessed.WithLabelValues("timeout", "test-provider")), 0)
+		assert.InDelta(t, 1, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassTimeout)), 0)
+	})
+}
+
+func TestProcessTask_BlankAddress(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	sampleTasks := []models.Task{{ID: 1, Address: "   "}, {ID: 2, Address: "Kyiv"}, {ID: 3, Address: "\t, ,\n"}}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("blank addresses are marked without calling the provider", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		audit := &recordingAuditLogger{}
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithAuditLogger(audit),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockRepo.On("MarkInvalidAddress", ctx, 1, geocodeError(errorCodeInvalidAddress, errBlankAddress)).Return(nil).Once()
+		mockRepo.On("MarkInvalidAddress", ctx, 3, geocodeError(errorCodeInvalidAddress, errBlankAddress)).Return(nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 2, *sampleCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 2, counterValue(t, metrics.InvalidAddresses), 0)
+		require.Len(t, audit.records, 3)
+		assert.Equal(t, AuditStatusInvalid, audit.records[0].Status)
+		assert.Equal(t, AuditStatusInvalid, audit.records[1].Status)
+		assert.Equal(t, AuditStatusSuccess, audit.records[2].Status)
+	})
+
+	t.Run("only blank addresses", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "")
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks[:1], nil).Once()
+		mockRepo.On("MarkInvalidAddress", ctx, 1, geocodeError(errorCodeInvalidAddress, errBlankAddress)).
+			Return(assert.AnError).Once()
+
+		service.processTask(ctx)
+
+		mockProvider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+		assert.InDelta(t, 1, counterValue(t, metrics.InvalidAddresses), 0)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithDryRun(true),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks[:1], nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "MarkInvalidAddress", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 1, counterValue(t, metrics.InvalidAddresses), 0)
+	})
+}
+
+func TestProcessTask_AddressNormalizer(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "")
+
+	sampleTasks := []models.Task{{ID: 1, Address: "село Грабовець,  вулиця Польова, 3"}}
+	sampleCoords := &models.Coordinates{Latitude: 49.12, Longitude: 24.56}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	// The provider receives the normalized address
+	mockProvider.On("Geocode", ctx, "с. Грабовець, вул. Польова, 3").Return(sampleCoords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestProcessTask_AddressTemplate(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithAddressTemplate("{address}, Україна"),
+	)
+
+	sampleTasks := []models.Task{{ID: 1, Address: "село Грабовець, вулиця Польова, 3"}}
+	sampleCoords := &models.Coordinates{Latitude: 49.12, Longitude: 24.56}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	// The template is applied to the normalized address
+	mockProvider.On("Geocode", ctx, "с. Грабовець, вул. Польова, 3, Україна").Return(sampleCoords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+// batchProvider is a provider mock with a native batch API returning preset results per address,
+// and geocoding.ErrEmptyResponse for the others. If run is set, it is called before the results are returned.
+type batchProvider struct {
+	*mocks.Provider
+
+	results map[string]*models.Coordinates
+	run     func(ctx context.Context)
+	calls   [][]string
+}
+
+func (bp *batchProvider) GeocodeBatch(ctx context.Context, addresses []string) ([]*models.Coordinates, []error) {
+	bp.calls = append(bp.calls, addresses)
+	if bp.run != nil {
+		bp.run(ctx)
+	}
+
+	coords := make([]*models.Coordinates, len(addresses))
+	errs := make([]error, len(addresses))
+	for i, address := range addresses {
+		if result, ok := bp.results[address]; ok {
+			coords[i] = result
+		} else {
+			errs[i] = fmt.Errorf("%w: %s", geocoding.ErrEmptyResponse, address)
+		}
+	}
+
+	return coords, errs
+}
+
+func TestProcessTask_Batch(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+
+	kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+	provider := &batchProvider{
+		Provider: mocks.NewProvider(t),
+		results:  map[string]*models.Coordinates{"Kyiv": kyivCoords, "Lviv": lvivCoords},
+	}
+	service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, 1*time.Second, "")
+
+	sampleTasks := []models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "Nowhere"},
+		{ID: 3, Address: "Lviv"},
+		{ID: 4, Address: "kyiv"},
+	}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *kyivCoords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 4, *kyivCoords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 3, *lvivCoords).Return(nil).Once()
+	// One bad address doesn't fail the whole batch
+	mockRepo.On("IncrementFailureCount", ctx, 2,
+		newGeocodeError(fmt.Errorf("%w: %s", geocoding.ErrEmptyResponse, "Nowhere"))).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	require.Len(t, provider.calls, 1, "the whole polling batch must be geocoded in a single call")
+	assert.Equal(t, []string{"Kyiv", "Nowhere", "Lviv"}, provider.calls[0])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessTask_BatchWithRoutedTasks(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	hereProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+
+	kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+	provider := &batchProvider{
+		Provider: mocks.NewProvider(t),
+		results:  map[string]*models.Coordinates{"Kyiv": kyivCoords},
+	}
+	service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, 1*time.Second, "",
+		WithProviders(map[string]geocoding.Provider{"here": hereProvider}))
+
+	sampleTasks := []models.Task{
+		{ID: 1, Address: "Kyiv"},
+		{ID: 2, Address: "Lviv", PreferredProvider: "here"},
+	}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	hereProvider.On("Geocode", ctx, "Lviv").Return(lvivCoords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *kyivCoords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *lvivCoords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	require.Len(t, provider.calls, 1)
+	assert.Equal(t, []string{"Kyiv"}, provider.calls[0], "routed tasks must not be sent to the batch API")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessTask_BatchChecks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	kyivCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	lvivCoords := &models.Coordinates{Latitude: 49.84, Longitude: 24.03}
+
+	newBatchProvider := func(t *testing.T) *batchProvider {
+		t.Helper()
+
+		return &batchProvider{
+			Provider: mocks.NewProvider(t),
+			results:  map[string]*models.Coordinates{"Kyiv": kyivCoords, "Lviv": lvivCoords},
+		}
+	}
+
+	t.Run("cached addresses aren't sent to the batch API", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockCache := mocks.NewCache(t)
+		provider := newBatchProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, time.Second, "",
+			WithGeocodeCache(mockCache))
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+			Return([]models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}}, nil).Once()
+		mockCache.On("LookupCachedCoordinates", mock.Anything, "Kyiv").Return(kyivCoords, nil).Once()
+		mockCache.On("LookupCachedCoordinates", mock.Anything, "Lviv").Return(nil, repository.ErrCacheMiss).Once()
+		mockCache.On("StoreCachedCoordinates", mock.Anything, "Lviv", *lvivCoords, "test-provider").
+			Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, 1, *kyivCoords).Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, 2, *lvivCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.Equal(t, [][]string{{"Lviv"}}, provider.calls)
+	})
+
+	t.Run("structured and language groups are left to the worker pool", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := newBatchProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, time.Second, "",
+			WithStructuredAddresses(true))
+
+		structured := &models.StructuredAddress{City: "Lviv"}
+		mockRepo.On("FetchStructuredTasksForGeocoding", ctx, 100).Return([]models.Task{
+			{ID: 1, Address: "Kyiv"},
+			{ID: 2, Address: "Lviv", Structured: structured},
+			{ID: 3, Address: "Odesa", Language: "en"},
+		}, nil).Once()
+		provider.On("Geocode", mock.Anything, structured.String()).Return(lvivCoords, nil).Once()
+		provider.On("Geocode", mock.Anything, "Odesa").Return(kyivCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+		service.processTask(ctx)
+
+		assert.Equal(t, [][]string{{"Kyiv"}}, provider.calls)
+	})
+
+	t.Run("exhausted request budget defers the batch", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := newBatchProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, time.Second, "")
+
+		budgetCtx := geocoding.WithRequestBudget(ctx, geocoding.NewRequestBudget(0))
+		groups := service.groupBatch(budgetCtx, []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}})
+		assert.Empty(t, service.processNativeBatch(budgetCtx, groups))
+
+		assert.Empty(t, provider.calls)
+		assert.InDelta(t, 2, counterValue(t, metrics.BudgetExhausted), 0)
+	})
+
+	t.Run("panicking batch marks its tasks as failed", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := newBatchProvider(t)
+		provider.run = func(context.Context) { panic("index out of range") }
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, time.Second, "",
+			WithMaxConcurrentRequests(1))
+
+		panicErr := models.GeocodeError{
+			Code:    errorClassOther,
+			Message: "worker panicked while processing the task: index out of range",
+		}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+			Return([]models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}}, nil).Twice()
+		mockRepo.On("IncrementFailureCount", mock.Anything, 1, panicErr).Return(nil).Once()
+		mockRepo.On("IncrementFailureCount", mock.Anything, 2, panicErr).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		// The request slot of the panicking call was released, so the next poll isn't blocked
+		provider.run = nil
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+		service.processTask(ctx)
+
+		assert.InDelta(t, 1, counterValue(t, metrics.WorkerPanics), 0)
+	})
+
+	t.Run("slow batch is left for the next poll", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := newBatchProvider(t)
+		provider.results = nil
+		provider.run = func(ctx context.Context) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, time.Second, "",
+			WithTaskTimeout(50*time.Millisecond))
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+			Return([]models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}}, nil).Once()
+
+		start := time.Now()
+		service.processTask(ctx)
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 2, counterValue(t, metrics.TaskProcessed.WithLabelValues("timeout", "test-provider")), 0)
+	})
+
+	t.Run("batch goes through a chain of providers", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		provider := newBatchProvider(t)
+		fallback := mocks.NewProvider(t)
+		chain := geocoding.NewChainProvider(logger, provider, fallback)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, chain, "test-provider", metrics, 2, time.Second, "")
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+			Return([]models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Odesa"}}, nil).Once()
+		fallback.On("Geocode", mock.Anything, "Odesa").Return(lvivCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, 1, *kyivCoords).Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", mock.Anything, 2, *lvivCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.Equal(t, [][]string{{"Kyiv", "Odesa"}}, provider.calls)
+	})
+}
+
+// detailedProvider is a provider mock reporting place metadata with preset results per address.
+type detailedProvider struct {
+	*mocks.Provider
+
+	results map[string]*models.GeocodeResult
+}
+
+func (dp *detailedProvider) GeocodeDetailed(_ context.Context, address string) (*models.GeocodeResult, error) {
+	if result, ok := dp.results[address]; ok {
+		return result, nil
+	}
+
+	return nil, errors.New("address not found")
+}
+
+func TestProcessTask_DetailedResult(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+
+	kyivResult := &models.GeocodeResult{
+		Coordinates:      models.Coordinates{Latitude: 50.45, Longitude: 30.52, Precision: models.PrecisionRooftop},
+		PlaceID:          "ChIJBUVa4U7P1EAR_kYBF9IxSXY",
+		FormattedAddress: "Kyiv, Ukraine, 02000",
+	}
+	lvivResult := &models.GeocodeResult{Coordinates: models.Coordinates{Latitude: 49.84, Longitude: 24.03}}
+	provider := &detailedProvider{
+		Provider: mocks.NewProvider(t),
+		results:  map[string]*models.GeocodeResult{"Kyiv": kyivResult, "Lviv": lvivResult},
+	}
+	service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, 1*time.Second, "")
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+		Return([]models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}}, nil).Once()
+	mockRepo.On("UpdateTaskResult", ctx, 1, *kyivResult).Return(nil).Once()
+	// Results without place metadata keep using the coordinates-only update
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, lvivResult.Coordinates).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	provider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessTask_AddressRewrite(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(
+		logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "", WithAddressRewrite(true),
+	)
+
+	sampleTasks := []models.Task{
+		{ID: 1, Address: "село Грабовець,  вулиця Польова, 3"},
+		{ID: 2, Address: "м. Київ, вул. Хрещатик, 1"},
+	}
+	grabovets := &models.Coordinates{Latitude: 49.95, Longitude: 24.95}
+	kyiv := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, "с. Грабовець, вул. Польова, 3").Return(grabovets, nil).Once()
+	mockProvider.On("Geocode", ctx, "м. Київ, вул. Хрещатик, 1").Return(kyiv, nil).Once()
+	mockRepo.On("UpdateTaskGeocodeResult", ctx, 1, "с. Грабовець, вул. Польова, 3",
+		models.GeocodeResult{Coordinates: *grabovets}).Return(nil).Once()
+	// An address that is already normalized is left as is
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *kyiv).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestProcessTask_AddressRewriteDetailedResult(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+
+	grabovets := &models.GeocodeResult{
+		Coordinates:      models.Coordinates{Latitude: 49.95, Longitude: 24.95, Precision: models.PrecisionStreet},
+		PlaceID:          "ChIJ2Uq3Gm3OOkcRuXzvqJ4QG3s",
+		FormattedAddress: "вулиця Польова, 3, Грабовець, Україна",
+	}
+	provider := &detailedProvider{
+		Provider: mocks.NewProvider(t),
+		results:  map[string]*models.GeocodeResult{"с. Грабовець, вул. Польова, 3": grabovets},
+	}
+	service := NewGeocodingServie(
+		logger, mockRepo, provider, "test-provider", metrics, 1, 1*time.Second, "", WithAddressRewrite(true),
+	)
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+		Return([]models.Task{{ID: 1, Address: "село Грабовець,  вулиця Польова, 3"}}, nil).Once()
+	// The place metadata is stored by the same statement as the rewritten address
+	mockRepo.On("UpdateTaskGeocodeResult", ctx, 1, "с. Грабовець, вул. Польова, 3", *grabovets).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	provider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// structuredProvider is a provider mock looking up structured addresses, recording the addresses it got.
+type structuredProvider struct {
+	*mocks.Provider
+
+	mu        sync.Mutex
+	addresses []models.StructuredAddress
+}
+
+func (sp *structuredProvider) GeocodeStructured(
+	_ context.Context,
+	address models.StructuredAddress,
+) (*models.GeocodeResult, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.addresses = append(sp.addresses, address)
+
+	return &models.GeocodeResult{Coordinates: models.Coordinates{Latitude: 50.45, Longitude: 30.52}}, nil
+}
+
+func TestProcessTask_StructuredAddresses(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	provider := &structuredProvider{Provider: mocks.NewProvider(t)}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	// Address rewriting is enabled to check that structured addresses are left as is
+	service := NewGeocodingServie(logger, mockRepo, provider, "test-provider", metrics, 2, 1*time.Second, "",
+		WithStructuredAddresses(true), WithAddressRewrite(true),
+	)
+
+	kyiv := models.StructuredAddress{City: "м. Київ", Street: "вулиця Хрещатик", HouseNumber: "1"}
+	sampleTasks := []models.Task{
+		{ID: 1, Address: kyiv.String(), Structured: &kyiv},
+		{ID: 2, Address: kyiv.String(), Structured: &kyiv},
+		{ID: 3}, // a payload that couldn't be decoded
+	}
+	coords := models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	mockRepo.On("FetchStructuredTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockRepo.On("MarkInvalidAddress", ctx, 3, geocodeError(errorCodeInvalidAddress, errBlankAddress)).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, coords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, coords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertNotCalled(t, "FetchTasksForGeocoding", mock.Anything, mock.Anything)
+	provider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+	assert.Equal(t, []models.StructuredAddress{kyiv}, provider.addresses, "tasks sharing an address are grouped")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessTask_ServiceArea(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	ukraine := &models.BoundingBox{MinLatitude: 44.38, MinLongitude: 22.14, MaxLatitude: 52.38, MaxLongitude: 40.23}
+	kyiv := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	// A namesake village on another continent
+	elsewhere := &models.Coordinates{Latitude: 40.7128, Longitude: -74.006}
+
+	t.Run("results outside the area are failures", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		mockCache := mocks.NewCache(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		audit := &recordingAuditLogger{}
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithServiceArea(ukraine), WithGeocodeCache(mockCache), WithAuditLogger(audit),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+			Return([]models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Hrabovets"}}, nil).Once()
+		mockCache.On("LookupCachedCoordinates", ctx, mock.Anything).Return(nil, repository.ErrCacheMiss).Twice()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(kyiv, nil).Once()
+		mockProvider.On("Geocode", ctx, "Hrabovets").Return(elsewhere, nil).Once()
+		mockCache.On("StoreCachedCoordinates", ctx, "Kyiv", *kyiv, "test-provider").Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *kyiv).Return(nil).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 2, models.GeocodeError{
+			Code:    errorClassOutsideArea,
+			Message: "geocoding result is outside the service area: POINT(-74.006 40.7128)",
+		}).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		// The rejected result must not be cached, so that the next attempt asks the provider again
+		mockCache.AssertNotCalled(t, "StoreCachedCoordinates", mock.Anything, "Hrabovets", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+		assert.InDelta(t, 1, counterValue(t, metrics.OutsideServiceArea.WithLabelValues("test-provider")), 0)
+		assert.InDelta(t, 1, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassOutsideArea)), 0)
+		require.Len(t, audit.records, 2)
+		assert.Equal(t, AuditStatusFailure, audit.records[1].Status)
+	})
+
+	t.Run("results anywhere without an area", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "")
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 2, Address: "Hrabovets"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Hrabovets").Return(elsewhere, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 2, *elsewhere).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertExpectations(t)
+		assert.InDelta(t, 0, counterValue(t, metrics.OutsideServiceArea.WithLabelValues("test-provider")), 0)
+	})
+}
+
+func TestProcessTask_StaleRefresh(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	olderThan := 30 * 24 * time.Hour
+	moved := &models.Coordinates{Latitude: 50.4547, Longitude: 30.5238}
+
+	t.Run("stale tasks are refreshed when the queue is empty", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithStaleRefresh(olderThan),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once()
+		mockRepo.On("FetchStaleTasks", ctx, olderThan, 100).
+			Return([]models.Task{{ID: 7, Address: "Kyiv, Khreshchatyk, 1"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv, Khreshchatyk, 1").Return(moved, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 7, *moved).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("new tasks come first", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithStaleRefresh(olderThan),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Lviv"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Lviv").Return(moved, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *moved).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "FetchStaleTasks", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("a failed refresh isn't counted against the task", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithStaleRefresh(olderThan), WithNotFoundStatus(true),
+		)
+
+		stale := []models.Task{{ID: 7, Address: "Kyiv, Khreshchatyk, 1"}, {ID: 8, Address: "Lviv, Rynok, 1"}}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once()
+		mockRepo.On("FetchStaleTasks", ctx, olderThan, 100).Return(stale, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv, Khreshchatyk, 1").Return(nil, geocoding.ErrServerError).Once()
+		mockProvider.On("Geocode", ctx, "Lviv, Rynok, 1").Return(nil, geocoding.ErrVisicomEmptyResponse).Once()
+		mockRepo.On("PostponeStaleRefresh", ctx, 7).Return(nil).Once()
+		mockRepo.On("PostponeStaleRefresh", ctx, 8).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkTaskNotFound", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 2, counterValue(t, metrics.TaskProcessed.WithLabelValues("failure", "test-provider")), 0)
+	})
+
+	t.Run("a rate limited refresh isn't postponed", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithStaleRefresh(olderThan),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once()
+		mockRepo.On("FetchStaleTasks", ctx, olderThan, 100).
+			Return([]models.Task{{ID: 7, Address: "Kyiv, Khreshchatyk, 1"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv, Khreshchatyk, 1").
+			Return(nil, &geocoding.RateLimitError{Provider: "test-provider"}).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "PostponeStaleRefresh", mock.Anything, mock.Anything)
+	})
+
+	t.Run("dry run doesn't postpone a failed refresh", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+			WithStaleRefresh(olderThan), WithDryRun(true),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once()
+		mockRepo.On("FetchStaleTasks", ctx, olderThan, 100).
+			Return([]models.Task{{ID: 7, Address: "Kyiv, Khreshchatyk, 1"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv, Khreshchatyk, 1").Return(nil, geocoding.ErrServerError).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "PostponeStaleRefresh", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mocks.NewProvider(t), "test-provider", metrics, 1,
+			1*time.Second, "", WithStaleRefresh(olderThan),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once()
+		mockRepo.On("FetchStaleTasks", ctx, olderThan, 100).Return(nil, assert.AnError).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProcessTask_WorkerPanic(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	// A single worker and request slot, so the batch only completes if the worker survives the panic
+	// and the slot held by the panicking call is released
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithMaxConcurrentRequests(1),
+	)
+
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).
+		Return([]models.Task{{ID: 1, Address: "Bad Response"}, {ID: 2, Address: "Kyiv"}}, nil).Once()
+	mockProvider.On("Geocode", ctx, "Bad Response").Run(func(mock.Arguments) {
+		panic("index out of range")
+	}).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+	mockRepo.On("IncrementFailureCount", ctx, 1, models.GeocodeError{
+		Code:    errorClassOther,
+		Message: "worker panicked while processing the task: index out of range",
+	}).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 2, *sampleCoords).Return(nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.processTask(ctx)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch did not complete after a worker panic")
+	}
+
+	mockRepo.AssertExpectations(t)
+	assert.InDelta(t, 1, counterValue(t, metrics.WorkerPanics), 0)
+	assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("failure", "test-provider")), 0)
+}
+
+func TestProcessTask_RateLimited(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	audit := &recordingAuditLogger{}
+	ctx := t.Context()
+	service := NewGeocodingServie(
+		logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "", WithAuditLogger(audit),
+	)
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "kyiv"}}
+	rateLimitErr := &geocoding.RateLimitError{Provider: "nominatim", RetryAfter: time.Minute}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, rateLimitErr).Once()
+
+	service.processTask(ctx)
+
+	// Neither IncrementFailureCount nor UpdateTaskCoordinates must be called,
+	// so the tasks are fetched again on the next poll with their attempt count intact.
+	mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+
+	assert.InDelta(t, 1, counterValue(t, metrics.RateLimited.WithLabelValues("test-provider")), 0)
+	assert.InDelta(t, 1, counterValue(t, metrics.APIErrors.WithLabelValues("rate_limited")), 0)
+	assert.InDelta(t, 0, counterValue(t, metrics.APIErrors.WithLabelValues("other")), 0)
+	assert.Equal(t, uint64(2), histogramCount(t, metrics.TaskDurationSeconds.WithLabelValues("rate_limited")))
+	assert.InDelta(t, 2, counterValue(t, metrics.TaskProcessed.WithLabelValues("rate_limited", "test-provider")), 0)
+
+	require.Len(t, audit.records, 2)
+	for _, record := range audit.records {
+		assert.Equal(t, AuditStatusRateLimited, record.Status)
+	}
+}
+
+// counterValue returns the current value of the counter.
+// geocodeError returns the failure stored with a task for err with the given code.
+func geocodeError(code string, err error) models.GeocodeError {
+	return models.GeocodeError{Code: code, Message: err.Error()}
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+
+	var written dto.Metric
+	require.NoError(t, counter.Write(&written))
+
+	return written.GetCounter().GetValue()
+}
+
+func TestUpdatePendingTasks(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "")
+
+	mockRepo.On("CountPendingTasks", ctx).Return(42, nil).Once()
+	service.updatePendingTasks(ctx)
+	assert.InDelta(t, 42, gaugeValue(t, metrics.PendingTasks), 0)
+
+	// A failed count keeps the previous value
+	mockRepo.On("CountPendingTasks", ctx).Return(0, assert.AnError).Once()
+	service.updatePendingTasks(ctx)
+	assert.InDelta(t, 42, gaugeValue(t, metrics.PendingTasks), 0)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdatePendingTasks_Structured(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mocks.NewProvider(t), "test-provider", metrics, 2, time.Second, "",
+		WithStructuredAddresses(true))
+
+	mockRepo.On("CountPendingStructuredTasks", ctx).Return(7, nil).Once()
+	service.updatePendingTasks(ctx)
+
+	assert.InDelta(t, 7, gaugeValue(t, metrics.PendingTasks), 0)
+}
+
+// gaugeValue returns the current value of the gauge.
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	t.Helper()
+
+	var written dto.Metric
+	require.NoError(t, gauge.Write(&written))
+
+	return written.GetGauge().GetValue()
+}
+
+func TestProcessTask_DryRun(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	audit := &recordingAuditLogger{}
+	ctx := t.Context()
+	service := NewGeocodingServie(
+		logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithAuditLogger(audit), WithDryRun(true),
+	)
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Invalid Address"}}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+	mockProvider.On("Geocode", ctx, "Invalid Address").Return(nil, errors.New("geocoding failed")).Once()
+
+	service.processTask(ctx)
+
+	// Only the fetch is expected, any repository write fails the test
+	mockRepo.AssertNotCalled(t, "UpdateTaskCoordinates", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+
+	assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("success", "test-provider")), 0)
+	assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("failure", "test-provider")), 0)
+	assert.Equal(t, uint64(1), histogramCount(t, metrics.TaskDurationSeconds.WithLabelValues("success")))
+	assert.Len(t, audit.records, 2)
+}
+
+func TestRun_ImmediatePoll(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, time.Hour, "")
+
+	polled := make(chan struct{})
+	mockRepo.On("CountPendingTasks", ctx).Return(0, nil).Once()
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once().
+		Run(func(mock.Arguments) { close(polled) })
+
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("the first poll must start without waiting for the poll interval")
+	}
+
+	cancel()
+	<-done
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRun_WithoutImmediatePoll(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	service := NewGeocodingServie(
+		logger, mockRepo, mockProvider, "test-provider", metrics, 1, time.Hour, "", WithImmediatePoll(false),
+	)
+
+	// Nothing is polled before the first interval elapses
+	service.Run(ctx)
+
+	mockRepo.AssertNotCalled(t, "FetchTasksForGeocoding", mock.Anything, mock.Anything)
+}
+
+func TestRunUntil_DrainsCurrentBatch(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, time.Millisecond, "")
+
+	// Polling is stopped while the batch is being geocoded
+	stop := make(chan struct{})
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	mockRepo.On("CountPendingTasks", ctx).Return(1, nil).Once()
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once().
+		Run(func(mock.Arguments) { close(stop) })
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		service.RunUntil(ctx, stop)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the service must stop after the current batch")
+	}
+
+	// The batch in progress is finished, and no further batch is polled
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestNextPollAt(t *testing.T) {
+	lastPoll := time.Now()
+
+	t.Run("without jitter", func(t *testing.T) {
+		service := &GeocodingService{pollInterval: time.Minute}
+
+		assert.Equal(t, lastPoll.Add(time.Minute), service.nextPollAt(lastPoll))
+	})
+
+	t.Run("with jitter", func(t *testing.T) {
+		service := &GeocodingService{pollInterval: time.Minute, pollJitter: 10 * time.Second}
+
+		for range 100 {
+			next := service.nextPollAt(lastPoll)
+			assert.False(t, next.Before(lastPoll.Add(time.Minute)))
+			assert.True(t, next.Before(lastPoll.Add(time.Minute+10*time.Second)))
+		}
+	})
+}
+
+func TestFollowingPollAt(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	t.Run("batch within the interval", func(t *testing.T) {
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := &GeocodingService{log: logger, metrics: metrics, pollInterval: time.Minute}
+		lastPoll := time.Now().Add(-10 * time.Second)
+
+		assert.Equal(t, lastPoll.Add(time.Minute), service.followingPollAt(t.Context(), lastPoll))
+		assert.Zero(t, counterValue(t, metrics.PollsSkipped))
+	})
+
+	t.Run("batch longer than the interval", func(t *testing.T) {
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := &GeocodingService{log: logger, metrics: metrics, pollInterval: time.Minute}
+		lastPoll := time.Now().Add(-150 * time.Second)
+
+		next := service.followingPollAt(t.Context(), lastPoll)
+
+		// The two polls due during the batch are skipped, the next one is an interval after it finished
+		assert.InDelta(t, 2, counterValue(t, metrics.PollsSkipped), 0)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), next, time.Second)
+	})
+}
+
+func TestRun_SkipsOverlappingPolls(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	interval := 10 * time.Millisecond
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, interval, "")
+
+	// The first batch takes several intervals, the second poll finds nothing
+	var batchDone, secondPoll time.Time
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	mockRepo.On("CountPendingTasks", ctx).Return(1, nil)
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once().
+		Run(func(mock.Arguments) { time.Sleep(4 * interval) })
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once().
+		Run(func(mock.Arguments) { batchDone = time.Now() })
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once().
+		Run(func(mock.Arguments) {
+			secondPoll = time.Now()
+			cancel()
+		})
+
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the service must poll again after the overrunning batch")
+	}
+
+	// The polls that became due during the batch are skipped instead of running back to back
+	assert.GreaterOrEqual(t, counterValue(t, metrics.PollsSkipped), 3.0)
+	assert.GreaterOrEqual(t, secondPoll.Sub(batchDone), interval)
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
+
+func TestProcessTask_FetchRetry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	transientErr := &pgconn.PgError{Code: "08006", Message: "connection failure"}
+
+	newService := func(t *testing.T, opts ...Option) (*GeocodingService, *mocks.Interface) {
+		t.Helper()
+		mockRepo := mocks.NewInterface(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		opts = append([]Option{WithFetchRetry(2, time.Millisecond)}, opts...)
+		service := NewGeocodingServie(
+			logger, mockRepo, mocks.NewProvider(t), "test-provider", metrics, 1, time.Second, "", opts...,
+		)
+		return service, mockRepo
+	}
+
+	t.Run("transient error is retried", func(t *testing.T) {
+		ctx := t.Context()
+		service, mockRepo := newService(t)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(nil, transientErr).Once()
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNumberOfCalls(t, "FetchTasksForGeocoding", 2)
+	})
+
+	t.Run("retries are capped", func(t *testing.T) {
+		ctx := t.Context()
+		service, mockRepo := newService(t)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(nil, transientErr).Times(3)
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNumberOfCalls(t, "FetchTasksForGeocoding", 3)
+	})
+
+	t.Run("query error is not retried", func(t *testing.T) {
+		ctx := t.Context()
+		service, mockRepo := newService(t)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(nil, &pgconn.PgError{Code: "42703"}).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNumberOfCalls(t, "FetchTasksForGeocoding", 1)
+	})
+
+	t.Run("retrying stops when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		service, mockRepo := newService(t, WithFetchRetry(2, time.Hour))
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(nil, transientErr).Once().
+			Run(func(mock.Arguments) { cancel() })
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNumberOfCalls(t, "FetchTasksForGeocoding", 1)
+	})
+}
+
+func TestProcessTask_GeocodeCache(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := t.Context()
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("cache hit skips the provider", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		mockCache := mocks.NewCache(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(
+			logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithGeocodeCache(mockCache),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockCache.On("LookupCachedCoordinates", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockProvider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+		mockCache.AssertNotCalled(t, "StoreCachedCoordinates", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 1, counterValue(t, metrics.CacheLookups.WithLabelValues("hit")), 0)
+	})
+
+	t.Run("cache miss geocodes and stores the result", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		mockCache := mocks.NewCache(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(
+			logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithGeocodeCache(mockCache),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockCache.On("LookupCachedCoordinates", ctx, "Kyiv").Return(nil, repository.ErrCacheMiss).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockCache.On("StoreCachedCoordinates", ctx, "Kyiv", *sampleCoords, "test-provider").Return(nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.InDelta(t, 1, counterValue(t, metrics.CacheLookups.WithLabelValues("miss")), 0)
+	})
+
+	t.Run("cache errors fall back to the provider", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		mockCache := mocks.NewCache(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(
+			logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithGeocodeCache(mockCache),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockCache.On("LookupCachedCoordinates", ctx, "Kyiv").Return(nil, assert.AnError).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockCache.On("StoreCachedCoordinates", ctx, "Kyiv", *sampleCoords, "test-provider").Return(assert.AnError).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.InDelta(t, 1, counterValue(t, metrics.CacheLookups.WithLabelValues("error")), 0)
+	})
+
+	t.Run("failed geocoding is not cached", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		mockCache := mocks.NewCache(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(
+			logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithGeocodeCache(mockCache),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockCache.On("LookupCachedCoordinates", ctx, "Kyiv").Return(nil, repository.ErrCacheMiss).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, assert.AnError).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 1, geocodeError(errorClassOther, assert.AnError)).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockCache.AssertNotCalled(t, "StoreCachedCoordinates", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockCache.AssertNotCalled(t, "StoreCachedNotFound", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty response is cached as not found", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		mockCache := mocks.NewCache(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(
+			logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithGeocodeCache(mockCache),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockCache.On("LookupCachedCoordinates", ctx, "Kyiv").Return(nil, repository.ErrCacheMiss).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrNominatimEmptyResponse).Once()
+		mockCache.On("StoreCachedNotFound", ctx, "Kyiv", "test-provider").Return(nil).Once()
+		notFound := geocodeError(errorClassEmptyResponse, geocoding.ErrNominatimEmptyResponse)
+		mockRepo.On("IncrementFailureCount", ctx, 1, notFound).Return(nil).Once()
+
+		service.processTask(ctx)
+	})
+
+	t.Run("cached not found skips the provider", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		mockCache := mocks.NewCache(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(
+			logger, mockRepo, mockProvider, "test-provider", metrics, 2, 1*time.Second, "",
+			WithGeocodeCache(mockCache),
+		)
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockCache.On("LookupCachedCoordinates", ctx, "Kyiv").Return(nil, repository.ErrCachedNotFound).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 1, geocodeError(errorClassEmptyResponse, repository.ErrCachedNotFound)).
+			Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockProvider.AssertNotCalled(t, "Geocode", mock.Anything, mock.Anything)
+		assert.InDelta(t, 1, counterValue(t, metrics.NegativeCacheHits), 0)
+		assert.InDelta(t, 0, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassOther)), 0)
+	})
+}
+
+func TestProcessTask_ResultPrecision(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name      string
+		precision models.Precision
+		expected  string
+	}{
+		{name: "rooftop", precision: models.PrecisionRooftop, expected: "rooftop"},
+		{name: "street", precision: models.PrecisionStreet, expected: "street"},
+		{name: "locality", precision: models.PrecisionLocality, expected: "locality"},
+		{name: "region", precision: models.PrecisionRegion, expected: "region"},
+		{name: "approximate", precision: models.PrecisionApproximate, expected: "approximate"},
+		{name: "not reported", expected: "approximate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewInterface(t)
+			mockProvider := mocks.NewProvider(t)
+			metrics := metrics.NewMetrics(prometheus.NewRegistry())
+			service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, time.Second, "")
+			ctx := t.Context()
+
+			coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52, Precision: tt.precision}
+			mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+			mockProvider.On("Geocode", ctx, "Kyiv").Return(coords, nil).Once()
+			mockRepo.On("UpdateTaskCoordinates", ctx, 1, *coords).Return(nil).Once()
+
+			service.processTask(ctx)
+
+			counter := metrics.ResultPrecision.WithLabelValues(tt.expected, "test-provider")
+			assert.InDelta(t, 1, counterValue(t, counter), 0)
+			assert.Equal(t, 1, testutil.CollectAndCount(metrics.ResultPrecision), "only one precision is counted")
+		})
+	}
+
+	t.Run("a failed update isn't counted", func(t *testing.T) {
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, time.Second, "")
+		ctx := t.Context()
+
+		coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52, Precision: models.PrecisionRooftop}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(coords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *coords).Return(assert.AnError).Once()
+
+		service.processTask(ctx)
+
+		assert.Zero(t, testutil.CollectAndCount(metrics.ResultPrecision))
+	})
+}
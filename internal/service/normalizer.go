@@ -0,0 +1,80 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// AddressNormalizer preprocesses an address before it is sent to the geocoding provider.
+// The normalized form is only used for geocoding; the original address is kept in the database.
+type AddressNormalizer interface {
+	Normalize(address string) string
+}
+
+// AddressPlaceholder is replaced by the address in an address template (see WithAddressTemplate).
+const AddressPlaceholder = "{address}"
+
+// applyAddressTemplate returns the template with every AddressPlaceholder replaced by the address,
+// e.g. "{address}, Україна". An empty template returns the address unchanged.
+func applyAddressTemplate(template, address string) string {
+	if template == "" {
+		return address
+	}
+
+	return strings.ReplaceAll(template, AddressPlaceholder, address)
+}
+
+// PrepareAddress returns the address as the service sends it to the provider: normalized,
+// preceded by the address prefix and placed in the address template (see WithAddressTemplate).
+func PrepareAddress(normalizer AddressNormalizer, prefix, template, address string) string {
+	return applyAddressTemplate(template, prefix+normalizer.Normalize(address))
+}
+
+// abbreviationRule rewrites a leading settlement or street type in an address component
+// to its canonical abbreviation.
+type abbreviationRule struct {
+	pattern   *regexp.Regexp // pattern matches the type word and the separator that follows it
+	canonical string         // canonical is the replacement, including the trailing space
+}
+
+// ukrainianAbbreviations lists the common Ukrainian address type variants.
+// The rules are applied to each comma-separated component, and the first matching rule wins,
+// so longer forms must come before their shorter prefixes (e.g. "смт" before "с").
+var ukrainianAbbreviations = []abbreviationRule{
+	{regexp.MustCompile(`(?i)^(?:селище міського типу|смт)(?:\.\s*|\s+)`), "смт "},
+	{regexp.MustCompile(`(?i)^(?:село|с)(?:\.\s*|\s+)`), "с. "},
+	{regexp.MustCompile(`(?i)^(?:місто|м)(?:\.\s*|\s+)`), "м. "},
+	{regexp.MustCompile(`(?i)^(?:вулиця|вул)(?:\.\s*|\s+)`), "вул. "},
+	{regexp.MustCompile(`(?i)^(?:провулок|пров)(?:\.\s*|\s+)`), "пров. "},
+	{regexp.MustCompile(`(?i)^(?:проспект|просп|пр-т)(?:\.\s*|\s+)`), "просп. "},
+	{regexp.MustCompile(`(?i)^(?:бульвар|бульв|б-р)(?:\.\s*|\s+)`), "бульв. "},
+}
+
+// UkrainianAddressNormalizer standardizes common Ukrainian address abbreviations
+// ("село", "с" -> "с.", "вулиця", "вул" -> "вул.", etc.) and trims and collapses whitespace.
+type UkrainianAddressNormalizer struct{}
+
+// Normalize returns the address with whitespace collapsed and each comma-separated
+// component's leading type word replaced by its canonical abbreviation.
+func (UkrainianAddressNormalizer) Normalize(address string) string {
+	parts := strings.Split(address, ",")
+	components := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		component := strings.Join(strings.Fields(part), " ")
+		if component == "" {
+			continue
+		}
+
+		for _, rule := range ukrainianAbbreviations {
+			if loc := rule.pattern.FindStringIndex(component); loc != nil {
+				component = strings.TrimSpace(rule.canonical + component[loc[1]:])
+				break
+			}
+		}
+
+		components = append(components, component)
+	}
+
+	return strings.Join(components, ", ")
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUkrainianAddressNormalizer_Normalize(t *testing.T) {
+	normalizer := UkrainianAddressNormalizer{}
+
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+	}{
+		{
+			name:     "canonical form is unchanged",
+			address:  "с. Грабовець, вул. Польова, 3",
+			expected: "с. Грабовець, вул. Польова, 3",
+		},
+		{name: "village without dot", address: "с Грабовець", expected: "с. Грабовець"},
+		{name: "village without space", address: "с.Грабовець", expected: "с. Грабовець"},
+		{name: "village full word", address: "село Грабовець", expected: "с. Грабовець"},
+		{name: "village capitalized", address: "Село Грабовець", expected: "с. Грабовець"},
+		{name: "street full word", address: "вулиця Польова, 3", expected: "вул. Польова, 3"},
+		{name: "street without dot", address: "вул Польова", expected: "вул. Польова"},
+		{
+			name:     "city full word",
+			address:  "місто Київ, вулиця Хрещатик, 1",
+			expected: "м. Київ, вул. Хрещатик, 1",
+		},
+		{
+			name:     "urban-type settlement",
+			address:  "селище міського типу Ворохта",
+			expected: "смт Ворохта",
+		},
+		{name: "urban-type settlement with dot", address: "смт.Ворохта", expected: "смт Ворохта"},
+		{name: "lane", address: "провулок Тихий", expected: "пров. Тихий"},
+		{name: "avenue", address: "пр-т Перемоги", expected: "просп. Перемоги"},
+		{name: "boulevard", address: "бульвар Шевченка", expected: "бульв. Шевченка"},
+		{
+			name:     "whitespace is trimmed and collapsed",
+			address:  "  с.   Грабовець ,  вул.  Польова  ",
+			expected: "с. Грабовець, вул. Польова",
+		},
+		{
+			name:     "empty components are dropped",
+			address:  "с. Грабовець,, вул. Польова,",
+			expected: "с. Грабовець, вул. Польова",
+		},
+		{
+			name:     "words starting with a type letter are kept",
+			address:  "Садова, Миру",
+			expected: "Садова, Миру",
+		},
+		{
+			name:     "non-Ukrainian address",
+			address:  "1600 Amphitheatre Parkway, Mountain View",
+			expected: "1600 Amphitheatre Parkway, Mountain View",
+		},
+		{name: "empty address", address: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizer.Normalize(tt.address))
+		})
+	}
+}
+
+func TestApplyAddressTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		address  string
+		expected string
+	}{
+		{name: "no template", address: "м. Київ, вул. Хрещатик, 1", expected: "м. Київ, вул. Хрещатик, 1"},
+		{
+			name:     "country suffix",
+			template: "{address}, Україна",
+			address:  "м. Київ, вул. Хрещатик, 1",
+			expected: "м. Київ, вул. Хрещатик, 1, Україна",
+		},
+		{
+			name:     "region prefix",
+			template: "Львівська область, {address}",
+			address:  "с. Грабовець",
+			expected: "Львівська область, с. Грабовець",
+		},
+		{
+			name:     "every placeholder is replaced",
+			template: "{address} ({address})",
+			address:  "Київ",
+			expected: "Київ (Київ)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, applyAddressTemplate(tt.template, tt.address))
+		})
+	}
+}
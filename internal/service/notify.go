@@ -0,0 +1,48 @@
+package service
+
+import (
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/webhook"
+)
+
+// CompletionNotifier is told about every task whose geocoding completed, e.g. webhook.Notifier.
+// Notify is called by the workers, so it must not block.
+type CompletionNotifier interface {
+	Notify(event webhook.Event)
+}
+
+// notifyGeocoded notifies the completion notifier, if any, of a task whose coordinates were stored.
+func (gs *GeocodingService) notifyGeocoded(taskID int, providerName string, coords models.Coordinates) {
+	if gs.notifier == nil {
+		return
+	}
+
+	gs.notifier.Notify(webhook.Event{
+		TaskID:    taskID,
+		Status:    webhook.StatusGeocoded,
+		Provider:  providerName,
+		Latitude:  &coords.Latitude,
+		Longitude: &coords.Longitude,
+		Precision: string(coords.Precision),
+		Time:      time.Now(),
+	})
+}
+
+// notifyFailed notifies the completion notifier, if any, of a task that is no longer geocoded because
+// it failed for good, with status webhook.StatusNotFound or webhook.StatusInvalid. Failures retried
+// on a later poll aren't a completion, so they aren't notified.
+func (gs *GeocodingService) notifyFailed(taskID int, status, providerName string, err error) {
+	if gs.notifier == nil {
+		return
+	}
+
+	gs.notifier.Notify(webhook.Event{
+		TaskID:   taskID,
+		Status:   status,
+		Provider: providerName,
+		Error:    err.Error(),
+		Time:     time.Now(),
+	})
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"cmp"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/internal/webhook"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier is a CompletionNotifier recording the notified events.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []webhook.Event
+}
+
+func (rn *recordingNotifier) Notify(event webhook.Event) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	rn.events = append(rn.events, event)
+}
+
+// sorted returns the notified events ordered by task, with their time cleared.
+func (rn *recordingNotifier) sorted() []webhook.Event {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	events := slices.Clone(rn.events)
+	for i := range events {
+		events[i].Time = time.Time{}
+	}
+	slices.SortFunc(events, func(a, b webhook.Event) int { return cmp.Compare(a.TaskID, b.TaskID) })
+
+	return events
+}
+
+func TestProcessTask_CompletionNotifier(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52, Precision: models.PrecisionStreet}
+
+	newService := func(t *testing.T, notifier CompletionNotifier, opts ...Option) (
+		*GeocodingService, *mocks.Interface, *mocks.Provider,
+	) {
+		t.Helper()
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		opts = append(opts, WithCompletionNotifier(notifier), WithNotFoundStatus(true))
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "visicom", metrics, 1, time.Second, "",
+			opts...)
+
+		return service, mockRepo, mockProvider
+	}
+
+	t.Run("geocoded, not found and invalid tasks are notified, retried ones aren't", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, mockRepo, mockProvider := newService(t, notifier)
+		ctx := t.Context()
+
+		tasks := []models.Task{
+			{ID: 1, Address: "Kyiv"},
+			{ID: 2, Address: "Nowhere"},
+			{ID: 3, Address: "Lviv"},
+			{ID: 4, Address: "  "},
+		}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(tasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, geocoding.ErrVisicomEmptyResponse).Once()
+		mockProvider.On("Geocode", ctx, "Lviv").Return(nil, geocoding.ErrServerError).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+		mockRepo.On("MarkTaskNotFound", ctx, 2, newGeocodeError(geocoding.ErrVisicomEmptyResponse)).Return(nil).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 3, newGeocodeError(geocoding.ErrServerError)).Return(nil).Once()
+		mockRepo.On("MarkInvalidAddress", ctx, 4, geocodeError(errorCodeInvalidAddress, errBlankAddress)).
+			Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.Equal(t, []webhook.Event{
+			{
+				TaskID:    1,
+				Status:    webhook.StatusGeocoded,
+				Provider:  "visicom",
+				Latitude:  &sampleCoords.Latitude,
+				Longitude: &sampleCoords.Longitude,
+				Precision: "street",
+			},
+			{
+				TaskID:   2,
+				Status:   webhook.StatusNotFound,
+				Provider: "visicom",
+				Error:    geocoding.ErrVisicomEmptyResponse.Error(),
+			},
+			{TaskID: 4, Status: webhook.StatusInvalid, Error: errBlankAddress.Error()},
+		}, notifier.sorted())
+	})
+
+	t.Run("failed database updates aren't notified", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, mockRepo, mockProvider := newService(t, notifier)
+		ctx := t.Context()
+
+		tasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Nowhere"}}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(tasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockProvider.On("Geocode", ctx, "Nowhere").Return(nil, geocoding.ErrVisicomEmptyResponse).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(assert.AnError).Once()
+		mockRepo.On("MarkTaskNotFound", ctx, 2, newGeocodeError(geocoding.ErrVisicomEmptyResponse)).
+			Return(assert.AnError).Once()
+
+		service.processTask(ctx)
+
+		assert.Empty(t, notifier.sorted())
+	})
+
+	t.Run("dry run doesn't notify", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, mockRepo, mockProvider := newService(t, notifier, WithDryRun(true))
+		ctx := t.Context()
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+
+		service.processTask(ctx)
+
+		assert.Empty(t, notifier.sorted())
+	})
+}
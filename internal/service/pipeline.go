@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// processPipeline processes the backlog like processTask, but a producer keeps fetching the next batches
+// while the worker pool geocodes the previous ones, so the workers don't idle while a batch is fetched.
+// The channels between them are bounded: the producer holds at most the pipeline depth of fetched batches
+// ahead of the workers, and waits for them to catch up otherwise.
+// The producer stops once a fetch comes back short, finds only tasks already processed during the poll,
+// the request budget runs out, the default provider rejects the API key or stop is closed, and each task
+// is processed at most once per poll.
+// It returns the number of tasks fetched.
+func (gs *GeocodingService) processPipeline(ctx context.Context, stop <-chan struct{}) int {
+	ctx, span := gs.startSpan(ctx, "GeocodingService.processPipeline")
+	defer span.End()
+
+	var budget *geocoding.RequestBudget
+	if gs.budget > 0 {
+		budget = geocoding.NewRequestBudget(gs.budget)
+		ctx = geocoding.WithRequestBudget(ctx, budget)
+		defer gs.logBudgetExhausted(ctx, budget)
+	}
+
+	batches := make(chan []models.Task, gs.pipeline)
+	var (
+		found   int
+		claimed []models.Task
+	)
+	// The producer owns found and claimed until it closes batches
+	go func() {
+		defer close(batches)
+		found, claimed = gs.produceBatches(ctx, stop, budget, batches)
+	}()
+
+	jobs := make(chan taskGroup, gs.numWorkers)
+	var wgr sync.WaitGroup
+	for i := 1; i <= gs.numWorkers; i++ {
+		wgr.Add(1)
+		go gs.worker(ctx, i, &wgr, jobs)
+	}
+
+	for tasks := range batches {
+		tasks = gs.skipInvalidAddresses(ctx, tasks)
+		if len(tasks) == 0 {
+			continue
+		}
+
+		groups := gs.processNativeBatch(ctx, gs.groupBatch(ctx, tasks))
+		gs.log.InfoContext(ctx, "Queued batch for the worker pool", "tasks", len(tasks), "jobs", len(groups))
+		for _, group := range groups {
+			jobs <- group
+		}
+	}
+	close(jobs)
+
+	wgr.Wait()
+	gs.releaseClaims(ctx, claimed)
+	gs.releaseInFlight(claimed)
+	if found == 0 {
+		gs.log.InfoContext(ctx, "No tasks to process.")
+	} else {
+		gs.log.InfoContext(ctx, "Processing pipeline finished", "tasks", found)
+	}
+
+	return found
+}
+
+// produceBatches fetches batches of tasks and sends them to batches until the backlog is drained, like
+// processTask refreshing stale coordinates only if the first fetch finds no task. Tasks fetched again during
+// the poll, e.g. after a failed attempt released their claim, are dropped, so that they aren't retried
+// until the next poll. It returns the number of tasks sent and the tasks claimed by claimInFlight.
+func (gs *GeocodingService) produceBatches(
+	ctx context.Context,
+	stop <-chan struct{},
+	budget *geocoding.RequestBudget,
+	batches chan<- []models.Task,
+) (int, []models.Task) {
+	var (
+		found   int
+		claimed []models.Task
+	)
+	seen := make(map[int]struct{})
+
+	for first := true; gs.keepFetching(ctx, stop, budget); first = false {
+		tasks, err := gs.fetchTasks(ctx, taskBatchSize)
+		stale := false
+		if err == nil && len(tasks) == 0 && first {
+			tasks, err = gs.fetchStaleTasks(ctx, taskBatchSize)
+			stale = true
+		}
+		if err != nil {
+			gs.log.ErrorContext(ctx, "Failed to fetch tasks", "error", err)
+			recordSpanError(trace.SpanFromContext(ctx), err)
+			return found, claimed
+		}
+
+		fetched := len(tasks)
+		tasks = unseenTasks(seen, tasks)
+		if len(tasks) == 0 {
+			return found, claimed
+		}
+		found += len(tasks)
+
+		tasks = gs.claimInFlight(ctx, tasks)
+		claimed = append(claimed, tasks...)
+
+		select {
+		case batches <- tasks:
+		case <-ctx.Done():
+			return found, claimed
+		}
+
+		if stale || fetched < taskBatchSize {
+			return found, claimed
+		}
+	}
+
+	return found, claimed
+}
+
+// keepFetching reports whether the producer fetches another batch: the poll isn't stopped, the default provider
+// didn't reject the API key and the request budget, if any, isn't spent.
+func (gs *GeocodingService) keepFetching(
+	ctx context.Context,
+	stop <-chan struct{},
+	budget *geocoding.RequestBudget,
+) bool {
+	return !stopped(ctx, stop) && !gs.defaultProviderAborted() && (budget == nil || !budget.Exhausted())
+}
+
+// unseenTasks returns the tasks whose ID isn't in seen, and adds their IDs to it.
+func unseenTasks(seen map[int]struct{}, tasks []models.Task) []models.Task {
+	unseen := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if _, ok := seen[task.ID]; ok {
+			continue
+		}
+		seen[task.ID] = struct{}{}
+		unseen = append(unseen, task)
+	}
+
+	return unseen
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// batchRepo is a repository whose fetches yield its batches of tasks in order, and then no tasks.
+// The other methods are those of the embedded mock.
+type batchRepo struct {
+	*mocks.Interface
+
+	mu      sync.Mutex
+	batches [][]models.Task
+	fetches int
+	onFetch func(fetches int) // onFetch, if set, is called with the number of fetches after each fetch
+}
+
+func (br *batchRepo) FetchTasksForGeocoding(_ context.Context, _ int) ([]models.Task, error) {
+	br.mu.Lock()
+	br.fetches++
+	fetches := br.fetches
+	var batch []models.Task
+	if len(br.batches) > 0 {
+		batch, br.batches = br.batches[0], br.batches[1:]
+	}
+	br.mu.Unlock()
+
+	if br.onFetch != nil {
+		br.onFetch(fetches)
+	}
+
+	return batch, nil
+}
+
+func (br *batchRepo) fetchCount() int {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	return br.fetches
+}
+
+// pipelineTasks returns count tasks with distinct addresses, numbered from first.
+func pipelineTasks(first, count int) []models.Task {
+	tasks := make([]models.Task, count)
+	for i := range tasks {
+		tasks[i] = models.Task{ID: first + i, Address: fmt.Sprintf("Khreshchatyk %d", first+i)}
+	}
+
+	return tasks
+}
+
+func newPipelineService(t *testing.T, repo *batchRepo, provider *mocks.Provider, workers int) *GeocodingService {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+
+	return NewGeocodingServie(logger, repo, provider, "test-provider", metrics, workers, time.Second, "",
+		WithPipeline(1))
+}
+
+func TestProcessPipeline(t *testing.T) {
+	coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	t.Run("fetches batches until the backlog is drained", func(t *testing.T) {
+		repo := &batchRepo{
+			Interface: mocks.NewInterface(t),
+			batches: [][]models.Task{
+				pipelineTasks(1, taskBatchSize), pipelineTasks(101, taskBatchSize), pipelineTasks(201, 30),
+			},
+		}
+		provider := mocks.NewProvider(t)
+		service := newPipelineService(t, repo, provider, 4)
+
+		provider.On("Geocode", mock.Anything, mock.Anything).Return(coords, nil).Times(230)
+		repo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, *coords).Return(nil).Times(230)
+
+		found := service.processPipeline(t.Context(), nil)
+
+		assert.Equal(t, 230, found)
+		assert.Equal(t, 3, repo.fetchCount(), "a short batch must end the poll")
+	})
+
+	t.Run("fetches the next batch while the workers geocode", func(t *testing.T) {
+		secondFetch := make(chan struct{})
+		repo := &batchRepo{
+			Interface: mocks.NewInterface(t),
+			batches:   [][]models.Task{pipelineTasks(1, taskBatchSize), pipelineTasks(101, 1)},
+			onFetch: func(fetches int) {
+				if fetches == 2 {
+					close(secondFetch)
+				}
+			},
+		}
+		provider := mocks.NewProvider(t)
+		service := newPipelineService(t, repo, provider, 2)
+
+		var overlapped bool
+		var once sync.Once
+		provider.On("Geocode", mock.Anything, mock.Anything).Return(coords, nil).Times(101).
+			Run(func(mock.Arguments) {
+				once.Do(func() {
+					select {
+					case <-secondFetch:
+						overlapped = true
+					case <-time.After(time.Second):
+					}
+				})
+			})
+		repo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, *coords).Return(nil).Times(101)
+
+		found := service.processPipeline(t.Context(), nil)
+
+		assert.Equal(t, 101, found)
+		assert.True(t, overlapped, "the second batch must be fetched while the first one is geocoded")
+	})
+
+	t.Run("holds at most the pipeline depth of batches ahead of the workers", func(t *testing.T) {
+		batches := make([][]models.Task, 10)
+		for i := range batches {
+			batches[i] = pipelineTasks(i*taskBatchSize+1, taskBatchSize)
+		}
+		repo := &batchRepo{Interface: mocks.NewInterface(t), batches: batches}
+		provider := mocks.NewProvider(t)
+		service := newPipelineService(t, repo, provider, 2)
+
+		release := make(chan struct{})
+		provider.On("Geocode", mock.Anything, mock.Anything).Return(coords, nil).
+			Run(func(mock.Arguments) { <-release })
+		repo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, *coords).Return(nil)
+
+		done := make(chan int)
+		go func() {
+			done <- service.processPipeline(t.Context(), nil)
+		}()
+
+		// The batch being queued, the batch in the channel and the batch waiting to be sent
+		assert.Eventually(t, func() bool { return repo.fetchCount() == 3 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 3, repo.fetchCount(), "the producer must wait for the workers to catch up")
+
+		close(release)
+		assert.Equal(t, 10*taskBatchSize, <-done)
+	})
+
+	t.Run("processes tasks fetched again only once per poll", func(t *testing.T) {
+		tasks := pipelineTasks(1, taskBatchSize)
+		repo := &batchRepo{Interface: mocks.NewInterface(t), batches: [][]models.Task{tasks, tasks, tasks}}
+		provider := mocks.NewProvider(t)
+		service := newPipelineService(t, repo, provider, 4)
+
+		provider.On("Geocode", mock.Anything, mock.Anything).Return(coords, nil).Times(taskBatchSize)
+		repo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, *coords).Return(nil).Times(taskBatchSize)
+
+		found := service.processPipeline(t.Context(), nil)
+
+		assert.Equal(t, taskBatchSize, found)
+		assert.Equal(t, 2, repo.fetchCount())
+	})
+
+	t.Run("stops fetching once stopped", func(t *testing.T) {
+		repo := &batchRepo{Interface: mocks.NewInterface(t), batches: [][]models.Task{pipelineTasks(1, 1)}}
+		service := newPipelineService(t, repo, mocks.NewProvider(t), 2)
+
+		stop := make(chan struct{})
+		close(stop)
+
+		assert.Zero(t, service.processPipeline(t.Context(), stop))
+		assert.Zero(t, repo.fetchCount())
+	})
+}
+
+func TestPoll_Pipeline(t *testing.T) {
+	repo := &batchRepo{
+		Interface: mocks.NewInterface(t),
+		batches:   [][]models.Task{pipelineTasks(1, taskBatchSize), pipelineTasks(101, 5)},
+	}
+	provider := mocks.NewProvider(t)
+	service := newPipelineService(t, repo, provider, 2)
+	coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	repo.On("CountPendingTasks", mock.Anything).Return(105, nil).Once()
+	provider.On("Geocode", mock.Anything, mock.Anything).Return(coords, nil).Times(105)
+	repo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, *coords).Return(nil).Times(105)
+
+	assert.Equal(t, 105, service.poll(t.Context(), nil))
+	assert.Equal(t, 2, repo.fetchCount())
+}
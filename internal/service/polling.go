@@ -0,0 +1,73 @@
+package service
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// pollStats counts the outcomes of the task groups of the current poll, so that the poll interval
+// can be adapted to them.
+type pollStats struct {
+	geocoded       atomic.Int64 // geocoded counts the groups geocoded by the provider or the cache
+	providerErrors atomic.Int64 // providerErrors counts the groups failed by the provider rather than their address
+}
+
+// record counts the outcome err of the provider call of a task group.
+func (ps *pollStats) record(err error) {
+	switch {
+	case err == nil:
+		ps.geocoded.Add(1)
+	case providerFailed(err):
+		ps.providerErrors.Add(1)
+	}
+}
+
+// reset clears the counts before a poll.
+func (ps *pollStats) reset() {
+	ps.geocoded.Store(0)
+	ps.providerErrors.Store(0)
+}
+
+// providerFailed reports whether err tells that the provider, rather than the address, is failing:
+// an overload, a network error, a request timeout or a rejected API key.
+func providerFailed(err error) bool {
+	if isOverload(err) {
+		return true
+	}
+
+	switch classifyError(err) {
+	case errorClassTimeout, errorClassNetwork, errorClassUnauthorized:
+		return true
+	default:
+		return false
+	}
+}
+
+// currentInterval returns the interval between poll starts, adapted to the recent polls if adaptive polling
+// is enabled with WithAdaptivePolling.
+func (gs *GeocodingService) currentInterval() time.Duration {
+	if gs.interval > 0 {
+		return gs.interval
+	}
+
+	return gs.pollInterval
+}
+
+// adaptInterval adapts the poll interval to the poll that found tasks tasks: the interval is halved, down to
+// the floor, when the provider geocoded some of them, and doubled, up to the ceiling, when there were none or
+// the provider failed all of them. Polls whose tasks only failed for their addresses leave it as is.
+// Without adaptive polling it does nothing.
+func (gs *GeocodingService) adaptInterval(tasks int) {
+	if gs.minInterval <= 0 && gs.maxInterval <= 0 {
+		return
+	}
+
+	interval := gs.currentInterval()
+	switch {
+	case tasks == 0, gs.stats.geocoded.Load() == 0 && gs.stats.providerErrors.Load() > 0:
+		interval = min(interval*2, gs.maxInterval)
+	case gs.stats.geocoded.Load() > 0:
+		interval = max(interval/2, gs.minInterval)
+	}
+	gs.interval = interval
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdaptInterval(t *testing.T) {
+	// poll records the outcomes of the groups of a poll that found tasks tasks and adapts the interval to them
+	poll := func(service *GeocodingService, tasks int, outcomes ...error) {
+		service.stats.reset()
+		for _, err := range outcomes {
+			service.stats.record(err)
+		}
+		service.adaptInterval(tasks)
+	}
+
+	t.Run("polls finding work shrink the interval down to the floor", func(t *testing.T) {
+		service := &GeocodingService{pollInterval: 8 * time.Minute}
+		WithAdaptivePolling(time.Minute, time.Hour)(service)
+
+		for _, expected := range []time.Duration{4 * time.Minute, 2 * time.Minute, time.Minute, time.Minute} {
+			poll(service, 10, nil, geocoding.ErrEmptyResponse)
+			assert.Equal(t, expected, service.currentInterval())
+		}
+	})
+
+	t.Run("empty polls grow the interval up to the ceiling", func(t *testing.T) {
+		service := &GeocodingService{pollInterval: 20 * time.Minute}
+		WithAdaptivePolling(time.Minute, time.Hour)(service)
+
+		for _, expected := range []time.Duration{40 * time.Minute, time.Hour, time.Hour} {
+			poll(service, 0)
+			assert.Equal(t, expected, service.currentInterval())
+		}
+	})
+
+	t.Run("a failing provider grows the interval", func(t *testing.T) {
+		service := &GeocodingService{pollInterval: 10 * time.Minute}
+		WithAdaptivePolling(time.Minute, time.Hour)(service)
+
+		poll(service, 3, &geocoding.RateLimitError{Provider: "nominatim"}, errNetworkTest{},
+			geocoding.ErrEmptyResponse)
+
+		assert.Equal(t, 20*time.Minute, service.currentInterval())
+	})
+
+	t.Run("address failures leave the interval as is", func(t *testing.T) {
+		service := &GeocodingService{pollInterval: 10 * time.Minute}
+		WithAdaptivePolling(time.Minute, time.Hour)(service)
+
+		poll(service, 2, geocoding.ErrEmptyResponse, errOutsideServiceArea)
+
+		assert.Equal(t, 10*time.Minute, service.currentInterval())
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		service := &GeocodingService{pollInterval: 10 * time.Minute}
+
+		poll(service, 0)
+
+		assert.Equal(t, 10*time.Minute, service.currentInterval())
+	})
+}
+
+// errNetworkTest is a network error, classified as such by classifyError.
+type errNetworkTest struct{}
+
+func (errNetworkTest) Error() string   { return "connection refused" }
+func (errNetworkTest) Timeout() bool   { return false }
+func (errNetworkTest) Temporary() bool { return false }
+
+func TestPoll_AdaptsInterval(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 10*time.Minute, "",
+		WithAdaptivePolling(time.Minute, time.Hour),
+	)
+	coords := &models.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+
+	mockRepo.On("CountPendingTasks", ctx).Return(1, nil)
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(coords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, *coords).Return(nil).Once()
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{}, nil).Twice()
+
+	// A poll geocoding a task shrinks the interval, and empty polls grow it again
+	service.adaptInterval(service.poll(ctx, nil))
+	assert.Equal(t, 5*time.Minute, service.currentInterval())
+
+	service.adaptInterval(service.poll(ctx, nil))
+	service.adaptInterval(service.poll(ctx, nil))
+	assert.Equal(t, 20*time.Minute, service.currentInterval())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPoll_PollTimeout(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	audit := &recordingAuditLogger{}
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 25*time.Millisecond, "",
+		WithPollTimeout(2),
+		WithAuditLogger(audit),
+	)
+
+	mockRepo.On("CountPendingTasks", mock.Anything).Return(1, nil).Once()
+	mockRepo.On("FetchTasksForGeocoding", mock.Anything, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+	// The provider hangs far longer than the poll timeout unless its context is done
+	mockProvider.On("Geocode", mock.Anything, "Kyiv").Run(func(args mock.Arguments) {
+		select {
+		case <-args.Get(0).(context.Context).Done():
+		case <-time.After(5 * time.Second):
+		}
+	}).Return(nil, context.DeadlineExceeded).Once()
+
+	start := time.Now()
+	found := service.poll(t.Context(), nil)
+
+	assert.Equal(t, 1, found)
+	assert.Less(t, time.Since(start), 5*time.Second, "the poll must be canceled at the deadline")
+	mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+	assert.InDelta(t, 1, counterValue(t, metrics.PollTimeouts), 0)
+	assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("timeout", "test-provider")), 0)
+	assert.InDelta(t, 0, counterValue(t, metrics.APIErrors.WithLabelValues(errorClassTimeout)), 0)
+	if assert.Len(t, audit.records, 1) {
+		assert.Equal(t, AuditStatusTimeout, audit.records[0].Status)
+		assert.Contains(t, audit.records[0].Error, errPollTimeout.Error())
+	}
+}
+
+func TestCurrentPollTimeout(t *testing.T) {
+	service := &GeocodingService{pollInterval: 10 * time.Minute}
+	assert.Zero(t, service.currentPollTimeout(), "the poll timeout is disabled by default")
+
+	WithPollTimeout(3)(service)
+	assert.Equal(t, 30*time.Minute, service.currentPollTimeout())
+
+	// A shorter adaptive interval doesn't shorten the timeout, a longer one extends it
+	service.interval = time.Minute
+	assert.Equal(t, 30*time.Minute, service.currentPollTimeout())
+	service.interval = time.Hour
+	assert.Equal(t, 3*time.Hour, service.currentPollTimeout())
+}
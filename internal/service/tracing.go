@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope name of the service spans.
+const tracerName = "github.com/UnknownOlympus/atlas/internal/service"
+
+// Span attribute keys.
+const (
+	attrTasks         = "atlas.tasks"          // number of tasks fetched in a batch
+	attrJobs          = "atlas.jobs"           // number of distinct addresses in a batch
+	attrWorker        = "atlas.worker"         // index of the worker processing a task group
+	attrTaskIDs       = "atlas.task.ids"       // IDs of the tasks sharing the geocoded address
+	attrProvider      = "atlas.provider"       // name of the geocoding provider
+	attrAddressLength = "atlas.address.length" // length of the address sent to the provider, in characters
+	attrFallbackLevel = "atlas.fallback_level" // address fallback level that matched (0 is the full address)
+)
+
+// startSpan starts a span with the given attributes as a child of the span in ctx.
+// If tracing is disabled, it returns ctx unchanged and a no-op span.
+func (gs *GeocodingService) startSpan(
+	ctx context.Context,
+	name string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if gs.tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	return gs.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// recordSpanError records err on the span and marks the span as failed.
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// taskIDs returns the IDs of the given tasks.
+func taskIDs(tasks []models.Task) []int {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	return ids
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestProcessTask_Tracing(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	recorder := tracetest.NewSpanRecorder()
+	service := NewGeocodingServie(
+		logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "",
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+	)
+
+	sampleTasks := []models.Task{{ID: 1, Address: "Київ"}, {ID: 2, Address: "київ"}, {ID: 3, Address: "Nowhere"}}
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52, FallbackLevel: 1}
+	geocodeErr := errors.New("geocoding failed")
+
+	mockRepo.On("FetchTasksForGeocoding", mock.Anything, 100).Return(sampleTasks, nil).Once()
+	mockProvider.On("Geocode", mock.Anything, "Київ").Return(sampleCoords, nil).Once()
+	mockProvider.On("Geocode", mock.Anything, "Nowhere").Return(nil, geocodeErr).Once()
+	mockRepo.On("UpdateTaskCoordinates", mock.Anything, mock.Anything, *sampleCoords).Return(nil).Twice()
+	mockRepo.On("IncrementFailureCount", mock.Anything, 3, geocodeError(errorClassOther, geocodeErr)).Return(nil).Once()
+
+	service.processTask(t.Context())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 5, "expected one batch span and a worker and provider span per address")
+
+	byName := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+	require.Len(t, byName["GeocodingService.processTask"], 1)
+	require.Len(t, byName["GeocodingService.worker"], 2)
+	require.Len(t, byName["Provider.Geocode"], 2)
+
+	root := byName["GeocodingService.processTask"][0]
+	assert.Contains(t, root.Attributes(), attribute.Int(attrTasks, 3))
+	assert.Contains(t, root.Attributes(), attribute.Int(attrJobs, 2))
+
+	// Worker spans are children of the batch span, provider spans are children of a worker span
+	workers := make(map[string]sdktrace.ReadOnlySpan)
+	for _, worker := range byName["GeocodingService.worker"] {
+		assert.Equal(t, root.SpanContext().SpanID(), worker.Parent().SpanID())
+		workers[worker.SpanContext().SpanID().String()] = worker
+	}
+
+	for _, call := range byName["Provider.Geocode"] {
+		worker, ok := workers[call.Parent().SpanID().String()]
+		require.True(t, ok, "provider span must be a child of a worker span")
+		assert.Equal(t, root.SpanContext().TraceID(), call.SpanContext().TraceID())
+		assert.Contains(t, call.Attributes(), attribute.String(attrProvider, "test-provider"))
+
+		if call.Status().Code == codes.Error {
+			assert.Contains(t, call.Attributes(), attribute.Int(attrAddressLength, len("Nowhere")))
+			assert.Contains(t, worker.Attributes(), attribute.IntSlice(attrTaskIDs, []int{3}))
+			continue
+		}
+
+		assert.Contains(t, call.Attributes(), attribute.Int(attrAddressLength, 4))
+		assert.Contains(t, call.Attributes(), attribute.Int(attrFallbackLevel, 1))
+		assert.Contains(t, worker.Attributes(), attribute.IntSlice(attrTaskIDs, []int{1, 2}))
+	}
+}
+
+func TestProcessTask_TracingDisabled(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	mockProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 1, 1*time.Second, "")
+
+	// Without a tracer provider the context reaches the provider and repository unchanged
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+	mockProvider.On("Geocode", ctx, "Kyiv").Return(&models.Coordinates{Latitude: 50.45}, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 1, models.Coordinates{Latitude: 50.45}).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	mockRepo.AssertExpectations(t)
+	mockProvider.AssertExpectations(t)
+}
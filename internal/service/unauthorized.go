@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+)
+
+// abortOnUnauthorized aborts the current poll if fail fast is enabled with WithFailFastUnauthorized and the
+// named provider rejected the API key with err: the tasks of the group are left for the next poll without
+// counting an attempt, the provider is reported unhealthy and the groups routed to it afterwards are skipped
+// by providerAborted. Groups routed to other providers are still geocoded. It reports whether the group was
+// left as is.
+func (gs *GeocodingService) abortOnUnauthorized(
+	ctx context.Context,
+	idx int,
+	group taskGroup,
+	providerName string,
+	err error,
+) bool {
+	if !gs.failFast || classifyError(err) != errorClassUnauthorized {
+		return false
+	}
+
+	gs.metrics.ProviderUnhealthy.WithLabelValues(providerName).Set(1)
+	if _, loaded := gs.unauthorized.LoadOrStore(providerName, struct{}{}); !loaded {
+		gs.log.ErrorContext(ctx, "Geocoding provider rejected the API key, aborting its tasks of the poll without "+
+			"counting attempts until the key is fixed", "provider", providerName, "error", err)
+	}
+	gs.log.DebugContext(ctx, "Poll aborted for the provider, tasks are left for the next poll", "worker", idx,
+		"provider", providerName, "tasks", len(group.tasks))
+
+	return true
+}
+
+// providerAborted reports whether the named provider rejected the API key earlier in the current poll, in which
+// case the remaining task groups routed to it are left for the next poll. It is always false unless fail fast
+// is enabled.
+func (gs *GeocodingService) providerAborted(providerName string) bool {
+	_, aborted := gs.unauthorized.Load(providerName)
+
+	return aborted
+}
+
+// defaultProviderAborted reports whether the default provider rejected the API key earlier in the current poll,
+// so that a pipelined poll stops fetching tasks it would mostly leave for the next poll.
+func (gs *GeocodingService) defaultProviderAborted() bool {
+	return gs.providerAborted(gs.providers.Load().name)
+}
+
+// markProviderHealthy clears the unhealthy state of the named provider after it geocoded an address,
+// if fail fast is enabled.
+func (gs *GeocodingService) markProviderHealthy(providerName string) {
+	if gs.failFast {
+		gs.metrics.ProviderUnhealthy.WithLabelValues(providerName).Set(0)
+	}
+}
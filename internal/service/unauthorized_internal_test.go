@@ -0,0 +1,144 @@
+package service
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/geocoding"
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProcessTask_FailFastUnauthorized(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	sampleTasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Lviv"}, {ID: 3, Address: "Odesa"}}
+
+	newService := func(t *testing.T, failFast bool) (
+		*GeocodingService, *mocks.Interface, *mocks.Provider, *metrics.Metrics,
+	) {
+		t.Helper()
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "visicom", metrics, 1, time.Second, "",
+			WithFailFastUnauthorized(failFast))
+
+		return service, mockRepo, mockProvider, metrics
+	}
+
+	t.Run("a rejected API key aborts the batch without counting attempts", func(t *testing.T) {
+		service, mockRepo, mockProvider, metrics := newService(t, true)
+		ctx := t.Context()
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrVisicomUnathorized).Once()
+
+		service.processTask(ctx)
+
+		mockProvider.AssertNumberOfCalls(t, "Geocode", 1)
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 1, gaugeValue(t, metrics.ProviderUnhealthy.WithLabelValues("visicom")), 0)
+		assert.Zero(t, counterValue(t, metrics.TaskProcessed.WithLabelValues("failure", "visicom")))
+	})
+
+	t.Run("other errors don't abort the batch", func(t *testing.T) {
+		service, mockRepo, mockProvider, metrics := newService(t, true)
+		ctx := t.Context()
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks[:2], nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrServerError).Once()
+		mockProvider.On("Geocode", ctx, "Lviv").Return(nil, geocoding.ErrServerError).Once()
+		mockRepo.On("IncrementFailureCount", ctx, mock.Anything, newGeocodeError(geocoding.ErrServerError)).
+			Return(nil).Twice()
+
+		service.processTask(ctx)
+
+		assert.Zero(t, gaugeValue(t, metrics.ProviderUnhealthy.WithLabelValues("visicom")))
+	})
+
+	t.Run("disabled, a rejected API key counts an attempt for every task", func(t *testing.T) {
+		service, mockRepo, mockProvider, _ := newService(t, false)
+		ctx := t.Context()
+
+		unauthorized := geocodeError(errorClassUnauthorized, geocoding.ErrVisicomUnathorized)
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks, nil).Once()
+		mockProvider.On("Geocode", ctx, mock.Anything).Return(nil, geocoding.ErrVisicomUnathorized).Times(3)
+		mockRepo.On("IncrementFailureCount", ctx, mock.Anything, unauthorized).Return(nil).Times(3)
+
+		service.processTask(ctx)
+	})
+
+	t.Run("the next poll retries and clears the unhealthy gauge", func(t *testing.T) {
+		service, mockRepo, mockProvider, metrics := newService(t, true)
+		ctx := t.Context()
+		coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+		mockRepo.On("CountPendingTasks", ctx).Return(1, nil).Twice()
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(sampleTasks[:1], nil).Twice()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrVisicomUnathorized).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(coords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *coords).Return(nil).Once()
+
+		service.poll(ctx, nil)
+		assert.InDelta(t, 1, gaugeValue(t, metrics.ProviderUnhealthy.WithLabelValues("visicom")), 0)
+
+		service.poll(ctx, nil)
+		assert.Zero(t, gaugeValue(t, metrics.ProviderUnhealthy.WithLabelValues("visicom")))
+	})
+}
+
+func TestProcessTask_FailFastUnauthorizedRoutedProvider(t *testing.T) {
+	mockRepo := mocks.NewInterface(t)
+	defaultProvider := mocks.NewProvider(t)
+	hereProvider := mocks.NewProvider(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := metrics.NewMetrics(prometheus.NewRegistry())
+	ctx := t.Context()
+	service := NewGeocodingServie(logger, mockRepo, defaultProvider, "visicom", metrics, 1, time.Second, "",
+		WithFailFastUnauthorized(true),
+		WithProviders(map[string]geocoding.Provider{"here": hereProvider}))
+	coords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	tasks := []models.Task{
+		{ID: 1, Address: "Kyiv", PreferredProvider: "here"},
+		{ID: 2, Address: "Lviv", PreferredProvider: "here"},
+		{ID: 3, Address: "Odesa"},
+		{ID: 4, Address: "Dnipro"},
+	}
+	mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(tasks, nil).Once()
+	hereProvider.On("Geocode", ctx, "Kyiv").Return(nil, geocoding.ErrHereUnauthorized).Once()
+	defaultProvider.On("Geocode", ctx, "Odesa").Return(coords, nil).Once()
+	defaultProvider.On("Geocode", ctx, "Dnipro").Return(coords, nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 3, *coords).Return(nil).Once()
+	mockRepo.On("UpdateTaskCoordinates", ctx, 4, *coords).Return(nil).Once()
+
+	service.processTask(ctx)
+
+	hereProvider.AssertNumberOfCalls(t, "Geocode", 1)
+	mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+	assert.InDelta(t, 1, gaugeValue(t, metrics.ProviderUnhealthy.WithLabelValues("here")), 0)
+	assert.Zero(t, gaugeValue(t, metrics.ProviderUnhealthy.WithLabelValues("visicom")))
+}
+
+func TestProcessPipeline_FailFastUnauthorized(t *testing.T) {
+	batches := make([][]models.Task, 10)
+	for i := range batches {
+		batches[i] = pipelineTasks(i*taskBatchSize+1, taskBatchSize)
+	}
+	repo := &batchRepo{Interface: mocks.NewInterface(t), batches: batches}
+	provider := mocks.NewProvider(t)
+	service := newPipelineService(t, repo, provider, 1)
+	service.failFast = true
+
+	provider.On("Geocode", mock.Anything, mock.Anything).Return(nil, geocoding.ErrVisicomUnathorized).Once()
+
+	service.processPipeline(t.Context(), nil)
+
+	assert.Less(t, repo.fetchCount(), len(batches), "an aborted poll must stop fetching")
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/UnknownOlympus/atlas/internal/models"
+)
+
+// What3WordsConverter converts coordinates into the what3words address of the square containing them,
+// e.g. geocoding.What3WordsClient.
+type What3WordsConverter interface {
+	ConvertTo3wa(ctx context.Context, coords models.Coordinates) (string, error)
+}
+
+// storeWhat3Words converts the coordinates geocoded for a group into their what3words address, once for the
+// whole group, and stores it for the tasks identified by taskIDs, if enabled with WithWhat3Words.
+// The 3 word address is only an extra for field apps, so a failure is logged and counted, and leaves
+// the stored coordinates in place.
+func (gs *GeocodingService) storeWhat3Words(ctx context.Context, idx int, taskIDs []int, coords models.Coordinates) {
+	if gs.what3words == nil {
+		return
+	}
+
+	words, err := gs.what3words.ConvertTo3wa(ctx, coords)
+	if err != nil {
+		gs.metrics.What3WordsErrors.Inc()
+		gs.log.WarnContext(ctx, "Failed to convert coordinates to a what3words address",
+			"worker", idx, "tasks", taskIDs, "error", err)
+		return
+	}
+
+	if err = gs.repo.UpdateTaskWhat3Words(ctx, taskIDs, words); err != nil {
+		gs.metrics.What3WordsErrors.Inc()
+		gs.log.WarnContext(ctx, "Failed to store what3words address", "worker", idx, "tasks", taskIDs,
+			"error", err)
+		return
+	}
+
+	gs.log.DebugContext(ctx, "Stored what3words address", "worker", idx, "tasks", taskIDs, "words", words)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/metrics"
+	"github.com/UnknownOlympus/atlas/internal/models"
+	"github.com/UnknownOlympus/atlas/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeWhat3Words is a What3WordsConverter returning words or err, recording the converted coordinates.
+type fakeWhat3Words struct {
+	words string
+	err   error
+
+	mu        sync.Mutex
+	converted []models.Coordinates
+}
+
+func (fw *fakeWhat3Words) ConvertTo3wa(_ context.Context, coords models.Coordinates) (string, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	fw.converted = append(fw.converted, coords)
+
+	return fw.words, fw.err
+}
+
+func TestProcessTask_What3Words(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	sampleCoords := &models.Coordinates{Latitude: 50.45, Longitude: 30.52}
+
+	newService := func(t *testing.T, converter What3WordsConverter, opts ...Option) (
+		*GeocodingService, *mocks.Interface, *mocks.Provider, *metrics.Metrics,
+	) {
+		t.Helper()
+		mockRepo := mocks.NewInterface(t)
+		mockProvider := mocks.NewProvider(t)
+		metrics := metrics.NewMetrics(prometheus.NewRegistry())
+		opts = append(opts, WithWhat3Words(converter))
+		service := NewGeocodingServie(logger, mockRepo, mockProvider, "test-provider", metrics, 2, time.Second, "",
+			opts...)
+
+		return service, mockRepo, mockProvider, metrics
+	}
+
+	t.Run("stores the 3 word address of every task of a group", func(t *testing.T) {
+		converter := &fakeWhat3Words{words: "filled.count.soap"}
+		service, mockRepo, mockProvider, metrics := newService(t, converter)
+		ctx := t.Context()
+
+		tasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "kyiv"}}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(tasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, mock.Anything, *sampleCoords).Return(nil).Twice()
+		mockRepo.On("UpdateTaskWhat3Words", ctx, []int{1, 2}, "filled.count.soap").Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.Equal(t, []models.Coordinates{*sampleCoords}, converter.converted, "a group is converted once")
+		assert.Zero(t, counterValue(t, metrics.What3WordsErrors))
+	})
+
+	t.Run("a failed conversion keeps the coordinates", func(t *testing.T) {
+		converter := &fakeWhat3Words{err: errors.New("what3words API returned status 402")}
+		service, mockRepo, mockProvider, metrics := newService(t, converter)
+		ctx := t.Context()
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		mockRepo.AssertNotCalled(t, "IncrementFailureCount", mock.Anything, mock.Anything, mock.Anything)
+		assert.InDelta(t, 1, counterValue(t, metrics.What3WordsErrors), 0)
+		assert.InDelta(t, 1, counterValue(t, metrics.TaskProcessed.WithLabelValues("success", "test-provider")), 0)
+	})
+
+	t.Run("a failed update of the 3 word address is counted", func(t *testing.T) {
+		converter := &fakeWhat3Words{words: "filled.count.soap"}
+		service, mockRepo, mockProvider, metrics := newService(t, converter)
+		ctx := t.Context()
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(nil).Once()
+		mockRepo.On("UpdateTaskWhat3Words", ctx, []int{1}, "filled.count.soap").Return(assert.AnError).Once()
+
+		service.processTask(ctx)
+
+		assert.InDelta(t, 1, counterValue(t, metrics.What3WordsErrors), 0)
+	})
+
+	t.Run("tasks without stored coordinates aren't converted", func(t *testing.T) {
+		converter := &fakeWhat3Words{words: "filled.count.soap"}
+		service, mockRepo, mockProvider, _ := newService(t, converter)
+		ctx := t.Context()
+
+		geocodeErr := errors.New("geocoding failed")
+		tasks := []models.Task{{ID: 1, Address: "Kyiv"}, {ID: 2, Address: "Invalid Address"}}
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return(tasks, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+		mockProvider.On("Geocode", ctx, "Invalid Address").Return(nil, geocodeErr).Once()
+		mockRepo.On("UpdateTaskCoordinates", ctx, 1, *sampleCoords).Return(assert.AnError).Once()
+		mockRepo.On("IncrementFailureCount", ctx, 2, geocodeError(errorClassOther, geocodeErr)).Return(nil).Once()
+
+		service.processTask(ctx)
+
+		assert.Empty(t, converter.converted)
+	})
+
+	t.Run("dry run doesn't convert", func(t *testing.T) {
+		converter := &fakeWhat3Words{words: "filled.count.soap"}
+		service, mockRepo, mockProvider, _ := newService(t, converter, WithDryRun(true))
+		ctx := t.Context()
+
+		mockRepo.On("FetchTasksForGeocoding", ctx, 100).Return([]models.Task{{ID: 1, Address: "Kyiv"}}, nil).Once()
+		mockProvider.On("Geocode", ctx, "Kyiv").Return(sampleCoords, nil).Once()
+
+		service.processTask(ctx)
+
+		assert.Empty(t, converter.converted)
+	})
+}
@@ -0,0 +1,29 @@
+// Package version reports the build that is running. Version and Commit are set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/UnknownOlympus/atlas/internal/version.Version=v1.2.0 \
+//	  -X github.com/UnknownOlympus/atlas/internal/version.Commit=$(git rev-parse --short HEAD)" ./cmd
+package version
+
+import "runtime"
+
+// Version is the release version of the build, set with -ldflags.
+var Version = "dev"
+
+// Commit is the VCS revision of the build, set with -ldflags.
+var Commit = "unknown"
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`    // Version is the release version of the build
+	Commit    string `json:"commit"`     // Commit is the VCS revision of the build
+	GoVersion string `json:"go_version"` // GoVersion is the Go toolchain the binary was built with
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,247 @@
+// Package webhook notifies an HTTP endpoint of completed geocoding tasks, so that consumers can be pushed
+// the outcome of a task instead of polling the database for it.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SignatureHeader is the header holding the signature of a payload, "sha256=" followed by the hex-encoded
+// HMAC-SHA256 of the request body keyed with the shared secret.
+const SignatureHeader = "X-Atlas-Signature"
+
+// Statuses of a completed task.
+const (
+	StatusGeocoded = "geocoded"        // StatusGeocoded is a task whose coordinates were stored.
+	StatusNotFound = "not_found"       // StatusNotFound is a task the provider found no match for.
+	StatusInvalid  = "invalid_address" // StatusInvalid is a task skipped for a blank address.
+)
+
+// Outcomes of a notification, used as the "outcome" label of the webhook notifications metric.
+const (
+	OutcomeDelivered = "delivered" // OutcomeDelivered is a notification the endpoint accepted.
+	OutcomeFailed    = "failed"    // OutcomeFailed is a notification the endpoint didn't accept after every retry.
+	OutcomeDropped   = "dropped"   // OutcomeDropped is a notification dropped because the queue was full.
+)
+
+// Defaults used when the Config leaves them unset.
+const (
+	DefaultQueueSize = 1000
+	DefaultBackoff   = time.Second
+	DefaultTimeout   = 10 * time.Second
+)
+
+// Event is the JSON payload posted for a completed task.
+type Event struct {
+	TaskID    int       `json:"task_id"`             // TaskID is the identifier of the task.
+	Status    string    `json:"status"`              // Status is one of the Status* constants.
+	Provider  string    `json:"provider,omitempty"`  // Provider is the name of the provider, if one was called.
+	Latitude  *float64  `json:"latitude,omitempty"`  // Latitude is the stored latitude, nil unless geocoded.
+	Longitude *float64  `json:"longitude,omitempty"` // Longitude is the stored longitude, nil unless geocoded.
+	Precision string    `json:"precision,omitempty"` // Precision is the match precision, if the provider reported one.
+	Error     string    `json:"error,omitempty"`     // Error is the failure reason, empty if geocoded.
+	Time      time.Time `json:"time"`                // Time is when the task completed.
+}
+
+// OutcomeObserver is called with the outcome of every notification.
+type OutcomeObserver func(outcome string)
+
+// Config configures a Notifier.
+type Config struct {
+	URL    string // URL is the endpoint events are posted to.
+	Secret string // Secret is the key the payloads are signed with.
+
+	// QueueSize is the number of events waiting to be posted, beyond which new events are dropped.
+	// Zero uses DefaultQueueSize.
+	QueueSize int
+
+	// Retries is the number of times a notification the endpoint didn't accept is posted again,
+	// waiting Backoff before the first retry and doubling the delay after each one. Zero Backoff uses
+	// DefaultBackoff.
+	Retries int
+	Backoff time.Duration
+
+	// Timeout is the deadline of a single post, zero uses DefaultTimeout.
+	Timeout time.Duration
+
+	// HTTPClient sends the posts, nil uses a client without a timeout of its own.
+	HTTPClient *http.Client
+
+	// Logger logs failed notifications, nil discards them.
+	Logger *slog.Logger
+
+	// OnOutcome, if set, is called with the outcome of every notification.
+	OnOutcome OutcomeObserver
+}
+
+// Notifier posts events to a webhook endpoint in the background. Notify only queues the event, so a slow
+// or unavailable endpoint never holds up geocoding: events beyond the queue size are dropped instead.
+// Events are posted one at a time, in the order they were queued, by Run.
+type Notifier struct {
+	cfg    Config
+	queue  chan Event    // queue holds the events waiting to be posted
+	closed chan struct{} // closed is closed by Close, once no more events are queued
+	once   sync.Once     // once guards closing closed
+}
+
+// NewNotifier creates a Notifier posting events to the endpoint of cfg. Run must be called to post them.
+func NewNotifier(cfg Config) *Notifier {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.DiscardHandler)
+	}
+
+	return &Notifier{
+		cfg:    cfg,
+		queue:  make(chan Event, cfg.QueueSize),
+		closed: make(chan struct{}),
+	}
+}
+
+// Notify queues the event to be posted without blocking. The event is dropped if the queue is full.
+func (n *Notifier) Notify(event Event) {
+	select {
+	case n.queue <- event:
+	default:
+		n.cfg.Logger.Warn("Webhook queue full, dropping notification", "task", event.TaskID, "status", event.Status)
+		n.observe(OutcomeDropped)
+	}
+}
+
+// Close makes Run return once the queued events are posted. Events notified after Run returns are never posted.
+func (n *Notifier) Close() {
+	n.once.Do(func() { close(n.closed) })
+}
+
+// Run posts the queued events until Close is called and the queue is drained, or ctx is canceled,
+// which abandons the queued events.
+func (n *Notifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-n.queue:
+			n.deliver(ctx, event)
+		case <-n.closed:
+			for ctx.Err() == nil {
+				select {
+				case event := <-n.queue:
+					n.deliver(ctx, event)
+				default:
+					return
+				}
+			}
+			return
+		}
+	}
+}
+
+// deliver posts the event, retrying it while the endpoint doesn't accept it.
+func (n *Notifier) deliver(ctx context.Context, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.cfg.Logger.ErrorContext(ctx, "Failed to encode webhook notification", "task", event.TaskID, "error", err)
+		n.observe(OutcomeFailed)
+		return
+	}
+
+	backoff := n.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		retryable, postErr := n.post(ctx, body)
+		if postErr == nil {
+			n.observe(OutcomeDelivered)
+			return
+		}
+		if !retryable || attempt == n.cfg.Retries || ctx.Err() != nil {
+			n.cfg.Logger.WarnContext(ctx, "Failed to deliver webhook notification",
+				"task", event.TaskID, "status", event.Status, "attempts", attempt+1, "error", postErr)
+			n.observe(OutcomeFailed)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the signed payload once. It returns an error unless the endpoint responded with a 2xx status,
+// and whether the post may succeed if retried: on a network error, a 429 or a 5xx response.
+func (n *Notifier) post(ctx context.Context, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(n.cfg.Secret, body))
+
+	resp, err := n.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to post webhook notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+
+	return retryable, fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+}
+
+// observe reports the outcome of a notification to the observer, if any.
+func (n *Notifier) observe(outcome string) {
+	if n.cfg.OnOutcome != nil {
+		n.cfg.OnOutcome(outcome)
+	}
+}
+
+// ErrInvalidSignature is returned by Verify if the signature doesn't match the payload.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the value of SignatureHeader for the payload body signed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that signature, the value of SignatureHeader, is the signature of body with secret,
+// so that receivers can authenticate the notifications.
+func Verify(secret string, body []byte, signature string) error {
+	if !hmac.Equal([]byte(Sign(secret, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
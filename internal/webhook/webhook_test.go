@@ -0,0 +1,232 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/atlas/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "webhook-secret"
+
+// receiver is a mock webhook endpoint recording the events whose signature is valid.
+// It responds with the statuses in turn, then with 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	events   []webhook.Event
+	posts    int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.posts++
+	body, _ := io.ReadAll(r.Body)
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+		webhook.Verify(secret, body, r.Header.Get(webhook.SignatureHeader)) != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if len(rc.statuses) > 0 {
+		status := rc.statuses[0]
+		rc.statuses = rc.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+
+	var event webhook.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rc.events = append(rc.events, event)
+}
+
+func (rc *receiver) received() ([]webhook.Event, int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return rc.events, rc.posts
+}
+
+// outcomes counts the outcomes reported to a webhook.OutcomeObserver.
+type outcomes struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (o *outcomes) observe(outcome string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.counts == nil {
+		o.counts = make(map[string]int)
+	}
+	o.counts[outcome]++
+}
+
+func (o *outcomes) get() map[string]int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.counts
+}
+
+// deliverAll queues the events and runs the notifier until they are all handled.
+func deliverAll(t *testing.T, notifier *webhook.Notifier, events ...webhook.Event) {
+	t.Helper()
+
+	for _, event := range events {
+		notifier.Notify(event)
+	}
+	notifier.Close()
+	notifier.Run(t.Context())
+}
+
+func TestNotifier(t *testing.T) {
+	lat, lon := 50.45, 30.52
+	completedAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	geocoded := webhook.Event{
+		TaskID: 1, Status: webhook.StatusGeocoded, Provider: "visicom", Latitude: &lat, Longitude: &lon,
+		Precision: "rooftop", Time: completedAt,
+	}
+	notFound := webhook.Event{
+		TaskID: 2, Status: webhook.StatusNotFound, Provider: "visicom", Error: "no match", Time: completedAt,
+	}
+
+	newNotifier := func(t *testing.T, rc *receiver, observed *outcomes, retries int) *webhook.Notifier {
+		t.Helper()
+		server := httptest.NewServer(rc)
+		t.Cleanup(server.Close)
+
+		return webhook.NewNotifier(webhook.Config{
+			URL:       server.URL,
+			Secret:    secret,
+			Retries:   retries,
+			Backoff:   time.Millisecond,
+			OnOutcome: observed.observe,
+		})
+	}
+
+	t.Run("posts the signed payload of every event in order", func(t *testing.T) {
+		rc, observed := &receiver{}, &outcomes{}
+
+		deliverAll(t, newNotifier(t, rc, observed, 0), geocoded, notFound)
+
+		events, posts := rc.received()
+		assert.Equal(t, []webhook.Event{geocoded, notFound}, events)
+		assert.Equal(t, 2, posts)
+		assert.Equal(t, map[string]int{webhook.OutcomeDelivered: 2}, observed.get())
+	})
+
+	t.Run("retries server errors and rate limits", func(t *testing.T) {
+		rc := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests}}
+		observed := &outcomes{}
+
+		deliverAll(t, newNotifier(t, rc, observed, 2), geocoded)
+
+		events, posts := rc.received()
+		assert.Equal(t, []webhook.Event{geocoded}, events)
+		assert.Equal(t, 3, posts)
+		assert.Equal(t, map[string]int{webhook.OutcomeDelivered: 1}, observed.get())
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		rc := &receiver{statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}}
+		observed := &outcomes{}
+
+		deliverAll(t, newNotifier(t, rc, observed, 1), geocoded)
+
+		events, posts := rc.received()
+		assert.Empty(t, events)
+		assert.Equal(t, 2, posts)
+		assert.Equal(t, map[string]int{webhook.OutcomeFailed: 1}, observed.get())
+	})
+
+	t.Run("client errors aren't retried", func(t *testing.T) {
+		rc, observed := &receiver{statuses: []int{http.StatusBadRequest}}, &outcomes{}
+
+		deliverAll(t, newNotifier(t, rc, observed, 3), geocoded, notFound)
+
+		events, posts := rc.received()
+		assert.Equal(t, []webhook.Event{notFound}, events)
+		assert.Equal(t, 2, posts)
+		assert.Equal(t, map[string]int{webhook.OutcomeFailed: 1, webhook.OutcomeDelivered: 1}, observed.get())
+	})
+
+	t.Run("a payload with a wrong signature is rejected", func(t *testing.T) {
+		rc, observed := &receiver{}, &outcomes{}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+		notifier := webhook.NewNotifier(webhook.Config{URL: server.URL, Secret: "other-secret",
+			OnOutcome: observed.observe})
+
+		deliverAll(t, notifier, geocoded)
+
+		events, _ := rc.received()
+		assert.Empty(t, events)
+		assert.Equal(t, map[string]int{webhook.OutcomeFailed: 1}, observed.get())
+	})
+
+	t.Run("events beyond the queue size are dropped", func(t *testing.T) {
+		rc, observed := &receiver{}, &outcomes{}
+		server := httptest.NewServer(rc)
+		defer server.Close()
+		notifier := webhook.NewNotifier(webhook.Config{URL: server.URL, Secret: secret, QueueSize: 1,
+			OnOutcome: observed.observe})
+
+		deliverAll(t, notifier, geocoded, notFound)
+
+		events, _ := rc.received()
+		assert.Equal(t, []webhook.Event{geocoded}, events)
+		assert.Equal(t, map[string]int{webhook.OutcomeDelivered: 1, webhook.OutcomeDropped: 1}, observed.get())
+	})
+
+	t.Run("notify doesn't block on a slow endpoint", func(t *testing.T) {
+		var posts atomic.Int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			posts.Add(1)
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+		notifier := webhook.NewNotifier(webhook.Config{URL: server.URL, Secret: secret, QueueSize: 1})
+		ctx, cancel := context.WithCancel(t.Context())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			notifier.Run(ctx)
+		}()
+
+		notifier.Notify(geocoded)
+		require.Eventually(t, func() bool { return posts.Load() == 1 }, time.Second, time.Millisecond)
+		notifier.Notify(geocoded)
+		notifier.Notify(notFound)
+
+		cancel()
+		<-done
+	})
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"task_id":1,"status":"geocoded"}`)
+	signature := webhook.Sign(secret, body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	require.NoError(t, webhook.Verify(secret, body, signature))
+	require.ErrorIs(t, webhook.Verify("other-secret", body, signature), webhook.ErrInvalidSignature)
+	require.ErrorIs(t, webhook.Verify(secret, []byte(`{"task_id":2}`), signature), webhook.ErrInvalidSignature)
+}
@@ -0,0 +1,3 @@
+-- Stores the match precision reported by the geocoding provider
+-- (rooftop, street, locality, region or approximate).
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS geocoding_precision TEXT;
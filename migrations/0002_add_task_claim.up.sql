@@ -0,0 +1,4 @@
+-- Claim columns used by ATLAS_TASK_LOCK=claim, so that several replicas
+-- don't fetch and geocode the same tasks.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS locked_by TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ;
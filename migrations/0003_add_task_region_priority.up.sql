@@ -0,0 +1,3 @@
+-- Columns used by ATLAS_TASK_REGION and ATLAS_TASK_PRIORITY to filter and prioritize fetched tasks.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS region TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+-- Geocoding results shared by all replicas and kept across restarts, enabled with ATLAS_GEOCODE_CACHE.
+-- Entries are keyed by the SHA-256 of the address sent to the provider.
+CREATE TABLE IF NOT EXISTS geocode_cache (
+    address_hash TEXT PRIMARY KEY,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    geocoding_precision TEXT,
+    provider TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Time of the last failed geocoding attempt, used by ATLAS_MIN_ATTEMPT_INTERVAL
+-- to hold back recently failed tasks until their cooldown has passed.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS last_attempt_at TIMESTAMPTZ;
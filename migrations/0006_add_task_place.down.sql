@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS geocoding_address;
+ALTER TABLE tasks DROP COLUMN IF EXISTS geocoding_place_id;
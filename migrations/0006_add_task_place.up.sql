@@ -0,0 +1,4 @@
+-- Place metadata reported by the geocoding provider (currently Google's place_id and
+-- formatted_address), used to deduplicate places and link them to the provider's map.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS geocoding_place_id TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS geocoding_address TEXT;
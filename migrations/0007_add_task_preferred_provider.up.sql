@@ -0,0 +1,3 @@
+-- Provider a task should be geocoded with, e.g. "here". Read when ATLAS_ROUTED_PROVIDERS is set;
+-- NULL, or a provider that isn't configured, geocodes the task with the default provider.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS preferred_provider TEXT;
@@ -0,0 +1,4 @@
+DELETE FROM geocode_cache WHERE not_found;
+ALTER TABLE geocode_cache ALTER COLUMN longitude SET NOT NULL;
+ALTER TABLE geocode_cache ALTER COLUMN latitude SET NOT NULL;
+ALTER TABLE geocode_cache DROP COLUMN IF EXISTS not_found;
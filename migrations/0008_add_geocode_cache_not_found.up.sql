@@ -0,0 +1,5 @@
+-- Addresses the provider found nothing for, cached without coordinates for ATLAS_GEOCODE_CACHE_NEGATIVE_TTL,
+-- so that un-geocodable addresses don't use up provider quota on every poll.
+ALTER TABLE geocode_cache ADD COLUMN IF NOT EXISTS not_found BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE geocode_cache ALTER COLUMN latitude DROP NOT NULL;
+ALTER TABLE geocode_cache ALTER COLUMN longitude DROP NOT NULL;
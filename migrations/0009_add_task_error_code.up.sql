@@ -0,0 +1,3 @@
+-- Machine-readable class of the last geocoding error, e.g. "unauthorized" or "empty_response",
+-- so that failures can be grouped in SQL; geocoding_error keeps the full message.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS geocoding_error_code TEXT;
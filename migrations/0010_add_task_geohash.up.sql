@@ -0,0 +1,4 @@
+-- Geohash of the task coordinates, stored when ATLAS_GEOHASH_PRECISION is set,
+-- so that tasks near each other can be found by a common prefix.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS geohash TEXT;
+CREATE INDEX IF NOT EXISTS tasks_geohash_idx ON tasks (geohash text_pattern_ops);
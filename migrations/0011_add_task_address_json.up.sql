@@ -0,0 +1,3 @@
+-- Structured address of a task, e.g. {"city": "м. Київ", "street": "вул. Хрещатик", "house_number": "1"}.
+-- Read instead of the address column when ATLAS_ADDRESS_FORMAT is "json".
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS address_json JSONB;
@@ -0,0 +1,4 @@
+-- Time the coordinates of a task were stored, recorded when ATLAS_STALE_AFTER is set,
+-- so that tasks geocoded longer ago than that are geocoded again while the queue is empty.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS geocoded_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS tasks_geocoded_at_idx ON tasks (geocoded_at) WHERE latitude IS NOT NULL;
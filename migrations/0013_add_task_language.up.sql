@@ -0,0 +1,3 @@
+-- Preferred result language of a task, e.g. "ru" or "ru,uk", read when ATLAS_TASK_LANGUAGE is set,
+-- overriding the language configured for the provider. NULL uses the configured language.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS language TEXT;
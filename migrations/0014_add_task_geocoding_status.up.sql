@@ -0,0 +1,4 @@
+-- Geocoding status of a task set by operators, read when ATLAS_TASK_SKIP is set.
+-- Tasks with the status 'skip' are no longer fetched for geocoding, e.g. after a wrong data import,
+-- without deleting them. NULL geocodes the task as usual.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS geocoding_status TEXT;
@@ -0,0 +1,3 @@
+-- what3words address of the task coordinates, e.g. 'filled.count.soap', stored when ATLAS_W3W_ENABLED is set
+-- for field apps that locate places by their 3 word address. NULL until the coordinates are converted.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS w3w TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tasks_location_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS location;
@@ -0,0 +1,5 @@
+-- PostGIS point of the task coordinates, stored when ATLAS_GEOMETRY_COLUMN is set to location,
+-- so that tasks can be queried with spatial functions such as ST_DWithin.
+CREATE EXTENSION IF NOT EXISTS postgis;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS location geography(Point, 4326);
+CREATE INDEX IF NOT EXISTS tasks_location_idx ON tasks USING GIST (location);
@@ -7,6 +7,8 @@ import (
 
 	models "github.com/UnknownOlympus/atlas/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Interface is an autogenerated mock type for the Interface type
@@ -14,6 +16,122 @@ type Interface struct {
 	mock.Mock
 }
 
+// CountPendingStructuredTasks provides a mock function with given fields: ctx
+func (_m *Interface) CountPendingStructuredTasks(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountPendingStructuredTasks")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountPendingTasks provides a mock function with given fields: ctx
+func (_m *Interface) CountPendingTasks(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountPendingTasks")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchStructuredTasksForGeocoding provides a mock function with given fields: ctx, limit
+func (_m *Interface) FetchStructuredTasksForGeocoding(ctx context.Context, limit int) ([]models.Task, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchStructuredTasksForGeocoding")
+	}
+
+	var r0 []models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]models.Task, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.Task); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchStaleTasks provides a mock function with given fields: ctx, olderThan, limit
+func (_m *Interface) FetchStaleTasks(ctx context.Context, olderThan time.Duration, limit int) ([]models.Task, error) {
+	ret := _m.Called(ctx, olderThan, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FetchStaleTasks")
+	}
+
+	var r0 []models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, int) ([]models.Task, error)); ok {
+		return rf(ctx, olderThan, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, int) []models.Task); ok {
+		r0 = rf(ctx, olderThan, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration, int) error); ok {
+		r1 = rf(ctx, olderThan, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FetchTasksForGeocoding provides a mock function with given fields: ctx, limit
 func (_m *Interface) FetchTasksForGeocoding(ctx context.Context, limit int) ([]models.Task, error) {
 	ret := _m.Called(ctx, limit)
@@ -44,17 +162,53 @@ func (_m *Interface) FetchTasksForGeocoding(ctx context.Context, limit int) ([]m
 	return r0, r1
 }
 
-// IncrementFailureCount provides a mock function with given fields: ctx, taskID, errMsg
-func (_m *Interface) IncrementFailureCount(ctx context.Context, taskID int, errMsg string) error {
-	ret := _m.Called(ctx, taskID, errMsg)
+// IncrementFailureCount provides a mock function with given fields: ctx, taskID, failure
+func (_m *Interface) IncrementFailureCount(ctx context.Context, taskID int, failure models.GeocodeError) error {
+	ret := _m.Called(ctx, taskID, failure)
 
 	if len(ret) == 0 {
 		panic("no return value specified for IncrementFailureCount")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
-		r0 = rf(ctx, taskID, errMsg)
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.GeocodeError) error); ok {
+		r0 = rf(ctx, taskID, failure)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkInvalidAddress provides a mock function with given fields: ctx, taskID, reason
+func (_m *Interface) MarkInvalidAddress(ctx context.Context, taskID int, reason models.GeocodeError) error {
+	ret := _m.Called(ctx, taskID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkInvalidAddress")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.GeocodeError) error); ok {
+		r0 = rf(ctx, taskID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkTaskNotFound provides a mock function with given fields: ctx, taskID, failure
+func (_m *Interface) MarkTaskNotFound(ctx context.Context, taskID int, failure models.GeocodeError) error {
+	ret := _m.Called(ctx, taskID, failure)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkTaskNotFound")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.GeocodeError) error); ok {
+		r0 = rf(ctx, taskID, failure)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -62,6 +216,108 @@ func (_m *Interface) IncrementFailureCount(ctx context.Context, taskID int, errM
 	return r0
 }
 
+// PostponeStaleRefresh provides a mock function with given fields: ctx, taskID
+func (_m *Interface) PostponeStaleRefresh(ctx context.Context, taskID int) error {
+	ret := _m.Called(ctx, taskID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PostponeStaleRefresh")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, taskID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetFailedGeocodingAttempts provides a mock function with given fields: ctx
+func (_m *Interface) ResetFailedGeocodingAttempts(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetFailedGeocodingAttempts")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetGeocodingAttempts provides a mock function with given fields: ctx, taskIDs
+func (_m *Interface) ResetGeocodingAttempts(ctx context.Context, taskIDs []int) (int64, error) {
+	ret := _m.Called(ctx, taskIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetGeocodingAttempts")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) (int64, error)); ok {
+		return rf(ctx, taskIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) int64); ok {
+		r0 = rf(ctx, taskIDs)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, taskIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SkipTaskGeocoding provides a mock function with given fields: ctx, taskIDs
+func (_m *Interface) SkipTaskGeocoding(ctx context.Context, taskIDs []int) (int64, error) {
+	ret := _m.Called(ctx, taskIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SkipTaskGeocoding")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) (int64, error)); ok {
+		return rf(ctx, taskIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) int64); ok {
+		r0 = rf(ctx, taskIDs)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, taskIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateTaskCoordinates provides a mock function with given fields: ctx, taskID, coords
 func (_m *Interface) UpdateTaskCoordinates(ctx context.Context, taskID int, coords models.Coordinates) error {
 	ret := _m.Called(ctx, taskID, coords)
@@ -80,6 +336,60 @@ func (_m *Interface) UpdateTaskCoordinates(ctx context.Context, taskID int, coor
 	return r0
 }
 
+// UpdateTaskGeocodeResult provides a mock function with given fields: ctx, taskID, cleanedAddress, result
+func (_m *Interface) UpdateTaskGeocodeResult(ctx context.Context, taskID int, cleanedAddress string, result models.GeocodeResult) error {
+	ret := _m.Called(ctx, taskID, cleanedAddress, result)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTaskGeocodeResult")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, models.GeocodeResult) error); ok {
+		r0 = rf(ctx, taskID, cleanedAddress, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTaskResult provides a mock function with given fields: ctx, taskID, result
+func (_m *Interface) UpdateTaskResult(ctx context.Context, taskID int, result models.GeocodeResult) error {
+	ret := _m.Called(ctx, taskID, result)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTaskResult")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.GeocodeResult) error); ok {
+		r0 = rf(ctx, taskID, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTaskWhat3Words provides a mock function with given fields: ctx, taskIDs, words
+func (_m *Interface) UpdateTaskWhat3Words(ctx context.Context, taskIDs []int, words string) error {
+	ret := _m.Called(ctx, taskIDs, words)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTaskWhat3Words")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, string) error); ok {
+		r0 = rf(ctx, taskIDs, words)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewInterface creates a new instance of Interface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewInterface(t interface {